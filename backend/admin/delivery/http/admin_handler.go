@@ -0,0 +1,303 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v4"
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+	_MyMiddleware "github.com/semka95/shortener/backend/middleware"
+	"github.com/semka95/shortener/backend/web"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+// AdminHandler represent the http handler for admin
+type AdminHandler struct {
+	adminUsecase  domain.AdminUsecase
+	authenticator *auth.Authenticator
+	policy        *auth.Policy
+	validator     *web.AppValidator
+	logger        *zap.Logger
+	tracer        trace.Tracer
+}
+
+// NewAdminHandler will initialize the admin/ resources endpoint
+func NewAdminHandler(as domain.AdminUsecase, authenticator *auth.Authenticator, policy *auth.Policy, v *web.AppValidator, logger *zap.Logger, tracer trace.Tracer) *AdminHandler {
+	return &AdminHandler{
+		adminUsecase:  as,
+		authenticator: authenticator,
+		policy:        policy,
+		validator:     v,
+		logger:        logger,
+		tracer:        tracer,
+	}
+}
+
+// RegisterRoutes registers routes for a path with matching handler, every
+// route requires its permission
+func (ah *AdminHandler) RegisterRoutes(e *echo.Echo) {
+	myMiddl := _MyMiddleware.InitMiddleware(ah.logger, ah.policy)
+	g := e.Group("/v1/admin", echojwt.WithConfig(ah.authenticator.JWTConfig))
+	g.GET("/users", ah.ListUsers, myMiddl.HasPermission(auth.PermUserReadAny))
+	g.POST("/users/:id/suspend", ah.SuspendUser, myMiddl.HasPermission(auth.PermUserModerate))
+	g.POST("/users/:id/unsuspend", ah.UnsuspendUser, myMiddl.HasPermission(auth.PermUserModerate))
+	g.PUT("/users/:id/roles", ah.UpdateRoles, myMiddl.HasPermission(auth.PermUserModerate))
+	g.GET("/urls", ah.ListURLs, myMiddl.HasPermission(auth.PermURLReadAny))
+	g.POST("/urls/:id/disable", ah.DisableURL, myMiddl.HasPermission(auth.PermURLModerate))
+	g.POST("/urls/:id/enable", ah.EnableURL, myMiddl.HasPermission(auth.PermURLModerate))
+	g.GET("/reports", ah.ListReports, myMiddl.HasPermission(auth.PermReportRead))
+	g.POST("/reports/:id/resolve", ah.ResolveReport, myMiddl.HasPermission(auth.PermReportResolve))
+	g.GET("/audit", ah.ListAudit, myMiddl.HasPermission(auth.PermAuditRead))
+}
+
+// ListUsers will list users by given query
+func (ah *AdminHandler) ListUsers(c echo.Context) error {
+	ctx, span := ah.start(c, "http ListUsers")
+	defer span.End()
+
+	q := new(domain.UserListQuery)
+	admin, err := ah.bind(c, q)
+	if admin == nil {
+		span.RecordError(domain.ErrBadParamInput)
+		return err
+	}
+
+	list, err := ah.adminUsecase.ListUsers(ctx, *q, admin)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, ah.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusOK, list)
+}
+
+// ListURLs will list URLs of all users by given query
+func (ah *AdminHandler) ListURLs(c echo.Context) error {
+	ctx, span := ah.start(c, "http ListURLs")
+	defer span.End()
+
+	q := new(domain.URLListQuery)
+	admin, err := ah.bind(c, q)
+	if admin == nil {
+		span.RecordError(domain.ErrBadParamInput)
+		return err
+	}
+
+	list, err := ah.adminUsecase.ListURLs(ctx, *q, admin)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, ah.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusOK, list)
+}
+
+// DisableURL will disable URL by given id
+func (ah *AdminHandler) DisableURL(c echo.Context) error {
+	return ah.setURLDisabled(c, true)
+}
+
+// EnableURL will enable URL disabled by admin
+func (ah *AdminHandler) EnableURL(c echo.Context) error {
+	return ah.setURLDisabled(c, false)
+}
+
+func (ah *AdminHandler) setURLDisabled(c echo.Context, disabled bool) error {
+	ctx, span := ah.start(c, "http setURLDisabled")
+	defer span.End()
+
+	r := new(domain.ModerateURL)
+	admin, err := ah.bind(c, r)
+	if admin == nil {
+		span.RecordError(domain.ErrBadParamInput)
+		return err
+	}
+
+	u, err := ah.adminUsecase.SetURLDisabled(ctx, *r, disabled, admin)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, ah.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("urlid", r.ID),
+	)
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusOK, u)
+}
+
+// SuspendUser will suspend user by given id
+func (ah *AdminHandler) SuspendUser(c echo.Context) error {
+	return ah.setUserSuspended(c, true)
+}
+
+// UnsuspendUser will lift suspension of user by given id
+func (ah *AdminHandler) UnsuspendUser(c echo.Context) error {
+	return ah.setUserSuspended(c, false)
+}
+
+func (ah *AdminHandler) setUserSuspended(c echo.Context, suspended bool) error {
+	ctx, span := ah.start(c, "http setUserSuspended")
+	defer span.End()
+
+	r := new(domain.ModerateUser)
+	admin, err := ah.bind(c, r)
+	if admin == nil {
+		span.RecordError(domain.ErrBadParamInput)
+		return err
+	}
+
+	u, err := ah.adminUsecase.SetUserSuspended(ctx, *r, suspended, admin)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, ah.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("userid", r.ID),
+	)
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusOK, u)
+}
+
+// UpdateRoles will replace roles of user by given request body
+func (ah *AdminHandler) UpdateRoles(c echo.Context) error {
+	ctx, span := ah.start(c, "http UpdateRoles")
+	defer span.End()
+
+	r := new(domain.UpdateRoles)
+	admin, err := ah.bind(c, r)
+	if admin == nil {
+		span.RecordError(domain.ErrBadParamInput)
+		return err
+	}
+
+	u, err := ah.adminUsecase.UpdateRoles(ctx, *r, admin)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, ah.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("userid", r.ID),
+	)
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusOK, u)
+}
+
+// ListReports will list abuse reports by given query
+func (ah *AdminHandler) ListReports(c echo.Context) error {
+	ctx, span := ah.start(c, "http ListReports")
+	defer span.End()
+
+	q := new(domain.AbuseReportListQuery)
+	admin, err := ah.bind(c, q)
+	if admin == nil {
+		span.RecordError(domain.ErrBadParamInput)
+		return err
+	}
+
+	list, err := ah.adminUsecase.ListReports(ctx, *q, admin)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, ah.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusOK, list)
+}
+
+// ResolveReport will resolve abuse report by given request body
+func (ah *AdminHandler) ResolveReport(c echo.Context) error {
+	ctx, span := ah.start(c, "http ResolveReport")
+	defer span.End()
+
+	r := new(domain.ResolveAbuseReport)
+	admin, err := ah.bind(c, r)
+	if admin == nil {
+		span.RecordError(domain.ErrBadParamInput)
+		return err
+	}
+
+	report, err := ah.adminUsecase.ResolveReport(ctx, *r, admin)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, ah.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("reportid", r.ID),
+	)
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusOK, report)
+}
+
+// ListAudit will list audit log entries by given query
+func (ah *AdminHandler) ListAudit(c echo.Context) error {
+	ctx, span := ah.start(c, "http ListAudit")
+	defer span.End()
+
+	q := new(domain.AuditQuery)
+	admin, err := ah.bind(c, q)
+	if admin == nil {
+		span.RecordError(domain.ErrBadParamInput)
+		return err
+	}
+
+	list, err := ah.adminUsecase.ListAudit(ctx, *q, admin)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, ah.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusOK, list)
+}
+
+func (ah *AdminHandler) start(c echo.Context, name string) (context.Context, trace.Span) {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return ah.tracer.Start(
+		ctx,
+		name,
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+}
+
+// bind binds and validates request and returns claims of admin, if claims
+// are nil the response is already written and returned error must be
+// returned by handler
+func (ah *AdminHandler) bind(c echo.Context, req interface{}) (*auth.Claims, error) {
+	if err := c.Bind(req); err != nil {
+		return nil, c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
+	}
+
+	if err := c.Validate(req); err != nil {
+		fields := err.(validator.ValidationErrors).Translate(ah.validator.Translator)
+		return nil, c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok || token == nil {
+		return nil, c.JSON(http.StatusForbidden, domain.ResponseError{Error: domain.ErrForbidden.Error()})
+	}
+	admin, ok := token.Claims.(*auth.Claims)
+	if !ok {
+		return nil, fmt.Errorf("%w can't convert jwt.Claims to auth.Claims", domain.ErrInternalServerError)
+	}
+
+	return admin, nil
+}
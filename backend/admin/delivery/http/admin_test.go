@@ -0,0 +1,261 @@
+package http_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+
+	adminHttp "github.com/semka95/shortener/backend/admin/delivery/http"
+	"github.com/semka95/shortener/backend/admin/mock"
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/tests"
+	"github.com/semka95/shortener/backend/web"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+func TestAdminHTTP(t *testing.T) {
+	tUser := tests.NewUser()
+	tURL := tests.NewURL()
+	tReport := tests.NewAbuseReport()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	kid := "4754d86b-7a6d-4df5-9c65-224741361492"
+	kf := auth.NewSimpleKeyLookupFunc(kid, key.Public().(*rsa.PublicKey))
+	authenticator, err := auth.NewAuthenticator(key, kid, "RS256", kf)
+	require.NoError(t, err)
+
+	adminClaims := auth.NewClaims("507f191e810c19729de860eb", []string{auth.RoleAdmin}, time.Now(), time.Hour)
+	adminToken, err := authenticator.GenerateToken(adminClaims)
+	require.NoError(t, err)
+	userToken, err := authenticator.GenerateToken(auth.NewClaims(tUser.ID.Hex(), tUser.Roles, time.Now(), time.Hour))
+	require.NoError(t, err)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	uc := mock.NewMockAdminUsecase(controller)
+
+	tracer := sdktrace.NewTracerProvider().Tracer("")
+	v, err := web.NewAppValidator()
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Validator = v
+	handler := adminHttp.NewAdminHandler(uc, authenticator, auth.DefaultPolicy(), v, zap.NewNop(), tracer)
+	handler.RegisterRoutes(e)
+
+	cases := []struct {
+		description   string
+		method        string
+		target        string
+		reqBody       string
+		token         string
+		mockCalls     func()
+		checkResponse func(rec *httptest.ResponseRecorder)
+	}{
+		{
+			description: "ListURLs success",
+			method:      echo.GET,
+			target:      "/v1/admin/urls?flagged=true&user_id=" + tUser.ID.Hex(),
+			token:       adminToken,
+			mockCalls: func() {
+				uc.EXPECT().ListURLs(gomock.Any(), domain.URLListQuery{Flagged: true, UserID: tUser.ID.Hex()}, gomock.Any()).
+					Return(&domain.URLList{Items: []*domain.URL{tURL}}, nil)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.URLList)
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(body))
+				assert.Len(t, body.Items, 1)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			description: "ListURLs not admin",
+			method:      echo.GET,
+			target:      "/v1/admin/urls",
+			token:       userToken,
+			mockCalls:   func() {},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			description: "ListURLs without token",
+			method:      echo.GET,
+			target:      "/v1/admin/urls",
+			mockCalls:   func() {},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			},
+		},
+		{
+			description: "ListUsers validation error",
+			method:      echo.GET,
+			target:      "/v1/admin/users?status=deleted",
+			token:       adminToken,
+			mockCalls:   func() {},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(body))
+				assert.Contains(t, body.Fields, "UserListQuery.status")
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			description: "DisableURL success",
+			method:      echo.POST,
+			target:      "/v1/admin/urls/" + tURL.ID + "/disable",
+			reqBody:     `{"reason":"phishing"}`,
+			token:       adminToken,
+			mockCalls: func() {
+				uc.EXPECT().SetURLDisabled(gomock.Any(), domain.ModerateURL{ID: tURL.ID, Reason: "phishing"}, true, gomock.Any()).Return(tURL, nil)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			description: "EnableURL not found",
+			method:      echo.POST,
+			target:      "/v1/admin/urls/none/enable",
+			token:       adminToken,
+			mockCalls: func() {
+				uc.EXPECT().SetURLDisabled(gomock.Any(), domain.ModerateURL{ID: "none"}, false, gomock.Any()).Return(nil, domain.ErrNotFound)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, rec.Code)
+			},
+		},
+		{
+			description: "SuspendUser success",
+			method:      echo.POST,
+			target:      "/v1/admin/users/" + tUser.ID.Hex() + "/suspend",
+			token:       adminToken,
+			mockCalls: func() {
+				uc.EXPECT().SetUserSuspended(gomock.Any(), domain.ModerateUser{ID: tUser.ID.Hex()}, true, gomock.Any()).Return(tUser, nil)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			description: "UnsuspendUser wrong id",
+			method:      echo.POST,
+			target:      "/v1/admin/users/wrong/unsuspend",
+			token:       adminToken,
+			mockCalls:   func() {},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			description: "UpdateRoles success",
+			method:      echo.PUT,
+			target:      "/v1/admin/users/" + tUser.ID.Hex() + "/roles",
+			reqBody:     `{"roles":["USER","ADMIN"]}`,
+			token:       adminToken,
+			mockCalls: func() {
+				uc.EXPECT().UpdateRoles(gomock.Any(), domain.UpdateRoles{ID: tUser.ID.Hex(), Roles: []string{auth.RoleUser, auth.RoleAdmin}}, gomock.Any()).Return(tUser, nil)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			description: "UpdateRoles unknown role",
+			method:      echo.PUT,
+			target:      "/v1/admin/users/" + tUser.ID.Hex() + "/roles",
+			reqBody:     `{"roles":["ROOT"]}`,
+			token:       adminToken,
+			mockCalls:   func() {},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			description: "ListReports success",
+			method:      echo.GET,
+			target:      "/v1/admin/reports?status=open",
+			token:       adminToken,
+			mockCalls: func() {
+				uc.EXPECT().ListReports(gomock.Any(), domain.AbuseReportListQuery{Status: domain.ReportStatusOpen}, gomock.Any()).
+					Return(&domain.AbuseReportList{Items: []*domain.AbuseReport{tReport}}, nil)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			description: "ResolveReport already resolved",
+			method:      echo.POST,
+			target:      "/v1/admin/reports/" + tReport.ID.Hex() + "/resolve",
+			reqBody:     `{"resolution":"link disabled"}`,
+			token:       adminToken,
+			mockCalls: func() {
+				uc.EXPECT().ResolveReport(gomock.Any(), domain.ResolveAbuseReport{ID: tReport.ID.Hex(), Resolution: "link disabled"}, gomock.Any()).Return(nil, domain.ErrConflict)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, rec.Code)
+			},
+		},
+		{
+			description: "ListAudit success",
+			method:      echo.GET,
+			target:      "/v1/admin/audit?actor=" + tUser.ID.Hex() + "&target=" + tURL.ID + "&from=2023-03-01T00:00:00Z&to=2023-03-02T00:00:00Z",
+			token:       adminToken,
+			mockCalls: func() {
+				uc.EXPECT().ListAudit(gomock.Any(), domain.AuditQuery{
+					Actor:  tUser.ID.Hex(),
+					Target: tURL.ID,
+					From:   time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+					To:     time.Date(2023, 3, 2, 0, 0, 0, 0, time.UTC),
+				}, gomock.Any()).Return(&domain.AuditList{Items: []*domain.AuditEntry{{Action: domain.AuditUpdateURL}}}, nil)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.AuditList)
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(body))
+				assert.Len(t, body.Items, 1)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			description: "ListAudit wrong time",
+			method:      echo.GET,
+			target:      "/v1/admin/audit?from=yesterday",
+			token:       adminToken,
+			mockCalls:   func() {},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls()
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tc.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tc.token)
+			}
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			tc.checkResponse(rec)
+		})
+	}
+}
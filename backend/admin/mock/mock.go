@@ -0,0 +1,157 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./domain/admin.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/semka95/shortener/backend/domain"
+	auth "github.com/semka95/shortener/backend/web/auth"
+)
+
+// MockAdminUsecase is a mock of AdminUsecase interface.
+type MockAdminUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminUsecaseMockRecorder
+}
+
+// MockAdminUsecaseMockRecorder is the mock recorder for MockAdminUsecase.
+type MockAdminUsecaseMockRecorder struct {
+	mock *MockAdminUsecase
+}
+
+// NewMockAdminUsecase creates a new mock instance.
+func NewMockAdminUsecase(ctrl *gomock.Controller) *MockAdminUsecase {
+	mock := &MockAdminUsecase{ctrl: ctrl}
+	mock.recorder = &MockAdminUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminUsecase) EXPECT() *MockAdminUsecaseMockRecorder {
+	return m.recorder
+}
+
+// ListAudit mocks base method.
+func (m *MockAdminUsecase) ListAudit(ctx context.Context, query domain.AuditQuery, admin *auth.Claims) (*domain.AuditList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAudit", ctx, query, admin)
+	ret0, _ := ret[0].(*domain.AuditList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAudit indicates an expected call of ListAudit.
+func (mr *MockAdminUsecaseMockRecorder) ListAudit(ctx, query, admin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAudit", reflect.TypeOf((*MockAdminUsecase)(nil).ListAudit), ctx, query, admin)
+}
+
+// ListReports mocks base method.
+func (m *MockAdminUsecase) ListReports(ctx context.Context, query domain.AbuseReportListQuery, admin *auth.Claims) (*domain.AbuseReportList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReports", ctx, query, admin)
+	ret0, _ := ret[0].(*domain.AbuseReportList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReports indicates an expected call of ListReports.
+func (mr *MockAdminUsecaseMockRecorder) ListReports(ctx, query, admin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReports", reflect.TypeOf((*MockAdminUsecase)(nil).ListReports), ctx, query, admin)
+}
+
+// ListURLs mocks base method.
+func (m *MockAdminUsecase) ListURLs(ctx context.Context, query domain.URLListQuery, admin *auth.Claims) (*domain.URLList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListURLs", ctx, query, admin)
+	ret0, _ := ret[0].(*domain.URLList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListURLs indicates an expected call of ListURLs.
+func (mr *MockAdminUsecaseMockRecorder) ListURLs(ctx, query, admin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListURLs", reflect.TypeOf((*MockAdminUsecase)(nil).ListURLs), ctx, query, admin)
+}
+
+// ListUsers mocks base method.
+func (m *MockAdminUsecase) ListUsers(ctx context.Context, query domain.UserListQuery, admin *auth.Claims) (*domain.UserList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", ctx, query, admin)
+	ret0, _ := ret[0].(*domain.UserList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockAdminUsecaseMockRecorder) ListUsers(ctx, query, admin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockAdminUsecase)(nil).ListUsers), ctx, query, admin)
+}
+
+// ResolveReport mocks base method.
+func (m *MockAdminUsecase) ResolveReport(ctx context.Context, req domain.ResolveAbuseReport, admin *auth.Claims) (*domain.AbuseReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveReport", ctx, req, admin)
+	ret0, _ := ret[0].(*domain.AbuseReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveReport indicates an expected call of ResolveReport.
+func (mr *MockAdminUsecaseMockRecorder) ResolveReport(ctx, req, admin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveReport", reflect.TypeOf((*MockAdminUsecase)(nil).ResolveReport), ctx, req, admin)
+}
+
+// SetURLDisabled mocks base method.
+func (m *MockAdminUsecase) SetURLDisabled(ctx context.Context, req domain.ModerateURL, disabled bool, admin *auth.Claims) (*domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetURLDisabled", ctx, req, disabled, admin)
+	ret0, _ := ret[0].(*domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetURLDisabled indicates an expected call of SetURLDisabled.
+func (mr *MockAdminUsecaseMockRecorder) SetURLDisabled(ctx, req, disabled, admin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetURLDisabled", reflect.TypeOf((*MockAdminUsecase)(nil).SetURLDisabled), ctx, req, disabled, admin)
+}
+
+// SetUserSuspended mocks base method.
+func (m *MockAdminUsecase) SetUserSuspended(ctx context.Context, req domain.ModerateUser, suspended bool, admin *auth.Claims) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserSuspended", ctx, req, suspended, admin)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserSuspended indicates an expected call of SetUserSuspended.
+func (mr *MockAdminUsecaseMockRecorder) SetUserSuspended(ctx, req, suspended, admin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserSuspended", reflect.TypeOf((*MockAdminUsecase)(nil).SetUserSuspended), ctx, req, suspended, admin)
+}
+
+// UpdateRoles mocks base method.
+func (m *MockAdminUsecase) UpdateRoles(ctx context.Context, req domain.UpdateRoles, admin *auth.Claims) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRoles", ctx, req, admin)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRoles indicates an expected call of UpdateRoles.
+func (mr *MockAdminUsecaseMockRecorder) UpdateRoles(ctx, req, admin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRoles", reflect.TypeOf((*MockAdminUsecase)(nil).UpdateRoles), ctx, req, admin)
+}
@@ -0,0 +1,485 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/semka95/shortener/backend/audit"
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+const defaultListLimit = 20
+
+type adminUsecase struct {
+	urlRepo        domain.URLRepository
+	urlCache       domain.URLCache
+	userRepo       domain.UserRepository
+	reportRepo     domain.AbuseReportRepository
+	auditRepo      domain.AuditRepository
+	transactor     domain.Transactor
+	tokenUsecase   domain.TokenUsecase
+	policy         *auth.Policy
+	contextTimeout time.Duration
+	tracer         trace.Tracer
+}
+
+// NewAdminUsecase will create new an adminUsecase object representation of admin.Usecase interface,
+// u is URL repository without cache, as it is used in transactions, changed URLs are invalidated in c.
+// Policy grants permissions of roles
+func NewAdminUsecase(u domain.URLRepository, c domain.URLCache, us domain.UserRepository, r domain.AbuseReportRepository, a domain.AuditRepository, tx domain.Transactor, t domain.TokenUsecase, policy *auth.Policy, timeout time.Duration, tracer trace.Tracer) domain.AdminUsecase {
+	return &adminUsecase{
+		urlRepo:        u,
+		urlCache:       c,
+		userRepo:       us,
+		reportRepo:     r,
+		auditRepo:      a,
+		transactor:     tx,
+		tokenUsecase:   t,
+		policy:         policy,
+		contextTimeout: timeout,
+		tracer:         tracer,
+	}
+}
+
+// record stores audit entry before the action is performed, so failed
+// action leaves extra entry in audit log instead of a lost one
+func (uc *adminUsecase) record(ctx context.Context, admin *auth.Claims, action, target string, details map[string]string) error {
+	for k, v := range details {
+		if v == "" {
+			delete(details, k)
+		}
+	}
+
+	e := audit.NewEntry(ctx, admin, action, target)
+	if len(details) > 0 {
+		e.Details = details
+	}
+
+	return uc.auditRepo.Store(ctx, e)
+}
+
+func (uc *adminUsecase) ListUsers(c context.Context, query domain.UserListQuery, admin *auth.Claims) (*domain.UserList, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase ListUsers",
+		trace.WithAttributes(
+			attribute.String("adminid", admin.Subject)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	if query.Limit == 0 {
+		query.Limit = defaultListLimit
+	}
+
+	err := uc.record(ctx, admin, domain.AuditListUsers, "", map[string]string{
+		"status": query.Status,
+		"role":   query.Role,
+		"search": query.Search,
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	list, err := uc.userRepo.List(ctx, query)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// ListURLs lists URLs of all users, unlike user's list it may be filtered by
+// owner, flagged and disabled URLs
+func (uc *adminUsecase) ListURLs(c context.Context, query domain.URLListQuery, admin *auth.Claims) (*domain.URLList, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase ListURLs",
+		trace.WithAttributes(
+			attribute.String("adminid", admin.Subject)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	if query.Limit == 0 {
+		query.Limit = defaultListLimit
+	}
+	if query.Sort == "" {
+		query.Sort = "created_at"
+	}
+	if query.Order == "" {
+		query.Order = "desc"
+	}
+
+	details := map[string]string{
+		"user_id": query.UserID,
+		"status":  query.Status,
+		"search":  query.Search,
+	}
+	if query.Flagged {
+		details["flagged"] = "true"
+	}
+	if query.Disabled {
+		details["disabled"] = "true"
+	}
+	err := uc.record(ctx, admin, domain.AuditListURLs, "", details)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	list, err := uc.urlRepo.List(ctx, query)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, u := range list.Items {
+		u.Expired = u.IsExpired(now)
+	}
+
+	return list, nil
+}
+
+// SetURLDisabled disables or enables URL, disabled URL doesn't redirect and
+// can't be changed by its owner
+func (uc *adminUsecase) SetURLDisabled(c context.Context, req domain.ModerateURL, disabled bool, admin *auth.Claims) (*domain.URL, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase SetURLDisabled",
+		trace.WithAttributes(
+			attribute.String("adminid", admin.Subject),
+			attribute.String("urlid", req.ID),
+			attribute.Bool("disabled", disabled)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	u, err := uc.urlRepo.GetByID(ctx, req.ID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	action := domain.AuditEnableURL
+	if disabled {
+		action = domain.AuditDisableURL
+	}
+	err = uc.record(ctx, admin, action, u.ID, map[string]string{
+		"reason": req.Reason,
+		"link":   u.Link,
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	u.Disabled = disabled
+	u.UpdatedAt = time.Now().Truncate(time.Millisecond).UTC()
+
+	if err = uc.urlRepo.SetDisabled(ctx, u.ID, disabled, u.UpdatedAt); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	uc.urlCache.Invalidate(ctx, u.ID)
+
+	return u, nil
+}
+
+// SetUserSuspended suspends or unsuspends user, suspended user is logged out
+// everywhere and their URLs stop redirecting
+func (uc *adminUsecase) SetUserSuspended(c context.Context, req domain.ModerateUser, suspended bool, admin *auth.Claims) (*domain.User, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase SetUserSuspended",
+		trace.WithAttributes(
+			attribute.String("adminid", admin.Subject),
+			attribute.String("userid", req.ID),
+			attribute.Bool("suspended", suspended)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	if suspended && req.ID == admin.Subject {
+		err := fmt.Errorf("admin can't suspend own account: %w", domain.ErrBadParamInput)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	u, err := uc.getUser(ctx, req.ID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	action := domain.AuditUnsuspendUser
+	if suspended {
+		action = domain.AuditSuspendUser
+	}
+
+	now := time.Now().Truncate(time.Millisecond).UTC()
+	u.SuspendedAt = nil
+	if suspended {
+		u.SuspendedAt = &now
+	}
+	u.UpdatedAt = now
+
+	var ids []string
+	err = uc.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		err := uc.record(ctx, admin, action, req.ID, map[string]string{
+			"reason": req.Reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err = uc.userRepo.Update(ctx, u); err != nil {
+			return err
+		}
+
+		ids, err = uc.urlRepo.SetOwnerSuspended(ctx, req.ID, suspended)
+		return err
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	uc.urlCache.Invalidate(ctx, ids...)
+
+	// tokens are revoked outside of transaction, which can be retried
+	if suspended {
+		if err = uc.tokenUsecase.RevokeAll(ctx, req.ID); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	return u, nil
+}
+
+// UpdateRoles replaces roles of user, user's tokens are revoked, so new
+// roles take effect on next login
+func (uc *adminUsecase) UpdateRoles(c context.Context, req domain.UpdateRoles, admin *auth.Claims) (*domain.User, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase UpdateRoles",
+		trace.WithAttributes(
+			attribute.String("adminid", admin.Subject),
+			attribute.String("userid", req.ID)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	// admin can't lock themselves out of role management
+	if req.ID == admin.Subject && uc.policy.Authorize(&auth.Claims{Roles: req.Roles}, auth.PermUserModerate, nil) != nil {
+		err := fmt.Errorf("admin can't remove own permission to manage roles: %w", domain.ErrBadParamInput)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	u, err := uc.getUser(ctx, req.ID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	err = uc.record(ctx, admin, domain.AuditUpdateRoles, req.ID, map[string]string{
+		"from": strings.Join(u.Roles, ","),
+		"to":   strings.Join(req.Roles, ","),
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	u.Roles = req.Roles
+	u.UpdatedAt = time.Now().Truncate(time.Millisecond).UTC()
+
+	if err = uc.userRepo.Update(ctx, u); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err = uc.tokenUsecase.RevokeAll(ctx, req.ID); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return u, nil
+}
+
+func (uc *adminUsecase) ListReports(c context.Context, query domain.AbuseReportListQuery, admin *auth.Claims) (*domain.AbuseReportList, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase ListReports",
+		trace.WithAttributes(
+			attribute.String("adminid", admin.Subject)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	if query.Limit == 0 {
+		query.Limit = defaultListLimit
+	}
+
+	err := uc.record(ctx, admin, domain.AuditListReports, "", map[string]string{
+		"status": query.Status,
+		"url_id": query.URLID,
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	list, err := uc.reportRepo.List(ctx, query)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// ResolveReport closes abuse report with admin's resolution, resolved report
+// can't be resolved again
+func (uc *adminUsecase) ResolveReport(c context.Context, req domain.ResolveAbuseReport, admin *auth.Claims) (*domain.AbuseReport, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase ResolveReport",
+		trace.WithAttributes(
+			attribute.String("adminid", admin.Subject),
+			attribute.String("reportid", req.ID)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	id, err := primitive.ObjectIDFromHex(req.ID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("report ID is not valid ObjectID: %w: %s", domain.ErrBadParamInput, err.Error())
+	}
+
+	r, err := uc.reportRepo.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if r.Status == domain.ReportStatusResolved {
+		err = fmt.Errorf("report %s is already resolved: %w", req.ID, domain.ErrConflict)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	err = uc.record(ctx, admin, domain.AuditResolveReport, req.ID, map[string]string{
+		"url_id":     r.URLID,
+		"resolution": req.Resolution,
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	now := time.Now().Truncate(time.Millisecond).UTC()
+	r.Status = domain.ReportStatusResolved
+	r.Resolution = req.Resolution
+	r.ResolvedBy = admin.Subject
+	r.ResolvedAt = &now
+
+	if err = uc.reportRepo.Update(ctx, r); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// ListAudit lists audit log entries, reading of audit log is recorded too
+func (uc *adminUsecase) ListAudit(c context.Context, query domain.AuditQuery, admin *auth.Claims) (*domain.AuditList, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase ListAudit",
+		trace.WithAttributes(
+			attribute.String("adminid", admin.Subject)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		err := fmt.Errorf("from must be before to: %w", domain.ErrBadParamInput)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if query.Limit == 0 {
+		query.Limit = defaultListLimit
+	}
+
+	details := make(map[string]string)
+	for k, v := range map[string]string{"actor": query.Actor, "target": query.Target, "action": query.Action} {
+		if v != "" {
+			details[k] = v
+		}
+	}
+	if !query.From.IsZero() {
+		details["from"] = query.From.UTC().Format(time.RFC3339)
+	}
+	if !query.To.IsZero() {
+		details["to"] = query.To.UTC().Format(time.RFC3339)
+	}
+	if err := uc.record(ctx, admin, domain.AuditListAudit, "", details); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	list, err := uc.auditRepo.List(ctx, query)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (uc *adminUsecase) getUser(ctx context.Context, id string) (*domain.User, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("user ID is not valid ObjectID: %w: %s", domain.ErrBadParamInput, err.Error())
+	}
+
+	return uc.userRepo.GetByID(ctx, objID)
+}
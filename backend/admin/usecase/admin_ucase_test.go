@@ -0,0 +1,316 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/semka95/shortener/backend/admin/usecase"
+	auditMock "github.com/semka95/shortener/backend/audit/mock"
+	"github.com/semka95/shortener/backend/domain"
+	reportMock "github.com/semka95/shortener/backend/report/mock"
+	storeMock "github.com/semka95/shortener/backend/store/mock"
+	"github.com/semka95/shortener/backend/tests"
+	tokenMock "github.com/semka95/shortener/backend/token/mock"
+	urlMock "github.com/semka95/shortener/backend/url/mock"
+	userMock "github.com/semka95/shortener/backend/user/mock"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+var tracer = sdktrace.NewTracerProvider().Tracer("")
+
+type mocks struct {
+	url      *urlMock.MockURLRepository
+	urlCache *urlMock.MockURLCache
+	user     *userMock.MockUserRepository
+	report   *reportMock.MockAbuseReportRepository
+	audit    *auditMock.MockAuditRepository
+	tx       *storeMock.MockTransactor
+	token    *tokenMock.MockTokenUsecase
+}
+
+func newUsecase(controller *gomock.Controller) (domain.AdminUsecase, mocks) {
+	m := mocks{
+		url:      urlMock.NewMockURLRepository(controller),
+		urlCache: urlMock.NewMockURLCache(controller),
+		user:     userMock.NewMockUserRepository(controller),
+		report:   reportMock.NewMockAbuseReportRepository(controller),
+		audit:    auditMock.NewMockAuditRepository(controller),
+		tx:       storeMock.NewMockTransactor(controller),
+		token:    tokenMock.NewMockTokenUsecase(controller),
+	}
+	uc := usecase.NewAdminUsecase(m.url, m.urlCache, m.user, m.report, m.audit, m.tx, m.token, auth.DefaultPolicy(), 10*time.Second, tracer)
+	return uc, m
+}
+
+// expectTransaction makes transactor run fn without transaction
+func expectTransaction(m mocks) {
+	m.tx.EXPECT().WithTransaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	})
+}
+
+func expectAudit(t *testing.T, m mocks, admin *auth.Claims, action, target string) {
+	m.audit.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.AuditEntry) error {
+		assert.Equal(t, admin.Subject, e.Actor)
+		assert.Equal(t, action, e.Action)
+		assert.Equal(t, target, e.Target)
+		return nil
+	})
+}
+
+func TestAdminUsecase_ListURLs(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	admin := auth.NewClaims("507f191e810c19729de860eb", []string{auth.RoleAdmin}, time.Now(), time.Minute)
+	uc, m := newUsecase(controller)
+
+	t.Run("success", func(t *testing.T) {
+		tURL := tests.NewURL()
+		expectAudit(t, m, admin, domain.AuditListURLs, "")
+		m.url.EXPECT().List(gomock.Any(), domain.URLListQuery{
+			Limit:   20,
+			Sort:    "created_at",
+			Order:   "desc",
+			Flagged: true,
+		}).Return(&domain.URLList{Items: []*domain.URL{tURL}}, nil)
+
+		result, err := uc.ListURLs(context.Background(), domain.URLListQuery{Flagged: true}, admin)
+		require.NoError(t, err)
+		assert.Len(t, result.Items, 1)
+	})
+
+	t.Run("audit error", func(t *testing.T) {
+		m.audit.EXPECT().Store(gomock.Any(), gomock.Any()).Return(domain.ErrInternalServerError)
+
+		result, err := uc.ListURLs(context.Background(), domain.URLListQuery{}, admin)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+		assert.Nil(t, result)
+	})
+}
+
+func TestAdminUsecase_SetURLDisabled(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	admin := auth.NewClaims("507f191e810c19729de860eb", []string{auth.RoleAdmin}, time.Now(), time.Minute)
+	uc, m := newUsecase(controller)
+
+	t.Run("disable", func(t *testing.T) {
+		tURL := tests.NewURL()
+		m.url.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		expectAudit(t, m, admin, domain.AuditDisableURL, tURL.ID)
+		m.url.EXPECT().SetDisabled(gomock.Any(), tURL.ID, true, gomock.Any()).Return(nil)
+		m.urlCache.EXPECT().Invalidate(gomock.Any(), tURL.ID)
+
+		result, err := uc.SetURLDisabled(context.Background(), domain.ModerateURL{ID: tURL.ID, Reason: "phishing"}, true, admin)
+		require.NoError(t, err)
+		assert.True(t, result.Disabled)
+	})
+
+	t.Run("enable", func(t *testing.T) {
+		tURL := tests.NewURL()
+		tURL.Disabled = true
+		m.url.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		expectAudit(t, m, admin, domain.AuditEnableURL, tURL.ID)
+		m.url.EXPECT().SetDisabled(gomock.Any(), tURL.ID, false, gomock.Any()).Return(nil)
+		m.urlCache.EXPECT().Invalidate(gomock.Any(), tURL.ID)
+
+		result, err := uc.SetURLDisabled(context.Background(), domain.ModerateURL{ID: tURL.ID}, false, admin)
+		require.NoError(t, err)
+		assert.False(t, result.Disabled)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		m.url.EXPECT().GetByID(gomock.Any(), "none").Return(nil, domain.ErrNotFound)
+
+		result, err := uc.SetURLDisabled(context.Background(), domain.ModerateURL{ID: "none"}, true, admin)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+func TestAdminUsecase_SetUserSuspended(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	admin := auth.NewClaims("507f191e810c19729de860eb", []string{auth.RoleAdmin}, time.Now(), time.Minute)
+	uc, m := newUsecase(controller)
+
+	t.Run("suspend", func(t *testing.T) {
+		tUser := tests.NewUser()
+		id := tUser.ID.Hex()
+		m.user.EXPECT().GetByID(gomock.Any(), tUser.ID).Return(tUser, nil)
+		expectTransaction(m)
+		expectAudit(t, m, admin, domain.AuditSuspendUser, id)
+		m.user.EXPECT().Update(gomock.Any(), tUser).Return(nil)
+		m.url.EXPECT().SetOwnerSuspended(gomock.Any(), id, true).Return([]string{"test123"}, nil)
+		m.urlCache.EXPECT().Invalidate(gomock.Any(), "test123")
+		m.token.EXPECT().RevokeAll(gomock.Any(), id).Return(nil)
+
+		result, err := uc.SetUserSuspended(context.Background(), domain.ModerateUser{ID: id}, true, admin)
+		require.NoError(t, err)
+		assert.True(t, result.IsSuspended())
+	})
+
+	t.Run("unsuspend", func(t *testing.T) {
+		tUser := tests.NewUser()
+		tUser.SuspendedAt = tests.DatePointer(time.Now())
+		id := tUser.ID.Hex()
+		m.user.EXPECT().GetByID(gomock.Any(), tUser.ID).Return(tUser, nil)
+		expectTransaction(m)
+		expectAudit(t, m, admin, domain.AuditUnsuspendUser, id)
+		m.user.EXPECT().Update(gomock.Any(), tUser).Return(nil)
+		m.url.EXPECT().SetOwnerSuspended(gomock.Any(), id, false).Return(nil, nil)
+		m.urlCache.EXPECT().Invalidate(gomock.Any())
+
+		result, err := uc.SetUserSuspended(context.Background(), domain.ModerateUser{ID: id}, false, admin)
+		require.NoError(t, err)
+		assert.False(t, result.IsSuspended())
+	})
+
+	t.Run("admin suspends own account", func(t *testing.T) {
+		result, err := uc.SetUserSuspended(context.Background(), domain.ModerateUser{ID: admin.Subject}, true, admin)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+		assert.Nil(t, result)
+	})
+
+	t.Run("audit error stops action", func(t *testing.T) {
+		tUser := tests.NewUser()
+		m.user.EXPECT().GetByID(gomock.Any(), tUser.ID).Return(tUser, nil)
+		expectTransaction(m)
+		m.audit.EXPECT().Store(gomock.Any(), gomock.Any()).Return(domain.ErrInternalServerError)
+
+		result, err := uc.SetUserSuspended(context.Background(), domain.ModerateUser{ID: tUser.ID.Hex()}, true, admin)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+		assert.Nil(t, result)
+	})
+
+	t.Run("failed transaction keeps tokens", func(t *testing.T) {
+		tUser := tests.NewUser()
+		id := tUser.ID.Hex()
+		m.user.EXPECT().GetByID(gomock.Any(), tUser.ID).Return(tUser, nil)
+		expectTransaction(m)
+		expectAudit(t, m, admin, domain.AuditSuspendUser, id)
+		m.user.EXPECT().Update(gomock.Any(), tUser).Return(nil)
+		m.url.EXPECT().SetOwnerSuspended(gomock.Any(), id, true).Return(nil, domain.ErrInternalServerError)
+
+		result, err := uc.SetUserSuspended(context.Background(), domain.ModerateUser{ID: id}, true, admin)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+		assert.Nil(t, result)
+	})
+}
+
+func TestAdminUsecase_UpdateRoles(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	admin := auth.NewClaims("507f191e810c19729de860eb", []string{auth.RoleAdmin}, time.Now(), time.Minute)
+	uc, m := newUsecase(controller)
+
+	t.Run("success", func(t *testing.T) {
+		tUser := tests.NewUser()
+		id := tUser.ID.Hex()
+		roles := []string{auth.RoleUser, auth.RoleAdmin}
+		m.user.EXPECT().GetByID(gomock.Any(), tUser.ID).Return(tUser, nil)
+		m.audit.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.AuditEntry) error {
+			assert.Equal(t, domain.AuditUpdateRoles, e.Action)
+			assert.Equal(t, map[string]string{"from": "USER", "to": "USER,ADMIN"}, e.Details)
+			return nil
+		})
+		m.user.EXPECT().Update(gomock.Any(), tUser).Return(nil)
+		m.token.EXPECT().RevokeAll(gomock.Any(), id).Return(nil)
+
+		result, err := uc.UpdateRoles(context.Background(), domain.UpdateRoles{ID: id, Roles: roles}, admin)
+		require.NoError(t, err)
+		assert.Equal(t, roles, result.Roles)
+	})
+
+	t.Run("admin removes own admin role", func(t *testing.T) {
+		result, err := uc.UpdateRoles(context.Background(), domain.UpdateRoles{ID: admin.Subject, Roles: []string{auth.RoleUser}}, admin)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+		assert.Nil(t, result)
+	})
+
+	t.Run("wrong user id", func(t *testing.T) {
+		result, err := uc.UpdateRoles(context.Background(), domain.UpdateRoles{ID: "wrong", Roles: []string{auth.RoleUser}}, admin)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+		assert.Nil(t, result)
+	})
+}
+
+func TestAdminUsecase_ResolveReport(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	admin := auth.NewClaims("507f191e810c19729de860eb", []string{auth.RoleAdmin}, time.Now(), time.Minute)
+	uc, m := newUsecase(controller)
+
+	t.Run("success", func(t *testing.T) {
+		tReport := tests.NewAbuseReport()
+		m.report.EXPECT().GetByID(gomock.Any(), tReport.ID).Return(tReport, nil)
+		expectAudit(t, m, admin, domain.AuditResolveReport, tReport.ID.Hex())
+		m.report.EXPECT().Update(gomock.Any(), tReport).Return(nil)
+
+		result, err := uc.ResolveReport(context.Background(), domain.ResolveAbuseReport{ID: tReport.ID.Hex(), Resolution: "link disabled"}, admin)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ReportStatusResolved, result.Status)
+		assert.Equal(t, "link disabled", result.Resolution)
+		assert.Equal(t, admin.Subject, result.ResolvedBy)
+		assert.NotNil(t, result.ResolvedAt)
+	})
+
+	t.Run("already resolved", func(t *testing.T) {
+		tReport := tests.NewAbuseReport()
+		tReport.Status = domain.ReportStatusResolved
+		m.report.EXPECT().GetByID(gomock.Any(), tReport.ID).Return(tReport, nil)
+
+		result, err := uc.ResolveReport(context.Background(), domain.ResolveAbuseReport{ID: tReport.ID.Hex(), Resolution: "again"}, admin)
+		assert.ErrorIs(t, err, domain.ErrConflict)
+		assert.Nil(t, result)
+	})
+}
+
+func TestAdminUsecase_ListAudit(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	admin := auth.NewClaims("507f191e810c19729de860eb", []string{auth.RoleAdmin}, time.Now(), time.Minute)
+	uc, m := newUsecase(controller)
+	from := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	t.Run("success", func(t *testing.T) {
+		m.audit.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.AuditEntry) error {
+			assert.Equal(t, domain.AuditListAudit, e.Action)
+			assert.Equal(t, map[string]string{
+				"actor": "507f191e810c19729de860ea",
+				"from":  "2023-03-01T00:00:00Z",
+				"to":    "2023-03-02T00:00:00Z",
+			}, e.Details)
+			return nil
+		})
+		m.audit.EXPECT().List(gomock.Any(), domain.AuditQuery{
+			Limit: 20,
+			Actor: "507f191e810c19729de860ea",
+			From:  from,
+			To:    to,
+		}).Return(&domain.AuditList{Items: []*domain.AuditEntry{{Action: domain.AuditUpdateURL}}}, nil)
+
+		result, err := uc.ListAudit(context.Background(), domain.AuditQuery{Actor: "507f191e810c19729de860ea", From: from, To: to}, admin)
+		require.NoError(t, err)
+		assert.Len(t, result.Items, 1)
+	})
+
+	t.Run("wrong time range", func(t *testing.T) {
+		result, err := uc.ListAudit(context.Background(), domain.AuditQuery{From: to, To: from}, admin)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+		assert.Nil(t, result)
+	})
+}
@@ -0,0 +1,158 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v4"
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/web"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+// APIKeyHandler represent the http handler for API keys
+type APIKeyHandler struct {
+	apiKeyUsecase domain.APIKeyUsecase
+	authenticator *auth.Authenticator
+	validator     *web.AppValidator
+	logger        *zap.Logger
+	tracer        trace.Tracer
+}
+
+// NewAPIKeyHandler will initialize the apikeys/ resources endpoint
+func NewAPIKeyHandler(ks domain.APIKeyUsecase, authenticator *auth.Authenticator, v *web.AppValidator, logger *zap.Logger, tracer trace.Tracer) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyUsecase: ks,
+		authenticator: authenticator,
+		validator:     v,
+		logger:        logger,
+		tracer:        tracer,
+	}
+}
+
+// RegisterRoutes registers routes for a path with matching handler, API keys
+// can't be managed with API keys, so routes accept only user tokens
+func (kh *APIKeyHandler) RegisterRoutes(e *echo.Echo) {
+	g := e.Group("/v1/user/apikeys", echojwt.WithConfig(kh.authenticator.JWTConfig))
+	g.GET("", kh.List)
+	g.POST("", kh.Create)
+	g.DELETE("/:id", kh.Revoke)
+}
+
+// Create will create API key of user by given request body
+func (kh *APIKeyHandler) Create(c echo.Context) error {
+	ctx, span := kh.start(c, "http Create")
+	defer span.End()
+
+	r := new(domain.CreateAPIKey)
+	if err := c.Bind(r); err != nil {
+		span.RecordError(err)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
+	}
+
+	if err := c.Validate(r); err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(kh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	user, err := kh.claims(c)
+	if user == nil {
+		span.RecordError(domain.ErrForbidden)
+		return err
+	}
+
+	k, err := kh.apiKeyUsecase.Create(ctx, *r, user)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, kh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("apikeyid", k.ID.Hex()),
+	)
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusCreated, k)
+}
+
+// List will list API keys of user
+func (kh *APIKeyHandler) List(c echo.Context) error {
+	ctx, span := kh.start(c, "http List")
+	defer span.End()
+
+	user, err := kh.claims(c)
+	if user == nil {
+		span.RecordError(domain.ErrForbidden)
+		return err
+	}
+
+	list, err := kh.apiKeyUsecase.List(ctx, user)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, kh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusOK, list)
+}
+
+// Revoke will revoke API key by given id
+func (kh *APIKeyHandler) Revoke(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx, span := kh.start(c, "http Revoke")
+	defer span.End()
+
+	user, err := kh.claims(c)
+	if user == nil {
+		span.RecordError(domain.ErrForbidden)
+		return err
+	}
+
+	if err = kh.apiKeyUsecase.Revoke(ctx, id, user); err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, kh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("apikeyid", id),
+	)
+	span.SetStatus(codes.Ok, "success")
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (kh *APIKeyHandler) start(c echo.Context, name string) (context.Context, trace.Span) {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return kh.tracer.Start(
+		ctx,
+		name,
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+}
+
+// claims returns claims of authenticated user, if claims are nil the response
+// is already written and returned error must be returned by handler
+func (kh *APIKeyHandler) claims(c echo.Context) (*auth.Claims, error) {
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok || token == nil {
+		return nil, c.JSON(http.StatusForbidden, domain.ResponseError{Error: domain.ErrForbidden.Error()})
+	}
+	user, ok := token.Claims.(*auth.Claims)
+	if !ok {
+		return nil, fmt.Errorf("%w can't convert jwt.Claims to auth.Claims", domain.ErrInternalServerError)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,184 @@
+package http_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+
+	apiKeyHttp "github.com/semka95/shortener/backend/apikey/delivery/http"
+	"github.com/semka95/shortener/backend/apikey/mock"
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/tests"
+	"github.com/semka95/shortener/backend/web"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+func TestAPIKeyHTTP(t *testing.T) {
+	tUser := tests.NewUser()
+	tKey := tests.NewAPIKey()
+	claims := auth.NewClaims(tUser.ID.Hex(), tUser.Roles, time.Now(), time.Hour)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	kid := "4754d86b-7a6d-4df5-9c65-224741361492"
+	kf := auth.NewSimpleKeyLookupFunc(kid, key.Public().(*rsa.PublicKey))
+	authenticator, err := auth.NewAuthenticator(key, kid, "RS256", kf)
+	require.NoError(t, err)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	uc := mock.NewMockAPIKeyUsecase(controller)
+
+	tracer := sdktrace.NewTracerProvider().Tracer("")
+	v, err := web.NewAppValidator()
+	require.NoError(t, err)
+
+	handler := apiKeyHttp.NewAPIKeyHandler(uc, authenticator, v, zap.NewNop(), tracer)
+
+	e := echo.New()
+	e.Validator = v
+	req := new(http.Request)
+	c := e.NewContext(req, nil)
+
+	// Test APIKeyHandler.Create
+	casesCreate := []struct {
+		description   string
+		mockCalls     func()
+		reqBody       string
+		checkResponse func(rec *httptest.ResponseRecorder)
+	}{
+		{
+			description: "Create success",
+			mockCalls: func() {
+				uc.EXPECT().Create(gomock.Any(), domain.CreateAPIKey{Name: tKey.Name, Scopes: tKey.Scopes}, claims).
+					Return(&domain.NewAPIKey{APIKey: *tKey, Key: "shk_test"}, nil)
+			},
+			reqBody: `{"name":"notifications","scopes":["url:create"]}`,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := make(map[string]interface{})
+				err = json.NewDecoder(rec.Body).Decode(&body)
+				require.NoError(t, err)
+				assert.Equal(t, "shk_test", body["key"])
+				assert.Equal(t, tKey.Prefix, body["prefix"])
+				assert.NotContains(t, body, "hash")
+				assert.Equal(t, http.StatusCreated, rec.Code)
+			},
+		},
+		{
+			description: "Create unknown scope",
+			mockCalls:   func() {},
+			reqBody:     `{"name":"notifications","scopes":["user:delete"]}`,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Contains(t, body.Fields, "CreateAPIKey.scopes[0]")
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			description: "Create without scopes",
+			mockCalls:   func() {},
+			reqBody:     `{"name":"notifications","scopes":[]}`,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+	}
+
+	for _, tc := range casesCreate {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls()
+			req = httptest.NewRequest(echo.POST, "/v1/user/apikeys", strings.NewReader(tc.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+			c.SetPath("/v1/user/apikeys")
+			c.Set("user", token)
+
+			err = handler.Create(c)
+			require.NoError(t, err)
+
+			tc.checkResponse(rec)
+		})
+	}
+
+	// Test APIKeyHandler.List
+	t.Run("List success", func(t *testing.T) {
+		uc.EXPECT().List(gomock.Any(), claims).Return([]*domain.APIKey{tKey}, nil)
+		req = httptest.NewRequest(echo.GET, "/v1/user/apikeys", nil)
+
+		rec := httptest.NewRecorder()
+		c.Reset(req, rec)
+		c.SetPath("/v1/user/apikeys")
+		c.Set("user", token)
+
+		err = handler.List(c)
+		require.NoError(t, err)
+
+		body := make([]*domain.APIKey, 0)
+		err = json.NewDecoder(rec.Body).Decode(&body)
+		require.NoError(t, err)
+		require.Len(t, body, 1)
+		assert.Equal(t, tKey.ID, body[0].ID)
+		assert.Empty(t, body[0].Hash)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	// Test APIKeyHandler.Revoke
+	casesRevoke := []struct {
+		description string
+		mockCalls   func()
+		code        int
+	}{
+		{
+			description: "Revoke success",
+			mockCalls: func() {
+				uc.EXPECT().Revoke(gomock.Any(), tKey.ID.Hex(), claims).Return(nil)
+			},
+			code: http.StatusNoContent,
+		},
+		{
+			description: "Revoke key of other user",
+			mockCalls: func() {
+				uc.EXPECT().Revoke(gomock.Any(), tKey.ID.Hex(), claims).Return(domain.ErrForbidden)
+			},
+			code: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range casesRevoke {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls()
+			req = httptest.NewRequest(echo.DELETE, "/v1/user/apikeys/"+tKey.ID.Hex(), nil)
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+			c.SetPath("/v1/user/apikeys/:id")
+			c.SetParamNames("id")
+			c.SetParamValues(tKey.ID.Hex())
+			c.Set("user", token)
+
+			err = handler.Revoke(c)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.code, rec.Code)
+		})
+	}
+}
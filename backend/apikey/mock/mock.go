@@ -0,0 +1,208 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./domain/apikey.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/semka95/shortener/backend/domain"
+	auth "github.com/semka95/shortener/backend/web/auth"
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockAPIKeyUsecase is a mock of APIKeyUsecase interface.
+type MockAPIKeyUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyUsecaseMockRecorder
+}
+
+// MockAPIKeyUsecaseMockRecorder is the mock recorder for MockAPIKeyUsecase.
+type MockAPIKeyUsecaseMockRecorder struct {
+	mock *MockAPIKeyUsecase
+}
+
+// NewMockAPIKeyUsecase creates a new mock instance.
+func NewMockAPIKeyUsecase(ctrl *gomock.Controller) *MockAPIKeyUsecase {
+	mock := &MockAPIKeyUsecase{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyUsecase) EXPECT() *MockAPIKeyUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockAPIKeyUsecase) Authenticate(ctx context.Context, key string) (*auth.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, key)
+	ret0, _ := ret[0].(*auth.Claims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockAPIKeyUsecaseMockRecorder) Authenticate(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAPIKeyUsecase)(nil).Authenticate), ctx, key)
+}
+
+// Create mocks base method.
+func (m *MockAPIKeyUsecase) Create(ctx context.Context, key domain.CreateAPIKey, claims *auth.Claims) (*domain.NewAPIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, key, claims)
+	ret0, _ := ret[0].(*domain.NewAPIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAPIKeyUsecaseMockRecorder) Create(ctx, key, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAPIKeyUsecase)(nil).Create), ctx, key, claims)
+}
+
+// List mocks base method.
+func (m *MockAPIKeyUsecase) List(ctx context.Context, claims *auth.Claims) ([]*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, claims)
+	ret0, _ := ret[0].([]*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAPIKeyUsecaseMockRecorder) List(ctx, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAPIKeyUsecase)(nil).List), ctx, claims)
+}
+
+// Revoke mocks base method.
+func (m *MockAPIKeyUsecase) Revoke(ctx context.Context, id string, claims *auth.Claims) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id, claims)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockAPIKeyUsecaseMockRecorder) Revoke(ctx, id, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockAPIKeyUsecase)(nil).Revoke), ctx, id, claims)
+}
+
+// MockAPIKeyRepository is a mock of APIKeyRepository interface.
+type MockAPIKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyRepositoryMockRecorder
+}
+
+// MockAPIKeyRepositoryMockRecorder is the mock recorder for MockAPIKeyRepository.
+type MockAPIKeyRepositoryMockRecorder struct {
+	mock *MockAPIKeyRepository
+}
+
+// NewMockAPIKeyRepository creates a new mock instance.
+func NewMockAPIKeyRepository(ctrl *gomock.Controller) *MockAPIKeyRepository {
+	mock := &MockAPIKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyRepository) EXPECT() *MockAPIKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockAPIKeyRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockAPIKeyRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAPIKeyRepository)(nil).Delete), ctx, id)
+}
+
+// GetByHash mocks base method.
+func (m *MockAPIKeyRepository) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", ctx, hash)
+	ret0, _ := ret[0].(*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash.
+func (mr *MockAPIKeyRepositoryMockRecorder) GetByHash(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockAPIKeyRepository)(nil).GetByHash), ctx, hash)
+}
+
+// GetByID mocks base method.
+func (m *MockAPIKeyRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockAPIKeyRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockAPIKeyRepository)(nil).GetByID), ctx, id)
+}
+
+// ListByUser mocks base method.
+func (m *MockAPIKeyRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]*domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUser", ctx, userID)
+	ret0, _ := ret[0].([]*domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUser indicates an expected call of ListByUser.
+func (mr *MockAPIKeyRepositoryMockRecorder) ListByUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUser", reflect.TypeOf((*MockAPIKeyRepository)(nil).ListByUser), ctx, userID)
+}
+
+// Store mocks base method.
+func (m *MockAPIKeyRepository) Store(ctx context.Context, key *domain.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store.
+func (mr *MockAPIKeyRepositoryMockRecorder) Store(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockAPIKeyRepository)(nil).Store), ctx, key)
+}
+
+// UpdateLastUsed mocks base method.
+func (m *MockAPIKeyRepository) UpdateLastUsed(ctx context.Context, id primitive.ObjectID, t time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLastUsed", ctx, id, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLastUsed indicates an expected call of UpdateLastUsed.
+func (mr *MockAPIKeyRepositoryMockRecorder) UpdateLastUsed(ctx, id, t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLastUsed", reflect.TypeOf((*MockAPIKeyRepository)(nil).UpdateLastUsed), ctx, id, t)
+}
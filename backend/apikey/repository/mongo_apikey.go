@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+)
+
+const apiKeyCollection = "api_key"
+
+type mongoAPIKeyRepository struct {
+	Conn   *mongo.Database
+	logger *zap.Logger
+	tracer trace.Tracer
+}
+
+// NewMongoAPIKeyRepository will create an object that represent the apikey.Repository interface
+func NewMongoAPIKeyRepository(c *mongo.Client, db string, logger *zap.Logger, tracer trace.Tracer) domain.APIKeyRepository {
+	return &mongoAPIKeyRepository{
+		Conn:   c.Database(db),
+		logger: logger,
+		tracer: tracer,
+	}
+}
+
+func (m *mongoAPIKeyRepository) Store(ctx context.Context, key *domain.APIKey) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository Store",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("userid", key.UserID.Hex())),
+	)
+	defer span.End()
+
+	_, err := m.Conn.Collection(apiKeyCollection).InsertOne(ctx, key)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("API key store error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (m *mongoAPIKeyRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.APIKey, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository GetByID",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("apikeyid", id.Hex())),
+	)
+	defer span.End()
+
+	key, err := m.findOne(ctx, bson.D{primitive.E{Key: "_id", Value: id}})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (m *mongoAPIKeyRepository) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository GetByHash",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	key, err := m.findOne(ctx, bson.D{primitive.E{Key: "hash", Value: hash}})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (m *mongoAPIKeyRepository) findOne(ctx context.Context, filter bson.D) (*domain.APIKey, error) {
+	key := new(domain.APIKey)
+	err := m.Conn.Collection(apiKeyCollection).FindOne(ctx, filter).Decode(key)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("API key was not found: %w", domain.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("API key get error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return key, nil
+}
+
+// ListByUser returns all API keys of user, newest first
+func (m *mongoAPIKeyRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]*domain.APIKey, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository ListByUser",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("userid", userID.Hex())),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "user_id", Value: userID}}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+
+	cur, err := m.Conn.Collection(apiKeyCollection).Find(ctx, filter, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("API key list error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	list := make([]*domain.APIKey, 0)
+	if err = cur.All(ctx, &list); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("can't decode API keys: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return list, nil
+}
+
+func (m *mongoAPIKeyRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository Delete",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("apikeyid", id.Hex())),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "_id", Value: id}}
+
+	res, err := m.Conn.Collection(apiKeyCollection).DeleteOne(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("API key delete error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	if res.DeletedCount == 0 {
+		err = fmt.Errorf("API key was not deleted: %w", domain.ErrNoAffected)
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoAPIKeyRepository) UpdateLastUsed(ctx context.Context, id primitive.ObjectID, t time.Time) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository UpdateLastUsed",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("apikeyid", id.Hex())),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "_id", Value: id}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "last_used_at", Value: t}}}}
+
+	_, err := m.Conn.Collection(apiKeyCollection).UpdateOne(ctx, filter, update)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("API key update error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/semka95/shortener/backend/apikey/repository"
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/tests"
+)
+
+var tracer = sdktrace.NewTracerProvider().Tracer("")
+var noopCtx = context.Background()
+
+const tableName = "shortener.api_key"
+
+func apiKeyBsonD(k *domain.APIKey) bson.D {
+	return bson.D{
+		{Key: "_id", Value: k.ID},
+		{Key: "user_id", Value: k.UserID},
+		{Key: "name", Value: k.Name},
+		{Key: "prefix", Value: k.Prefix},
+		{Key: "hash", Value: k.Hash},
+		{Key: "scopes", Value: bson.A{k.Scopes[0]}},
+		{Key: "created_at", Value: k.CreatedAt},
+	}
+}
+
+func TestMongoAPIKeyRepository_Store(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tKey := tests.NewAPIKey()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		r := repository.NewMongoAPIKeyRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Store(noopCtx, tKey)
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   1,
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoAPIKeyRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Store(noopCtx, tKey)
+
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoAPIKeyRepository_GetByHash(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tKey := tests.NewAPIKey()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, apiKeyBsonD(tKey)))
+		r := repository.NewMongoAPIKeyRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetByHash(noopCtx, tKey.Hash)
+
+		require.NoError(mt, err)
+		assert.EqualValues(mt, tKey, result)
+	})
+
+	mt.Run("not exists", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch))
+		r := repository.NewMongoAPIKeyRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetByHash(noopCtx, tKey.Hash)
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrNotFound)
+	})
+}
+
+func TestMongoAPIKeyRepository_ListByUser(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tKey := tests.NewAPIKey()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, tableName, mtest.FirstBatch, apiKeyBsonD(tKey)),
+			mtest.CreateCursorResponse(0, tableName, mtest.NextBatch),
+		)
+		r := repository.NewMongoAPIKeyRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.ListByUser(noopCtx, tKey.UserID)
+
+		require.NoError(mt, err)
+		assert.Equal(mt, []*domain.APIKey{tKey}, result)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoAPIKeyRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.ListByUser(noopCtx, tKey.UserID)
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoAPIKeyRepository_Delete(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tKey := tests.NewAPIKey()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}})
+		r := repository.NewMongoAPIKeyRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Delete(noopCtx, tKey.ID)
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("not exists", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}})
+		r := repository.NewMongoAPIKeyRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Delete(noopCtx, tKey.ID)
+
+		assert.ErrorIs(mt, err, domain.ErrNoAffected)
+	})
+}
+
+func TestMongoAPIKeyRepository_UpdateLastUsed(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tKey := tests.NewAPIKey()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+		r := repository.NewMongoAPIKeyRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.UpdateLastUsed(noopCtx, tKey.ID, time.Now())
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoAPIKeyRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.UpdateLastUsed(noopCtx, tKey.ID, time.Now())
+
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
@@ -0,0 +1,242 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/audit"
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+const (
+	// keyPrefix starts every API key, so leaked keys are easy to find by scanners
+	keyPrefix = "shk_"
+	// shownPrefixLen is the length of key beginning which is stored in plain text
+	shownPrefixLen = len(keyPrefix) + 8
+	// lastUsedPrecision limits how often last usage of key is written
+	lastUsedPrecision = time.Minute
+)
+
+type apiKeyUsecase struct {
+	apiKeyRepo     domain.APIKeyRepository
+	userRepo       domain.UserRepository
+	auditRepo      domain.AuditRepository
+	contextTimeout time.Duration
+	logger         *zap.Logger
+	tracer         trace.Tracer
+}
+
+// NewAPIKeyUsecase will create new an apiKeyUsecase object representation of apikey.Usecase interface
+func NewAPIKeyUsecase(k domain.APIKeyRepository, u domain.UserRepository, a domain.AuditRepository, timeout time.Duration, logger *zap.Logger, tracer trace.Tracer) domain.APIKeyUsecase {
+	return &apiKeyUsecase{
+		apiKeyRepo:     k,
+		userRepo:       u,
+		auditRepo:      a,
+		contextTimeout: timeout,
+		logger:         logger,
+		tracer:         tracer,
+	}
+}
+
+// Create creates API key of authenticated user, the key is returned only once
+func (uc *apiKeyUsecase) Create(c context.Context, create domain.CreateAPIKey, claims *auth.Claims) (*domain.NewAPIKey, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Create",
+		trace.WithAttributes(
+			attribute.String("userid", claims.Subject)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	userID, err := primitive.ObjectIDFromHex(claims.Subject)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("user ID is not valid ObjectID: %w: %s", domain.ErrBadParamInput, err.Error())
+	}
+
+	key, err := generateKey()
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("can't generate API key: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	k := &domain.NewAPIKey{
+		APIKey: domain.APIKey{
+			ID:        primitive.NewObjectID(),
+			UserID:    userID,
+			Name:      create.Name,
+			Prefix:    key[:shownPrefixLen],
+			Hash:      hashKey(key),
+			Scopes:    create.Scopes,
+			CreatedAt: time.Now().Truncate(time.Millisecond).UTC(),
+		},
+		Key: key,
+	}
+
+	if err = uc.apiKeyRepo.Store(ctx, &k.APIKey); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err = uc.record(ctx, claims, domain.AuditCreateAPIKey, nil, &k.APIKey); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// List returns API keys of authenticated user
+func (uc *apiKeyUsecase) List(c context.Context, claims *auth.Claims) ([]*domain.APIKey, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase List",
+		trace.WithAttributes(
+			attribute.String("userid", claims.Subject)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	userID, err := primitive.ObjectIDFromHex(claims.Subject)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("user ID is not valid ObjectID: %w: %s", domain.ErrBadParamInput, err.Error())
+	}
+
+	return uc.apiKeyRepo.ListByUser(ctx, userID)
+}
+
+// Revoke deletes API key, only owner of the key can revoke it
+func (uc *apiKeyUsecase) Revoke(c context.Context, id string, claims *auth.Claims) error {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Revoke",
+		trace.WithAttributes(
+			attribute.String("apikeyid", id)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("API key ID is not valid ObjectID: %w: %s", domain.ErrBadParamInput, err.Error())
+	}
+
+	k, err := uc.apiKeyRepo.GetByID(ctx, objID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if k.UserID.Hex() != claims.Subject {
+		span.RecordError(domain.ErrForbidden)
+		return domain.ErrForbidden
+	}
+
+	if err = uc.record(ctx, claims, domain.AuditRevokeAPIKey, k, nil); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return uc.apiKeyRepo.Delete(ctx, objID)
+}
+
+// Authenticate returns claims of user who owns API key, claims are limited
+// by scopes of the key. Keys of suspended and deleted users are rejected.
+func (uc *apiKeyUsecase) Authenticate(c context.Context, key string) (*auth.Claims, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Authenticate",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	k, err := uc.apiKeyRepo.GetByHash(ctx, hashKey(key))
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("%w: %s", domain.ErrAuthenticationFailure, err.Error())
+	}
+	span.SetAttributes(attribute.String("apikeyid", k.ID.Hex()))
+
+	u, err := uc.userRepo.GetByID(ctx, k.UserID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("%w: %s", domain.ErrAuthenticationFailure, err.Error())
+	}
+
+	if u.IsSuspended() {
+		err = fmt.Errorf("user %s is suspended: %w", u.ID.Hex(), domain.ErrForbidden)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	now := time.Now()
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= lastUsedPrecision {
+		// failed write of last usage must not break requests of services
+		if err = uc.apiKeyRepo.UpdateLastUsed(ctx, k.ID, now.Truncate(time.Millisecond).UTC()); err != nil {
+			uc.logger.Warn("can't update API key last usage", zap.String("apikeyid", k.ID.Hex()), zap.Error(err))
+		}
+	}
+
+	claims := auth.NewClaims(u.ID.Hex(), u.Roles, now, auth.AccessTokenTTL)
+	claims.Scopes = k.Scopes
+	return claims, nil
+}
+
+// record stores audit entry of API key change, creates are recorded after key
+// is stored, revokes are recorded before key is deleted
+func (uc *apiKeyUsecase) record(ctx context.Context, claims *auth.Claims, action string, before, after *domain.APIKey) error {
+	target := ""
+	if after != nil {
+		target = after.ID.Hex()
+	} else if before != nil {
+		target = before.ID.Hex()
+	}
+
+	e := audit.NewEntry(ctx, claims, action, target)
+
+	var err error
+	if e.Changes, err = audit.Diff(before, after); err != nil {
+		return err
+	}
+
+	return uc.auditRepo.Store(ctx, e)
+}
+
+func generateKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return keyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashKey(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,171 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/apikey/mock"
+	"github.com/semka95/shortener/backend/apikey/usecase"
+	auditMock "github.com/semka95/shortener/backend/audit/mock"
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/tests"
+	userMock "github.com/semka95/shortener/backend/user/mock"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+var tracer = sdktrace.NewTracerProvider().Tracer("")
+
+func TestAPIKeyUsecase_Create(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tUser := tests.NewUser()
+	claims := auth.NewClaims(tUser.ID.Hex(), tUser.Roles, time.Now(), time.Minute)
+	create := domain.CreateAPIKey{Name: "notifications", Scopes: []string{auth.ScopeURLCreate}}
+
+	repository := mock.NewMockAPIKeyRepository(controller)
+	auditRepository := auditMock.NewMockAuditRepository(controller)
+	uc := usecase.NewAPIKeyUsecase(repository, userMock.NewMockUserRepository(controller), auditRepository, 10*time.Second, zap.NewNop(), tracer)
+
+	t.Run("success", func(t *testing.T) {
+		var stored *domain.APIKey
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, k *domain.APIKey) error {
+			stored = k
+			return nil
+		})
+		auditRepository.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.AuditEntry) error {
+			assert.Equal(t, domain.AuditCreateAPIKey, e.Action)
+			assert.Equal(t, tUser.ID.Hex(), e.Actor)
+			assert.NotContains(t, e.Changes, "hash")
+			return nil
+		})
+
+		result, err := uc.Create(context.Background(), create, claims)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(result.Key, "shk_"))
+		assert.True(t, strings.HasPrefix(result.Key, result.Prefix))
+		assert.Equal(t, tUser.ID, stored.UserID)
+		assert.Equal(t, create.Scopes, stored.Scopes)
+		assert.NotEmpty(t, stored.Hash)
+		assert.NotContains(t, stored.Hash, result.Key)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(domain.ErrInternalServerError)
+
+		result, err := uc.Create(context.Background(), create, claims)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+		assert.Nil(t, result)
+	})
+}
+
+func TestAPIKeyUsecase_Revoke(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tKey := tests.NewAPIKey()
+	owner := auth.NewClaims(tKey.UserID.Hex(), []string{auth.RoleUser}, time.Now(), time.Minute)
+	other := auth.NewClaims("507f191e810c19729de860eb", []string{auth.RoleUser}, time.Now(), time.Minute)
+
+	repository := mock.NewMockAPIKeyRepository(controller)
+	auditRepository := auditMock.NewMockAuditRepository(controller)
+	uc := usecase.NewAPIKeyUsecase(repository, userMock.NewMockUserRepository(controller), auditRepository, 10*time.Second, zap.NewNop(), tracer)
+
+	t.Run("success", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tKey.ID).Return(tKey, nil)
+		auditRepository.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.AuditEntry) error {
+			assert.Equal(t, domain.AuditRevokeAPIKey, e.Action)
+			assert.Equal(t, tKey.ID.Hex(), e.Target)
+			return nil
+		})
+		repository.EXPECT().Delete(gomock.Any(), tKey.ID).Return(nil)
+
+		err := uc.Revoke(context.Background(), tKey.ID.Hex(), owner)
+		require.NoError(t, err)
+	})
+
+	t.Run("not owner", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tKey.ID).Return(tKey, nil)
+
+		err := uc.Revoke(context.Background(), tKey.ID.Hex(), other)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("id is not valid", func(t *testing.T) {
+		err := uc.Revoke(context.Background(), "not valid id", owner)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	})
+}
+
+func TestAPIKeyUsecase_Authenticate(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repository := mock.NewMockAPIKeyRepository(controller)
+	userRepository := userMock.NewMockUserRepository(controller)
+	uc := usecase.NewAPIKeyUsecase(repository, userRepository, auditMock.NewMockAuditRepository(controller), 10*time.Second, zap.NewNop(), tracer)
+
+	t.Run("success", func(t *testing.T) {
+		tKey := tests.NewAPIKey()
+		tUser := tests.NewUser()
+		repository.EXPECT().GetByHash(gomock.Any(), tKey.Hash).Return(tKey, nil)
+		userRepository.EXPECT().GetByID(gomock.Any(), tKey.UserID).Return(tUser, nil)
+		repository.EXPECT().UpdateLastUsed(gomock.Any(), tKey.ID, gomock.Any()).Return(nil)
+
+		claims, err := uc.Authenticate(context.Background(), "shk_test")
+		require.NoError(t, err)
+		assert.Equal(t, tUser.ID.Hex(), claims.Subject)
+		assert.Equal(t, tUser.Roles, claims.Roles)
+		assert.Equal(t, tKey.Scopes, claims.Scopes)
+	})
+
+	t.Run("recently used key", func(t *testing.T) {
+		tKey := tests.NewAPIKey()
+		lastUsed := time.Now().Add(-time.Second)
+		tKey.LastUsedAt = &lastUsed
+		repository.EXPECT().GetByHash(gomock.Any(), tKey.Hash).Return(tKey, nil)
+		userRepository.EXPECT().GetByID(gomock.Any(), tKey.UserID).Return(tests.NewUser(), nil)
+
+		_, err := uc.Authenticate(context.Background(), "shk_test")
+		require.NoError(t, err)
+	})
+
+	t.Run("last usage error", func(t *testing.T) {
+		tKey := tests.NewAPIKey()
+		repository.EXPECT().GetByHash(gomock.Any(), tKey.Hash).Return(tKey, nil)
+		userRepository.EXPECT().GetByID(gomock.Any(), tKey.UserID).Return(tests.NewUser(), nil)
+		repository.EXPECT().UpdateLastUsed(gomock.Any(), tKey.ID, gomock.Any()).Return(domain.ErrInternalServerError)
+
+		_, err := uc.Authenticate(context.Background(), "shk_test")
+		require.NoError(t, err)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		repository.EXPECT().GetByHash(gomock.Any(), gomock.Any()).Return(nil, domain.ErrNotFound)
+
+		claims, err := uc.Authenticate(context.Background(), "shk_unknown")
+		assert.ErrorIs(t, err, domain.ErrAuthenticationFailure)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("suspended user", func(t *testing.T) {
+		tKey := tests.NewAPIKey()
+		tUser := tests.NewUser()
+		suspendedAt := time.Now()
+		tUser.SuspendedAt = &suspendedAt
+		repository.EXPECT().GetByHash(gomock.Any(), tKey.Hash).Return(tKey, nil)
+		userRepository.EXPECT().GetByID(gomock.Any(), tKey.UserID).Return(tUser, nil)
+
+		claims, err := uc.Authenticate(context.Background(), "shk_test")
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, claims)
+	})
+}
@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/web"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+// NewEntry creates audit entry of action, request id and ip are taken from
+// ctx. If actor is nil, claims stored in ctx are used, so actions of
+// unauthenticated users have no actor.
+func NewEntry(ctx context.Context, actor *auth.Claims, action, target string) *domain.AuditEntry {
+	if actor == nil {
+		actor = auth.FromContext(ctx)
+	}
+	info := web.RequestInfoFromContext(ctx)
+
+	e := &domain.AuditEntry{
+		ID:        primitive.NewObjectID(),
+		Action:    action,
+		Target:    target,
+		RequestID: info.ID,
+		IP:        info.IP,
+		CreatedAt: time.Now().Truncate(time.Millisecond).UTC(),
+	}
+	if actor != nil {
+		e.Actor = actor.Subject
+		e.ActorRoles = actor.Roles
+	}
+
+	return e
+}
+
+// Diff returns changed fields of item, before is nil for created items and
+// after is nil for deleted ones. Fields are compared by their json
+// representation, so fields hidden from json (e.g. password hashes) are
+// never recorded.
+func Diff(before, after interface{}) (map[string]domain.AuditChange, error) {
+	b, err := toMap(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := toMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]domain.AuditChange)
+	for k, v := range b {
+		if !reflect.DeepEqual(v, a[k]) {
+			changes[k] = domain.AuditChange{Before: v, After: a[k]}
+		}
+	}
+	for k, v := range a {
+		if _, ok := b[k]; !ok {
+			changes[k] = domain.AuditChange{After: v}
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return changes, nil
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	if v == nil {
+		return m, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return m, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("can't marshal audited item: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+	if err = json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("can't unmarshal audited item: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return m, nil
+}
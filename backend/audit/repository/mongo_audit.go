@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+)
+
+const auditCollection = "audit"
+
+type mongoAuditRepository struct {
+	Conn   *mongo.Database
+	logger *zap.Logger
+	tracer trace.Tracer
+}
+
+// NewMongoAuditRepository will create an object that represent the audit.Repository interface
+func NewMongoAuditRepository(c *mongo.Client, db string, logger *zap.Logger, tracer trace.Tracer) domain.AuditRepository {
+	return &mongoAuditRepository{
+		Conn:   c.Database(db),
+		logger: logger,
+		tracer: tracer,
+	}
+}
+
+func (m *mongoAuditRepository) Store(ctx context.Context, e *domain.AuditEntry) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository Store",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("actor", e.Actor),
+			attribute.String("action", e.Action)),
+	)
+	defer span.End()
+
+	_, err := m.Conn.Collection(auditCollection).InsertOne(ctx, e)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("audit entry store error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (m *mongoAuditRepository) StoreMany(ctx context.Context, entries []*domain.AuditEntry) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository StoreMany",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.Int("entries", len(entries))),
+	)
+	defer span.End()
+
+	docs := make([]interface{}, len(entries))
+	for i, e := range entries {
+		docs[i] = e
+	}
+
+	_, err := m.Conn.Collection(auditCollection).InsertMany(ctx, docs)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("audit entries store error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// List returns page of entries sorted by id, so newest entries are first,
+// the cursor is id of the last entry on the page
+func (m *mongoAuditRepository) List(ctx context.Context, query domain.AuditQuery) (*domain.AuditList, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository List",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	filter := bson.D{}
+	if query.Actor != "" {
+		filter = append(filter, primitive.E{Key: "actor", Value: query.Actor})
+	}
+	if query.Target != "" {
+		filter = append(filter, primitive.E{Key: "target", Value: query.Target})
+	}
+	if query.Action != "" {
+		filter = append(filter, primitive.E{Key: "action", Value: query.Action})
+	}
+
+	created := bson.D{}
+	if !query.From.IsZero() {
+		created = append(created, primitive.E{Key: "$gte", Value: query.From.UTC()})
+	}
+	if !query.To.IsZero() {
+		created = append(created, primitive.E{Key: "$lt", Value: query.To.UTC()})
+	}
+	if len(created) > 0 {
+		filter = append(filter, primitive.E{Key: "created_at", Value: created})
+	}
+
+	if query.Cursor != "" {
+		cursor, err := primitive.ObjectIDFromHex(query.Cursor)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("can't decode cursor: %w: %s", domain.ErrBadParamInput, err.Error())
+		}
+		filter = append(filter, primitive.E{Key: "_id", Value: bson.D{primitive.E{Key: "$lt", Value: cursor}}})
+	}
+
+	// one extra document is fetched to find out whether there is a next page
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(int64(query.Limit + 1))
+
+	cur, err := m.Conn.Collection(auditCollection).Find(ctx, filter, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("audit entries list error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	list := make([]*domain.AuditEntry, 0)
+	if err = cur.All(ctx, &list); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("can't decode audit entries: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	result := &domain.AuditList{Items: list}
+	if len(list) > query.Limit {
+		result.Items = list[:query.Limit]
+		result.NextCursor = result.Items[len(result.Items)-1].ID.Hex()
+	}
+
+	return result, nil
+}
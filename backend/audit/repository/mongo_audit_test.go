@@ -0,0 +1,159 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/semka95/shortener/backend/audit/repository"
+	"github.com/semka95/shortener/backend/domain"
+)
+
+var tracer = sdktrace.NewTracerProvider().Tracer("")
+var noopCtx = context.Background()
+
+func TestMongoAuditRepository_Store(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	entry := &domain.AuditEntry{
+		ID:        primitive.NewObjectID(),
+		Actor:     "507f191e810c19729de860ea",
+		Action:    domain.AuditDisableURL,
+		Target:    "test123",
+		Details:   map[string]string{"reason": "phishing"},
+		CreatedAt: time.Now().Truncate(time.Millisecond).UTC(),
+	}
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		r := repository.NewMongoAuditRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Store(noopCtx, entry)
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   1,
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoAuditRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Store(noopCtx, entry)
+
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoAuditRepository_StoreMany(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	entries := []*domain.AuditEntry{
+		{ID: primitive.NewObjectID(), Action: domain.AuditDeleteURL, Target: "test123"},
+		{ID: primitive.NewObjectID(), Action: domain.AuditDeleteURL, Target: "test456"},
+	}
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		r := repository.NewMongoAuditRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.StoreMany(noopCtx, entries)
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   1,
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoAuditRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.StoreMany(noopCtx, entries)
+
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoAuditRepository_List(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	newer, _ := primitive.ObjectIDFromHex("640f1c2e9b1e8a3d5c7b9a10")
+	older, _ := primitive.ObjectIDFromHex("640f1c2e9b1e8a3d5c7b9a0f")
+	createdAt := time.Now().Truncate(time.Millisecond).UTC()
+	entryBsonD := func(id primitive.ObjectID) bson.D {
+		return bson.D{
+			{Key: "_id", Value: id},
+			{Key: "actor", Value: "507f191e810c19729de860ea"},
+			{Key: "actor_roles", Value: bson.A{"user"}},
+			{Key: "action", Value: domain.AuditUpdateURL},
+			{Key: "target", Value: "test123"},
+			{Key: "changes", Value: bson.D{
+				{Key: "link", Value: bson.D{
+					{Key: "before", Value: "http://www.example.org"},
+					{Key: "after", Value: "http://www.example.com"},
+				}},
+			}},
+			{Key: "request_id", Value: "request-id"},
+			{Key: "ip", Value: "192.0.2.1"},
+			{Key: "created_at", Value: createdAt},
+		}
+	}
+
+	mt.Run("success with next page", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "db.audit", mtest.FirstBatch, entryBsonD(newer), entryBsonD(older)))
+		r := repository.NewMongoAuditRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.List(noopCtx, domain.AuditQuery{
+			Limit:  1,
+			Actor:  "507f191e810c19729de860ea",
+			Target: "test123",
+			From:   createdAt.Add(-time.Hour),
+			To:     createdAt.Add(time.Hour),
+		})
+
+		require.NoError(mt, err)
+		require.Len(mt, result.Items, 1)
+		e := result.Items[0]
+		assert.Equal(mt, newer, e.ID)
+		assert.Equal(mt, []string{"user"}, e.ActorRoles)
+		assert.Equal(mt, domain.AuditChange{Before: "http://www.example.org", After: "http://www.example.com"}, e.Changes["link"])
+		assert.Equal(mt, "192.0.2.1", e.IP)
+		assert.Equal(mt, createdAt, e.CreatedAt)
+		assert.Equal(mt, newer.Hex(), result.NextCursor)
+	})
+
+	mt.Run("last page", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "db.audit", mtest.FirstBatch, entryBsonD(older)))
+		r := repository.NewMongoAuditRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.List(noopCtx, domain.AuditQuery{Limit: 10, Cursor: newer.Hex()})
+
+		require.NoError(mt, err)
+		assert.Len(mt, result.Items, 1)
+		assert.Empty(mt, result.NextCursor)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoAuditRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.List(noopCtx, domain.AuditQuery{Limit: 10})
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
@@ -0,0 +1,17 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrCacheMiss will throw if the requested key is not in the cache
+var ErrCacheMiss = errors.New("cache: key not found")
+
+// Cache represents key-value cache backend contract
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// LRU is an in-process least recently used cache with per entry TTL
+type LRU struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+// NewLRU will create new an LRU cache which holds at most size entries
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+// Get returns value by given key, ErrCacheMiss is returned if key is absent or expired
+func (c *LRU) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+
+	entry := el.Value.(*lruEntry)
+	if !entry.expiresAt.After(time.Now()) {
+		c.removeElement(el)
+		return nil, ErrCacheMiss
+	}
+	c.ll.MoveToFront(el)
+
+	return entry.value, nil
+}
+
+// Set stores value by given key, the least recently used entry is evicted if cache is full
+func (c *LRU) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.ll.MoveToFront(el)
+		return nil
+	}
+
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+
+	for c.size > 0 && c.ll.Len() > c.size {
+		c.removeElement(c.ll.Back())
+	}
+
+	return nil
+}
+
+// Delete removes value by given key
+func (c *LRU) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+
+	return nil
+}
+
+// Len returns number of entries in the cache, including expired ones that were not evicted yet
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+func (c *LRU) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*lruEntry).key)
+}
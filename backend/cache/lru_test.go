@@ -0,0 +1,56 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/semka95/shortener/backend/cache"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewLRU(2)
+
+	t.Run("miss", func(t *testing.T) {
+		_, err := c.Get(ctx, "none")
+		assert.ErrorIs(t, err, cache.ErrCacheMiss)
+	})
+
+	t.Run("hit", func(t *testing.T) {
+		require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+		v, err := c.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("1"), v)
+	})
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		require.NoError(t, c.Set(ctx, "b", []byte("2"), time.Minute))
+		_, err := c.Get(ctx, "a")
+		require.NoError(t, err)
+		require.NoError(t, c.Set(ctx, "c", []byte("3"), time.Minute))
+
+		_, err = c.Get(ctx, "b")
+		assert.ErrorIs(t, err, cache.ErrCacheMiss)
+		_, err = c.Get(ctx, "a")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, c.Len())
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		require.NoError(t, c.Set(ctx, "d", []byte("4"), time.Millisecond))
+		time.Sleep(5 * time.Millisecond)
+		_, err := c.Get(ctx, "d")
+		assert.ErrorIs(t, err, cache.ErrCacheMiss)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, c.Set(ctx, "e", []byte("5"), time.Minute))
+		require.NoError(t, c.Delete(ctx, "e"))
+		_, err := c.Get(ctx, "e")
+		assert.ErrorIs(t, err, cache.ErrCacheMiss)
+	})
+}
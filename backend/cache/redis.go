@@ -0,0 +1,283 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// RedisConfig stores Redis configuration
+type RedisConfig struct {
+	Address  string `yaml:"address"`
+	Password string `yaml:"pwd"`
+	DB       int    `yaml:"db"`
+	PoolSize int    `yaml:"pool_size"`
+}
+
+// RedisError represents error reply returned by server
+type RedisError string
+
+func (e RedisError) Error() string {
+	return "redis: " + string(e)
+}
+
+type redisConn struct {
+	conn net.Conn
+	rd   *bufio.Reader
+	wr   *bufio.Writer
+}
+
+// Redis is a minimal client for Redis and other RESP compatible servers,
+// it supports only commands which are needed for caching
+type Redis struct {
+	cfg    RedisConfig
+	dialer net.Dialer
+	pool   chan *redisConn
+}
+
+// NewRedis will create new a Redis client, connections are established lazily
+func NewRedis(cfg RedisConfig) *Redis {
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = 10
+	}
+
+	return &Redis{
+		cfg:    cfg,
+		dialer: net.Dialer{Timeout: 5 * time.Second},
+		pool:   make(chan *redisConn, cfg.PoolSize),
+	}
+}
+
+// Get returns value by given key, ErrCacheMiss is returned if key is absent
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	reply, err := r.Do(ctx, "GET", key)
+	if err != nil {
+		return nil, err
+	}
+
+	if reply == nil {
+		return nil, ErrCacheMiss
+	}
+
+	b, ok := reply.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("redis: unexpected GET reply type %T", reply)
+	}
+
+	return b, nil
+}
+
+// Set stores value by given key with given time to live
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	ms := ttl.Milliseconds()
+	if ms <= 0 {
+		ms = 1
+	}
+
+	_, err := r.Do(ctx, "SET", key, value, "PX", strconv.FormatInt(ms, 10))
+	return err
+}
+
+// Delete removes value by given key
+func (r *Redis) Delete(ctx context.Context, key string) error {
+	_, err := r.Do(ctx, "DEL", key)
+	return err
+}
+
+// Ping checks connection to the server
+func (r *Redis) Ping(ctx context.Context) error {
+	_, err := r.Do(ctx, "PING")
+	return err
+}
+
+// Close closes all idle connections
+func (r *Redis) Close() error {
+	for {
+		select {
+		case c := <-r.pool:
+			_ = c.conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// Do sends command to the server and returns its reply. Reply is one of
+// nil, string, int64, []byte or []interface{} types.
+func (r *Redis) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	c, err := r.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := c.do(ctx, args...)
+	var rErr RedisError
+	if err != nil && !errors.As(err, &rErr) {
+		// connection state is unknown after network or protocol error
+		_ = c.conn.Close()
+		return nil, err
+	}
+	r.put(c)
+
+	return reply, err
+}
+
+func (r *Redis) conn(ctx context.Context) (*redisConn, error) {
+	select {
+	case c := <-r.pool:
+		return c, nil
+	default:
+	}
+
+	conn, err := r.dialer.DialContext(ctx, "tcp", r.cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("redis: can't connect: %w", err)
+	}
+
+	c := &redisConn{
+		conn: conn,
+		rd:   bufio.NewReader(conn),
+		wr:   bufio.NewWriter(conn),
+	}
+
+	if r.cfg.Password != "" {
+		if _, err = c.do(ctx, "AUTH", r.cfg.Password); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	if r.cfg.DB != 0 {
+		if _, err = c.do(ctx, "SELECT", strconv.Itoa(r.cfg.DB)); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (r *Redis) put(c *redisConn) {
+	select {
+	case r.pool <- c:
+	default:
+		_ = c.conn.Close()
+	}
+}
+
+func (c *redisConn) do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Time{}
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if err := writeCommand(c.wr, args...); err != nil {
+		return nil, err
+	}
+	if err := c.wr.Flush(); err != nil {
+		return nil, err
+	}
+
+	return readReply(c.rd)
+}
+
+func writeCommand(w *bufio.Writer, args ...interface{}) error {
+	if _, err := fmt.Fprintf(w, "*%d\r\n", len(args)); err != nil {
+		return err
+	}
+
+	for _, arg := range args {
+		var b []byte
+		switch v := arg.(type) {
+		case string:
+			b = []byte(v)
+		case []byte:
+			b = v
+		default:
+			return fmt.Errorf("redis: unsupported argument type %T", arg)
+		}
+
+		if _, err := fmt.Fprintf(w, "$%d\r\n", len(b)); err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+		if _, err := w.WriteString("\r\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readReply(rd *bufio.Reader) (interface{}, error) {
+	line, err := readLine(rd)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return string(line[1:]), nil
+	case '-':
+		return nil, RedisError(line[1:])
+	case ':':
+		return strconv.ParseInt(string(line[1:]), 10, 64)
+	case '$':
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil {
+			return nil, fmt.Errorf("redis: bad bulk length: %w", err)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+
+		b := make([]byte, n+2)
+		if _, err = io.ReadFull(rd, b); err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	case '*':
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil {
+			return nil, fmt.Errorf("redis: bad array length: %w", err)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+
+		result := make([]interface{}, n)
+		for i := range result {
+			if result[i], err = readReply(rd); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
+
+func readLine(rd *bufio.Reader) ([]byte, error) {
+	line, err := rd.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: bad line terminator %q", line)
+	}
+
+	return line[:len(line)-2], nil
+}
@@ -0,0 +1,210 @@
+package cache_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/semka95/shortener/backend/cache"
+)
+
+// fakeRedis is an in-memory server which speaks enough RESP to test the client
+type fakeRedis struct {
+	mu       sync.Mutex
+	ln       net.Listener
+	password string
+	data     map[string]string
+	expires  map[string]time.Time
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &fakeRedis{
+		ln:       ln,
+		password: password,
+		data:     make(map[string]string),
+		expires:  make(map[string]time.Time),
+	}
+	go s.serve()
+	t.Cleanup(func() { _ = ln.Close() })
+
+	return s
+}
+
+func (s *fakeRedis) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+	authed := s.password == ""
+
+	for {
+		args, err := readCommand(rd)
+		if err != nil {
+			return
+		}
+
+		cmd := strings.ToUpper(args[0])
+		if !authed && cmd != "AUTH" {
+			fmt.Fprint(conn, "-NOAUTH Authentication required.\r\n")
+			continue
+		}
+
+		switch cmd {
+		case "AUTH":
+			if args[1] != s.password {
+				fmt.Fprint(conn, "-WRONGPASS invalid password\r\n")
+				continue
+			}
+			authed = true
+			fmt.Fprint(conn, "+OK\r\n")
+		case "PING":
+			fmt.Fprint(conn, "+PONG\r\n")
+		case "GET":
+			v, ok := s.get(args[1])
+			if !ok {
+				fmt.Fprint(conn, "$-1\r\n")
+				continue
+			}
+			fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(v), v)
+		case "SET":
+			s.set(args[1], args[2], args[3:])
+			fmt.Fprint(conn, "+OK\r\n")
+		case "DEL":
+			fmt.Fprintf(conn, ":%d\r\n", s.del(args[1]))
+		default:
+			fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", args[0])
+		}
+	}
+}
+
+func (s *fakeRedis) get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if exp, ok := s.expires[key]; ok && !exp.After(time.Now()) {
+		delete(s.data, key)
+		delete(s.expires, key)
+	}
+	v, ok := s.data[key]
+	return v, ok
+}
+
+func (s *fakeRedis) set(key, value string, opts []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data[key] = value
+	delete(s.expires, key)
+	if len(opts) == 2 && strings.ToUpper(opts[0]) == "PX" {
+		ms, _ := strconv.Atoi(opts[1])
+		s.expires[key] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+	}
+}
+
+func (s *fakeRedis) del(key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data[key]; !ok {
+		return 0
+	}
+	delete(s.data, key)
+	delete(s.expires, key)
+	return 1
+}
+
+func readCommand(rd *bufio.Reader) ([]string, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		if _, err = rd.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := rd.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimSuffix(arg, "\r\n")
+	}
+
+	return args, nil
+}
+
+func TestRedis(t *testing.T) {
+	ctx := context.Background()
+	srv := newFakeRedis(t, "secret")
+	c := cache.NewRedis(cache.RedisConfig{Address: srv.ln.Addr().String(), Password: "secret"})
+	defer c.Close()
+
+	t.Run("ping", func(t *testing.T) {
+		require.NoError(t, c.Ping(ctx))
+	})
+
+	t.Run("miss", func(t *testing.T) {
+		_, err := c.Get(ctx, "none")
+		assert.ErrorIs(t, err, cache.ErrCacheMiss)
+	})
+
+	t.Run("set and get", func(t *testing.T) {
+		require.NoError(t, c.Set(ctx, "key", []byte("value"), time.Minute))
+		v, err := c.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value"), v)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		require.NoError(t, c.Set(ctx, "short", []byte("value"), time.Millisecond))
+		time.Sleep(5 * time.Millisecond)
+		_, err := c.Get(ctx, "short")
+		assert.ErrorIs(t, err, cache.ErrCacheMiss)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, c.Delete(ctx, "key"))
+		_, err := c.Get(ctx, "key")
+		assert.ErrorIs(t, err, cache.ErrCacheMiss)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		_, err := c.Do(ctx, "UNKNOWN")
+		var rErr cache.RedisError
+		assert.ErrorAs(t, err, &rErr)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		wrong := cache.NewRedis(cache.RedisConfig{Address: srv.ln.Addr().String(), Password: "wrong"})
+		defer wrong.Close()
+
+		err := wrong.Ping(ctx)
+		var rErr cache.RedisError
+		assert.ErrorAs(t, err, &rErr)
+	})
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+)
+
+// topLimit is the number of entries returned in each top list of link statistics
+const topLimit = 10
+
+type mongoClickRepository struct {
+	Conn   *mongo.Database
+	logger *zap.Logger
+	tracer trace.Tracer
+}
+
+// NewMongoClickRepository will create an object that represent the click.Repository interface
+func NewMongoClickRepository(c *mongo.Client, db string, logger *zap.Logger, tracer trace.Tracer) domain.ClickRepository {
+	return &mongoClickRepository{
+		Conn:   c.Database(db),
+		logger: logger,
+		tracer: tracer,
+	}
+}
+
+func (m *mongoClickRepository) StoreMany(ctx context.Context, clicks []*domain.Click) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository StoreMany",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.Int("clicks", len(clicks))),
+	)
+	defer span.End()
+
+	if len(clicks) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, len(clicks))
+	for i, c := range clicks {
+		docs[i] = c
+	}
+
+	_, err := m.Conn.Collection("click").InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("clicks store error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// statsResult represents the result of stats aggregation $facet stage
+type statsResult struct {
+	Total []struct {
+		Clicks int64 `bson:"clicks"`
+	} `bson:"total"`
+	Unique []struct {
+		Visitors int64 `bson:"visitors"`
+	} `bson:"unique"`
+	Buckets   []domain.StatsBucket `bson:"buckets"`
+	Referrers []domain.StatsEntry  `bson:"referrers"`
+	Browsers  []domain.StatsEntry  `bson:"browsers"`
+	OS        []domain.StatsEntry  `bson:"os"`
+	Countries []domain.StatsEntry  `bson:"countries"`
+}
+
+func topPipeline(field string) bson.A {
+	return bson.A{
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$" + field},
+			{Key: "clicks", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "clicks", Value: -1}, {Key: "_id", Value: 1}}}},
+		bson.D{{Key: "$limit", Value: topLimit}},
+	}
+}
+
+func (m *mongoClickRepository) Stats(ctx context.Context, query domain.StatsQuery) (*domain.ClickStats, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository Stats",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("urlid", query.URLID)),
+	)
+	defer span.End()
+
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: bson.D{
+			{Key: "url_id", Value: query.URLID},
+			{Key: "timestamp", Value: bson.D{
+				{Key: "$gte", Value: query.From},
+				{Key: "$lt", Value: query.To},
+			}},
+		}}},
+		bson.D{{Key: "$facet", Value: bson.D{
+			{Key: "total", Value: bson.A{
+				bson.D{{Key: "$count", Value: "clicks"}},
+			}},
+			{Key: "unique", Value: bson.A{
+				bson.D{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$visitor_id"}}}},
+				bson.D{{Key: "$count", Value: "visitors"}},
+			}},
+			{Key: "buckets", Value: bson.A{
+				bson.D{{Key: "$group", Value: bson.D{
+					{Key: "_id", Value: bson.D{{Key: "$dateTrunc", Value: bson.D{
+						{Key: "date", Value: "$timestamp"},
+						{Key: "unit", Value: query.Interval},
+					}}}},
+					{Key: "clicks", Value: bson.D{{Key: "$sum", Value: 1}}},
+				}}},
+				bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+			}},
+			{Key: "referrers", Value: topPipeline("referrer")},
+			{Key: "browsers", Value: topPipeline("browser")},
+			{Key: "os", Value: topPipeline("os")},
+			{Key: "countries", Value: topPipeline("country")},
+		}}},
+	}
+
+	cur, err := m.Conn.Collection("click").Aggregate(ctx, pipeline)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("clicks stats error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	defer func(ctx context.Context) {
+		err = cur.Close(ctx)
+		if err != nil {
+			m.logger.Error("can't close cursor: ", zap.Error(err))
+		}
+	}(ctx)
+
+	res := new(statsResult)
+	if cur.Next(ctx) {
+		if err = cur.Decode(res); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("can't unmarshal document into stats: %w: %s", domain.ErrInternalServerError, err.Error())
+		}
+	}
+
+	if err = cur.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("stats cursor error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	stats := &domain.ClickStats{
+		URLID:        query.URLID,
+		From:         query.From,
+		To:           query.To,
+		Interval:     query.Interval,
+		Clicks:       res.Buckets,
+		TopReferrers: res.Referrers,
+		TopBrowsers:  res.Browsers,
+		TopOS:        res.OS,
+		TopCountries: res.Countries,
+	}
+	if len(res.Total) > 0 {
+		stats.TotalClicks = res.Total[0].Clicks
+	}
+	if len(res.Unique) > 0 {
+		stats.UniqueVisitors = res.Unique[0].Visitors
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,124 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/semka95/shortener/backend/click/repository"
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/tests"
+)
+
+var tracer = sdktrace.NewTracerProvider().Tracer("")
+var noopCtx = context.Background()
+
+func TestMongoClickRepository_StoreMany(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	clicks := []*domain.Click{tests.NewClick(), tests.NewClick()}
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		r := repository.NewMongoClickRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.StoreMany(noopCtx, clicks)
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("empty batch", func(mt *mtest.T) {
+		r := repository.NewMongoClickRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.StoreMany(noopCtx, nil)
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   1,
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoClickRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.StoreMany(noopCtx, clicks)
+
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoClickRepository_Stats(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	day := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	query := domain.StatsQuery{
+		URLID:    "test123",
+		From:     day,
+		To:       day.AddDate(0, 0, 2),
+		Interval: domain.StatsIntervalDay,
+	}
+
+	mt.Run("success", func(mt *mtest.T) {
+		ns := mt.Coll.Database().Name() + ".click"
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+			{Key: "total", Value: bson.A{bson.D{{Key: "clicks", Value: int32(3)}}}},
+			{Key: "unique", Value: bson.A{bson.D{{Key: "visitors", Value: int32(2)}}}},
+			{Key: "buckets", Value: bson.A{
+				bson.D{{Key: "_id", Value: day}, {Key: "clicks", Value: int32(1)}},
+				bson.D{{Key: "_id", Value: day.AddDate(0, 0, 1)}, {Key: "clicks", Value: int32(2)}},
+			}},
+			{Key: "referrers", Value: bson.A{bson.D{{Key: "_id", Value: "https://www.example.com/"}, {Key: "clicks", Value: int32(3)}}}},
+			{Key: "browsers", Value: bson.A{bson.D{{Key: "_id", Value: "Firefox"}, {Key: "clicks", Value: int32(3)}}}},
+			{Key: "os", Value: bson.A{bson.D{{Key: "_id", Value: "Linux"}, {Key: "clicks", Value: int32(3)}}}},
+			{Key: "countries", Value: bson.A{bson.D{{Key: "_id", Value: "NL"}, {Key: "clicks", Value: int32(3)}}}},
+		}))
+		r := repository.NewMongoClickRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		stats, err := r.Stats(noopCtx, query)
+
+		require.NoError(mt, err)
+		assert.Equal(mt, query.URLID, stats.URLID)
+		assert.EqualValues(mt, 3, stats.TotalClicks)
+		assert.EqualValues(mt, 2, stats.UniqueVisitors)
+		assert.Equal(mt, []domain.StatsBucket{{Time: day, Clicks: 1}, {Time: day.AddDate(0, 0, 1), Clicks: 2}}, stats.Clicks)
+		assert.Equal(mt, []domain.StatsEntry{{Value: "https://www.example.com/", Clicks: 3}}, stats.TopReferrers)
+		assert.Equal(mt, []domain.StatsEntry{{Value: "Firefox", Clicks: 3}}, stats.TopBrowsers)
+		assert.Equal(mt, []domain.StatsEntry{{Value: "Linux", Clicks: 3}}, stats.TopOS)
+		assert.Equal(mt, []domain.StatsEntry{{Value: "NL", Clicks: 3}}, stats.TopCountries)
+	})
+
+	mt.Run("no clicks", func(mt *mtest.T) {
+		ns := mt.Coll.Database().Name() + ".click"
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+			{Key: "total", Value: bson.A{}},
+			{Key: "unique", Value: bson.A{}},
+		}))
+		r := repository.NewMongoClickRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		stats, err := r.Stats(noopCtx, query)
+
+		require.NoError(mt, err)
+		assert.Zero(mt, stats.TotalClicks)
+		assert.Zero(mt, stats.UniqueVisitors)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoClickRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		_, err := r.Stats(noopCtx, query)
+
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
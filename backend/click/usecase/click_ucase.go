@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+const (
+	// defaultStatsRange is used when stats query has no lower bound
+	defaultStatsRange = 7 * 24 * time.Hour
+	// maxHourStatsRange limits number of hourly buckets
+	maxHourStatsRange = 31 * 24 * time.Hour
+	// maxDayStatsRange limits number of daily buckets
+	maxDayStatsRange = 366 * 24 * time.Hour
+)
+
+type clickUsecase struct {
+	clickRepo      domain.ClickRepository
+	urlRepo        domain.URLRepository
+	urlUsecase     domain.URLUsecase
+	geo            GeoLocator
+	contextTimeout time.Duration
+	batchSize      int
+	flushInterval  time.Duration
+	logger         *zap.Logger
+	tracer         trace.Tracer
+
+	mu     sync.RWMutex
+	closed bool
+	events chan *domain.Click
+	done   chan struct{}
+}
+
+// NewClickUsecase will create new a clickUsecase object representation of click.Usecase interface.
+// Clicks are buffered in memory and written by background worker in batches of batchSize clicks
+// or every flushInterval, whatever comes first. If buffer is full, clicks are dropped.
+// Access to stats is authorized by urls the same way as access to URLs.
+func NewClickUsecase(c domain.ClickRepository, u domain.URLRepository, urls domain.URLUsecase, geo GeoLocator, timeout time.Duration, bufferSize, batchSize int, flushInterval time.Duration, logger *zap.Logger, tracer trace.Tracer) domain.ClickUsecase {
+	uc := &clickUsecase{
+		clickRepo:      c,
+		urlRepo:        u,
+		urlUsecase:     urls,
+		geo:            geo,
+		contextTimeout: timeout,
+		batchSize:      batchSize,
+		flushInterval:  flushInterval,
+		logger:         logger,
+		tracer:         tracer,
+		events:         make(chan *domain.Click, bufferSize),
+		done:           make(chan struct{}),
+	}
+
+	go uc.run()
+
+	return uc
+}
+
+func (uc *clickUsecase) Record(ctx context.Context, click *domain.Click) {
+	_, span := uc.tracer.Start(
+		ctx,
+		"usecase Record",
+		trace.WithAttributes(
+			attribute.String("urlid", click.URLID)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+
+	if uc.closed {
+		return
+	}
+
+	select {
+	case uc.events <- click:
+	default:
+		span.AddEvent("click dropped, buffer is full")
+		uc.logger.Warn("click buffer is full, dropping click", zap.String("urlid", click.URLID))
+	}
+}
+
+func (uc *clickUsecase) Stats(c context.Context, query domain.StatsQuery, user *auth.Claims) (*domain.ClickStats, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Stats",
+		trace.WithAttributes(
+			attribute.String("urlid", query.URLID)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	u, err := uc.urlRepo.GetByID(ctx, query.URLID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("can't get %s url: %w", query.URLID, err)
+	}
+
+	if err = uc.urlUsecase.Authorize(ctx, u, user, auth.ActionStatsRead); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err = normalizeStatsQuery(&query, time.Now()); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	stats, err := uc.clickRepo.Stats(ctx, query)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// normalizeStatsQuery fills default query values and checks that requested range is valid
+func normalizeStatsQuery(query *domain.StatsQuery, now time.Time) error {
+	if query.Interval == "" {
+		query.Interval = domain.StatsIntervalDay
+	}
+	if query.To.IsZero() {
+		query.To = now
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-defaultStatsRange)
+	}
+	query.From = query.From.UTC()
+	query.To = query.To.UTC()
+
+	if !query.From.Before(query.To) {
+		return fmt.Errorf("stats range start must be before its end: %w", domain.ErrBadParamInput)
+	}
+
+	maxRange := maxDayStatsRange
+	if query.Interval == domain.StatsIntervalHour {
+		maxRange = maxHourStatsRange
+	}
+	if query.To.Sub(query.From) > maxRange {
+		return fmt.Errorf("stats range is too long for %s interval: %w", query.Interval, domain.ErrBadParamInput)
+	}
+
+	return nil
+}
+
+// Close stops accepting new clicks and waits until buffered ones are written
+func (uc *clickUsecase) Close(ctx context.Context) error {
+	uc.mu.Lock()
+	if !uc.closed {
+		uc.closed = true
+		close(uc.events)
+	}
+	uc.mu.Unlock()
+
+	select {
+	case <-uc.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (uc *clickUsecase) run() {
+	defer close(uc.done)
+
+	ticker := time.NewTicker(uc.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*domain.Click, 0, uc.batchSize)
+	for {
+		select {
+		case click, ok := <-uc.events:
+			if !ok {
+				uc.flush(batch)
+				return
+			}
+
+			uc.enrich(click)
+			batch = append(batch, click)
+			if len(batch) >= uc.batchSize {
+				uc.flush(batch)
+				batch = make([]*domain.Click, 0, uc.batchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				uc.flush(batch)
+				batch = make([]*domain.Click, 0, uc.batchSize)
+			}
+		}
+	}
+}
+
+func (uc *clickUsecase) flush(batch []*domain.Click) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase flush",
+		trace.WithAttributes(
+			attribute.Int("clicks", len(batch))),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	if err := uc.clickRepo.StoreMany(ctx, batch); err != nil {
+		span.RecordError(err)
+		uc.logger.Error("can't store clicks: ", zap.Int("clicks", len(batch)), zap.Error(err))
+	}
+}
+
+// enrich fills fields derived from request data, it runs in background worker
+// to keep redirect latency unaffected
+func (uc *clickUsecase) enrich(click *domain.Click) {
+	if click.ID.IsZero() {
+		click.ID = primitive.NewObjectID()
+	}
+
+	click.Browser, click.OS, click.Device = parseUserAgent(click.UserAgent)
+	click.Country = uc.geo.Country(click.IP)
+
+	// raw IP is not stored, visitor id is enough to count unique visitors
+	h := sha256.Sum256([]byte(click.IP.String() + "|" + click.UserAgent))
+	click.VisitorID = hex.EncodeToString(h[:8])
+}
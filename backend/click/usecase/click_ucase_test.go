@@ -0,0 +1,220 @@
+package usecase_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/click/mock"
+	"github.com/semka95/shortener/backend/click/usecase"
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/tests"
+	urlMock "github.com/semka95/shortener/backend/url/mock"
+	urlUcase "github.com/semka95/shortener/backend/url/usecase"
+	"github.com/semka95/shortener/backend/web/auth"
+	workspaceMock "github.com/semka95/shortener/backend/workspace/mock"
+)
+
+var tracer = sdktrace.NewTracerProvider().Tracer("")
+
+func TestClickUsecase_Record(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	geoFile := filepath.Join(t.TempDir(), "geo.csv")
+	require.NoError(t, os.WriteFile(geoFile, []byte("# test ranges\n203.0.113.0/24,nl\n"), 0o600))
+	geo, err := usecase.NewCIDRLocator(geoFile)
+	require.NoError(t, err)
+
+	t.Run("flushes full batch", func(t *testing.T) {
+		repository := mock.NewMockClickRepository(controller)
+		uc := usecase.NewClickUsecase(repository, nil, nil, geo, time.Second, 10, 2, time.Hour, zap.NewNop(), tracer)
+
+		var stored []*domain.Click
+		var mu sync.Mutex
+		repository.EXPECT().StoreMany(gomock.Any(), gomock.Len(2)).DoAndReturn(func(_ context.Context, clicks []*domain.Click) error {
+			mu.Lock()
+			defer mu.Unlock()
+			stored = append(stored, clicks...)
+			return nil
+		})
+
+		ua := "Mozilla/5.0 (iPhone; CPU iPhone OS 16_3 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.3 Mobile/15E148 Safari/604.1"
+		uc.Record(context.Background(), &domain.Click{URLID: "test123", UserAgent: ua, IP: net.ParseIP("203.0.113.7")})
+		uc.Record(context.Background(), &domain.Click{URLID: "test123", IP: net.ParseIP("198.51.100.1")})
+		require.NoError(t, uc.Close(context.Background()))
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, stored, 2)
+		assert.False(t, stored[0].ID.IsZero())
+		assert.Equal(t, "Safari", stored[0].Browser)
+		assert.Equal(t, "iOS", stored[0].OS)
+		assert.Equal(t, "mobile", stored[0].Device)
+		assert.Equal(t, "NL", stored[0].Country)
+		assert.NotEmpty(t, stored[0].VisitorID)
+		assert.Equal(t, "", stored[1].Country)
+		assert.Equal(t, "unknown", stored[1].Device)
+	})
+
+	t.Run("flushes remaining clicks on close", func(t *testing.T) {
+		repository := mock.NewMockClickRepository(controller)
+		uc := usecase.NewClickUsecase(repository, nil, nil, usecase.NewNoopLocator(), time.Second, 10, 5, time.Hour, zap.NewNop(), tracer)
+
+		repository.EXPECT().StoreMany(gomock.Any(), gomock.Len(1)).Return(nil)
+
+		uc.Record(context.Background(), &domain.Click{URLID: "test123"})
+		require.NoError(t, uc.Close(context.Background()))
+
+		// clicks recorded after close are ignored
+		uc.Record(context.Background(), &domain.Click{URLID: "test123"})
+	})
+
+	t.Run("flushes by interval", func(t *testing.T) {
+		repository := mock.NewMockClickRepository(controller)
+		uc := usecase.NewClickUsecase(repository, nil, nil, usecase.NewNoopLocator(), time.Second, 10, 5, 10*time.Millisecond, zap.NewNop(), tracer)
+
+		flushed := make(chan struct{})
+		repository.EXPECT().StoreMany(gomock.Any(), gomock.Len(1)).DoAndReturn(func(context.Context, []*domain.Click) error {
+			close(flushed)
+			return nil
+		})
+
+		uc.Record(context.Background(), &domain.Click{URLID: "test123"})
+		select {
+		case <-flushed:
+		case <-time.After(time.Second):
+			t.Fatal("clicks were not flushed")
+		}
+		require.NoError(t, uc.Close(context.Background()))
+	})
+
+	t.Run("repository error does not stop worker", func(t *testing.T) {
+		repository := mock.NewMockClickRepository(controller)
+		uc := usecase.NewClickUsecase(repository, nil, nil, usecase.NewNoopLocator(), time.Second, 10, 1, time.Hour, zap.NewNop(), tracer)
+
+		gomock.InOrder(
+			repository.EXPECT().StoreMany(gomock.Any(), gomock.Len(1)).Return(domain.ErrInternalServerError),
+			repository.EXPECT().StoreMany(gomock.Any(), gomock.Len(1)).Return(nil),
+		)
+
+		uc.Record(context.Background(), &domain.Click{URLID: "test123"})
+		uc.Record(context.Background(), &domain.Click{URLID: "test123"})
+		require.NoError(t, uc.Close(context.Background()))
+	})
+}
+
+func TestClickUsecase_Stats(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tURL := tests.NewURL()
+	tStats := &domain.ClickStats{URLID: tURL.ID, TotalClicks: 3, UniqueVisitors: 2}
+
+	repository := mock.NewMockClickRepository(controller)
+	urlRepository := urlMock.NewMockURLRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	urlUsecase := urlUcase.NewURLUsecase(urlUcase.Deps{URLRepo: urlRepository, WorkspaceRepo: workspaceRepository, Policy: auth.DefaultPolicy(), Tracer: tracer}, urlUcase.Config{Timeout: time.Second})
+	uc := usecase.NewClickUsecase(repository, urlRepository, urlUsecase, usecase.NewNoopLocator(), time.Second, 10, 1, time.Hour, zap.NewNop(), tracer)
+	defer func() {
+		require.NoError(t, uc.Close(context.Background()))
+	}()
+	claims := auth.NewClaims("507f191e810c19729de860ea", []string{auth.RoleUser}, time.Now(), time.Minute)
+
+	t.Run("success with defaults", func(t *testing.T) {
+		urlRepository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		repository.EXPECT().Stats(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, q domain.StatsQuery) (*domain.ClickStats, error) {
+			assert.Equal(t, domain.StatsIntervalDay, q.Interval)
+			assert.Equal(t, 7*24*time.Hour, q.To.Sub(q.From))
+			assert.WithinDuration(t, time.Now(), q.To, time.Minute)
+			return tStats, nil
+		})
+
+		stats, err := uc.Stats(context.Background(), domain.StatsQuery{URLID: tURL.ID}, claims)
+		require.NoError(t, err)
+		assert.Equal(t, tStats, stats)
+	})
+
+	t.Run("range start after end", func(t *testing.T) {
+		urlRepository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		q := domain.StatsQuery{URLID: tURL.ID, From: time.Now(), To: time.Now().Add(-time.Hour)}
+
+		_, err := uc.Stats(context.Background(), q, claims)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	})
+
+	t.Run("range too long for hourly buckets", func(t *testing.T) {
+		urlRepository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		q := domain.StatsQuery{URLID: tURL.ID, From: time.Now().AddDate(0, -2, 0), Interval: domain.StatsIntervalHour}
+
+		_, err := uc.Stats(context.Background(), q, claims)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	})
+
+	t.Run("url not found", func(t *testing.T) {
+		urlRepository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(nil, domain.ErrNotFound)
+
+		_, err := uc.Stats(context.Background(), domain.StatsQuery{URLID: tURL.ID}, claims)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		urlRepository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		repository.EXPECT().Stats(gomock.Any(), gomock.Any()).Return(nil, domain.ErrInternalServerError)
+
+		_, err := uc.Stats(context.Background(), domain.StatsQuery{URLID: tURL.ID}, claims)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+	})
+
+	t.Run("wrong user", func(t *testing.T) {
+		claims.Subject = "wrong user"
+		urlRepository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+
+		_, err := uc.Stats(context.Background(), domain.StatsQuery{URLID: tURL.ID}, claims)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("success by wrong user, but with admin role", func(t *testing.T) {
+		claims.Roles = append(claims.Roles, auth.RoleAdmin)
+		urlRepository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		repository.EXPECT().Stats(gomock.Any(), gomock.Any()).Return(tStats, nil)
+
+		stats, err := uc.Stats(context.Background(), domain.StatsQuery{URLID: tURL.ID}, claims)
+		require.NoError(t, err)
+		assert.Equal(t, tStats, stats)
+	})
+
+	t.Run("workspace member", func(t *testing.T) {
+		viewer := auth.NewClaims("507f191e810c19729de860ec", []string{auth.RoleUser}, time.Now(), time.Minute)
+		tWorkspace := tests.NewWorkspace()
+		tWorkspace.Members = append(tWorkspace.Members, domain.WorkspaceMember{UserID: viewer.Subject, Role: auth.WorkspaceViewer})
+		workspaceURL := tests.NewURL()
+		workspaceURL.WorkspaceID = tWorkspace.ID.Hex()
+		urlRepository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(workspaceURL, nil)
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+		repository.EXPECT().Stats(gomock.Any(), gomock.Any()).Return(tStats, nil)
+
+		stats, err := uc.Stats(context.Background(), domain.StatsQuery{URLID: tURL.ID}, viewer)
+		require.NoError(t, err)
+		assert.Equal(t, tStats, stats)
+	})
+
+	t.Run("url created by not authorized user", func(t *testing.T) {
+		anonURL := tests.NewURL()
+		anonURL.UserID = ""
+		urlRepository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(anonURL, nil)
+
+		_, err := uc.Stats(context.Background(), domain.StatsQuery{URLID: tURL.ID}, claims)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+}
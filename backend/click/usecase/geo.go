@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// GeoLocator resolves IP address to ISO 3166-1 alpha-2 country code,
+// empty string means that location is unknown
+type GeoLocator interface {
+	Country(ip net.IP) string
+}
+
+type noopLocator struct{}
+
+// NewNoopLocator will create GeoLocator which never resolves location
+func NewNoopLocator() GeoLocator {
+	return noopLocator{}
+}
+
+func (noopLocator) Country(net.IP) string {
+	return ""
+}
+
+type cidrRange struct {
+	network *net.IPNet
+	country string
+}
+
+type cidrLocator struct {
+	ranges []cidrRange
+}
+
+// NewCIDRLocator will create GeoLocator from file, where each line
+// contains network in CIDR notation and country code separated by comma:
+//
+//	5.255.255.0/24,RU
+//
+// Empty lines and lines starting with # are ignored.
+func NewCIDRLocator(path string) (GeoLocator, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open geo file: %w", err)
+	}
+	defer f.Close()
+
+	l := new(cidrLocator)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		network, country, found := strings.Cut(line, ",")
+		if !found {
+			return nil, fmt.Errorf("geo file line %d: missing country", n)
+		}
+
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(network))
+		if err != nil {
+			return nil, fmt.Errorf("geo file line %d: %w", n, err)
+		}
+
+		l.ranges = append(l.ranges, cidrRange{network: ipNet, country: strings.ToUpper(strings.TrimSpace(country))})
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("can't read geo file: %w", err)
+	}
+
+	return l, nil
+}
+
+func (l *cidrLocator) Country(ip net.IP) string {
+	if ip == nil {
+		return ""
+	}
+
+	for _, r := range l.ranges {
+		if r.network.Contains(ip) {
+			return r.country
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import "strings"
+
+// userAgentToken maps User-Agent substring to a human readable name,
+// order matters as many browsers mimic each other
+type userAgentToken struct {
+	token string
+	name  string
+}
+
+var browserTokens = []userAgentToken{
+	{"edg/", "Edge"},
+	{"opr/", "Opera"},
+	{"yabrowser/", "Yandex"},
+	{"samsungbrowser/", "Samsung Internet"},
+	{"firefox/", "Firefox"},
+	{"fxios/", "Firefox"},
+	{"crios/", "Chrome"},
+	{"chrome/", "Chrome"},
+	{"safari/", "Safari"},
+	{"msie ", "Internet Explorer"},
+	{"trident/", "Internet Explorer"},
+	{"curl/", "curl"},
+}
+
+var osTokens = []userAgentToken{
+	{"windows", "Windows"},
+	{"iphone", "iOS"},
+	{"ipad", "iOS"},
+	{"android", "Android"},
+	{"mac os x", "macOS"},
+	{"cros", "Chrome OS"},
+	{"linux", "Linux"},
+}
+
+var botTokens = []string{"bot", "crawler", "spider", "slurp", "preview"}
+
+// parseUserAgent extracts browser, operating system and device type from User-Agent header
+func parseUserAgent(ua string) (browser, os, device string) {
+	lower := strings.ToLower(ua)
+
+	browser = match(lower, browserTokens)
+	os = match(lower, osTokens)
+
+	switch {
+	case ua == "":
+		device = "unknown"
+	case containsAny(lower, botTokens):
+		device = "bot"
+	case strings.Contains(lower, "ipad") || strings.Contains(lower, "tablet"):
+		device = "tablet"
+	case strings.Contains(lower, "mobi") || strings.Contains(lower, "iphone"):
+		device = "mobile"
+	default:
+		device = "desktop"
+	}
+
+	return browser, os, device
+}
+
+func match(ua string, tokens []userAgentToken) string {
+	for _, t := range tokens {
+		if strings.Contains(ua, t.token) {
+			return t.name
+		}
+	}
+
+	return "Other"
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+
+	return false
+}
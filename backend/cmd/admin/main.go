@@ -10,6 +10,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
@@ -76,7 +78,14 @@ func run(logger *zap.Logger) error {
 	case "seed":
 		err = store.Seed(ctx, client.Database(cfg.MongoConfig.Name))
 	case "keygen":
-		err = keygen(os.Args[2], logger)
+		if len(os.Args) < 3 {
+			return errors.New("keygen missing argument for keys directory")
+		}
+		kid := ""
+		if len(os.Args) > 3 {
+			kid = os.Args[3]
+		}
+		err = keygen(os.Args[2], kid, logger)
 	default:
 		err = errors.New("must specify a command")
 	}
@@ -108,10 +117,18 @@ func migrateMongo(db *mongo.Client, dbName string) error {
 	return nil
 }
 
-// keygen creates an x509 private key for signing auth tokens.
-func keygen(path string, logger *zap.Logger) error {
-	if path == "" {
-		return errors.New("keygen missing argument for key path")
+// keygen creates an x509 private key for signing auth tokens and adds it to
+// the key ring directory as "<kid>.pem". Existing keys are never overwritten,
+// to make the new key active set it as auth.key_id in config.
+func keygen(dir, kid string, logger *zap.Logger) error {
+	if dir == "" {
+		return errors.New("keygen missing argument for keys directory")
+	}
+	if kid == "" {
+		kid = time.Now().UTC().Format("20060102150405")
+	}
+	if kid != filepath.Base(kid) || strings.HasPrefix(kid, ".") {
+		return fmt.Errorf("invalid key id %q", kid)
 	}
 
 	key, err := rsa.GenerateKey(rand.Reader, 2048)
@@ -119,7 +136,12 @@ func keygen(path string, logger *zap.Logger) error {
 		return fmt.Errorf("generating keys: %w", err)
 	}
 
-	file, err := os.Create(path)
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("creating keys directory: %w", err)
+	}
+
+	path := filepath.Join(dir, kid+".pem")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
 	if err != nil {
 		return fmt.Errorf("creating private file: %w", err)
 	}
@@ -139,5 +161,7 @@ func keygen(path string, logger *zap.Logger) error {
 		return fmt.Errorf("encoding to private file: %w", err)
 	}
 
+	logger.Info("key added to the ring", zap.String("kid", kid), zap.String("path", path))
+
 	return nil
 }
@@ -2,17 +2,18 @@ package main
 
 import (
 	"context"
-	"crypto/rsa"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
 	"go.opentelemetry.io/otel"
 
@@ -23,13 +24,36 @@ import (
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 
+	_AdminHttpDelivery "github.com/semka95/shortener/backend/admin/delivery/http"
+	_AdminUcase "github.com/semka95/shortener/backend/admin/usecase"
+	_APIKeyHttpDelivery "github.com/semka95/shortener/backend/apikey/delivery/http"
+	_APIKeyRepo "github.com/semka95/shortener/backend/apikey/repository"
+	_APIKeyUcase "github.com/semka95/shortener/backend/apikey/usecase"
+	_AuditRepo "github.com/semka95/shortener/backend/audit/repository"
+	"github.com/semka95/shortener/backend/cache"
+	_ClickRepo "github.com/semka95/shortener/backend/click/repository"
+	_ClickUcase "github.com/semka95/shortener/backend/click/usecase"
 	"github.com/semka95/shortener/backend/cmd"
+	_CustomDomainHttpDelivery "github.com/semka95/shortener/backend/customdomain/delivery/http"
+	_CustomDomainRepo "github.com/semka95/shortener/backend/customdomain/repository"
+	_CustomDomainUcase "github.com/semka95/shortener/backend/customdomain/usecase"
+	"github.com/semka95/shortener/backend/domain"
 	"github.com/semka95/shortener/backend/metrics"
 	_MyMiddleware "github.com/semka95/shortener/backend/middleware"
+	"github.com/semka95/shortener/backend/middleware/ratelimit"
+	_OIDCHttpDelivery "github.com/semka95/shortener/backend/oidc/delivery/http"
+	_OIDCRepo "github.com/semka95/shortener/backend/oidc/repository"
+	_OIDCUcase "github.com/semka95/shortener/backend/oidc/usecase"
+	_ReportHttpDelivery "github.com/semka95/shortener/backend/report/delivery/http"
+	_ReportRepo "github.com/semka95/shortener/backend/report/repository"
+	_ReportUcase "github.com/semka95/shortener/backend/report/usecase"
 	"github.com/semka95/shortener/backend/store"
+	_TokenRepo "github.com/semka95/shortener/backend/token/repository"
+	_TokenUcase "github.com/semka95/shortener/backend/token/usecase"
 	_URLHttpDelivery "github.com/semka95/shortener/backend/url/delivery/http"
 	_URLRepo "github.com/semka95/shortener/backend/url/repository"
 	_URLUcase "github.com/semka95/shortener/backend/url/usecase"
@@ -38,6 +62,9 @@ import (
 	_UserUcase "github.com/semka95/shortener/backend/user/usecase"
 	"github.com/semka95/shortener/backend/web"
 	"github.com/semka95/shortener/backend/web/auth"
+	_WorkspaceHttpDelivery "github.com/semka95/shortener/backend/workspace/delivery/http"
+	_WorkspaceRepo "github.com/semka95/shortener/backend/workspace/repository"
+	_WorkspaceUcase "github.com/semka95/shortener/backend/workspace/usecase"
 )
 
 func main() {
@@ -70,10 +97,21 @@ func run(logger *zap.Logger) error {
 	}
 
 	// Initialize authentication support
-	authenticator, err := createAuth(cfg.Auth.PrivateKeyFile, cfg.Auth.KeyID, cfg.Auth.Algorithm)
+	keyRing, err := auth.LoadKeyRing(cfg.Auth.KeysDir, cfg.Auth.KeyID)
+	if err != nil {
+		return fmt.Errorf("can't load auth keys: %w", err)
+	}
+	authenticator, err := auth.NewAuthenticator(keyRing.ActiveKey(), keyRing.ActiveKID(), cfg.Auth.Algorithm, keyRing.PublicKey)
 	if err != nil {
 		return err
 	}
+	policy := auth.DefaultPolicy()
+	if len(cfg.Roles) > 0 {
+		policy, err = auth.NewPolicy(cfg.Roles)
+		if err != nil {
+			return fmt.Errorf("invalid roles config: %w", err)
+		}
+	}
 
 	// Initialize context
 	timeoutContext := time.Duration(cfg.Server.Timeout) * time.Second
@@ -144,10 +182,16 @@ func run(logger *zap.Logger) error {
 
 	// Echo configure
 	e := echo.New()
-	middL := _MyMiddleware.InitMiddleware(logger)
+	e.IPExtractor, err = ratelimit.IPExtractor(cfg.Server.TrustedProxies)
+	if err != nil {
+		return err
+	}
+	middL := _MyMiddleware.InitMiddleware(logger, policy)
 	e.Pre(middleware.Rewrite(map[string]string{
 		"/api/*": "/$1",
 	}))
+	e.Use(middleware.RequestID())
+	e.Use(middL.RequestInfo)
 	e.Use(middL.CORS)
 	e.Use(middL.Logger)
 	e.Use(middleware.RecoverWithConfig(middleware.DefaultRecoverConfig))
@@ -172,10 +216,89 @@ func run(logger *zap.Logger) error {
 	}
 	e.Validator = v
 
+	// Create rate limiter, its store also keeps wrong passwords of protected URLs
+	rs, err := rateLimitStore(cfg, client, tracer)
+	if err != nil {
+		return fmt.Errorf("rate limit store creation failed: %w", err)
+	}
+	limiter, err := ratelimit.NewLimiter(rs, cfg.RateLimit.Policies, authenticator.Subject, logger)
+	if err != nil {
+		return fmt.Errorf("rate limiter creation failed: %w", err)
+	}
+	e.Use(limiter.Limit)
+
+	// Create URL repository
+	mur := _URLRepo.NewMongoURLRepository(client, cfg.MongoConfig.Name, logger, tracer)
+	ur, err := cachedURLRepository(mur, cfg, meterProvider, logger, tracer)
+	if err != nil {
+		return fmt.Errorf("url cache creation failed: %w", err)
+	}
+	// transactions use URL repository without cache, URLs changed by them
+	// are invalidated after commit
+	urlCache, ok := ur.(domain.URLCache)
+	if !ok {
+		urlCache = _URLRepo.NewNoopURLCache()
+	}
+
+	// Workspace membership grants access to URLs and their stats
+	wr := _WorkspaceRepo.NewMongoWorkspaceRepository(client, cfg.MongoConfig.Name, logger, tracer)
+	dr := _CustomDomainRepo.NewMongoCustomDomainRepository(client, cfg.MongoConfig.Name, logger, tracer)
+
+	// Create click analytics sink
+	cr := _ClickRepo.NewMongoClickRepository(client, cfg.MongoConfig.Name, logger, tracer)
+	geo := _ClickUcase.NewNoopLocator()
+	if cfg.Analytics.GeoFile != "" {
+		geo, err = _ClickUcase.NewCIDRLocator(cfg.Analytics.GeoFile)
+		if err != nil {
+			return fmt.Errorf("geo locator creation failed: %w", err)
+		}
+	}
+	flushInterval := time.Duration(cfg.Analytics.FlushInterval) * time.Second
+
 	// Create URL API
-	ur := _URLRepo.NewMongoURLRepository(client, cfg.MongoConfig.Name, logger, tracer)
-	uu := _URLUcase.NewURLUsecase(ur, timeoutContext, tracer, cfg.Server.URLExpiration)
-	uh, err := _URLHttpDelivery.NewURLHandler(uu, authenticator, v, logger, tracer)
+	if !domain.ValidRedirectType(cfg.Server.DefaultRedirectType) {
+		return fmt.Errorf("invalid default redirect type %d", cfg.Server.DefaultRedirectType)
+	}
+	urr := _URLRepo.NewMongoURLRevisionRepository(client, cfg.MongoConfig.Name, logger, tracer)
+	ar := _AuditRepo.NewMongoAuditRepository(client, cfg.MongoConfig.Name, logger, tracer)
+	gen, err := urlTokenGenerator(cfg, client, logger, tracer)
+	if err != nil {
+		return fmt.Errorf("url id generator creation failed: %w", err)
+	}
+	screener, err := linkScreener(ctx, cfg, logger, tracer)
+	if err != nil {
+		return fmt.Errorf("link screener creation failed: %w", err)
+	}
+	if cfg.Deletion.GracePeriod < 0 || cfg.Deletion.PurgeInterval < 1 || cfg.Deletion.ExpiredRetention < 0 {
+		return fmt.Errorf("invalid deletion grace period %d, purge interval %d or expired retention %d", cfg.Deletion.GracePeriod, cfg.Deletion.PurgeInterval, cfg.Deletion.ExpiredRetention)
+	}
+	deletionGrace := time.Duration(cfg.Deletion.GracePeriod) * time.Second
+	if err = checkCustomDomains(ctx, cfg, dr, logger); err != nil {
+		return err
+	}
+	// URLs of custom domains are opened on hosts other than server hosts
+	uu := _URLUcase.NewURLUsecase(_URLUcase.Deps{
+		URLRepo:          ur,
+		RevisionRepo:     urr,
+		AuditRepo:        ar,
+		WorkspaceRepo:    wr,
+		CustomDomainRepo: dr,
+		TokenGen:         gen,
+		Screener:         screener,
+		Policy:           policy,
+		UnlockStore:      rs,
+		Tracer:           tracer,
+	}, _URLUcase.Config{
+		Timeout:             timeoutContext,
+		DeletionGrace:       deletionGrace,
+		ExpiredRetention:    time.Duration(cfg.Deletion.ExpiredRetention) * time.Second,
+		URLExpiration:       cfg.Server.URLExpiration,
+		DefaultRedirectType: cfg.Server.DefaultRedirectType,
+		Hosts:               cfg.Server.Hosts,
+	})
+	// access to stats is authorized the same way as access to URLs
+	cu := _ClickUcase.NewClickUsecase(cr, ur, uu, geo, timeoutContext, cfg.Analytics.BufferSize, cfg.Analytics.BatchSize, flushInterval, logger, tracer)
+	uh, err := _URLHttpDelivery.NewURLHandler(uu, cu, authenticator, v, logger, tracer)
 	if err != nil {
 		return fmt.Errorf("url handler creation failed: %w", err)
 	}
@@ -183,13 +306,67 @@ func run(logger *zap.Logger) error {
 
 	// Create User API
 	usr := _UserRepo.NewMongoUserRepository(client, cfg.MongoConfig.Name, logger, tracer)
-	usu := _UserUcase.NewUserUsecase(usr, timeoutContext, tracer)
-	ush := _UserHttpDelivery.NewUserHandler(usu, authenticator, v, logger, tracer)
+	if cfg.Deletion.UserLinks != domain.LinkPolicyDelete && cfg.Deletion.UserLinks != domain.LinkPolicyOrphan {
+		return fmt.Errorf("invalid user links policy %q", cfg.Deletion.UserLinks)
+	}
+	tx := store.NewMongoTransactor(client, tracer)
+	tr := _TokenRepo.NewMongoTokenRepository(client, cfg.MongoConfig.Name, logger, tracer)
+	refreshTTL := time.Duration(cfg.Auth.RefreshTTL) * time.Second
+	tu := _TokenUcase.NewTokenUsecase(tr, usr, timeoutContext, refreshTTL, logger, tracer)
+	usu := _UserUcase.NewUserUsecase(usr, mur, urlCache, ar, tx, tu, cfg.Deletion.UserLinks, policy, timeoutContext, deletionGrace, tracer)
+	authenticator.SetRevocationCheck(tu.CheckRevoked)
+	ush := _UserHttpDelivery.NewUserHandler(usu, tu, authenticator, policy, v, logger, tracer)
 	ush.RegisterRoutes(e)
 
+	// Create OpenID Connect login API, it's disabled if issuer isn't set
+	if cfg.OIDC.Issuer != "" {
+		provider := _OIDCUcase.NewOIDCProvider(cfg.OIDC, &http.Client{Timeout: timeoutContext}, tracer)
+		olr := _OIDCRepo.NewMongoOIDCLoginRepository(client, cfg.MongoConfig.Name, logger, tracer)
+		ou := _OIDCUcase.NewOIDCUsecase(olr, usr, ar, provider, timeoutContext, tracer)
+		oh := _OIDCHttpDelivery.NewOIDCHandler(ou, tu, authenticator, v, logger, tracer)
+		oh.RegisterRoutes(e)
+	}
+
+	// Create API key API
+	kr := _APIKeyRepo.NewMongoAPIKeyRepository(client, cfg.MongoConfig.Name, logger, tracer)
+	ku := _APIKeyUcase.NewAPIKeyUsecase(kr, usr, ar, timeoutContext, logger, tracer)
+	authenticator.SetAPIKeyLookup(ku.Authenticate)
+	kh := _APIKeyHttpDelivery.NewAPIKeyHandler(ku, authenticator, v, logger, tracer)
+	kh.RegisterRoutes(e)
+
+	// Create workspace API
+	wu := _WorkspaceUcase.NewWorkspaceUsecase(wr, usr, ur, ar, policy, timeoutContext, tracer)
+	wh := _WorkspaceHttpDelivery.NewWorkspaceHandler(wu, authenticator, v, logger, tracer)
+	wh.RegisterRoutes(e)
+
+	// Create custom domain API, domains are verified by DNS TXT records
+	du := _CustomDomainUcase.NewCustomDomainUsecase(dr, wr, ar, net.DefaultResolver, policy, timeoutContext, tracer)
+	dh := _CustomDomainHttpDelivery.NewCustomDomainHandler(du, authenticator, v, logger, tracer)
+	dh.RegisterRoutes(e)
+
+	// Create abuse report API
+	rr := _ReportRepo.NewMongoReportRepository(client, cfg.MongoConfig.Name, logger, tracer)
+	ru := _ReportUcase.NewReportUsecase(rr, ur, timeoutContext, tracer)
+	rh := _ReportHttpDelivery.NewReportHandler(ru, authenticator, v, logger, tracer)
+	rh.RegisterRoutes(e)
+
+	// Create Admin API
+	au := _AdminUcase.NewAdminUsecase(mur, urlCache, usr, rr, ar, tx, tu, policy, timeoutContext, tracer)
+	ah := _AdminHttpDelivery.NewAdminHandler(au, authenticator, policy, v, logger, tracer)
+	ah.RegisterRoutes(e)
+
+	// Publish public keys for token verification
+	auth.NewJWKSHandler(e, keyRing, cfg.Auth.Algorithm)
+
 	// Status check
 	store.NewStatusHandler(e, client.Database(cfg.MongoConfig.Name))
 
+	// Remove deleted URLs and users whose grace period has ended
+	go purge(ctx, time.Duration(cfg.Deletion.PurgeInterval)*time.Second, logger, map[string]purgeFunc{
+		"url":  uu.Purge,
+		"user": usu.Purge,
+	})
+
 	go func() {
 		if err := e.Start(cfg.Server.Address); err != nil {
 			logger.Error("can't start server: ", zap.Error(err))
@@ -205,22 +382,123 @@ func run(logger *zap.Logger) error {
 	if err := e.Shutdown(shutdownCtx); err != nil {
 		return fmt.Errorf("can't shutdownn server: %w", err)
 	}
+	if err := cu.Close(shutdownCtx); err != nil {
+		logger.Error("can't flush click events: ", zap.Error(err))
+	}
 
 	return nil
 }
 
-func createAuth(privateKeyFile, keyID, algorithm string) (*auth.Authenticator, error) {
-	keyContents, err := os.ReadFile(privateKeyFile)
-	if err != nil {
-		return nil, fmt.Errorf("can't read auth private key: %w", err)
+func cachedURLRepository(ur domain.URLRepository, cfg *cmd.Config, mp *metric.MeterProvider, logger *zap.Logger, tracer trace.Tracer) (domain.URLRepository, error) {
+	var c cache.Cache
+	switch cfg.Cache.Type {
+	case "":
+		return ur, nil
+	case "lru":
+		c = cache.NewLRU(cfg.Cache.Size)
+	case "redis":
+		c = cache.NewRedis(cfg.Cache.Redis)
+	default:
+		return nil, fmt.Errorf("unknown cache type %q", cfg.Cache.Type)
 	}
 
-	key, err := jwt.ParseRSAPrivateKeyFromPEM(keyContents)
+	ttl := time.Duration(cfg.Cache.TTL) * time.Second
+	return _URLRepo.NewCachedURLRepository(ur, c, ttl, mp.Meter("shortener-url-cache"), logger, tracer)
+}
+
+func urlTokenGenerator(cfg *cmd.Config, client *mongo.Client, logger *zap.Logger, tracer trace.Tracer) (domain.TokenGenerator, error) {
+	switch cfg.IDGenerator.Type {
+	case "", "random":
+		if cfg.IDGenerator.Length < 1 {
+			return nil, fmt.Errorf("invalid id length %d", cfg.IDGenerator.Length)
+		}
+		return _URLUcase.NewRandomTokenGenerator(cfg.IDGenerator.Length), nil
+	case "counter":
+		if cfg.IDGenerator.RangeSize < 1 {
+			return nil, fmt.Errorf("invalid id range size %d", cfg.IDGenerator.RangeSize)
+		}
+		cr := _URLRepo.NewMongoCounterRepository(client, cfg.MongoConfig.Name, logger, tracer)
+		return _URLUcase.NewCounterTokenGenerator(cr, "url_id", cfg.IDGenerator.RangeSize), nil
+	case "snowflake":
+		return _URLUcase.NewSnowflakeTokenGenerator(cfg.IDGenerator.NodeID)
+	default:
+		return nil, fmt.Errorf("unknown id generator type %q", cfg.IDGenerator.Type)
+	}
+}
+
+// linkScreener creates link screener, its domain list is reloaded until ctx is done
+func linkScreener(ctx context.Context, cfg *cmd.Config, logger *zap.Logger, tracer trace.Tracer) (domain.LinkScreener, error) {
+	var list *_URLUcase.DomainList
+	if cfg.Screener.ListFile != "" {
+		if cfg.Screener.ReloadInterval < 1 {
+			return nil, fmt.Errorf("invalid domain list reload interval %d", cfg.Screener.ReloadInterval)
+		}
+
+		var err error
+		list, err = _URLUcase.NewDomainList(cfg.Screener.ListFile)
+		if err != nil {
+			return nil, err
+		}
+		go list.Watch(ctx, time.Duration(cfg.Screener.ReloadInterval)*time.Second, logger)
+	}
+
+	ownHosts := append(append([]string{}, cfg.Server.Hosts...), cfg.Screener.OwnHosts...)
+	return _URLUcase.NewLinkScreener(ownHosts, list, nil, net.DefaultResolver, logger, tracer), nil
+}
+
+// checkCustomDomains fails if verified custom domains exist while server hosts
+// are not set, as their URLs can't be told from URLs opened by id then
+func checkCustomDomains(ctx context.Context, cfg *cmd.Config, dr domain.CustomDomainRepository, logger *zap.Logger) error {
+	if len(cfg.Server.Hosts) > 0 {
+		return nil
+	}
+
+	n, err := dr.CountVerified(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("can't parse auth private key: %w", err)
+		return fmt.Errorf("custom domains check failed: %w", err)
+	}
+	if n > 0 {
+		return fmt.Errorf("%d verified custom domains exist, but server hosts are not set", n)
+	}
+	logger.Warn("server hosts are not set, custom domains are disabled")
+
+	return nil
+}
+
+func rateLimitStore(cfg *cmd.Config, client *mongo.Client, tracer trace.Tracer) (ratelimit.Store, error) {
+	switch cfg.RateLimit.Store {
+	case "", "memory":
+		return ratelimit.NewMemoryStore(), nil
+	case "mongo":
+		return ratelimit.NewMongoStore(client, cfg.MongoConfig.Name, tracer), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", cfg.RateLimit.Store)
 	}
+}
+
+// purgeFunc removes deleted items whose grace period has ended and returns their number
+type purgeFunc func(ctx context.Context) (int, error)
 
-	public := auth.NewSimpleKeyLookupFunc(keyID, key.Public().(*rsa.PublicKey))
+// purge runs purge functions every interval until ctx is done
+func purge(ctx context.Context, interval time.Duration, logger *zap.Logger, funcs map[string]purgeFunc) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-	return auth.NewAuthenticator(key, keyID, algorithm, public)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for name, f := range funcs {
+				n, err := f(ctx)
+				if err != nil {
+					logger.Error("can't purge deleted items", zap.String("type", name), zap.Int("purged", n), zap.Error(err))
+					continue
+				}
+				if n > 0 {
+					logger.Info("deleted items purged", zap.String("type", name), zap.Int("purged", n))
+				}
+			}
+		}
+	}
 }
@@ -7,22 +7,64 @@ import (
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v3"
 
+	"github.com/semka95/shortener/backend/cache"
+	"github.com/semka95/shortener/backend/middleware/ratelimit"
+	_OIDCUcase "github.com/semka95/shortener/backend/oidc/usecase"
 	"github.com/semka95/shortener/backend/store"
 )
 
 // Config stores app configuration
 type Config struct {
 	Server struct {
-		Address       string `yaml:"address"`
-		Timeout       int    `yaml:"timeout"`
-		OtlpAddress   string `yaml:"otlp_address"`
-		URLExpiration int    `yaml:"url_expiration_years"`
+		Address             string   `yaml:"address"`
+		Timeout             int      `yaml:"timeout"`
+		OtlpAddress         string   `yaml:"otlp_address"`
+		URLExpiration       int      `yaml:"url_expiration_years"`
+		DefaultRedirectType int      `yaml:"default_redirect_type"`
+		TrustedProxies      []string `yaml:"trusted_proxies"`
+		Hosts               []string `yaml:"hosts"`
 	} `yaml:"server"`
 	Auth struct {
-		KeyID          string `yaml:"key_id"`
-		PrivateKeyFile string `yaml:"private_key_file"`
-		Algorithm      string `yaml:"algorithm"`
+		KeyID      string `yaml:"key_id"`
+		KeysDir    string `yaml:"keys_dir"`
+		Algorithm  string `yaml:"algorithm"`
+		RefreshTTL int    `yaml:"refresh_token_ttl"`
 	} `yaml:"auth"`
+	Cache struct {
+		Type  string            `yaml:"type"`
+		Size  int               `yaml:"size"`
+		TTL   int               `yaml:"ttl"`
+		Redis cache.RedisConfig `yaml:"redis"`
+	} `yaml:"cache"`
+	Analytics struct {
+		BufferSize    int    `yaml:"buffer_size"`
+		BatchSize     int    `yaml:"batch_size"`
+		FlushInterval int    `yaml:"flush_interval"`
+		GeoFile       string `yaml:"geo_file"`
+	} `yaml:"analytics"`
+	IDGenerator struct {
+		Type      string `yaml:"type"`
+		Length    int    `yaml:"length"`
+		RangeSize int64  `yaml:"range_size"`
+		NodeID    int64  `yaml:"node_id"`
+	} `yaml:"id_generator"`
+	Screener struct {
+		OwnHosts       []string `yaml:"own_hosts"`
+		ListFile       string   `yaml:"list_file"`
+		ReloadInterval int      `yaml:"reload_interval"`
+	} `yaml:"screener"`
+	Deletion struct {
+		GracePeriod      int    `yaml:"grace_period"`
+		PurgeInterval    int    `yaml:"purge_interval"`
+		UserLinks        string `yaml:"user_links"`
+		ExpiredRetention int    `yaml:"expired_retention"`
+	} `yaml:"deletion"`
+	RateLimit struct {
+		Store    string             `yaml:"store"`
+		Policies []ratelimit.Policy `yaml:"policies"`
+	} `yaml:"rate_limit"`
+	OIDC              _OIDCUcase.ProviderConfig `yaml:"oidc"`
+	Roles             map[string][]string       `yaml:"roles"`
 	store.MongoConfig `yaml:"mongo"`
 }
 
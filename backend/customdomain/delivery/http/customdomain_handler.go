@@ -0,0 +1,179 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v4"
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/web"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+// CustomDomainHandler represent the http handler for custom domains
+type CustomDomainHandler struct {
+	customDomainUsecase domain.CustomDomainUsecase
+	authenticator       *auth.Authenticator
+	validator           *web.AppValidator
+	logger              *zap.Logger
+	tracer              trace.Tracer
+}
+
+// NewCustomDomainHandler will initialize the workspace/:id/domains resources endpoint
+func NewCustomDomainHandler(ds domain.CustomDomainUsecase, authenticator *auth.Authenticator, v *web.AppValidator, logger *zap.Logger, tracer trace.Tracer) *CustomDomainHandler {
+	return &CustomDomainHandler{
+		customDomainUsecase: ds,
+		authenticator:       authenticator,
+		validator:           v,
+		logger:              logger,
+		tracer:              tracer,
+	}
+}
+
+// RegisterRoutes registers routes for a path with matching handler
+func (dh *CustomDomainHandler) RegisterRoutes(e *echo.Echo) {
+	g := e.Group("/v1/workspace/:id/domains", echojwt.WithConfig(dh.authenticator.JWTConfig))
+	g.GET("", dh.List)
+	g.POST("", dh.Register)
+	g.POST("/:domain_id/verify", dh.Verify)
+}
+
+// Register will register domain of workspace by given request body
+func (dh *CustomDomainHandler) Register(c echo.Context) error {
+	ctx, span := dh.start(c, "http Register")
+	defer span.End()
+
+	r := new(domain.CreateCustomDomain)
+	if err := c.Bind(r); err != nil {
+		span.RecordError(err)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
+	}
+
+	if err := c.Validate(r); err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(dh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	user, err := dh.claims(c)
+	if user == nil {
+		span.RecordError(domain.ErrForbidden)
+		return err
+	}
+
+	d, err := dh.customDomainUsecase.Register(ctx, *r, user)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, dh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("domainid", d.ID.Hex()),
+	)
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusCreated, d)
+}
+
+// List will list domains of workspace
+func (dh *CustomDomainHandler) List(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx, span := dh.start(c, "http List")
+	defer span.End()
+
+	if err := dh.validator.V.Var(id, "required,len=24,hexadecimal"); err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(dh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	user, err := dh.claims(c)
+	if user == nil {
+		span.RecordError(domain.ErrForbidden)
+		return err
+	}
+
+	list, err := dh.customDomainUsecase.List(ctx, id, user)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, dh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("workspaceid", id),
+	)
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusOK, list)
+}
+
+// Verify will check DNS TXT record of domain
+func (dh *CustomDomainHandler) Verify(c echo.Context) error {
+	ctx, span := dh.start(c, "http Verify")
+	defer span.End()
+
+	r := new(domain.VerifyCustomDomain)
+	if err := c.Bind(r); err != nil {
+		span.RecordError(err)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
+	}
+
+	if err := c.Validate(r); err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(dh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	user, err := dh.claims(c)
+	if user == nil {
+		span.RecordError(domain.ErrForbidden)
+		return err
+	}
+
+	d, err := dh.customDomainUsecase.Verify(ctx, *r, user)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, dh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("domainid", r.DomainID),
+	)
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusOK, d)
+}
+
+func (dh *CustomDomainHandler) start(c echo.Context, name string) (context.Context, trace.Span) {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return dh.tracer.Start(
+		ctx,
+		name,
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+}
+
+// claims returns claims of authenticated user, if claims are nil the response
+// is already written and returned error must be returned by handler
+func (dh *CustomDomainHandler) claims(c echo.Context) (*auth.Claims, error) {
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok || token == nil {
+		return nil, c.JSON(http.StatusForbidden, domain.ResponseError{Error: domain.ErrForbidden.Error()})
+	}
+	user, ok := token.Claims.(*auth.Claims)
+	if !ok {
+		return nil, fmt.Errorf("%w can't convert jwt.Claims to auth.Claims", domain.ErrInternalServerError)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,136 @@
+package http_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+
+	customDomainHttp "github.com/semka95/shortener/backend/customdomain/delivery/http"
+	"github.com/semka95/shortener/backend/customdomain/mock"
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/tests"
+	"github.com/semka95/shortener/backend/web"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+func TestCustomDomainHTTP(t *testing.T) {
+	tUser := tests.NewUser()
+	tDomain := tests.NewCustomDomain()
+	claims := auth.NewClaims(tUser.ID.Hex(), tUser.Roles, time.Now(), time.Hour)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	kid := "4754d86b-7a6d-4df5-9c65-224741361492"
+	kf := auth.NewSimpleKeyLookupFunc(kid, key.Public().(*rsa.PublicKey))
+	authenticator, err := auth.NewAuthenticator(key, kid, "RS256", kf)
+	require.NoError(t, err)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	uc := mock.NewMockCustomDomainUsecase(controller)
+
+	tracer := sdktrace.NewTracerProvider().Tracer("")
+	v, err := web.NewAppValidator()
+	require.NoError(t, err)
+
+	handler := customDomainHttp.NewCustomDomainHandler(uc, authenticator, v, zap.NewNop(), tracer)
+
+	e := echo.New()
+	e.Validator = v
+	req := new(http.Request)
+	c := e.NewContext(req, nil)
+
+	// Test CustomDomainHandler.Register
+	casesRegister := []struct {
+		description string
+		mockCalls   func()
+		reqBody     string
+		code        int
+	}{
+		{
+			description: "Register success",
+			mockCalls: func() {
+				create := domain.CreateCustomDomain{WorkspaceID: tDomain.WorkspaceID, Host: tDomain.Host}
+				uc.EXPECT().Register(gomock.Any(), create, claims).Return(tDomain, nil)
+			},
+			reqBody: `{"host":"go.example.com"}`,
+			code:    http.StatusCreated,
+		},
+		{
+			description: "Register not a host name",
+			mockCalls:   func() {},
+			reqBody:     `{"host":"https://go.example.com/"}`,
+			code:        http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range casesRegister {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls()
+			req = httptest.NewRequest(echo.POST, "/v1/workspace/"+tDomain.WorkspaceID+"/domains", strings.NewReader(tc.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+			c.SetPath("/v1/workspace/:id/domains")
+			c.SetParamNames("id")
+			c.SetParamValues(tDomain.WorkspaceID)
+			c.Set("user", token)
+
+			err = handler.Register(c)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.code, rec.Code)
+		})
+	}
+
+	// Test CustomDomainHandler.List
+	t.Run("List wrong workspace id", func(t *testing.T) {
+		req = httptest.NewRequest(echo.GET, "/v1/workspace/wrong/domains", nil)
+
+		rec := httptest.NewRecorder()
+		c.Reset(req, rec)
+		c.SetPath("/v1/workspace/:id/domains")
+		c.SetParamNames("id")
+		c.SetParamValues("wrong")
+		c.Set("user", token)
+
+		err = handler.List(c)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	// Test CustomDomainHandler.Verify
+	t.Run("Verify record not found", func(t *testing.T) {
+		verify := domain.VerifyCustomDomain{WorkspaceID: tDomain.WorkspaceID, DomainID: tDomain.ID.Hex()}
+		uc.EXPECT().Verify(gomock.Any(), verify, claims).Return(nil, domain.ErrBadParamInput)
+		req = httptest.NewRequest(echo.POST, "/v1/workspace/"+verify.WorkspaceID+"/domains/"+verify.DomainID+"/verify", nil)
+
+		rec := httptest.NewRecorder()
+		c.Reset(req, rec)
+		c.SetPath("/v1/workspace/:id/domains/:domain_id/verify")
+		c.SetParamNames("id", "domain_id")
+		c.SetParamValues(verify.WorkspaceID, verify.DomainID)
+		c.Set("user", token)
+
+		err = handler.Verify(c)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
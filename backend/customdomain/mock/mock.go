@@ -0,0 +1,233 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./domain/customdomain.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/semka95/shortener/backend/domain"
+	auth "github.com/semka95/shortener/backend/web/auth"
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockCustomDomainUsecase is a mock of CustomDomainUsecase interface.
+type MockCustomDomainUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockCustomDomainUsecaseMockRecorder
+}
+
+// MockCustomDomainUsecaseMockRecorder is the mock recorder for MockCustomDomainUsecase.
+type MockCustomDomainUsecaseMockRecorder struct {
+	mock *MockCustomDomainUsecase
+}
+
+// NewMockCustomDomainUsecase creates a new mock instance.
+func NewMockCustomDomainUsecase(ctrl *gomock.Controller) *MockCustomDomainUsecase {
+	mock := &MockCustomDomainUsecase{ctrl: ctrl}
+	mock.recorder = &MockCustomDomainUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCustomDomainUsecase) EXPECT() *MockCustomDomainUsecaseMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockCustomDomainUsecase) List(ctx context.Context, workspaceID string, claims *auth.Claims) ([]*domain.CustomDomain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, workspaceID, claims)
+	ret0, _ := ret[0].([]*domain.CustomDomain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockCustomDomainUsecaseMockRecorder) List(ctx, workspaceID, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCustomDomainUsecase)(nil).List), ctx, workspaceID, claims)
+}
+
+// Register mocks base method.
+func (m *MockCustomDomainUsecase) Register(ctx context.Context, create domain.CreateCustomDomain, claims *auth.Claims) (*domain.CustomDomain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, create, claims)
+	ret0, _ := ret[0].(*domain.CustomDomain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockCustomDomainUsecaseMockRecorder) Register(ctx, create, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockCustomDomainUsecase)(nil).Register), ctx, create, claims)
+}
+
+// Verify mocks base method.
+func (m *MockCustomDomainUsecase) Verify(ctx context.Context, verify domain.VerifyCustomDomain, claims *auth.Claims) (*domain.CustomDomain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, verify, claims)
+	ret0, _ := ret[0].(*domain.CustomDomain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockCustomDomainUsecaseMockRecorder) Verify(ctx, verify, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockCustomDomainUsecase)(nil).Verify), ctx, verify, claims)
+}
+
+// MockCustomDomainRepository is a mock of CustomDomainRepository interface.
+type MockCustomDomainRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCustomDomainRepositoryMockRecorder
+}
+
+// MockCustomDomainRepositoryMockRecorder is the mock recorder for MockCustomDomainRepository.
+type MockCustomDomainRepositoryMockRecorder struct {
+	mock *MockCustomDomainRepository
+}
+
+// NewMockCustomDomainRepository creates a new mock instance.
+func NewMockCustomDomainRepository(ctrl *gomock.Controller) *MockCustomDomainRepository {
+	mock := &MockCustomDomainRepository{ctrl: ctrl}
+	mock.recorder = &MockCustomDomainRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCustomDomainRepository) EXPECT() *MockCustomDomainRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountVerified mocks base method.
+func (m *MockCustomDomainRepository) CountVerified(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountVerified", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountVerified indicates an expected call of CountVerified.
+func (mr *MockCustomDomainRepositoryMockRecorder) CountVerified(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountVerified", reflect.TypeOf((*MockCustomDomainRepository)(nil).CountVerified), ctx)
+}
+
+// GetByHost mocks base method.
+func (m *MockCustomDomainRepository) GetByHost(ctx context.Context, host string) (*domain.CustomDomain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHost", ctx, host)
+	ret0, _ := ret[0].(*domain.CustomDomain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHost indicates an expected call of GetByHost.
+func (mr *MockCustomDomainRepositoryMockRecorder) GetByHost(ctx, host interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHost", reflect.TypeOf((*MockCustomDomainRepository)(nil).GetByHost), ctx, host)
+}
+
+// GetByID mocks base method.
+func (m *MockCustomDomainRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.CustomDomain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*domain.CustomDomain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockCustomDomainRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockCustomDomainRepository)(nil).GetByID), ctx, id)
+}
+
+// ListByWorkspace mocks base method.
+func (m *MockCustomDomainRepository) ListByWorkspace(ctx context.Context, workspaceID string) ([]*domain.CustomDomain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByWorkspace", ctx, workspaceID)
+	ret0, _ := ret[0].([]*domain.CustomDomain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByWorkspace indicates an expected call of ListByWorkspace.
+func (mr *MockCustomDomainRepositoryMockRecorder) ListByWorkspace(ctx, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByWorkspace", reflect.TypeOf((*MockCustomDomainRepository)(nil).ListByWorkspace), ctx, workspaceID)
+}
+
+// SetVerified mocks base method.
+func (m *MockCustomDomainRepository) SetVerified(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVerified", ctx, id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetVerified indicates an expected call of SetVerified.
+func (mr *MockCustomDomainRepositoryMockRecorder) SetVerified(ctx, id, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVerified", reflect.TypeOf((*MockCustomDomainRepository)(nil).SetVerified), ctx, id, at)
+}
+
+// Store mocks base method.
+func (m *MockCustomDomainRepository) Store(ctx context.Context, d *domain.CustomDomain) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, d)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store.
+func (mr *MockCustomDomainRepositoryMockRecorder) Store(ctx, d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockCustomDomainRepository)(nil).Store), ctx, d)
+}
+
+// MockTXTResolver is a mock of TXTResolver interface.
+type MockTXTResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockTXTResolverMockRecorder
+}
+
+// MockTXTResolverMockRecorder is the mock recorder for MockTXTResolver.
+type MockTXTResolverMockRecorder struct {
+	mock *MockTXTResolver
+}
+
+// NewMockTXTResolver creates a new mock instance.
+func NewMockTXTResolver(ctrl *gomock.Controller) *MockTXTResolver {
+	mock := &MockTXTResolver{ctrl: ctrl}
+	mock.recorder = &MockTXTResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTXTResolver) EXPECT() *MockTXTResolverMockRecorder {
+	return m.recorder
+}
+
+// LookupTXT mocks base method.
+func (m *MockTXTResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupTXT", ctx, name)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LookupTXT indicates an expected call of LookupTXT.
+func (mr *MockTXTResolverMockRecorder) LookupTXT(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupTXT", reflect.TypeOf((*MockTXTResolver)(nil).LookupTXT), ctx, name)
+}
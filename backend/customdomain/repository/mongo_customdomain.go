@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+)
+
+const customDomainCollection = "custom_domain"
+
+type mongoCustomDomainRepository struct {
+	Conn   *mongo.Database
+	logger *zap.Logger
+	tracer trace.Tracer
+}
+
+// NewMongoCustomDomainRepository will create an object that represent the customdomain.Repository interface
+func NewMongoCustomDomainRepository(c *mongo.Client, db string, logger *zap.Logger, tracer trace.Tracer) domain.CustomDomainRepository {
+	return &mongoCustomDomainRepository{
+		Conn:   c.Database(db),
+		logger: logger,
+		tracer: tracer,
+	}
+}
+
+func (m *mongoCustomDomainRepository) Store(ctx context.Context, d *domain.CustomDomain) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository Store",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("domainid", d.ID.Hex()),
+			attribute.String("host", d.Host)),
+	)
+	defer span.End()
+
+	_, err := m.Conn.Collection(customDomainCollection).InsertOne(ctx, d)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("custom domain store error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (m *mongoCustomDomainRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.CustomDomain, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository GetByID",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("domainid", id.Hex())),
+	)
+	defer span.End()
+
+	d, err := m.findOne(ctx, bson.D{primitive.E{Key: "_id", Value: id}})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// GetByHost returns verified domain with the given host
+func (m *mongoCustomDomainRepository) GetByHost(ctx context.Context, host string) (*domain.CustomDomain, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository GetByHost",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("host", host)),
+	)
+	defer span.End()
+
+	d, err := m.findOne(ctx, bson.D{
+		primitive.E{Key: "host", Value: host},
+		primitive.E{Key: "verified_at", Value: bson.D{{Key: "$exists", Value: true}}},
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// CountVerified returns number of verified domains of all workspaces
+func (m *mongoCustomDomainRepository) CountVerified(ctx context.Context) (int64, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository CountVerified",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "verified_at", Value: bson.D{{Key: "$exists", Value: true}}}}
+	n, err := m.Conn.Collection(customDomainCollection).CountDocuments(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("custom domain count error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return n, nil
+}
+
+func (m *mongoCustomDomainRepository) findOne(ctx context.Context, filter bson.D) (*domain.CustomDomain, error) {
+	d := new(domain.CustomDomain)
+	err := m.Conn.Collection(customDomainCollection).FindOne(ctx, filter).Decode(d)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("custom domain was not found: %w", domain.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("custom domain get error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return d, nil
+}
+
+// ListByWorkspace returns domains of workspace, newest first
+func (m *mongoCustomDomainRepository) ListByWorkspace(ctx context.Context, workspaceID string) ([]*domain.CustomDomain, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository ListByWorkspace",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("workspaceid", workspaceID)),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "workspace_id", Value: workspaceID}}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+
+	cur, err := m.Conn.Collection(customDomainCollection).Find(ctx, filter, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("custom domain list error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	list := make([]*domain.CustomDomain, 0)
+	if err = cur.All(ctx, &list); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("can't decode custom domains: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return list, nil
+}
+
+// SetVerified marks domain as verified, host verified by another workspace
+// is rejected with ErrConflict
+func (m *mongoCustomDomainRepository) SetVerified(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository SetVerified",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("domainid", id.Hex())),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "_id", Value: id}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "verified_at", Value: at}}}}
+
+	res, err := m.Conn.Collection(customDomainCollection).UpdateOne(ctx, filter, update)
+	if mongo.IsDuplicateKeyError(err) {
+		span.RecordError(err)
+		return fmt.Errorf("host is already verified by another workspace: %w", domain.ErrConflict)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("custom domain update error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	if res.MatchedCount == 0 {
+		err = fmt.Errorf("custom domain was not updated: %w", domain.ErrNoAffected)
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
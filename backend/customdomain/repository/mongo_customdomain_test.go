@@ -0,0 +1,151 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/semka95/shortener/backend/customdomain/repository"
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/tests"
+)
+
+var tracer = sdktrace.NewTracerProvider().Tracer("")
+var noopCtx = context.Background()
+
+const tableName = "shortener.custom_domain"
+
+func customDomainBsonD(d *domain.CustomDomain) bson.D {
+	return bson.D{
+		{Key: "_id", Value: d.ID},
+		{Key: "host", Value: d.Host},
+		{Key: "workspace_id", Value: d.WorkspaceID},
+		{Key: "token", Value: d.Token},
+		{Key: "created_at", Value: d.CreatedAt},
+	}
+}
+
+func TestMongoCustomDomainRepository_Store(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tDomain := tests.NewCustomDomain()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		r := repository.NewMongoCustomDomainRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Store(noopCtx, tDomain)
+
+		require.NoError(mt, err)
+	})
+}
+
+func TestMongoCustomDomainRepository_GetByHost(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tDomain := tests.NewCustomDomain()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, customDomainBsonD(tDomain)))
+		r := repository.NewMongoCustomDomainRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetByHost(noopCtx, tDomain.Host)
+
+		require.NoError(mt, err)
+		assert.EqualValues(mt, tDomain, result)
+	})
+
+	mt.Run("not verified", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch))
+		r := repository.NewMongoCustomDomainRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetByHost(noopCtx, tDomain.Host)
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrNotFound)
+	})
+}
+
+func TestMongoCustomDomainRepository_ListByWorkspace(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tDomain := tests.NewCustomDomain()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, customDomainBsonD(tDomain)))
+		r := repository.NewMongoCustomDomainRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		list, err := r.ListByWorkspace(noopCtx, tDomain.WorkspaceID)
+
+		require.NoError(mt, err)
+		assert.Equal(mt, []*domain.CustomDomain{tDomain}, list)
+	})
+}
+
+func TestMongoCustomDomainRepository_SetVerified(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tDomain := tests.NewCustomDomain()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+		r := repository.NewMongoCustomDomainRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.SetVerified(noopCtx, tDomain.ID, time.Now())
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("host verified by another workspace", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    11000,
+			Message: "duplicate key error",
+		}))
+		r := repository.NewMongoCustomDomainRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.SetVerified(noopCtx, tDomain.ID, time.Now())
+
+		assert.ErrorIs(mt, err, domain.ErrConflict)
+	})
+
+	mt.Run("not exists", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+		r := repository.NewMongoCustomDomainRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.SetVerified(noopCtx, tDomain.ID, time.Now())
+
+		assert.ErrorIs(mt, err, domain.ErrNoAffected)
+	})
+}
+
+func TestMongoCustomDomainRepository_CountVerified(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, bson.D{{Key: "n", Value: int64(2)}}))
+		r := repository.NewMongoCustomDomainRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		n, err := r.CountVerified(noopCtx)
+
+		require.NoError(mt, err)
+		assert.EqualValues(mt, 2, n)
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+		r := repository.NewMongoCustomDomainRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		n, err := r.CountVerified(noopCtx)
+
+		assert.Zero(mt, n)
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
@@ -0,0 +1,243 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/semka95/shortener/backend/audit"
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+type customDomainUsecase struct {
+	customDomainRepo domain.CustomDomainRepository
+	workspaceRepo    domain.WorkspaceRepository
+	auditRepo        domain.AuditRepository
+	resolver         domain.TXTResolver
+	policy           *auth.Policy
+	contextTimeout   time.Duration
+	tracer           trace.Tracer
+}
+
+// NewCustomDomainUsecase will create new a customDomainUsecase object representation of customdomain.Usecase interface,
+// resolver looks up TXT records which verify domains, policy grants permissions of roles
+func NewCustomDomainUsecase(d domain.CustomDomainRepository, w domain.WorkspaceRepository, a domain.AuditRepository, resolver domain.TXTResolver, policy *auth.Policy, timeout time.Duration, tracer trace.Tracer) domain.CustomDomainUsecase {
+	return &customDomainUsecase{
+		customDomainRepo: d,
+		workspaceRepo:    w,
+		auditRepo:        a,
+		resolver:         resolver,
+		policy:           policy,
+		contextTimeout:   timeout,
+		tracer:           tracer,
+	}
+}
+
+// Register registers host for workspace, only workspace owners can register
+// domains. Domain is used after TXT record with its token is published.
+func (uc *customDomainUsecase) Register(c context.Context, create domain.CreateCustomDomain, claims *auth.Claims) (*domain.CustomDomain, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Register",
+		trace.WithAttributes(
+			attribute.String("workspaceid", create.WorkspaceID),
+			attribute.String("host", create.Host)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	if err := uc.authorize(ctx, create.WorkspaceID, claims, auth.ActionWorkspaceManage); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("can't generate domain token: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	d := &domain.CustomDomain{
+		ID:          primitive.NewObjectID(),
+		Host:        strings.ToLower(create.Host),
+		WorkspaceID: create.WorkspaceID,
+		Token:       token,
+		CreatedAt:   time.Now().Truncate(time.Millisecond).UTC(),
+	}
+
+	if err = uc.customDomainRepo.Store(ctx, d); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err = uc.record(ctx, claims, domain.AuditCreateCustomDomain, nil, d); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// List returns domains of workspace, only its members can see them
+func (uc *customDomainUsecase) List(c context.Context, workspaceID string, claims *auth.Claims) ([]*domain.CustomDomain, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase List",
+		trace.WithAttributes(
+			attribute.String("workspaceid", workspaceID)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	if err := uc.authorize(ctx, workspaceID, claims, auth.ActionWorkspaceRead); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	list, err := uc.customDomainRepo.ListByWorkspace(ctx, workspaceID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// Verify checks TXT record of domain and marks domain as verified if the
+// record holds its token, only workspace owners can verify domains
+func (uc *customDomainUsecase) Verify(c context.Context, verify domain.VerifyCustomDomain, claims *auth.Claims) (*domain.CustomDomain, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Verify",
+		trace.WithAttributes(
+			attribute.String("workspaceid", verify.WorkspaceID),
+			attribute.String("domainid", verify.DomainID)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	if err := uc.authorize(ctx, verify.WorkspaceID, claims, auth.ActionWorkspaceManage); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	id, err := primitive.ObjectIDFromHex(verify.DomainID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("domain ID is not valid ObjectID: %w: %s", domain.ErrBadParamInput, err.Error())
+	}
+
+	d, err := uc.customDomainRepo.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if d.WorkspaceID != verify.WorkspaceID {
+		err = fmt.Errorf("domain %s doesn't belong to workspace: %w", verify.DomainID, domain.ErrNotFound)
+		span.RecordError(err)
+		return nil, err
+	}
+	if d.IsVerified() {
+		return d, nil
+	}
+
+	if err = uc.checkTXTRecord(ctx, d); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	before := *d
+	now := time.Now().Truncate(time.Millisecond).UTC()
+	d.VerifiedAt = &now
+
+	if err = uc.record(ctx, claims, domain.AuditVerifyCustomDomain, &before, d); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err = uc.customDomainRepo.SetVerified(ctx, d.ID, now); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// checkTXTRecord returns error if TXT record of domain doesn't hold its token
+func (uc *customDomainUsecase) checkTXTRecord(ctx context.Context, d *domain.CustomDomain) error {
+	name, value := d.TXTRecord()
+
+	records, err := uc.resolver.LookupTXT(ctx, name)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return fmt.Errorf("TXT record %s was not found: %w", name, domain.ErrBadParamInput)
+	}
+	if err != nil {
+		return fmt.Errorf("can't look up TXT record %s: %w: %s", name, domain.ErrInternalServerError, err.Error())
+	}
+
+	for _, r := range records {
+		if r == value {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("TXT record %s doesn't contain %s: %w", name, value, domain.ErrBadParamInput)
+}
+
+// authorize returns error if role of user in workspace doesn't allow action
+func (uc *customDomainUsecase) authorize(ctx context.Context, workspaceID string, claims *auth.Claims, action string) error {
+	id, err := primitive.ObjectIDFromHex(workspaceID)
+	if err != nil {
+		return fmt.Errorf("workspace ID is not valid ObjectID: %w: %s", domain.ErrBadParamInput, err.Error())
+	}
+
+	w, err := uc.workspaceRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = uc.policy.Authorize(claims, action, &auth.Resource{WorkspaceRole: w.Role(claims.Subject)}); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrForbidden, err.Error())
+	}
+
+	return nil
+}
+
+func (uc *customDomainUsecase) record(ctx context.Context, claims *auth.Claims, action string, before, after *domain.CustomDomain) error {
+	e := audit.NewEntry(ctx, claims, action, after.ID.Hex())
+
+	var err error
+	if e.Changes, err = audit.Diff(before, after); err != nil {
+		return err
+	}
+
+	return uc.auditRepo.Store(ctx, e)
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,218 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	auditMock "github.com/semka95/shortener/backend/audit/mock"
+	"github.com/semka95/shortener/backend/customdomain/mock"
+	"github.com/semka95/shortener/backend/customdomain/usecase"
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/tests"
+	"github.com/semka95/shortener/backend/web/auth"
+	workspaceMock "github.com/semka95/shortener/backend/workspace/mock"
+)
+
+var tracer = sdktrace.NewTracerProvider().Tracer("")
+
+const (
+	ownerID  = "507f191e810c19729de860ea"
+	viewerID = "507f191e810c19729de860ec"
+)
+
+// fakeResolver returns records of names, names without records aren't found
+type fakeResolver struct {
+	records map[string][]string
+	err     error
+}
+
+func (r *fakeResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	records, ok := r.records[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+// newWorkspace returns workspace with owner of tests.NewWorkspace and a viewer
+func newWorkspace() *domain.Workspace {
+	w := tests.NewWorkspace()
+	w.Members = append(w.Members, domain.WorkspaceMember{UserID: viewerID, Role: auth.WorkspaceViewer})
+	return w
+}
+
+func newClaims(subject string) *auth.Claims {
+	return auth.NewClaims(subject, []string{auth.RoleUser}, time.Now(), time.Minute)
+}
+
+func TestCustomDomainUsecase_Register(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repository := mock.NewMockCustomDomainRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	auditRepository := auditMock.NewMockAuditRepository(controller)
+	uc := usecase.NewCustomDomainUsecase(repository, workspaceRepository, auditRepository, &fakeResolver{}, auth.DefaultPolicy(), 10*time.Second, tracer)
+	tWorkspace := newWorkspace()
+	create := domain.CreateCustomDomain{WorkspaceID: tWorkspace.ID.Hex(), Host: "Go.Example.com"}
+
+	t.Run("success", func(t *testing.T) {
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		auditRepository.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.AuditEntry) error {
+			assert.Equal(t, domain.AuditCreateCustomDomain, e.Action)
+			return nil
+		})
+
+		d, err := uc.Register(context.Background(), create, newClaims(ownerID))
+		require.NoError(t, err)
+		assert.Equal(t, "go.example.com", d.Host)
+		assert.Len(t, d.Token, 32)
+		assert.False(t, d.IsVerified())
+	})
+
+	t.Run("by viewer", func(t *testing.T) {
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+
+		d, err := uc.Register(context.Background(), create, newClaims(viewerID))
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, d)
+	})
+}
+
+func TestCustomDomainUsecase_Verify(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repository := mock.NewMockCustomDomainRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	auditRepository := auditMock.NewMockAuditRepository(controller)
+	tWorkspace := newWorkspace()
+	tDomain := tests.NewCustomDomain()
+	name, value := tDomain.TXTRecord()
+	verify := domain.VerifyCustomDomain{WorkspaceID: tWorkspace.ID.Hex(), DomainID: tDomain.ID.Hex()}
+
+	cases := []struct {
+		description string
+		resolver    *fakeResolver
+		mockCalls   func()
+		err         error
+	}{
+		{
+			description: "success",
+			resolver:    &fakeResolver{records: map[string][]string{name: {"v=spf1 -all", value}}},
+			mockCalls: func() {
+				auditRepository.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.AuditEntry) error {
+					assert.Equal(t, domain.AuditVerifyCustomDomain, e.Action)
+					assert.Contains(t, e.Changes, "verified_at")
+					return nil
+				})
+				repository.EXPECT().SetVerified(gomock.Any(), tDomain.ID, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			description: "host verified by another workspace",
+			resolver:    &fakeResolver{records: map[string][]string{name: {value}}},
+			mockCalls: func() {
+				auditRepository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+				repository.EXPECT().SetVerified(gomock.Any(), tDomain.ID, gomock.Any()).Return(domain.ErrConflict)
+			},
+			err: domain.ErrConflict,
+		},
+		{
+			description: "wrong token",
+			resolver:    &fakeResolver{records: map[string][]string{name: {"shortener-verification=other"}}},
+			mockCalls:   func() {},
+			err:         domain.ErrBadParamInput,
+		},
+		{
+			description: "record not found",
+			resolver:    &fakeResolver{},
+			mockCalls:   func() {},
+			err:         domain.ErrBadParamInput,
+		},
+		{
+			description: "lookup error",
+			resolver:    &fakeResolver{err: errors.New("i/o timeout")},
+			mockCalls:   func() {},
+			err:         domain.ErrInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			uc := usecase.NewCustomDomainUsecase(repository, workspaceRepository, auditRepository, tc.resolver, auth.DefaultPolicy(), 10*time.Second, tracer)
+			workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+			repository.EXPECT().GetByID(gomock.Any(), tDomain.ID).Return(tests.NewCustomDomain(), nil)
+			tc.mockCalls()
+
+			d, err := uc.Verify(context.Background(), verify, newClaims(ownerID))
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				assert.Nil(t, d)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, d.IsVerified())
+		})
+	}
+
+	t.Run("domain of another workspace", func(t *testing.T) {
+		uc := usecase.NewCustomDomainUsecase(repository, workspaceRepository, auditRepository, &fakeResolver{}, auth.DefaultPolicy(), 10*time.Second, tracer)
+		other := tests.NewCustomDomain()
+		other.WorkspaceID = "640f1c2e9b1e8a3d5c7b9a06"
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+		repository.EXPECT().GetByID(gomock.Any(), tDomain.ID).Return(other, nil)
+
+		d, err := uc.Verify(context.Background(), verify, newClaims(ownerID))
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.Nil(t, d)
+	})
+
+	t.Run("by viewer", func(t *testing.T) {
+		uc := usecase.NewCustomDomainUsecase(repository, workspaceRepository, auditRepository, &fakeResolver{}, auth.DefaultPolicy(), 10*time.Second, tracer)
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+
+		d, err := uc.Verify(context.Background(), verify, newClaims(viewerID))
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, d)
+	})
+}
+
+func TestCustomDomainUsecase_List(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repository := mock.NewMockCustomDomainRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	uc := usecase.NewCustomDomainUsecase(repository, workspaceRepository, auditMock.NewMockAuditRepository(controller), &fakeResolver{}, auth.DefaultPolicy(), 10*time.Second, tracer)
+	tWorkspace := newWorkspace()
+
+	t.Run("by viewer", func(t *testing.T) {
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+		repository.EXPECT().ListByWorkspace(gomock.Any(), tWorkspace.ID.Hex()).Return([]*domain.CustomDomain{tests.NewCustomDomain()}, nil)
+
+		list, err := uc.List(context.Background(), tWorkspace.ID.Hex(), newClaims(viewerID))
+		require.NoError(t, err)
+		assert.Len(t, list, 1)
+	})
+
+	t.Run("by user outside of workspace", func(t *testing.T) {
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+
+		list, err := uc.List(context.Background(), tWorkspace.ID.Hex(), newClaims("507f191e810c19729de860ed"))
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, list)
+	})
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+// Audit log actions of admins
+const (
+	AuditListUsers     = "admin.user.list"
+	AuditListURLs      = "admin.url.list"
+	AuditListReports   = "admin.report.list"
+	AuditDisableURL    = "admin.url.disable"
+	AuditEnableURL     = "admin.url.enable"
+	AuditSuspendUser   = "admin.user.suspend"
+	AuditUnsuspendUser = "admin.user.unsuspend"
+	AuditUpdateRoles   = "admin.user.roles"
+	AuditResolveReport = "admin.report.resolve"
+	AuditListAudit     = "admin.audit.list"
+)
+
+// ModerateURL represents admin request to disable or enable URL
+type ModerateURL struct {
+	ID     string `json:"-" param:"id" validate:"required,max=20"`
+	Reason string `json:"reason" validate:"omitempty,max=500"`
+}
+
+// ModerateUser represents admin request to suspend or unsuspend user
+type ModerateUser struct {
+	ID     string `json:"-" param:"id" validate:"required,len=24,hexadecimal"`
+	Reason string `json:"reason" validate:"omitempty,max=500"`
+}
+
+// UpdateRoles represents admin request to replace roles of user
+type UpdateRoles struct {
+	ID    string   `json:"-" param:"id" validate:"required,len=24,hexadecimal"`
+	Roles []string `json:"roles" validate:"required,min=1,max=3,unique,dive,oneof=USER SUPPORT ADMIN"`
+}
+
+// AdminUsecase represents the admin's usecases, every call is recorded to
+// audit log on behalf of admin
+type AdminUsecase interface {
+	ListUsers(ctx context.Context, query UserListQuery, admin *auth.Claims) (*UserList, error)
+	ListURLs(ctx context.Context, query URLListQuery, admin *auth.Claims) (*URLList, error)
+	SetURLDisabled(ctx context.Context, req ModerateURL, disabled bool, admin *auth.Claims) (*URL, error)
+	SetUserSuspended(ctx context.Context, req ModerateUser, suspended bool, admin *auth.Claims) (*User, error)
+	UpdateRoles(ctx context.Context, req UpdateRoles, admin *auth.Claims) (*User, error)
+	ListReports(ctx context.Context, query AbuseReportListQuery, admin *auth.Claims) (*AbuseReportList, error)
+	ResolveReport(ctx context.Context, req ResolveAbuseReport, admin *auth.Claims) (*AbuseReport, error)
+	ListAudit(ctx context.Context, query AuditQuery, admin *auth.Claims) (*AuditList, error)
+}
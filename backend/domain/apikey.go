@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+// APIKey represents key which lets services act on behalf of user with
+// limited scopes, only hash of the key is stored. Prefix is the beginning of
+// the key, so user can tell their keys apart.
+type APIKey struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	UserID     primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Name       string             `json:"name" bson:"name"`
+	Prefix     string             `json:"prefix" bson:"prefix"`
+	Hash       string             `json:"-" bson:"hash"`
+	Scopes     []string           `json:"scopes" bson:"scopes"`
+	LastUsedAt *time.Time         `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// CreateAPIKey represents data to create new APIKey
+type CreateAPIKey struct {
+	Name   string   `json:"name" validate:"required,max=50"`
+	Scopes []string `json:"scopes" validate:"required,min=1,unique,dive,oneof=url:create url:read stats:read"`
+}
+
+// NewAPIKey represents created APIKey, Key is shown only once and can't be
+// recovered later
+type NewAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyUsecase represents the APIKey's usecases
+type APIKeyUsecase interface {
+	Create(ctx context.Context, key CreateAPIKey, claims *auth.Claims) (*NewAPIKey, error)
+	List(ctx context.Context, claims *auth.Claims) ([]*APIKey, error)
+	Revoke(ctx context.Context, id string, claims *auth.Claims) error
+	Authenticate(ctx context.Context, key string) (*auth.Claims, error)
+}
+
+// APIKeyRepository represents the APIKey's repository contract
+type APIKeyRepository interface {
+	Store(ctx context.Context, key *APIKey) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*APIKey, error)
+	GetByHash(ctx context.Context, hash string) (*APIKey, error)
+	ListByUser(ctx context.Context, userID primitive.ObjectID) ([]*APIKey, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	UpdateLastUsed(ctx context.Context, id primitive.ObjectID, t time.Time) error
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Audit log actions of URL, user, API key, workspace and custom domain usecases
+const (
+	AuditCreateURL             = "url.create"
+	AuditUpdateURL             = "url.update"
+	AuditDeleteURL             = "url.delete"
+	AuditRestoreURL            = "url.restore"
+	AuditCreateUser            = "user.create"
+	AuditUpdateUser            = "user.update"
+	AuditDeleteUser            = "user.delete"
+	AuditRestoreUser           = "user.restore"
+	AuditLinkIdentity          = "user.link_identity"
+	AuditCreateAPIKey          = "apikey.create"
+	AuditRevokeAPIKey          = "apikey.revoke"
+	AuditCreateWorkspace       = "workspace.create"
+	AuditUpdateWorkspace       = "workspace.update"
+	AuditTransferWorkspaceURLs = "workspace.transfer"
+	AuditCreateCustomDomain    = "domain.create"
+	AuditVerifyCustomDomain    = "domain.verify"
+)
+
+// AuditEntry represents action recorded to audit log, Actor is subject of
+// user who made the action and Target is id of changed item. Actor is empty
+// for actions of unauthenticated users.
+type AuditEntry struct {
+	ID         primitive.ObjectID     `json:"id" bson:"_id"`
+	Actor      string                 `json:"actor,omitempty" bson:"actor,omitempty"`
+	ActorRoles []string               `json:"actor_roles,omitempty" bson:"actor_roles,omitempty"`
+	Action     string                 `json:"action" bson:"action"`
+	Target     string                 `json:"target,omitempty" bson:"target,omitempty"`
+	Changes    map[string]AuditChange `json:"changes,omitempty" bson:"changes,omitempty"`
+	Details    map[string]string      `json:"details,omitempty" bson:"details,omitempty"`
+	RequestID  string                 `json:"request_id,omitempty" bson:"request_id,omitempty"`
+	IP         string                 `json:"ip,omitempty" bson:"ip,omitempty"`
+	CreatedAt  time.Time              `json:"created_at" bson:"created_at"`
+}
+
+// AuditChange represents change of a single field, Before is empty for
+// created items and After is empty for deleted ones
+type AuditChange struct {
+	Before interface{} `json:"before,omitempty" bson:"before,omitempty"`
+	After  interface{} `json:"after,omitempty" bson:"after,omitempty"`
+}
+
+// AuditQuery represents parameters of audit log request, entries are listed
+// newest first. From and To limit creation time of entries, zero values
+// mean no limit.
+type AuditQuery struct {
+	Cursor string    `json:"cursor" query:"cursor" validate:"omitempty,len=24,hexadecimal"`
+	Limit  int       `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+	Actor  string    `json:"actor" query:"actor" validate:"omitempty,max=100"`
+	Target string    `json:"target" query:"target" validate:"omitempty,max=100"`
+	Action string    `json:"action" query:"action" validate:"omitempty,max=100"`
+	From   time.Time `json:"from" query:"from"`
+	To     time.Time `json:"to" query:"to"`
+}
+
+// AuditList represents a page of audit entries, NextCursor is empty on the last page
+type AuditList struct {
+	Items      []*AuditEntry `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// AuditRepository represents the audit log repository contract, entries are
+// never changed or removed
+type AuditRepository interface {
+	Store(ctx context.Context, e *AuditEntry) error
+	StoreMany(ctx context.Context, entries []*AuditEntry) error
+	List(ctx context.Context, query AuditQuery) (*AuditList, error)
+}
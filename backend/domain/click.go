@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+const (
+	// StatsIntervalHour groups clicks by hour
+	StatsIntervalHour = "hour"
+	// StatsIntervalDay groups clicks by day
+	StatsIntervalDay = "day"
+)
+
+// Click represents single redirect event
+type Click struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	URLID     string             `json:"url_id" bson:"url_id"`
+	OwnerID   string             `json:"owner_id" bson:"owner_id"`
+	Timestamp time.Time          `json:"timestamp" bson:"timestamp"`
+	Referrer  string             `json:"referrer" bson:"referrer"`
+	UserAgent string             `json:"user_agent" bson:"user_agent"`
+	Browser   string             `json:"browser" bson:"browser"`
+	OS        string             `json:"os" bson:"os"`
+	Device    string             `json:"device" bson:"device"`
+	Country   string             `json:"country" bson:"country"`
+	VisitorID string             `json:"visitor_id" bson:"visitor_id"`
+	IP        net.IP             `json:"-" bson:"-"`
+}
+
+// StatsQuery represents parameters of link statistics request
+type StatsQuery struct {
+	URLID    string    `json:"id" param:"id" validate:"required,urlid,max=21"`
+	From     time.Time `json:"from" query:"from"`
+	To       time.Time `json:"to" query:"to"`
+	Interval string    `json:"interval" query:"interval" validate:"omitempty,oneof=hour day"`
+}
+
+// StatsBucket represents number of clicks in a time interval starting at Time
+type StatsBucket struct {
+	Time   time.Time `json:"time" bson:"_id"`
+	Clicks int64     `json:"clicks" bson:"clicks"`
+}
+
+// StatsEntry represents number of clicks with the same Value, e.g. browser or country
+type StatsEntry struct {
+	Value  string `json:"value" bson:"_id"`
+	Clicks int64  `json:"clicks" bson:"clicks"`
+}
+
+// ClickStats represents link statistics over the requested time range
+type ClickStats struct {
+	URLID          string        `json:"url_id"`
+	From           time.Time     `json:"from"`
+	To             time.Time     `json:"to"`
+	Interval       string        `json:"interval"`
+	TotalClicks    int64         `json:"total_clicks"`
+	UniqueVisitors int64         `json:"unique_visitors"`
+	Clicks         []StatsBucket `json:"clicks"`
+	TopReferrers   []StatsEntry  `json:"top_referrers"`
+	TopBrowsers    []StatsEntry  `json:"top_browsers"`
+	TopOS          []StatsEntry  `json:"top_os"`
+	TopCountries   []StatsEntry  `json:"top_countries"`
+}
+
+// ClickUsecase represents the Click's usecases
+type ClickUsecase interface {
+	Record(ctx context.Context, click *Click)
+	Stats(ctx context.Context, query StatsQuery, user *auth.Claims) (*ClickStats, error)
+	Close(ctx context.Context) error
+}
+
+// ClickRepository represents the Click's repository contract. Clicks are
+// written in batches, so it can be backed by a column store such as ClickHouse
+type ClickRepository interface {
+	StoreMany(ctx context.Context, clicks []*Click) error
+	Stats(ctx context.Context, query StatsQuery) (*ClickStats, error)
+}
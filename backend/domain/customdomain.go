@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+// customDomainURLIDLen is the length of hash in id of URLs served on custom domains
+const customDomainURLIDLen = 20
+
+// customDomainURLIDPrefix starts id of URLs served on custom domains, linkid
+// never contains it, so such ids can't be taken or opened on own hosts
+const customDomainURLIDPrefix = "~"
+
+// CustomDomain represents host name on which URLs of workspace are served.
+// Domain can be used only after it is verified, the same host may be
+// registered by several workspaces, but only one of them can verify it.
+type CustomDomain struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	Host        string             `json:"host" bson:"host"`
+	WorkspaceID string             `json:"workspace_id" bson:"workspace_id"`
+	Token       string             `json:"token" bson:"token"`
+	VerifiedAt  *time.Time         `json:"verified_at,omitempty" bson:"verified_at,omitempty"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// IsVerified reports whether control over domain was proven
+func (d *CustomDomain) IsVerified() bool {
+	return d.VerifiedAt != nil
+}
+
+// TXTRecord returns name and value of DNS TXT record which verifies domain
+func (d *CustomDomain) TXTRecord() (string, string) {
+	return "_shortener." + d.Host, "shortener-verification=" + d.Token
+}
+
+// CustomDomainURLID returns id of URL with the given slug on custom domain,
+// so the same slug can be used on different domains
+func CustomDomainURLID(host, slug string) string {
+	h := sha256.Sum256([]byte(host + "/" + slug))
+	return customDomainURLIDPrefix + base64.RawURLEncoding.EncodeToString(h[:])[:customDomainURLIDLen]
+}
+
+// IsCustomDomainURLID reports whether id is id of URL served on custom domain
+func IsCustomDomainURLID(id string) bool {
+	return strings.HasPrefix(id, customDomainURLIDPrefix)
+}
+
+// CreateCustomDomain represents host registered for workspace
+type CreateCustomDomain struct {
+	WorkspaceID string `json:"-" param:"id" validate:"required,len=24,hexadecimal"`
+	Host        string `json:"host" validate:"required,fqdn,max=253"`
+}
+
+// VerifyCustomDomain represents domain of workspace checked for TXT record
+type VerifyCustomDomain struct {
+	WorkspaceID string `param:"id" validate:"required,len=24,hexadecimal"`
+	DomainID    string `param:"domain_id" validate:"required,len=24,hexadecimal"`
+}
+
+// CustomDomainUsecase represents the custom domain usecases
+type CustomDomainUsecase interface {
+	Register(ctx context.Context, create CreateCustomDomain, claims *auth.Claims) (*CustomDomain, error)
+	List(ctx context.Context, workspaceID string, claims *auth.Claims) ([]*CustomDomain, error)
+	Verify(ctx context.Context, verify VerifyCustomDomain, claims *auth.Claims) (*CustomDomain, error)
+}
+
+// CustomDomainRepository represents the custom domain repository contract,
+// GetByHost returns only verified domain
+type CustomDomainRepository interface {
+	Store(ctx context.Context, d *CustomDomain) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*CustomDomain, error)
+	GetByHost(ctx context.Context, host string) (*CustomDomain, error)
+	ListByWorkspace(ctx context.Context, workspaceID string) ([]*CustomDomain, error)
+	SetVerified(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	CountVerified(ctx context.Context) (int64, error)
+}
+
+// TXTResolver looks up DNS TXT records of name, it is implemented by net.Resolver
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
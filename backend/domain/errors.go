@@ -24,6 +24,12 @@ var (
 	// ErrForbidden will throw if user tries to do something that he is not
 	// authorized to do
 	ErrForbidden = errors.New("attempted action is not allowed")
+	// ErrGone will throw if the requested item existed, but is no longer available
+	ErrGone = errors.New("your requested item is no longer available")
+	// ErrPasswordRequired will throw if the requested item is protected by password
+	ErrPasswordRequired = errors.New("password is required to open this link")
+	// ErrTooManyRequests will throw if too many attempts were made in a short time
+	ErrTooManyRequests = errors.New("too many requests, try again later")
 )
 
 // ResponseError represent the response error struct
@@ -52,6 +58,15 @@ func GetStatusCode(err error, logger *zap.Logger) int {
 	if errors.Is(err, ErrForbidden) {
 		return http.StatusForbidden
 	}
+	if errors.Is(err, ErrGone) {
+		return http.StatusGone
+	}
+	if errors.Is(err, ErrPasswordRequired) {
+		return http.StatusUnauthorized
+	}
+	if errors.Is(err, ErrTooManyRequests) {
+		return http.StatusTooManyRequests
+	}
 
 	logger.Error("Server error: ", zap.Error(err))
 	return http.StatusInternalServerError
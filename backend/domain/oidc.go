@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+// OIDCLogin represents login started at identity provider, it is finished
+// by callback with the same State only once. Nonce is expected in ID token
+// and Verifier is PKCE code verifier sent with authorization code. UserID is
+// set if signed in user links identity to their account.
+type OIDCLogin struct {
+	State     string    `bson:"_id"`
+	Nonce     string    `bson:"nonce"`
+	Verifier  string    `bson:"verifier"`
+	UserID    string    `bson:"user_id,omitempty"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// IDToken represents verified claims of ID token issued by identity provider
+type IDToken struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// OIDCCallback represents authorization response of identity provider,
+// Error is set instead of Code if user didn't grant access
+type OIDCCallback struct {
+	State            string `json:"state" query:"state" validate:"required,max=100"`
+	Code             string `json:"code" query:"code" validate:"omitempty,max=2048"`
+	Error            string `json:"error" query:"error" validate:"omitempty,max=200"`
+	ErrorDescription string `json:"error_description" query:"error_description" validate:"omitempty,max=1000"`
+}
+
+// OIDCProvider represents OpenID Connect identity provider, Exchange
+// redeems authorization code and returns claims of verified ID token
+type OIDCProvider interface {
+	AuthCodeURL(ctx context.Context, state, nonce, challenge string) (string, error)
+	Exchange(ctx context.Context, code, verifier, nonce string) (*IDToken, error)
+}
+
+// OIDCUsecase represents the OpenID Connect login usecases
+type OIDCUsecase interface {
+	Login(ctx context.Context) (*OIDCLogin, string, error)
+	Link(ctx context.Context, claims *auth.Claims) (*OIDCLogin, string, error)
+	Callback(ctx context.Context, now time.Time, callback OIDCCallback) (*auth.Claims, error)
+}
+
+// OIDCLoginRepository represents the OIDCLogin's repository contract, Take
+// returns login and removes it, so it can't be used twice
+type OIDCLoginRepository interface {
+	Store(ctx context.Context, login *OIDCLogin) error
+	Take(ctx context.Context, state string) (*OIDCLogin, error)
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// ReportStatusOpen is status of abuse report which is not reviewed yet
+	ReportStatusOpen = "open"
+	// ReportStatusResolved is status of abuse report reviewed by admin
+	ReportStatusResolved = "resolved"
+)
+
+// AbuseReport represents complaint about URL, Link is destination of URL at
+// the time of report. ReporterID is empty for anonymous reports.
+type AbuseReport struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	URLID      string             `json:"url_id" bson:"url_id"`
+	Link       string             `json:"link" bson:"link"`
+	Reason     string             `json:"reason" bson:"reason"`
+	Details    string             `json:"details,omitempty" bson:"details,omitempty"`
+	ReporterID string             `json:"reporter_id,omitempty" bson:"reporter_id,omitempty"`
+	Status     string             `json:"status" bson:"status"`
+	Resolution string             `json:"resolution,omitempty" bson:"resolution,omitempty"`
+	ResolvedBy string             `json:"resolved_by,omitempty" bson:"resolved_by,omitempty"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	ResolvedAt *time.Time         `json:"resolved_at,omitempty" bson:"resolved_at,omitempty"`
+}
+
+// CreateAbuseReport represents data to report URL
+type CreateAbuseReport struct {
+	URLID      string `json:"-" param:"id" validate:"required,max=20"`
+	Reason     string `json:"reason" validate:"required,oneof=phishing malware spam other"`
+	Details    string `json:"details" validate:"omitempty,max=2000"`
+	ReporterID string `json:"-"`
+}
+
+// ResolveAbuseReport represents admin decision on abuse report
+type ResolveAbuseReport struct {
+	ID         string `json:"-" param:"id" validate:"required,len=24,hexadecimal"`
+	Resolution string `json:"resolution" validate:"required,max=2000"`
+}
+
+// AbuseReportListQuery represents parameters of abuse report list request,
+// reports are listed newest first
+type AbuseReportListQuery struct {
+	Cursor string `json:"cursor" query:"cursor" validate:"omitempty,len=24,hexadecimal"`
+	Limit  int    `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+	Status string `json:"status" query:"status" validate:"omitempty,oneof=open resolved"`
+	URLID  string `json:"url_id" query:"url_id" validate:"omitempty,max=20"`
+}
+
+// AbuseReportList represents a page of abuse reports, NextCursor is empty on the last page
+type AbuseReportList struct {
+	Items      []*AbuseReport `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// AbuseReportUsecase represents the abuse report's usecases
+type AbuseReportUsecase interface {
+	Create(ctx context.Context, report CreateAbuseReport) (*AbuseReport, error)
+}
+
+// AbuseReportRepository represents the abuse report's repository contract
+type AbuseReportRepository interface {
+	Store(ctx context.Context, r *AbuseReport) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*AbuseReport, error)
+	Update(ctx context.Context, r *AbuseReport) error
+	List(ctx context.Context, query AbuseReportListQuery) (*AbuseReportList, error)
+}
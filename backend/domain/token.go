@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+// RefreshToken represents the refresh token model, only hash of the token is stored.
+// Tokens produced by rotation of one another share the same FamilyID.
+type RefreshToken struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	Hash      string             `bson:"hash"`
+	UserID    primitive.ObjectID `bson:"user_id"`
+	FamilyID  primitive.ObjectID `bson:"family_id"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+	CreatedAt time.Time          `bson:"created_at"`
+	UsedAt    *time.Time         `bson:"used_at"`
+	RevokedAt *time.Time         `bson:"revoked_at"`
+}
+
+// RevokedToken represents revoked access token. ID is either token id (jti)
+// or "user:" prefixed user id, which revokes all user tokens issued at or before RevokedAt.
+type RevokedToken struct {
+	ID        string    `bson:"_id"`
+	RevokedAt time.Time `bson:"revoked_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// RefreshTokenRequest represents data to refresh access token
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required,max=100"`
+}
+
+// TokenUsecase represents the Token's usecases
+type TokenUsecase interface {
+	Issue(ctx context.Context, claims *auth.Claims) (string, error)
+	Refresh(ctx context.Context, now time.Time, refreshToken string) (*auth.Claims, string, error)
+	Logout(ctx context.Context, refreshToken string, claims *auth.Claims) error
+	LogoutAll(ctx context.Context, claims *auth.Claims) error
+	RevokeAll(ctx context.Context, userID string) error
+	CheckRevoked(ctx context.Context, claims *auth.Claims) error
+}
+
+// TokenRepository represents the Token's repository contract
+type TokenRepository interface {
+	Store(ctx context.Context, token *RefreshToken) error
+	GetByHash(ctx context.Context, hash string) (*RefreshToken, error)
+	Use(ctx context.Context, hash string, now time.Time) (*RefreshToken, error)
+	RevokeFamily(ctx context.Context, familyID primitive.ObjectID, now time.Time) error
+	RevokeUser(ctx context.Context, userID primitive.ObjectID, now time.Time) error
+	RevokeAccess(ctx context.Context, token *RevokedToken) error
+	IsAccessRevoked(ctx context.Context, jti, subject string, issuedAt time.Time) (bool, error)
+}
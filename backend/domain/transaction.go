@@ -0,0 +1,9 @@
+package domain
+
+import "context"
+
+// Transactor runs functions in a transaction, changes made by repositories
+// called with ctx given to fn are committed only if fn returns nil
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -2,47 +2,290 @@ package domain
 
 import (
 	"context"
+	"net"
+	"net/http"
 	"time"
 
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
 	"github.com/semka95/shortener/backend/web/auth"
 )
 
-// URL represents the URL model
+// URL represents the URL model. MaxClicks limits number of redirects and
+// RemainingClicks is decremented on every redirect, both are zero for URLs
+// without limit. URL doesn't redirect before ValidFrom. Flagged URLs failed
+// link screening, warning page is shown instead of redirect to them. Disabled
+// URLs and URLs of suspended users don't redirect. Deleted URLs are kept until
+// deletion grace period ends, so they can be restored. URLs of workspace are
+// also accessed by its members. URLs of custom domain are opened by Slug on
+// Domain, their ID is derived from both.
 type URL struct {
-	ID             string    `json:"id" bson:"_id"`
-	Link           string    `json:"link" bson:"link"`
-	ExpirationDate time.Time `json:"expiration_date" bson:"expiration_date"`
-	UserID         string    `json:"user_id" bson:"user_id"`
-	CreatedAt      time.Time `json:"created_at" bson:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at" bson:"updated_at"`
+	ID              string     `json:"id" bson:"_id"`
+	Link            string     `json:"link" bson:"link"`
+	Title           string     `json:"title,omitempty" bson:"title"`
+	Notes           string     `json:"notes,omitempty" bson:"notes"`
+	ExpirationDate  time.Time  `json:"expiration_date" bson:"expiration_date"`
+	UserID          string     `json:"user_id" bson:"user_id"`
+	WorkspaceID     string     `json:"workspace_id,omitempty" bson:"workspace_id,omitempty"`
+	Domain          string     `json:"domain,omitempty" bson:"domain,omitempty"`
+	Slug            string     `json:"slug,omitempty" bson:"slug,omitempty"`
+	RedirectType    int        `json:"redirect_type" bson:"redirect_type,omitempty"`
+	HashedPassword  string     `json:"-" bson:"hashed_password,omitempty"`
+	MaxClicks       int64      `json:"max_clicks" bson:"max_clicks,omitempty"`
+	RemainingClicks int64      `json:"remaining_clicks" bson:"remaining_clicks,omitempty"`
+	ValidFrom       *time.Time `json:"valid_from,omitempty" bson:"valid_from,omitempty"`
+	Flagged         bool       `json:"flagged,omitempty" bson:"flagged"`
+	FlagReason      string     `json:"flag_reason,omitempty" bson:"flag_reason"`
+	Disabled        bool       `json:"disabled,omitempty" bson:"disabled"`
+	OwnerSuspended  bool       `json:"owner_suspended,omitempty" bson:"owner_suspended,omitempty"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" bson:"updated_at"`
+	Expired         bool       `json:"expired,omitempty" bson:"-"`
+}
+
+// IsClickLimited reports whether URL deactivates after a number of redirects
+func (u *URL) IsClickLimited() bool {
+	return u.MaxClicks > 0
+}
+
+// IsExhausted reports whether all redirects of click limited URL are used
+func (u *URL) IsExhausted() bool {
+	return u.IsClickLimited() && u.RemainingClicks <= 0
+}
+
+// IsActive reports whether URL activation time has come at the given time
+func (u *URL) IsActive(now time.Time) bool {
+	return u.ValidFrom == nil || !now.Before(*u.ValidFrom)
+}
+
+// IsProtected reports whether URL can be opened only with password
+func (u *URL) IsProtected() bool {
+	return u.HashedPassword != ""
+}
+
+// IsPermanentRedirect reports whether URL redirect can be cached by clients
+func (u *URL) IsPermanentRedirect() bool {
+	return u.RedirectType == http.StatusMovedPermanently || u.RedirectType == http.StatusPermanentRedirect
+}
+
+// ShortID returns id of URL in its short link
+func (u *URL) ShortID() string {
+	if u.Domain != "" {
+		return u.Slug
+	}
+	return u.ID
+}
+
+// IsExpired reports whether URL expiration date has passed at the given time
+func (u *URL) IsExpired(now time.Time) bool {
+	return !u.ExpirationDate.After(now)
 }
 
 // CreateURL represents data to create new URL
 type CreateURL struct {
 	ID             *string    `json:"id" validate:"omitempty,linkid,min=7,max=20"`
 	Link           string     `json:"link" validate:"required,url"`
+	Title          string     `json:"title" validate:"omitempty,max=200"`
+	Notes          string     `json:"notes" validate:"omitempty,max=2000"`
 	ExpirationDate *time.Time `json:"expiration_date" validate:"omitempty,gt"`
+	RedirectType   int        `json:"redirect_type" validate:"omitempty,oneof=301 302 307 308"`
+	Password       string     `json:"password" validate:"omitempty,min=4,max=72"`
+	MaxClicks      int64      `json:"max_clicks" validate:"omitempty,min=1,max=1000000"`
+	ValidFrom      *time.Time `json:"valid_from" validate:"omitempty,gt"`
+	WorkspaceID    string     `json:"workspace_id" validate:"omitempty,len=24,hexadecimal"`
+	Domain         string     `json:"domain" validate:"omitempty,fqdn,max=253"`
 	UserID         string     `json:"-"`
 }
 
-// UpdateURL represents data to update URL
+// URLUnlock represents password submitted to open protected URL, it is sent
+// either as JSON or as HTML form. Host is host name the URL is opened on.
+type URLUnlock struct {
+	ID       string `json:"-" form:"-" param:"id" validate:"required,linkid,max=20"`
+	Host     string `json:"-" form:"-"`
+	Password string `json:"password" form:"password" validate:"required,max=72"`
+}
+
+// UpdateURL represents data to update URL, only provided fields are changed
 type UpdateURL struct {
-	ID             string    `json:"id" validate:"required,linkid,max=20"`
-	ExpirationDate time.Time `json:"expiration_date" validate:"required,gt"`
+	ID             string     `json:"id" validate:"required,urlid,max=21"`
+	Link           *string    `json:"link" validate:"omitempty,url"`
+	Title          *string    `json:"title" validate:"omitempty,max=200"`
+	Notes          *string    `json:"notes" validate:"omitempty,max=2000"`
+	RedirectType   *int       `json:"redirect_type" validate:"omitempty,oneof=301 302 307 308"`
+	ExpirationDate *time.Time `json:"expiration_date" validate:"omitempty,gt"`
+}
+
+// BulkCreateURL represents data to create several URLs at once, items are
+// validated one by one, so invalid items don't fail the whole request
+type BulkCreateURL struct {
+	Items []CreateURL `json:"items" validate:"required,min=1,max=500"`
+}
+
+// BulkDeleteURL represents ids of URLs to delete at once
+type BulkDeleteURL struct {
+	IDs []string `json:"ids" validate:"required,min=1,max=500,dive,required,urlid,max=21"`
+}
+
+// BulkResult represents result of a single item of bulk operation,
+// Index is position of the item in request
+type BulkResult struct {
+	Index  int                                    `json:"index"`
+	ID     string                                 `json:"id,omitempty"`
+	Status int                                    `json:"status"`
+	Error  string                                 `json:"error,omitempty"`
+	Fields validator.ValidationErrorsTranslations `json:"fields,omitempty"`
+	Err    error                                  `json:"-"`
+}
+
+// BulkResponse represents results of bulk operation in request order
+type BulkResponse struct {
+	Results []BulkResult `json:"results"`
+}
+
+// URLRevision represents state of URL before it was changed
+type URLRevision struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	URLID          string             `json:"url_id" bson:"url_id"`
+	Link           string             `json:"link" bson:"link"`
+	Title          string             `json:"title,omitempty" bson:"title,omitempty"`
+	Notes          string             `json:"notes,omitempty" bson:"notes,omitempty"`
+	RedirectType   int                `json:"redirect_type" bson:"redirect_type,omitempty"`
+	ExpirationDate time.Time          `json:"expiration_date" bson:"expiration_date"`
+	ChangedBy      string             `json:"changed_by" bson:"changed_by"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// URLRollback represents data to restore URL to the given revision
+type URLRollback struct {
+	URLID      string `json:"-" param:"id" validate:"required,urlid,max=21"`
+	RevisionID string `json:"revision_id" validate:"required,len=24,hexadecimal"`
+}
+
+// ValidRedirectType reports whether code can be used as URL redirect status code
+func ValidRedirectType(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+const (
+	// URLStatusActive filters URLs which are not expired
+	URLStatusActive = "active"
+	// URLStatusExpired filters expired URLs
+	URLStatusExpired = "expired"
+)
+
+// URLListQuery represents parameters of URL list request, UserID is used only
+// by admins, users list their own URLs or URLs of workspace they are members of
+type URLListQuery struct {
+	Cursor      string `json:"cursor" query:"cursor" validate:"omitempty,max=200"`
+	Limit       int    `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+	Sort        string `json:"sort" query:"sort" validate:"omitempty,oneof=created_at expiration_date"`
+	Order       string `json:"order" query:"order" validate:"omitempty,oneof=asc desc"`
+	Status      string `json:"status" query:"status" validate:"omitempty,oneof=active expired"`
+	Search      string `json:"search" query:"search" validate:"omitempty,max=200"`
+	Flagged     bool   `json:"flagged" query:"flagged"`
+	Disabled    bool   `json:"disabled" query:"disabled"`
+	UserID      string `json:"user_id" query:"user_id" validate:"omitempty,len=24,hexadecimal"`
+	WorkspaceID string `json:"workspace_id" query:"workspace_id" validate:"omitempty,len=24,hexadecimal"`
+}
+
+// URLList represents a page of URLs, NextCursor is empty on the last page
+type URLList struct {
+	Items      []*URL `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // URLUsecase represents the URL's usecases
 type URLUsecase interface {
-	GetByID(ctx context.Context, id string) (*URL, error)
+	GetByID(ctx context.Context, id string, user *auth.Claims) (*URL, error)
+	Resolve(ctx context.Context, host, id string) (*URL, error)
 	Update(ctx context.Context, updateURL UpdateURL, user *auth.Claims) error
 	Store(ctx context.Context, createURL CreateURL) (*URL, error)
 	Delete(ctx context.Context, id string, user *auth.Claims) error
+	List(ctx context.Context, query URLListQuery, user *auth.Claims) (*URLList, error)
+	History(ctx context.Context, id string, user *auth.Claims) ([]*URLRevision, error)
+	Rollback(ctx context.Context, rollback URLRollback, user *auth.Claims) (*URL, error)
+	BulkStore(ctx context.Context, items []CreateURL) ([]BulkResult, error)
+	BulkDelete(ctx context.Context, ids []string, user *auth.Claims) ([]BulkResult, error)
+	Unlock(ctx context.Context, unlock URLUnlock) (*URL, error)
+	Consume(ctx context.Context, u *URL) (*URL, error)
+	Restore(ctx context.Context, id string, user *auth.Claims) (*URL, error)
+	Purge(ctx context.Context) (int, error)
+	Authorize(ctx context.Context, u *URL, user *auth.Claims, action string) error
 }
 
-// URLRepository represents the URL's repository contract
+// URLRepository represents the URL's repository contract, Delete and
+// DeleteMany mark URLs as deleted, deleted URLs are not returned by other
+// methods except GetDeleted and ListDeleted
 type URLRepository interface {
 	GetByID(ctx context.Context, id string) (*URL, error)
+	GetByDomain(ctx context.Context, host, slug string) (*URL, error)
 	Update(ctx context.Context, url *URL) error
 	Store(ctx context.Context, u *URL) error
 	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, query URLListQuery) (*URLList, error)
+	GetByIDs(ctx context.Context, ids []string) ([]*URL, error)
+	StoreMany(ctx context.Context, urls []*URL) ([]error, error)
+	DeleteMany(ctx context.Context, ids []string) error
+	ConsumeClick(ctx context.Context, id string) (*URL, error)
+	SetOwnerSuspended(ctx context.Context, userID string, suspended bool) ([]string, error)
+	SetDisabled(ctx context.Context, id string, disabled bool, at time.Time) error
+	GetDeleted(ctx context.Context, id string) (*URL, error)
+	Restore(ctx context.Context, id string) error
+	ListDeleted(ctx context.Context, before time.Time, limit int) ([]string, error)
+	ListExpired(ctx context.Context, before time.Time, limit int) ([]*URL, error)
+	Purge(ctx context.Context, ids []string) error
+	DeleteByUser(ctx context.Context, userID string) ([]string, error)
+	SetOwner(ctx context.Context, from, to string) ([]string, error)
+	SetWorkspace(ctx context.Context, ids []string, from, to string) ([]string, error)
+}
+
+// URLCache drops cached URLs, so they are read from the database again. URLs
+// changed in transactions are invalidated after commit, as transaction can be
+// retried or rolled back
+type URLCache interface {
+	Invalidate(ctx context.Context, ids ...string)
+}
+
+// URLRevisionRepository represents the URL revision's repository contract
+type URLRevisionRepository interface {
+	Store(ctx context.Context, r *URLRevision) error
+	GetByID(ctx context.Context, urlID string, id primitive.ObjectID) (*URLRevision, error)
+	List(ctx context.Context, urlID string, limit int) ([]*URLRevision, error)
+	DeleteByURL(ctx context.Context, urlIDs ...string) error
+}
+
+// TokenGenerator generates ids of new URLs. Ids are not guaranteed to be
+// unique, URL is stored with a new id if the generated one is already taken.
+type TokenGenerator interface {
+	Generate(ctx context.Context) (string, error)
+}
+
+// ScreenResult represents verdict of link screening, Reason explains why
+// link was blocked
+type ScreenResult struct {
+	Blocked bool
+	Reason  string
+}
+
+// LinkScreener checks destination links of URLs before they are stored,
+// URLs with blocked links are stored flagged, so admins can review them
+type LinkScreener interface {
+	Screen(ctx context.Context, link string) (ScreenResult, error)
+}
+
+// IPResolver looks up addresses of host, it is implemented by net.Resolver
+type IPResolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// CounterRepository represents the persistent counters contract, it is used
+// to lease ranges of ids, so several instances never get the same value
+type CounterRepository interface {
+	Lease(ctx context.Context, name string, size int64) (int64, error)
 }
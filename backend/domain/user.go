@@ -9,15 +9,60 @@ import (
 	"github.com/semka95/shortener/backend/web/auth"
 )
 
-// User represents the User model
+// User represents the User model, suspended user can't get tokens and
+// their URLs don't redirect. Deleted users are kept until deletion grace
+// period ends, so admins can restore them. Users signed in with identity
+// provider have Identities and may have no password.
 type User struct {
 	ID             primitive.ObjectID `json:"id" bson:"_id"`
 	FullName       string             `json:"full_name" bson:"full_name"`
 	Email          string             `json:"email" bson:"email"`
 	HashedPassword string             `json:"-" bson:"hashed_password"`
 	Roles          []string           `json:"roles" bson:"roles"`
+	Identities     []Identity         `json:"identities,omitempty" bson:"identities,omitempty"`
+	SuspendedAt    *time.Time         `json:"suspended_at,omitempty" bson:"suspended_at"`
+	DeletedAt      *time.Time         `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
 	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
+
+	// EmailVerifiedAt is set when email is verified by identity provider,
+	// it's reset when email is changed
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" bson:"email_verified_at"`
+}
+
+// Identity represents account of user at external identity provider
+type Identity struct {
+	Issuer   string    `json:"issuer" bson:"issuer"`
+	Subject  string    `json:"subject" bson:"subject"`
+	LinkedAt time.Time `json:"linked_at" bson:"linked_at"`
+}
+
+// IsSuspended reports whether user was suspended by admin
+func (u *User) IsSuspended() bool {
+	return u.SuspendedAt != nil
+}
+
+const (
+	// UserStatusActive filters users which are not suspended
+	UserStatusActive = "active"
+	// UserStatusSuspended filters suspended users
+	UserStatusSuspended = "suspended"
+)
+
+// UserListQuery represents parameters of user list request, users are listed
+// newest first. Search matches email and full name.
+type UserListQuery struct {
+	Cursor string `json:"cursor" query:"cursor" validate:"omitempty,len=24,hexadecimal"`
+	Limit  int    `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+	Status string `json:"status" query:"status" validate:"omitempty,oneof=active suspended"`
+	Role   string `json:"role" query:"role" validate:"omitempty,oneof=USER SUPPORT ADMIN"`
+	Search string `json:"search" query:"search" validate:"omitempty,max=200"`
+}
+
+// UserList represents a page of users, NextCursor is empty on the last page
+type UserList struct {
+	Items      []*User `json:"items"`
+	NextCursor string  `json:"next_cursor,omitempty"`
 }
 
 // CreateUser represents data to create new User
@@ -36,20 +81,58 @@ type UpdateUser struct {
 	NewPassword     *string            `json:"new_password" validate:"omitempty,min=8,max=30"`
 }
 
+const (
+	// LinkPolicyDelete deletes URLs of deleted user
+	LinkPolicyDelete = "delete"
+	// LinkPolicyTransfer moves URLs of deleted user to another user
+	LinkPolicyTransfer = "transfer"
+	// LinkPolicyOrphan keeps URLs of deleted user as ownerless read-only links
+	LinkPolicyOrphan = "orphan"
+)
+
+// DeleteUser represents request to delete User, Links sets what happens to
+// their URLs, default policy is used if it's empty. TransferTo is required by
+// transfer policy.
+type DeleteUser struct {
+	ID         string `json:"id" param:"id"`
+	Links      string `json:"links" query:"links" validate:"omitempty,oneof=delete transfer orphan"`
+	TransferTo string `json:"transfer_to" query:"transfer_to" validate:"omitempty,len=24,hexadecimal"`
+}
+
+// DeleteUserResult represents outcome of user deletion, URLs is the number
+// of URLs affected by link policy
+type DeleteUserResult struct {
+	ID         string `json:"id"`
+	Links      string `json:"links"`
+	URLs       int    `json:"urls"`
+	TransferTo string `json:"transfer_to,omitempty"`
+}
+
 // UserUsecase represents the User's usecases
 type UserUsecase interface {
 	GetByID(ctx context.Context, id string) (*User, error)
 	Update(ctx context.Context, user UpdateUser, claims *auth.Claims) error
 	Create(ctx context.Context, user CreateUser) (*User, error)
-	Delete(ctx context.Context, id string) error
+	Delete(ctx context.Context, req DeleteUser) (*DeleteUserResult, error)
 	Authenticate(ctx context.Context, now time.Time, email, password string) (*auth.Claims, error)
+	Restore(ctx context.Context, id string) (*User, error)
+	Purge(ctx context.Context) (int, error)
 }
 
-// UserRepository represents the User's repository contract
+// UserRepository represents the User's repository contract, Delete marks
+// user as deleted, deleted users are not returned by other methods except
+// GetDeleted and ListDeleted
 type UserRepository interface {
 	GetByID(ctx context.Context, id primitive.ObjectID) (*User, error)
 	GetByEmail(ctx context.Context, email string) (*User, error)
+	GetByIdentity(ctx context.Context, issuer, subject string) (*User, error)
+	AddIdentity(ctx context.Context, id primitive.ObjectID, identity Identity) error
 	Update(ctx context.Context, user *User) error
 	Create(ctx context.Context, user *User) error
 	Delete(ctx context.Context, id primitive.ObjectID) error
+	List(ctx context.Context, query UserListQuery) (*UserList, error)
+	GetDeleted(ctx context.Context, id primitive.ObjectID) (*User, error)
+	Restore(ctx context.Context, id primitive.ObjectID) error
+	ListDeleted(ctx context.Context, before time.Time, limit int) ([]primitive.ObjectID, error)
+	Purge(ctx context.Context, ids []primitive.ObjectID) error
 }
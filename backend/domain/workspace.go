@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+// Workspace represents group of users who manage URLs together, members get
+// access to URLs of workspace according to their roles
+type Workspace struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	Name      string             `json:"name" bson:"name"`
+	Members   []WorkspaceMember  `json:"members" bson:"members"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// WorkspaceMember represents user who is a member of workspace, Role is one
+// of owner, editor or viewer
+type WorkspaceMember struct {
+	UserID  string    `json:"user_id" bson:"user_id"`
+	Role    string    `json:"role" bson:"role"`
+	AddedAt time.Time `json:"added_at" bson:"added_at"`
+}
+
+// Role returns role of user in workspace, it is empty if user is not a member
+func (w *Workspace) Role(userID string) string {
+	for _, m := range w.Members {
+		if m.UserID == userID {
+			return m.Role
+		}
+	}
+	return ""
+}
+
+// Owners returns number of workspace owners
+func (w *Workspace) Owners() int {
+	n := 0
+	for _, m := range w.Members {
+		if m.Role == auth.WorkspaceOwner {
+			n++
+		}
+	}
+	return n
+}
+
+// CreateWorkspace represents data to create new Workspace
+type CreateWorkspace struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// InviteMember represents user invited to workspace by email, existing
+// member gets the new role
+type InviteMember struct {
+	WorkspaceID string `json:"-" param:"id" validate:"required,len=24,hexadecimal"`
+	Email       string `json:"email" validate:"required,email"`
+	Role        string `json:"role" validate:"required,oneof=owner editor viewer"`
+}
+
+// RemoveMember represents member removed from workspace
+type RemoveMember struct {
+	WorkspaceID string `param:"id" validate:"required,len=24,hexadecimal"`
+	UserID      string `param:"user_id" validate:"required,len=24,hexadecimal"`
+}
+
+// TransferURLs represents URLs moved from workspace to another one, URLs
+// which don't belong to the workspace are skipped
+type TransferURLs struct {
+	WorkspaceID string   `json:"-" param:"id" validate:"required,len=24,hexadecimal"`
+	To          string   `json:"to" validate:"required,len=24,hexadecimal"`
+	IDs         []string `json:"ids" validate:"required,min=1,max=500,dive,required,urlid,max=21"`
+}
+
+// TransferURLsResult represents ids of moved URLs
+type TransferURLsResult struct {
+	To   string   `json:"to"`
+	URLs []string `json:"urls"`
+}
+
+// WorkspaceUsecase represents the Workspace's usecases
+type WorkspaceUsecase interface {
+	Create(ctx context.Context, create CreateWorkspace, claims *auth.Claims) (*Workspace, error)
+	GetByID(ctx context.Context, id string, claims *auth.Claims) (*Workspace, error)
+	List(ctx context.Context, claims *auth.Claims) ([]*Workspace, error)
+	Invite(ctx context.Context, invite InviteMember, claims *auth.Claims) (*Workspace, error)
+	RemoveMember(ctx context.Context, remove RemoveMember, claims *auth.Claims) (*Workspace, error)
+	TransferURLs(ctx context.Context, transfer TransferURLs, claims *auth.Claims) (*TransferURLsResult, error)
+}
+
+// WorkspaceRepository represents the Workspace's repository contract
+type WorkspaceRepository interface {
+	Store(ctx context.Context, w *Workspace) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*Workspace, error)
+	ListByMember(ctx context.Context, userID string) ([]*Workspace, error)
+	Update(ctx context.Context, w *Workspace) error
+}
@@ -9,18 +9,22 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/semka95/shortener/backend/web"
 	"github.com/semka95/shortener/backend/web/auth"
 )
 
 // GoMiddleware represent the data-struct for middleware
 type GoMiddleware struct {
 	logger *zap.Logger
+	policy *auth.Policy
 }
 
-// InitMiddleware initialize the middleware
-func InitMiddleware(logger *zap.Logger) *GoMiddleware {
+// InitMiddleware initialize the middleware, policy grants permissions checked
+// by HasPermission
+func InitMiddleware(logger *zap.Logger, policy *auth.Policy) *GoMiddleware {
 	return &GoMiddleware{
 		logger: logger,
+		policy: policy,
 	}
 }
 
@@ -76,6 +80,24 @@ func (m *GoMiddleware) Logger(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
+// RequestInfo puts request id and client ip into request context, it must be
+// used after the middleware which sets request id
+func (m *GoMiddleware) RequestInfo(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+
+		id := req.Header.Get(echo.HeaderXRequestID)
+		if id == "" {
+			id = c.Response().Header().Get(echo.HeaderXRequestID)
+		}
+
+		info := web.RequestInfo{ID: id, IP: c.RealIP()}
+		c.SetRequest(req.WithContext(web.WithRequestInfo(req.Context(), info)))
+
+		return next(c)
+	}
+}
+
 // HasRole validates that an authenticated user has at least one role from a
 // specified list. This method constructs the actual function that is used.
 func (m *GoMiddleware) HasRole(roles ...string) echo.MiddlewareFunc {
@@ -98,3 +120,26 @@ func (m *GoMiddleware) HasRole(roles ...string) echo.MiddlewareFunc {
 		}
 	}
 }
+
+// HasPermission validates that roles of an authenticated user grant the
+// permission according to auth policy.
+func (m *GoMiddleware) HasPermission(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := c.Get("user").(*jwt.Token)
+			if !ok {
+				return echo.NewHTTPError(http.StatusBadRequest, "JWT token missing or invalid")
+			}
+			claims, ok := token.Claims.(*auth.Claims)
+			if !ok {
+				return echo.NewHTTPError(http.StatusInternalServerError, "can't convert jwt.Claims to auth.Claims")
+			}
+
+			if err := m.policy.Authorize(claims, permission, nil); err != nil {
+				return echo.NewHTTPError(http.StatusForbidden, "you are not authorized for that action").SetInternal(err)
+			}
+
+			return next(c)
+		}
+	}
+}
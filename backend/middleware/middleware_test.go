@@ -2,6 +2,7 @@ package middleware_test
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"encoding/json"
@@ -20,6 +21,7 @@ import (
 	"go.uber.org/zap/zapcore"
 
 	mdlwr "github.com/semka95/shortener/backend/middleware"
+	"github.com/semka95/shortener/backend/web"
 	"github.com/semka95/shortener/backend/web/auth"
 )
 
@@ -28,7 +30,7 @@ func TestCORS(t *testing.T) {
 	req := httptest.NewRequest(echo.GET, "/", nil)
 	res := httptest.NewRecorder()
 	c := e.NewContext(req, res)
-	m := mdlwr.InitMiddleware(nil)
+	m := mdlwr.InitMiddleware(nil, nil)
 
 	h := m.CORS(func(c echo.Context) error {
 		return c.NoContent(http.StatusOK)
@@ -39,6 +41,25 @@ func TestCORS(t *testing.T) {
 	assert.Equal(t, "*", res.Header().Get("Access-Control-Allow-Origin"))
 }
 
+func TestRequestInfo(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(echo.GET, "/", nil)
+	req.Header.Set(echo.HeaderXRequestID, "request-id")
+	req.Header.Set(echo.HeaderXRealIP, "192.0.2.1")
+	res := httptest.NewRecorder()
+	c := e.NewContext(req, res)
+	m := mdlwr.InitMiddleware(nil, nil)
+
+	h := m.RequestInfo(func(c echo.Context) error {
+		info := web.RequestInfoFromContext(c.Request().Context())
+		assert.Equal(t, web.RequestInfo{ID: "request-id", IP: "192.0.2.1"}, info)
+		return c.NoContent(http.StatusOK)
+	})
+
+	err := h(c)
+	require.NoError(t, err)
+}
+
 type loggerJSON struct {
 	Level   string `json:"L"`
 	Message string `json:"M"`
@@ -61,7 +82,7 @@ func TestLogger(t *testing.T) {
 		}
 	}()
 
-	m := mdlwr.InitMiddleware(logger)
+	m := mdlwr.InitMiddleware(logger, nil)
 
 	cases := []struct {
 		Description string
@@ -194,6 +215,30 @@ func TestJWT(t *testing.T) {
 			assert.Equal(t, test.Message, he.Message)
 		})
 	}
+
+	t.Run("auth token revoked", func(t *testing.T) {
+		authenticator.SetRevocationCheck(func(ctx context.Context, c *auth.Claims) error {
+			assert.Equal(t, claims.ID, c.ID)
+			return errors.New("revoked")
+		})
+		defer authenticator.SetRevocationCheck(nil)
+
+		e := echo.New()
+		req := httptest.NewRequest(echo.GET, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		res := httptest.NewRecorder()
+		c := e.NewContext(req, res)
+		m := echojwt.WithConfig(authenticator.JWTConfig)
+
+		h := m(func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+
+		err = h(c)
+		var he *echo.HTTPError
+		require.ErrorAs(t, err, &he)
+		assert.Equal(t, http.StatusUnauthorized, he.Code)
+	})
 }
 
 func TestHasRole(t *testing.T) {
@@ -207,7 +252,7 @@ func TestHasRole(t *testing.T) {
 		c := e.NewContext(req, res)
 		c.Set("user", token)
 
-		m := mdlwr.InitMiddleware(nil).HasRole(auth.RoleUser)
+		m := mdlwr.InitMiddleware(nil, nil).HasRole(auth.RoleUser)
 		h := m(func(c echo.Context) error {
 			return c.NoContent(http.StatusOK)
 		})
@@ -223,7 +268,7 @@ func TestHasRole(t *testing.T) {
 		c := e.NewContext(req, res)
 		c.Set("user", token)
 
-		m := mdlwr.InitMiddleware(nil).HasRole(auth.RoleAdmin)
+		m := mdlwr.InitMiddleware(nil, nil).HasRole(auth.RoleAdmin)
 		h := m(func(c echo.Context) error {
 			return c.NoContent(http.StatusOK)
 		})
@@ -236,3 +281,40 @@ func TestHasRole(t *testing.T) {
 		assert.EqualValues(t, herr.Message, "you are not authorized for that action")
 	})
 }
+
+func TestHasPermission(t *testing.T) {
+	cases := []struct {
+		Description string
+		Role        string
+		Permission  string
+		Code        int
+	}{
+		{"admin moderates users", auth.RoleAdmin, auth.PermUserModerate, http.StatusOK},
+		{"support reads users", auth.RoleSupport, auth.PermUserReadAny, http.StatusOK},
+		{"support can't moderate users", auth.RoleSupport, auth.PermUserModerate, http.StatusForbidden},
+		{"user can't read users", auth.RoleUser, auth.PermUserReadAny, http.StatusForbidden},
+	}
+
+	for _, test := range cases {
+		t.Run(test.Description, func(t *testing.T) {
+			claims := auth.NewClaims("test user", []string{test.Role}, time.Now(), time.Minute)
+			req := httptest.NewRequest(echo.GET, "/", nil)
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+			c.Set("user", jwt.NewWithClaims(jwt.SigningMethodHS256, claims))
+
+			m := mdlwr.InitMiddleware(nil, auth.DefaultPolicy()).HasPermission(test.Permission)
+			h := m(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			err := h(c)
+			if test.Code == http.StatusOK {
+				require.NoError(t, err)
+				return
+			}
+			var herr *echo.HTTPError
+			require.True(t, errors.As(err, &herr))
+			assert.Equal(t, test.Code, herr.Code)
+		})
+	}
+}
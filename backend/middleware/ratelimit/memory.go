@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// maxMemoryBuckets is the number of buckets after which full buckets are swept
+const maxMemoryBuckets = 100000
+
+type bucket struct {
+	tokens  float64
+	rate    float64
+	burst   float64
+	updated time.Time
+}
+
+// refill adds tokens for time elapsed since last update
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+		b.updated = now
+	}
+}
+
+type memoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// NewMemoryStore creates Store which keeps buckets in memory, every instance
+// of service limits requests separately
+func NewMemoryStore() Store {
+	return &memoryStore{
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (s *memoryStore) Take(_ context.Context, key string, rate float64, burst int, now time.Time) (float64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		if len(s.buckets) >= maxMemoryBuckets {
+			s.sweep(now)
+		}
+		b = &bucket{tokens: float64(burst), updated: now}
+		s.buckets[key] = b
+	}
+	b.rate, b.burst = rate, float64(burst)
+	b.refill(now)
+
+	if b.tokens < 1 {
+		return b.tokens, false, nil
+	}
+	b.tokens--
+
+	return b.tokens, true, nil
+}
+
+func (s *memoryStore) Refund(_ context.Context, key string, rate float64, burst int, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// missing bucket is full
+	b, ok := s.buckets[key]
+	if !ok {
+		return nil
+	}
+	b.rate, b.burst = rate, float64(burst)
+	b.refill(now)
+	b.tokens = math.Min(b.burst, b.tokens+1)
+
+	return nil
+}
+
+// sweep removes full buckets, they are the same as missing ones
+func (s *memoryStore) sweep(now time.Time) {
+	for k, b := range s.buckets {
+		b.refill(now)
+		if b.tokens >= b.burst {
+			delete(s.buckets, k)
+		}
+	}
+}
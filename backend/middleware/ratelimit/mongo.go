@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const rateLimitCollection = "rate_limit"
+
+type mongoStore struct {
+	Conn   *mongo.Database
+	tracer trace.Tracer
+}
+
+// NewMongoStore creates Store which keeps buckets in MongoDB, so limits are
+// shared by all instances of service. Buckets are updated atomically with
+// aggregation pipeline, so MongoDB 4.2 or newer is required.
+func NewMongoStore(c *mongo.Client, db string, tracer trace.Tracer) Store {
+	return &mongoStore{
+		Conn:   c.Database(db),
+		tracer: tracer,
+	}
+}
+
+func (s *mongoStore) Take(ctx context.Context, key string, rate float64, burst int, now time.Time) (float64, bool, error) {
+	ctx, span := s.tracer.Start(
+		ctx,
+		"ratelimit Take",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("key", key)),
+	)
+	defer span.End()
+
+	updatedAt, refilled := refill(rate, burst, now)
+
+	// bucket becomes full and can be removed after it is refilled from empty
+	expiresAt := now.Add(time.Duration(float64(burst) / rate * float64(time.Second)))
+
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{
+			{Key: "tokens", Value: refilled},
+			{Key: "updated_at", Value: bson.D{{Key: "$max", Value: bson.A{now, updatedAt}}}},
+		}}},
+		{{Key: "$set", Value: bson.D{
+			{Key: "allowed", Value: bson.D{{Key: "$gte", Value: bson.A{"$tokens", 1}}}},
+		}}},
+		{{Key: "$set", Value: bson.D{
+			{Key: "tokens", Value: bson.D{{Key: "$cond", Value: bson.A{"$allowed", bson.D{{Key: "$subtract", Value: bson.A{"$tokens", 1}}}, "$tokens"}}}},
+			{Key: "expires_at", Value: expiresAt},
+		}}},
+	}
+
+	filter := bson.D{primitive.E{Key: "_id", Value: key}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	b := struct {
+		Tokens  float64 `bson:"tokens"`
+		Allowed bool    `bson:"allowed"`
+	}{}
+	err := s.Conn.Collection(rateLimitCollection).FindOneAndUpdate(ctx, filter, update, opts).Decode(&b)
+	if err != nil {
+		span.RecordError(err)
+		return 0, false, fmt.Errorf("rate limit bucket update error: %w", err)
+	}
+
+	return b.Tokens, b.Allowed, nil
+}
+
+func (s *mongoStore) Refund(ctx context.Context, key string, rate float64, burst int, now time.Time) error {
+	ctx, span := s.tracer.Start(
+		ctx,
+		"ratelimit Refund",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("key", key)),
+	)
+	defer span.End()
+
+	updatedAt, refilled := refill(rate, burst, now)
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{
+			{Key: "tokens", Value: bson.D{{Key: "$min", Value: bson.A{burst, bson.D{{Key: "$add", Value: bson.A{refilled, 1}}}}}}},
+			{Key: "updated_at", Value: bson.D{{Key: "$max", Value: bson.A{now, updatedAt}}}},
+		}}},
+	}
+
+	// missing bucket is full, so it isn't created
+	filter := bson.D{primitive.E{Key: "_id", Value: key}}
+	if _, err := s.Conn.Collection(rateLimitCollection).UpdateOne(ctx, filter, update); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("rate limit bucket refund error: %w", err)
+	}
+
+	return nil
+}
+
+// refill returns aggregation expressions of last update time of bucket and
+// number of its tokens refilled by time elapsed since then
+func refill(rate float64, burst int, now time.Time) (bson.D, bson.D) {
+	updatedAt := bson.D{{Key: "$ifNull", Value: bson.A{"$updated_at", now}}}
+	elapsed := bson.D{{Key: "$divide", Value: bson.A{bson.D{{Key: "$subtract", Value: bson.A{now, updatedAt}}}, 1000}}}
+	refilled := bson.D{{Key: "$min", Value: bson.A{
+		burst,
+		bson.D{{Key: "$add", Value: bson.A{
+			bson.D{{Key: "$ifNull", Value: bson.A{"$tokens", burst}}},
+			bson.D{{Key: "$multiply", Value: bson.A{bson.D{{Key: "$max", Value: bson.A{elapsed, 0}}}, rate}}},
+		}}},
+	}}}
+
+	return updatedAt, refilled
+}
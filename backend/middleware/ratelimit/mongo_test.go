@@ -0,0 +1,103 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/semka95/shortener/backend/middleware/ratelimit"
+)
+
+var tracer = sdktrace.NewTracerProvider().Tracer("")
+
+func TestMongoStore_Take(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	now := time.Now()
+
+	mt.Run("allowed", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{
+			{Key: "ok", Value: 1},
+			{Key: "value", Value: bson.D{
+				{Key: "_id", Value: "POST /v1/url/create ip:192.0.2.1"},
+				{Key: "tokens", Value: 4.5},
+				{Key: "allowed", Value: true},
+			}},
+		})
+		s := ratelimit.NewMongoStore(mt.Client, mt.DB.Name(), tracer)
+
+		tokens, allowed, err := s.Take(context.Background(), "POST /v1/url/create ip:192.0.2.1", 1, 10, now)
+
+		require.NoError(mt, err)
+		assert.True(mt, allowed)
+		assert.Equal(mt, 4.5, tokens)
+	})
+
+	mt.Run("denied", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{
+			{Key: "ok", Value: 1},
+			{Key: "value", Value: bson.D{
+				{Key: "_id", Value: "POST /v1/url/create ip:192.0.2.1"},
+				{Key: "tokens", Value: 0.25},
+				{Key: "allowed", Value: false},
+			}},
+		})
+		s := ratelimit.NewMongoStore(mt.Client, mt.DB.Name(), tracer)
+
+		tokens, allowed, err := s.Take(context.Background(), "POST /v1/url/create ip:192.0.2.1", 1, 10, now)
+
+		require.NoError(mt, err)
+		assert.False(mt, allowed)
+		assert.Equal(mt, 0.25, tokens)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		s := ratelimit.NewMongoStore(mt.Client, mt.DB.Name(), tracer)
+
+		_, _, err := s.Take(context.Background(), "POST /v1/url/create ip:192.0.2.1", 1, 10, now)
+
+		assert.Error(mt, err)
+	})
+}
+
+func TestMongoStore_Refund(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	now := time.Now()
+	key := "unlock:DXB6V"
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+		s := ratelimit.NewMongoStore(mt.Client, mt.DB.Name(), tracer)
+
+		err := s.Refund(context.Background(), key, 0.5, 5, now)
+
+		require.NoError(mt, err)
+		updates, ok := mt.GetStartedEvent().Command.Lookup("updates").ArrayOK()
+		require.True(mt, ok)
+		upsert, ok := updates.Index(0).Value().Document().Lookup("upsert").BooleanOK()
+		assert.False(mt, ok && upsert)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		s := ratelimit.NewMongoStore(mt.Client, mt.DB.Name(), tracer)
+
+		err := s.Refund(context.Background(), key, 0.5, 5, now)
+
+		assert.Error(mt, err)
+	})
+}
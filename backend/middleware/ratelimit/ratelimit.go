@@ -0,0 +1,195 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+)
+
+const (
+	// KeyIP limits requests per client IP
+	KeyIP = "ip"
+	// KeyUser limits requests per authenticated user, anonymous requests are limited per IP
+	KeyUser = "user"
+	// KeyAPIKey limits requests per API key, requests without key are limited per IP
+	KeyAPIKey = "apikey"
+)
+
+// apiKeyScheme is authorization scheme of API key requests
+const apiKeyScheme = "ApiKey "
+
+// Policy represents token bucket limit of a route: Burst requests can be made
+// at once, then Requests tokens are refilled per Period seconds
+type Policy struct {
+	Method   string `yaml:"method"`
+	Path     string `yaml:"path"`
+	Requests int    `yaml:"requests"`
+	Period   int    `yaml:"period"`
+	Burst    int    `yaml:"burst"`
+	Key      string `yaml:"key"`
+}
+
+// rate returns number of tokens refilled per second
+func (p Policy) rate() float64 {
+	return float64(p.Requests) / float64(p.Period)
+}
+
+func (p Policy) validate() error {
+	if p.Method == "" || p.Path == "" {
+		return fmt.Errorf("rate limit policy must have method and path")
+	}
+	if p.Requests < 1 || p.Period < 1 || p.Burst < 1 {
+		return fmt.Errorf("rate limit policy %s %s must have positive requests, period and burst", p.Method, p.Path)
+	}
+	switch p.Key {
+	case KeyIP, KeyUser, KeyAPIKey:
+		return nil
+	default:
+		return fmt.Errorf("rate limit policy %s %s has unknown key %q", p.Method, p.Path, p.Key)
+	}
+}
+
+// Store keeps token buckets. Take refills bucket by elapsed time, takes one
+// token if there is any and returns number of tokens left. Refund gives back
+// token taken by Take, bucket is never filled over burst.
+type Store interface {
+	Take(ctx context.Context, key string, rate float64, burst int, now time.Time) (tokens float64, allowed bool, err error)
+	Refund(ctx context.Context, key string, rate float64, burst int, now time.Time) error
+}
+
+// SubjectFunc returns id of authenticated user of request or empty string
+type SubjectFunc func(c echo.Context) string
+
+// Limiter limits request rate of routes which have policy
+type Limiter struct {
+	store    Store
+	policies map[string]Policy
+	subject  SubjectFunc
+	logger   *zap.Logger
+}
+
+// NewLimiter creates Limiter, subject is used by policies keyed by user
+func NewLimiter(store Store, policies []Policy, subject SubjectFunc, logger *zap.Logger) (*Limiter, error) {
+	l := &Limiter{
+		store:    store,
+		policies: make(map[string]Policy, len(policies)),
+		subject:  subject,
+		logger:   logger,
+	}
+
+	for _, p := range policies {
+		if err := p.validate(); err != nil {
+			return nil, err
+		}
+		p.Method = strings.ToUpper(p.Method)
+		p.Path = normalizePath(p.Path)
+		l.policies[p.Method+" "+p.Path] = p
+	}
+
+	return l, nil
+}
+
+// Limit is a middleware that rejects requests over policy limit with 429. It
+// must be registered with Echo.Use, so route path is known.
+func (l *Limiter) Limit(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		p, ok := l.policies[c.Request().Method+" "+normalizePath(c.Path())]
+		if !ok {
+			return next(c)
+		}
+
+		key := p.Method + " " + p.Path + " " + l.clientKey(c, p)
+		tokens, allowed, err := l.store.Take(c.Request().Context(), key, p.rate(), p.Burst, time.Now())
+		if err != nil {
+			// limiter must not take service down, so requests are let through
+			l.logger.Error("can't take rate limit token", zap.String("key", key), zap.Error(err))
+			return next(c)
+		}
+
+		h := c.Response().Header()
+		h.Set("X-RateLimit-Limit", strconv.Itoa(p.Burst))
+		h.Set("X-RateLimit-Remaining", strconv.Itoa(int(math.Floor(tokens))))
+		h.Set("X-RateLimit-Reset", strconv.Itoa(seconds((float64(p.Burst)-tokens)/p.rate())))
+
+		if !allowed {
+			h.Set("Retry-After", strconv.Itoa(seconds((1-tokens)/p.rate())))
+			return c.JSON(http.StatusTooManyRequests, domain.ResponseError{Error: domain.ErrTooManyRequests.Error()})
+		}
+
+		return next(c)
+	}
+}
+
+// clientKey identifies client by policy key, clients which can't be
+// identified by user or API key are identified by IP
+func (l *Limiter) clientKey(c echo.Context, p Policy) string {
+	switch p.Key {
+	case KeyUser:
+		if l.subject != nil {
+			if sub := l.subject(c); sub != "" {
+				return "user:" + sub
+			}
+		}
+	case KeyAPIKey:
+		header := c.Request().Header.Get(echo.HeaderAuthorization)
+		if key := strings.TrimPrefix(header, apiKeyScheme); key != header && key != "" {
+			// API key is a secret, so only its hash is kept in store
+			sum := sha256.Sum256([]byte(key))
+			return "apikey:" + hex.EncodeToString(sum[:])
+		}
+	}
+
+	return "ip:" + c.RealIP()
+}
+
+// IPExtractor returns extractor of client IP, which must be set to Echo for
+// limits by IP to hold. X-Forwarded-For is trusted only if the request comes
+// from trustedProxies (CIDR ranges), otherwise peer address is used, so
+// clients can't get a new bucket by sending forged header.
+func IPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, cidr := range trustedProxies {
+		_, ipRange, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy range %q: %w", cidr, err)
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+func normalizePath(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		return "/" + path
+	}
+	return path
+}
+
+// seconds rounds duration in seconds up, so clients don't retry too early
+func seconds(s float64) int {
+	if s <= 0 {
+		return 0
+	}
+	return int(math.Ceil(s))
+}
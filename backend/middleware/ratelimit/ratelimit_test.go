@@ -0,0 +1,255 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/middleware/ratelimit"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, float64, int, time.Time) (float64, bool, error) {
+	return 0, false, errors.New("store is down")
+}
+
+func (failingStore) Refund(context.Context, string, float64, int, time.Time) error {
+	return errors.New("store is down")
+}
+
+func newTestServer(t *testing.T, store ratelimit.Store, policies []ratelimit.Policy, trustedProxies ...string) *echo.Echo {
+	subject := func(c echo.Context) string {
+		return c.Request().Header.Get("X-Test-User")
+	}
+	limiter, err := ratelimit.NewLimiter(store, policies, subject, zap.NewNop())
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.IPExtractor, err = ratelimit.IPExtractor(trustedProxies)
+	require.NoError(t, err)
+	e.Use(limiter.Limit)
+	ok := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+	e.POST("/v1/url/create", ok)
+	e.GET("v1/user/token", ok)
+	e.GET("/:id", ok)
+	e.GET("/v1/status", ok)
+
+	return e
+}
+
+func doRequest(e *echo.Echo, method, path string, headers map[string]string) *httptest.ResponseRecorder {
+	return doRequestFrom(e, "192.0.2.1:1234", method, path, headers)
+}
+
+func doRequestFrom(e *echo.Echo, remoteAddr, method, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = remoteAddr
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLimiter(t *testing.T) {
+	policies := []ratelimit.Policy{
+		{Method: "post", Path: "/v1/url/create", Requests: 1, Period: 60, Burst: 2, Key: ratelimit.KeyIP},
+		{Method: "GET", Path: "/v1/user/token", Requests: 1, Period: 60, Burst: 1, Key: ratelimit.KeyUser},
+		{Method: "GET", Path: "/:id", Requests: 1, Period: 60, Burst: 1, Key: ratelimit.KeyAPIKey},
+	}
+
+	t.Run("limit by ip", func(t *testing.T) {
+		e := newTestServer(t, ratelimit.NewMemoryStore(), policies)
+
+		rec := doRequest(e, http.MethodPost, "/v1/url/create", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, "1", rec.Header().Get("X-RateLimit-Remaining"))
+		assert.Equal(t, "60", rec.Header().Get("X-RateLimit-Reset"))
+
+		rec = doRequest(e, http.MethodPost, "/v1/url/create", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "0", rec.Header().Get("X-RateLimit-Remaining"))
+
+		rec = doRequest(e, http.MethodPost, "/v1/url/create", nil)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+		assert.Equal(t, "0", rec.Header().Get("X-RateLimit-Remaining"))
+		assert.Contains(t, rec.Body.String(), "too many requests")
+
+		rec = doRequestFrom(e, "198.51.100.1:1234", http.MethodPost, "/v1/url/create", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("forged forwarded headers don't get new bucket", func(t *testing.T) {
+		e := newTestServer(t, ratelimit.NewMemoryStore(), policies)
+
+		for i, ip := range []string{"198.51.100.1", "198.51.100.2", "198.51.100.3"} {
+			rec := doRequest(e, http.MethodPost, "/v1/url/create", map[string]string{
+				echo.HeaderXForwardedFor: ip,
+				echo.HeaderXRealIP:       ip,
+			})
+			if i < 2 {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				continue
+			}
+			assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		}
+	})
+
+	t.Run("forwarded header of trusted proxy", func(t *testing.T) {
+		e := newTestServer(t, ratelimit.NewMemoryStore(), policies, "192.0.2.0/24")
+		xff := map[string]string{echo.HeaderXForwardedFor: "198.51.100.1"}
+
+		assert.Equal(t, http.StatusOK, doRequest(e, http.MethodPost, "/v1/url/create", xff).Code)
+		assert.Equal(t, http.StatusOK, doRequest(e, http.MethodPost, "/v1/url/create", xff).Code)
+		assert.Equal(t, http.StatusTooManyRequests, doRequest(e, http.MethodPost, "/v1/url/create", xff).Code)
+
+		// another client behind the same proxy has its own bucket
+		rec := doRequest(e, http.MethodPost, "/v1/url/create", map[string]string{echo.HeaderXForwardedFor: "198.51.100.2"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("limit by user", func(t *testing.T) {
+		e := newTestServer(t, ratelimit.NewMemoryStore(), policies)
+
+		rec := doRequest(e, http.MethodGet, "/v1/user/token", map[string]string{"X-Test-User": "first"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		rec = doRequest(e, http.MethodGet, "/v1/user/token", map[string]string{"X-Test-User": "first"})
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+		rec = doRequest(e, http.MethodGet, "/v1/user/token", map[string]string{"X-Test-User": "second"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		// anonymous requests are limited by ip
+		rec = doRequest(e, http.MethodGet, "/v1/user/token", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		rec = doRequest(e, http.MethodGet, "/v1/user/token", nil)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	})
+
+	t.Run("limit by api key", func(t *testing.T) {
+		e := newTestServer(t, ratelimit.NewMemoryStore(), policies)
+
+		rec := doRequest(e, http.MethodGet, "/test123", map[string]string{echo.HeaderAuthorization: "ApiKey first"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		rec = doRequest(e, http.MethodGet, "/test123", map[string]string{echo.HeaderAuthorization: "ApiKey first"})
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+		rec = doRequest(e, http.MethodGet, "/test123", map[string]string{echo.HeaderAuthorization: "ApiKey second"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("route without policy", func(t *testing.T) {
+		e := newTestServer(t, ratelimit.NewMemoryStore(), policies)
+
+		for i := 0; i < 3; i++ {
+			rec := doRequest(e, http.MethodGet, "/v1/status", nil)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Empty(t, rec.Header().Get("X-RateLimit-Limit"))
+		}
+	})
+
+	t.Run("store failure lets requests through", func(t *testing.T) {
+		e := newTestServer(t, failingStore{}, policies)
+
+		for i := 0; i < 3; i++ {
+			rec := doRequest(e, http.MethodPost, "/v1/url/create", nil)
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+}
+
+func TestIPExtractor_InvalidRange(t *testing.T) {
+	_, err := ratelimit.IPExtractor([]string{"10.0.0.1"})
+	assert.Error(t, err)
+}
+
+func TestNewLimiter_InvalidPolicy(t *testing.T) {
+	cases := []struct {
+		description string
+		policy      ratelimit.Policy
+	}{
+		{"missing path", ratelimit.Policy{Method: "GET", Requests: 1, Period: 1, Burst: 1, Key: ratelimit.KeyIP}},
+		{"zero period", ratelimit.Policy{Method: "GET", Path: "/", Requests: 1, Burst: 1, Key: ratelimit.KeyIP}},
+		{"zero burst", ratelimit.Policy{Method: "GET", Path: "/", Requests: 1, Period: 1, Key: ratelimit.KeyIP}},
+		{"unknown key", ratelimit.Policy{Method: "GET", Path: "/", Requests: 1, Period: 1, Burst: 1, Key: "cookie"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			_, err := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), []ratelimit.Policy{tc.policy}, nil, zap.NewNop())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMemoryStore_Take(t *testing.T) {
+	s := ratelimit.NewMemoryStore()
+	now := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tokens, allowed, err := s.Take(context.Background(), "key", 1, 2, now)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, 1.0, tokens)
+
+	_, allowed, err = s.Take(context.Background(), "key", 1, 2, now)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	tokens, allowed, err = s.Take(context.Background(), "key", 1, 2, now.Add(500*time.Millisecond))
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 0.5, tokens)
+
+	tokens, allowed, err = s.Take(context.Background(), "key", 1, 2, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, 0.0, tokens)
+
+	// bucket is never refilled over burst
+	tokens, allowed, err = s.Take(context.Background(), "key", 1, 2, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, 1.0, tokens)
+}
+
+func TestMemoryStore_Refund(t *testing.T) {
+	s := ratelimit.NewMemoryStore()
+	now := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// refund of missing bucket keeps it full
+	require.NoError(t, s.Refund(context.Background(), "key", 1, 2, now))
+	tokens, allowed, err := s.Take(context.Background(), "key", 1, 2, now)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, 1.0, tokens)
+
+	_, _, err = s.Take(context.Background(), "key", 1, 2, now)
+	require.NoError(t, err)
+	require.NoError(t, s.Refund(context.Background(), "key", 1, 2, now))
+
+	tokens, allowed, err = s.Take(context.Background(), "key", 1, 2, now)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, 0.0, tokens)
+
+	// bucket is never refunded over burst
+	require.NoError(t, s.Refund(context.Background(), "key", 1, 2, now.Add(time.Hour)))
+	tokens, allowed, err = s.Take(context.Background(), "key", 1, 2, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, 1.0, tokens)
+}
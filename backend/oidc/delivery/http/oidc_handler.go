@@ -0,0 +1,183 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v4"
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/web"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+// stateCookie binds login to browser which started it, so user can't be
+// signed in to account of somebody who sent them callback link
+const stateCookie = "oidc_state"
+
+// tokenResponse represents issued access and refresh tokens
+type tokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// linkResponse represents URL of identity provider user links identity at
+type linkResponse struct {
+	URL string `json:"url"`
+}
+
+// OIDCHandler represent the http handler for login with identity provider
+type OIDCHandler struct {
+	oidcUsecase   domain.OIDCUsecase
+	tokenUsecase  domain.TokenUsecase
+	authenticator *auth.Authenticator
+	validator     *web.AppValidator
+	logger        *zap.Logger
+	tracer        trace.Tracer
+}
+
+// NewOIDCHandler will initialize the user/oidc/ resources endpoint
+func NewOIDCHandler(ou domain.OIDCUsecase, ts domain.TokenUsecase, authenticator *auth.Authenticator, v *web.AppValidator, logger *zap.Logger, tracer trace.Tracer) *OIDCHandler {
+	return &OIDCHandler{
+		oidcUsecase:   ou,
+		tokenUsecase:  ts,
+		authenticator: authenticator,
+		validator:     v,
+		logger:        logger,
+		tracer:        tracer,
+	}
+}
+
+// RegisterRoutes registers routes for a path with matching handler
+func (oh *OIDCHandler) RegisterRoutes(e *echo.Echo) {
+	e.GET("/v1/user/oidc/login", oh.Login)
+	e.GET("/v1/user/oidc/callback", oh.Callback)
+	e.POST("/v1/user/oidc/link", oh.Link, echojwt.WithConfig(oh.authenticator.JWTConfig))
+}
+
+// Login will redirect user to identity provider
+func (oh *OIDCHandler) Login(c echo.Context) error {
+	ctx, span := oh.start(c, "http Login")
+	defer span.End()
+
+	login, url, err := oh.oidcUsecase.Login(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, oh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	setStateCookie(c, login)
+
+	return c.Redirect(http.StatusFound, url)
+}
+
+// Link will return URL of identity provider, identity user signs in with
+// there is linked to their account on callback. Users whose accounts have
+// password link identity this way, as it isn't linked by email.
+func (oh *OIDCHandler) Link(c echo.Context) error {
+	ctx, span := oh.start(c, "http Link")
+	defer span.End()
+
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok || token == nil {
+		span.RecordError(domain.ErrForbidden)
+		return c.JSON(http.StatusForbidden, domain.ResponseError{Error: domain.ErrForbidden.Error()})
+	}
+	claims, ok := token.Claims.(*auth.Claims)
+	if !ok {
+		span.RecordError(domain.ErrInternalServerError)
+		return fmt.Errorf("%w can't convert jwt.Claims to auth.Claims", domain.ErrInternalServerError)
+	}
+
+	login, url, err := oh.oidcUsecase.Link(ctx, claims)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, oh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	setStateCookie(c, login)
+
+	return c.JSON(http.StatusOK, linkResponse{URL: url})
+}
+
+// Callback will return jwt token of user signed in at identity provider
+func (oh *OIDCHandler) Callback(c echo.Context) error {
+	ctx, span := oh.start(c, "http Callback")
+	defer span.End()
+
+	r := new(domain.OIDCCallback)
+	if err := c.Bind(r); err != nil {
+		span.RecordError(err)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
+	}
+
+	if err := c.Validate(r); err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(oh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	cookie, err := c.Cookie(stateCookie)
+	if err != nil || cookie.Value != r.State {
+		span.RecordError(domain.ErrAuthenticationFailure)
+		return c.JSON(http.StatusUnauthorized, domain.ResponseError{Error: "login was started in another browser"})
+	}
+	c.SetCookie(&http.Cookie{Name: stateCookie, Path: "/", MaxAge: -1, HttpOnly: true})
+
+	claims, err := oh.oidcUsecase.Callback(ctx, time.Now(), *r)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, oh.logger), domain.ResponseError{Error: err.Error()})
+	}
+	span.SetAttributes(
+		attribute.String("userid", claims.Subject),
+	)
+
+	tkn := new(tokenResponse)
+	tkn.Token, err = oh.authenticator.GenerateToken(claims)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, oh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	tkn.RefreshToken, err = oh.tokenUsecase.Issue(ctx, claims)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, oh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, tkn)
+}
+
+// setStateCookie binds login to browser
+func setStateCookie(c echo.Context, login *domain.OIDCLogin) {
+	c.SetCookie(&http.Cookie{
+		Name:     stateCookie,
+		Value:    login.State,
+		Path:     "/",
+		Expires:  login.ExpiresAt,
+		Secure:   c.IsTLS(),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (oh *OIDCHandler) start(c echo.Context, name string) (context.Context, trace.Span) {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return oh.tracer.Start(
+		ctx,
+		name,
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+}
@@ -0,0 +1,155 @@
+package http_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+	oidcHttp "github.com/semka95/shortener/backend/oidc/delivery/http"
+	"github.com/semka95/shortener/backend/oidc/mock"
+	"github.com/semka95/shortener/backend/tests"
+	tokenMock "github.com/semka95/shortener/backend/token/mock"
+	"github.com/semka95/shortener/backend/web"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+func TestOIDCHTTP(t *testing.T) {
+	tUser := tests.NewUser()
+	claims := auth.NewClaims(tUser.ID.Hex(), tUser.Roles, time.Now(), time.Hour)
+	login := &domain.OIDCLogin{State: "af0ifjsldkj", ExpiresAt: time.Now().Add(10 * time.Minute)}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	kid := "4754d86b-7a6d-4df5-9c65-224741361492"
+	kf := auth.NewSimpleKeyLookupFunc(kid, key.Public().(*rsa.PublicKey))
+	authenticator, err := auth.NewAuthenticator(key, kid, "RS256", kf)
+	require.NoError(t, err)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	uc := mock.NewMockOIDCUsecase(controller)
+	tu := tokenMock.NewMockTokenUsecase(controller)
+
+	tracer := sdktrace.NewTracerProvider().Tracer("")
+	v, err := web.NewAppValidator()
+	require.NoError(t, err)
+
+	handler := oidcHttp.NewOIDCHandler(uc, tu, authenticator, v, zap.NewNop(), tracer)
+
+	e := echo.New()
+	e.Validator = v
+	req := new(http.Request)
+	c := e.NewContext(req, nil)
+
+	// Test OIDCHandler.Login
+	t.Run("Login redirects to provider", func(t *testing.T) {
+		uc.EXPECT().Login(gomock.Any()).Return(login, "https://idp.example.com/authorize?state="+login.State, nil)
+		req = httptest.NewRequest(echo.GET, "/v1/user/oidc/login", nil)
+
+		rec := httptest.NewRecorder()
+		c.Reset(req, rec)
+
+		err = handler.Login(c)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusFound, rec.Code)
+		assert.Equal(t, "https://idp.example.com/authorize?state="+login.State, rec.Header().Get(echo.HeaderLocation))
+		cookies := rec.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, login.State, cookies[0].Value)
+		assert.True(t, cookies[0].HttpOnly)
+	})
+
+	// Test OIDCHandler.Link
+	t.Run("Link returns provider URL", func(t *testing.T) {
+		uc.EXPECT().Link(gomock.Any(), claims).Return(login, "https://idp.example.com/authorize?state="+login.State, nil)
+		req = httptest.NewRequest(echo.POST, "/v1/user/oidc/link", nil)
+
+		rec := httptest.NewRecorder()
+		c.Reset(req, rec)
+		c.Set("user", &jwt.Token{Claims: claims})
+
+		err = handler.Link(c)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"url":"https://idp.example.com/authorize?state=`+login.State+`"}`, rec.Body.String())
+		cookies := rec.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, login.State, cookies[0].Value)
+	})
+
+	// Test OIDCHandler.Callback
+	cases := []struct {
+		description string
+		mockCalls   func()
+		query       string
+		cookie      string
+		code        int
+	}{
+		{
+			description: "Callback success",
+			mockCalls: func() {
+				callback := domain.OIDCCallback{State: login.State, Code: "code"}
+				uc.EXPECT().Callback(gomock.Any(), gomock.Any(), callback).Return(claims, nil)
+				tu.EXPECT().Issue(gomock.Any(), claims).Return("refresh", nil)
+			},
+			query:  "?state=" + login.State + "&code=code",
+			cookie: login.State,
+			code:   http.StatusOK,
+		},
+		{
+			description: "Callback without state",
+			mockCalls:   func() {},
+			query:       "?code=code",
+			cookie:      login.State,
+			code:        http.StatusBadRequest,
+		},
+		{
+			description: "Callback from another browser",
+			mockCalls:   func() {},
+			query:       "?state=" + login.State + "&code=code",
+			code:        http.StatusUnauthorized,
+		},
+		{
+			description: "Callback of unknown login",
+			mockCalls: func() {
+				uc.EXPECT().Callback(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, domain.ErrAuthenticationFailure)
+			},
+			query:  "?state=" + login.State + "&code=code",
+			cookie: login.State,
+			code:   http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls()
+			req = httptest.NewRequest(echo.GET, "/v1/user/oidc/callback"+tc.query, nil)
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "oidc_state", Value: tc.cookie})
+			}
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+
+			err = handler.Callback(c)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.code, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./domain/oidc.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/semka95/shortener/backend/domain"
+	auth "github.com/semka95/shortener/backend/web/auth"
+)
+
+// MockOIDCProvider is a mock of OIDCProvider interface.
+type MockOIDCProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCProviderMockRecorder
+}
+
+// MockOIDCProviderMockRecorder is the mock recorder for MockOIDCProvider.
+type MockOIDCProviderMockRecorder struct {
+	mock *MockOIDCProvider
+}
+
+// NewMockOIDCProvider creates a new mock instance.
+func NewMockOIDCProvider(ctrl *gomock.Controller) *MockOIDCProvider {
+	mock := &MockOIDCProvider{ctrl: ctrl}
+	mock.recorder = &MockOIDCProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDCProvider) EXPECT() *MockOIDCProviderMockRecorder {
+	return m.recorder
+}
+
+// AuthCodeURL mocks base method.
+func (m *MockOIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, challenge string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthCodeURL", ctx, state, nonce, challenge)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthCodeURL indicates an expected call of AuthCodeURL.
+func (mr *MockOIDCProviderMockRecorder) AuthCodeURL(ctx, state, nonce, challenge interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthCodeURL", reflect.TypeOf((*MockOIDCProvider)(nil).AuthCodeURL), ctx, state, nonce, challenge)
+}
+
+// Exchange mocks base method.
+func (m *MockOIDCProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*domain.IDToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exchange", ctx, code, verifier, nonce)
+	ret0, _ := ret[0].(*domain.IDToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exchange indicates an expected call of Exchange.
+func (mr *MockOIDCProviderMockRecorder) Exchange(ctx, code, verifier, nonce interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exchange", reflect.TypeOf((*MockOIDCProvider)(nil).Exchange), ctx, code, verifier, nonce)
+}
+
+// MockOIDCUsecase is a mock of OIDCUsecase interface.
+type MockOIDCUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCUsecaseMockRecorder
+}
+
+// MockOIDCUsecaseMockRecorder is the mock recorder for MockOIDCUsecase.
+type MockOIDCUsecaseMockRecorder struct {
+	mock *MockOIDCUsecase
+}
+
+// NewMockOIDCUsecase creates a new mock instance.
+func NewMockOIDCUsecase(ctrl *gomock.Controller) *MockOIDCUsecase {
+	mock := &MockOIDCUsecase{ctrl: ctrl}
+	mock.recorder = &MockOIDCUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDCUsecase) EXPECT() *MockOIDCUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Callback mocks base method.
+func (m *MockOIDCUsecase) Callback(ctx context.Context, now time.Time, callback domain.OIDCCallback) (*auth.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Callback", ctx, now, callback)
+	ret0, _ := ret[0].(*auth.Claims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Callback indicates an expected call of Callback.
+func (mr *MockOIDCUsecaseMockRecorder) Callback(ctx, now, callback interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Callback", reflect.TypeOf((*MockOIDCUsecase)(nil).Callback), ctx, now, callback)
+}
+
+// Link mocks base method.
+func (m *MockOIDCUsecase) Link(ctx context.Context, claims *auth.Claims) (*domain.OIDCLogin, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Link", ctx, claims)
+	ret0, _ := ret[0].(*domain.OIDCLogin)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Link indicates an expected call of Link.
+func (mr *MockOIDCUsecaseMockRecorder) Link(ctx, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Link", reflect.TypeOf((*MockOIDCUsecase)(nil).Link), ctx, claims)
+}
+
+// Login mocks base method.
+func (m *MockOIDCUsecase) Login(ctx context.Context) (*domain.OIDCLogin, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", ctx)
+	ret0, _ := ret[0].(*domain.OIDCLogin)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockOIDCUsecaseMockRecorder) Login(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockOIDCUsecase)(nil).Login), ctx)
+}
+
+// MockOIDCLoginRepository is a mock of OIDCLoginRepository interface.
+type MockOIDCLoginRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCLoginRepositoryMockRecorder
+}
+
+// MockOIDCLoginRepositoryMockRecorder is the mock recorder for MockOIDCLoginRepository.
+type MockOIDCLoginRepositoryMockRecorder struct {
+	mock *MockOIDCLoginRepository
+}
+
+// NewMockOIDCLoginRepository creates a new mock instance.
+func NewMockOIDCLoginRepository(ctrl *gomock.Controller) *MockOIDCLoginRepository {
+	mock := &MockOIDCLoginRepository{ctrl: ctrl}
+	mock.recorder = &MockOIDCLoginRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDCLoginRepository) EXPECT() *MockOIDCLoginRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Store mocks base method.
+func (m *MockOIDCLoginRepository) Store(ctx context.Context, login *domain.OIDCLogin) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store.
+func (mr *MockOIDCLoginRepositoryMockRecorder) Store(ctx, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockOIDCLoginRepository)(nil).Store), ctx, login)
+}
+
+// Take mocks base method.
+func (m *MockOIDCLoginRepository) Take(ctx context.Context, state string) (*domain.OIDCLogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Take", ctx, state)
+	ret0, _ := ret[0].(*domain.OIDCLogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Take indicates an expected call of Take.
+func (mr *MockOIDCLoginRepositoryMockRecorder) Take(ctx, state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Take", reflect.TypeOf((*MockOIDCLoginRepository)(nil).Take), ctx, state)
+}
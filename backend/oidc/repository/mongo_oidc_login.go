@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+)
+
+const oidcLoginCollection = "oidc_login"
+
+type mongoOIDCLoginRepository struct {
+	Conn   *mongo.Database
+	logger *zap.Logger
+	tracer trace.Tracer
+}
+
+// NewMongoOIDCLoginRepository will create an object that represent the oidc.LoginRepository interface,
+// logins are removed by TTL index after they expire
+func NewMongoOIDCLoginRepository(c *mongo.Client, db string, logger *zap.Logger, tracer trace.Tracer) domain.OIDCLoginRepository {
+	return &mongoOIDCLoginRepository{
+		Conn:   c.Database(db),
+		logger: logger,
+		tracer: tracer,
+	}
+}
+
+func (m *mongoOIDCLoginRepository) Store(ctx context.Context, login *domain.OIDCLogin) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository Store",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	_, err := m.Conn.Collection(oidcLoginCollection).InsertOne(ctx, login)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("oidc login store error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// Take atomically removes login, so concurrent callbacks can't finish the same login twice
+func (m *mongoOIDCLoginRepository) Take(ctx context.Context, state string) (*domain.OIDCLogin, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository Take",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "_id", Value: state}}
+
+	login := new(domain.OIDCLogin)
+	err := m.Conn.Collection(oidcLoginCollection).FindOneAndDelete(ctx, filter).Decode(login)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.RecordError(domain.ErrNotFound)
+		return nil, fmt.Errorf("oidc login was not found: %w", domain.ErrNotFound)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("oidc login take error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return login, nil
+}
@@ -0,0 +1,79 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/oidc/repository"
+)
+
+var tracer = sdktrace.NewTracerProvider().Tracer("")
+var noopCtx = context.Background()
+
+func newLogin() *domain.OIDCLogin {
+	return &domain.OIDCLogin{
+		State:     "af0ifjsldkj",
+		Nonce:     "n-0S6_WzA2Mj",
+		Verifier:  "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk",
+		ExpiresAt: time.Now().Add(10 * time.Minute).Truncate(time.Millisecond).UTC(),
+	}
+}
+
+func TestMongoOIDCLoginRepository_Store(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		r := repository.NewMongoOIDCLoginRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Store(noopCtx, newLogin())
+
+		require.NoError(mt, err)
+	})
+}
+
+func TestMongoOIDCLoginRepository_Take(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tLogin := newLogin()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{
+			{Key: "ok", Value: 1},
+			{Key: "value", Value: bson.D{
+				{Key: "_id", Value: tLogin.State},
+				{Key: "nonce", Value: tLogin.Nonce},
+				{Key: "verifier", Value: tLogin.Verifier},
+				{Key: "expires_at", Value: tLogin.ExpiresAt},
+			}},
+		})
+		r := repository.NewMongoOIDCLoginRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.Take(noopCtx, tLogin.State)
+
+		require.NoError(mt, err)
+		assert.Equal(mt, tLogin, result)
+	})
+
+	mt.Run("already taken", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{
+			{Key: "ok", Value: 1},
+			{Key: "value", Value: nil},
+		})
+		r := repository.NewMongoOIDCLoginRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.Take(noopCtx, tLogin.State)
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrNotFound)
+	})
+}
@@ -0,0 +1,334 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+// maxResponseSize limits responses read from identity provider
+const maxResponseSize = 1 << 20
+
+// keysRefreshInterval is the minimal interval between fetches of provider
+// keys, keys are fetched again when ID token is signed by unknown key
+const keysRefreshInterval = time.Minute
+
+// ProviderConfig represents client registration at OpenID Connect identity
+// provider, RedirectURL must be registered at the provider
+type ProviderConfig struct {
+	Issuer       string   `yaml:"issuer"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url"`
+	Scopes       []string `yaml:"scopes"`
+}
+
+// providerMetadata represents the used part of OpenID Provider Metadata
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// tokenResponse represents response of token endpoint
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// idTokenClaims represents claims of ID token used to sign user in
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	AuthorizedParty string `json:"azp"`
+	Nonce           string `json:"nonce"`
+	Email           string `json:"email"`
+	EmailVerified   bool   `json:"email_verified"`
+	Name            string `json:"name"`
+}
+
+type oidcProvider struct {
+	cfg    ProviderConfig
+	client *http.Client
+	tracer trace.Tracer
+
+	mu          sync.Mutex
+	metadata    *providerMetadata
+	keys        map[string]*rsa.PublicKey
+	keysFetched time.Time
+}
+
+// NewOIDCProvider will create OIDCProvider of issuer, provider metadata is
+// discovered on first use, so the provider doesn't have to be available at
+// start. ID tokens must be signed with RS256.
+func NewOIDCProvider(cfg ProviderConfig, client *http.Client, tracer trace.Tracer) domain.OIDCProvider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	return &oidcProvider{
+		cfg:    cfg,
+		client: client,
+		tracer: tracer,
+	}
+}
+
+// AuthCodeURL returns URL of authorization endpoint which starts authorization
+// code flow, challenge is S256 PKCE code challenge
+func (p *oidcProvider) AuthCodeURL(ctx context.Context, state, nonce, challenge string) (string, error) {
+	ctx, span := p.tracer.Start(
+		ctx,
+		"oidc AuthCodeURL",
+		trace.WithAttributes(
+			attribute.String("issuer", p.cfg.Issuer)),
+	)
+	defer span.End()
+
+	meta, err := p.discover(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return meta.AuthorizationEndpoint + sep + params.Encode(), nil
+}
+
+// Exchange redeems authorization code at token endpoint and verifies
+// returned ID token, which must contain nonce of the login
+func (p *oidcProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*domain.IDToken, error) {
+	ctx, span := p.tracer.Start(
+		ctx,
+		"oidc Exchange",
+		trace.WithAttributes(
+			attribute.String("issuer", p.cfg.Issuer)),
+	)
+	defer span.End()
+
+	meta, err := p.discover(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	if p.cfg.ClientSecret == "" {
+		form.Set("client_id", p.cfg.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("can't create token request: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	tkn := new(tokenResponse)
+	status, err := p.do(req, tkn)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("token request error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+	// invalid_grant means code is wrong, expired or was issued for other verifier
+	if status == http.StatusBadRequest && tkn.Error == "invalid_grant" {
+		err = fmt.Errorf("authorization code was rejected: %w: %s", domain.ErrAuthenticationFailure, tkn.ErrorDescription)
+		span.RecordError(err)
+		return nil, err
+	}
+	if status != http.StatusOK || tkn.IDToken == "" {
+		err = fmt.Errorf("token endpoint responded with status %d, error %q: %w", status, tkn.Error, domain.ErrInternalServerError)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	token, err := p.verify(ctx, meta, tkn.IDToken, nonce)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("ID token is not valid: %w: %s", domain.ErrAuthenticationFailure, err.Error())
+	}
+
+	return token, nil
+}
+
+// verify checks signature and claims of ID token
+func (p *oidcProvider) verify(ctx context.Context, meta *providerMetadata, raw, nonce string) (*domain.IDToken, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256"}))
+
+	claims := new(idTokenClaims)
+	_, err := parser.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, meta, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case claims.Issuer != meta.Issuer:
+		return nil, fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	case !claims.VerifyAudience(p.cfg.ClientID, true):
+		return nil, fmt.Errorf("token is not issued for client %q", p.cfg.ClientID)
+	case len(claims.Audience) > 1 && claims.AuthorizedParty != p.cfg.ClientID:
+		return nil, fmt.Errorf("unexpected authorized party %q", claims.AuthorizedParty)
+	case claims.ExpiresAt == nil:
+		return nil, errors.New("token has no expiration time")
+	case claims.Subject == "":
+		return nil, errors.New("token has no subject")
+	case claims.Nonce != nonce:
+		return nil, errors.New("nonce doesn't match login")
+	}
+
+	return &domain.IDToken{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
+
+// discover returns provider metadata, it's fetched until the first success
+func (p *oidcProvider) discover(ctx context.Context) (*providerMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.cfg.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, fmt.Errorf("can't create discovery request: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	meta := new(providerMetadata)
+	status, err := p.do(req, meta)
+	if err != nil {
+		return nil, fmt.Errorf("discovery request error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("discovery responded with status %d: %w", status, domain.ErrInternalServerError)
+	}
+	if meta.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("provider issuer %q doesn't match configured %q: %w", meta.Issuer, p.cfg.Issuer, domain.ErrInternalServerError)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, fmt.Errorf("provider metadata misses endpoints: %w", domain.ErrInternalServerError)
+	}
+
+	p.metadata = meta
+	return meta, nil
+}
+
+// key returns provider key by key id, keys are fetched again if kid is
+// unknown, so keys rotated by provider are picked up. Token without kid can
+// be verified only if provider has a single key.
+func (p *oidcProvider) key(ctx context.Context, meta *providerMetadata, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookup(kid); ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetched) < keysRefreshInterval {
+		return nil, fmt.Errorf("unrecognized key id %q", kid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, meta.JWKSURI, nil)
+	if err != nil {
+		return nil, fmt.Errorf("can't create keys request: %w", err)
+	}
+
+	set := new(auth.JWKS)
+	status, err := p.do(req, set)
+	if err != nil {
+		return nil, fmt.Errorf("keys request error: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("keys endpoint responded with status %d", status)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.KeyType != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		key, err := k.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		keys[k.KeyID] = key
+	}
+	p.keys, p.keysFetched = keys, time.Now()
+
+	if key, ok := p.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unrecognized key id %q", kid)
+}
+
+func (p *oidcProvider) lookup(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// do sends request and decodes JSON response into v, response status is
+// returned as error responses of token endpoint have JSON body too
+func (p *oidcProvider) do(req *http.Request, v interface{}) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if err = json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v); err != nil && resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("can't decode response: %w", err)
+	}
+
+	return resp.StatusCode, nil
+}
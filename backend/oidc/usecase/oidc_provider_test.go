@@ -0,0 +1,301 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/oidc/usecase"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+var tracer = sdktrace.NewTracerProvider().Tracer("")
+
+const (
+	clientID     = "shortener"
+	clientSecret = "secret"
+	redirectURL  = "https://short.example.com/oidc/callback"
+	subject      = "248289761001"
+)
+
+// fakeIdP is a local OpenID Connect identity provider, it signs in every
+// user as subject and issues ID token with claims for code if code verifier
+// matches code challenge of authorization request
+type fakeIdP struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+	// issuer is announced by discovery, it's the server URL by default
+	issuer string
+	// claims override claims of issued ID tokens
+	claims jwt.MapClaims
+	// signKey signs ID tokens instead of published key if it's set
+	signKey *rsa.PrivateKey
+
+	mu       sync.Mutex
+	requests map[string]url.Values
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	idp := &fakeIdP{key: key, requests: make(map[string]url.Values)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/keys", idp.keys)
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/token", idp.token)
+	idp.Server = httptest.NewServer(mux)
+	idp.issuer = idp.URL
+	t.Cleanup(idp.Close)
+
+	return idp
+}
+
+func (idp *fakeIdP) discovery(w http.ResponseWriter, _ *http.Request) {
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 idp.issuer,
+		"authorization_endpoint": idp.URL + "/authorize",
+		"token_endpoint":         idp.URL + "/token",
+		"jwks_uri":               idp.URL + "/keys",
+	})
+}
+
+func (idp *fakeIdP) keys(w http.ResponseWriter, _ *http.Request) {
+	_ = json.NewEncoder(w).Encode(auth.JWKS{Keys: []auth.JWK{{
+		KeyType:   "RSA",
+		Use:       "sig",
+		Algorithm: "RS256",
+		KeyID:     "idp",
+		Modulus:   base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+		Exponent:  "AQAB",
+	}}})
+}
+
+// authorize signs user in at once and redirects them back with code
+func (idp *fakeIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	code := fmt.Sprintf("code-%d", time.Now().UnixNano())
+
+	idp.mu.Lock()
+	idp.requests[code] = query
+	idp.mu.Unlock()
+
+	http.Redirect(w, r, query.Get("redirect_uri")+"?"+url.Values{"code": {code}, "state": {query.Get("state")}}.Encode(), http.StatusFound)
+}
+
+func (idp *fakeIdP) token(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if !ok || id != clientID || secret != clientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	code := r.PostFormValue("code")
+	idp.mu.Lock()
+	req, ok := idp.requests[code]
+	delete(idp.requests, code)
+	idp.mu.Unlock()
+
+	challenge := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || r.PostFormValue("grant_type") != "authorization_code" ||
+		r.PostFormValue("redirect_uri") != req.Get("redirect_uri") ||
+		base64.RawURLEncoding.EncodeToString(challenge[:]) != req.Get("code_challenge") {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "code is not valid"})
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":            idp.issuer,
+		"sub":            subject,
+		"aud":            req.Get("client_id"),
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"nonce":          req.Get("nonce"),
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"name":           "Jane Doe",
+	}
+	for k, v := range idp.claims {
+		claims[k] = v
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "idp"
+	key := idp.key
+	if idp.signKey != nil {
+		key = idp.signKey
+	}
+	idToken, err := token.SignedString(key)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "at", "token_type": "Bearer", "id_token": idToken})
+}
+
+// signIn follows authorization URL like browser of user and returns code
+// from redirect to the client
+func signIn(t *testing.T, authURL string) string {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	return location.Query().Get("code")
+}
+
+func newProvider(idp *fakeIdP) domain.OIDCProvider {
+	cfg := usecase.ProviderConfig{
+		Issuer:       idp.URL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+	}
+	return usecase.NewOIDCProvider(cfg, idp.Client(), tracer)
+}
+
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestOIDCProvider_AuthCodeURL(t *testing.T) {
+	idp := newFakeIdP(t)
+	p := newProvider(idp)
+
+	authURL, err := p.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	require.NoError(t, err)
+
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, idp.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, url.Values{
+		"response_type":         {"code"},
+		"client_id":             {clientID},
+		"redirect_uri":          {redirectURL},
+		"scope":                 {"openid email profile"},
+		"state":                 {"state"},
+		"nonce":                 {"nonce"},
+		"code_challenge":        {"challenge"},
+		"code_challenge_method": {"S256"},
+	}, u.Query())
+
+	t.Run("issuer mismatch", func(t *testing.T) {
+		other := newFakeIdP(t)
+		other.issuer = "https://evil.example.com"
+
+		_, err := newProvider(other).AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestOIDCProvider_Exchange(t *testing.T) {
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	cases := []struct {
+		description string
+		claims      jwt.MapClaims
+		verifier    string
+		nonce       string
+		key         *rsa.PrivateKey
+		err         error
+	}{
+		{
+			description: "success",
+		},
+		{
+			description: "wrong code verifier",
+			verifier:    "wrong-verifier-wrong-verifier-wrong-verifier",
+			err:         domain.ErrAuthenticationFailure,
+		},
+		{
+			description: "wrong nonce",
+			nonce:       "other",
+			err:         domain.ErrAuthenticationFailure,
+		},
+		{
+			description: "issued for other client",
+			claims:      jwt.MapClaims{"aud": "other"},
+			err:         domain.ErrAuthenticationFailure,
+		},
+		{
+			description: "expired",
+			claims:      jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()},
+			err:         domain.ErrAuthenticationFailure,
+		},
+		{
+			description: "issued by other issuer",
+			claims:      jwt.MapClaims{"iss": "https://evil.example.com"},
+			err:         domain.ErrAuthenticationFailure,
+		},
+		{
+			description: "signed by unknown key",
+			key:         otherKey,
+			err:         domain.ErrAuthenticationFailure,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			idp := newFakeIdP(t)
+			idp.claims = tc.claims
+			idp.signKey = tc.key
+			p := newProvider(idp)
+
+			authURL, err := p.AuthCodeURL(context.Background(), "state", "nonce", challenge(verifier))
+			require.NoError(t, err)
+			code := signIn(t, authURL)
+
+			v, nonce := verifier, "nonce"
+			if tc.verifier != "" {
+				v = tc.verifier
+			}
+			if tc.nonce != "" {
+				nonce = tc.nonce
+			}
+
+			token, err := p.Exchange(context.Background(), code, v, nonce)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				assert.Nil(t, token)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, &domain.IDToken{
+				Issuer:        idp.URL,
+				Subject:       subject,
+				Email:         "jane@example.com",
+				EmailVerified: true,
+				Name:          "Jane Doe",
+			}, token)
+		})
+	}
+}
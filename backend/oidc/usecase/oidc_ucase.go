@@ -0,0 +1,325 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/semka95/shortener/backend/audit"
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+// loginTTL is the time user has to sign in at identity provider
+const loginTTL = 10 * time.Minute
+
+// maxFullNameLen matches max length of full name of created users
+const maxFullNameLen = 30
+
+type oidcUsecase struct {
+	loginRepo      domain.OIDCLoginRepository
+	userRepo       domain.UserRepository
+	auditRepo      domain.AuditRepository
+	provider       domain.OIDCProvider
+	contextTimeout time.Duration
+	tracer         trace.Tracer
+}
+
+// NewOIDCUsecase will create new an oidcUsecase object representation of oidc.Usecase interface,
+// users signed in at provider are linked to existing users by verified email or created
+func NewOIDCUsecase(l domain.OIDCLoginRepository, u domain.UserRepository, a domain.AuditRepository, provider domain.OIDCProvider, timeout time.Duration, tracer trace.Tracer) domain.OIDCUsecase {
+	return &oidcUsecase{
+		loginRepo:      l,
+		userRepo:       u,
+		auditRepo:      a,
+		provider:       provider,
+		contextTimeout: timeout,
+		tracer:         tracer,
+	}
+}
+
+// Login starts authorization code flow with PKCE, it returns started login
+// and URL of identity provider user should be redirected to
+func (uc *oidcUsecase) Login(c context.Context) (*domain.OIDCLogin, string, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Login",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	login, url, err := uc.start(ctx, "")
+	if err != nil {
+		span.RecordError(err)
+		return nil, "", err
+	}
+
+	return login, url, nil
+}
+
+// Link starts login like Login, but identity is linked to account of signed
+// in user instead of account with the same email
+func (uc *oidcUsecase) Link(c context.Context, claims *auth.Claims) (*domain.OIDCLogin, string, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Link",
+		trace.WithAttributes(
+			attribute.String("userid", claims.Subject)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	login, url, err := uc.start(ctx, claims.Subject)
+	if err != nil {
+		span.RecordError(err)
+		return nil, "", err
+	}
+
+	return login, url, nil
+}
+
+// start stores login of user with the given id, id is empty if user isn't
+// signed in, and returns URL of identity provider
+func (uc *oidcUsecase) start(ctx context.Context, userID string) (*domain.OIDCLogin, string, error) {
+	login := &domain.OIDCLogin{UserID: userID, ExpiresAt: time.Now().Add(loginTTL).Truncate(time.Millisecond).UTC()}
+	for _, v := range []*string{&login.State, &login.Nonce, &login.Verifier} {
+		var err error
+		if *v, err = randomString(); err != nil {
+			return nil, "", fmt.Errorf("can't generate login secrets: %w: %s", domain.ErrInternalServerError, err.Error())
+		}
+	}
+
+	if err := uc.loginRepo.Store(ctx, login); err != nil {
+		return nil, "", err
+	}
+
+	challenge := sha256.Sum256([]byte(login.Verifier))
+	url, err := uc.provider.AuthCodeURL(ctx, login.State, login.Nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return login, url, nil
+}
+
+// Callback finishes login started by Login or Link and returns claims of
+// signed in user. User is found by identity, linked by email verified by
+// provider or created if there is no user with such email. Identity of login
+// started by Link is linked to user who started it.
+func (uc *oidcUsecase) Callback(c context.Context, now time.Time, callback domain.OIDCCallback) (*auth.Claims, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Callback",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	login, err := uc.loginRepo.Take(ctx, callback.State)
+	if err != nil {
+		span.RecordError(err)
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("login is unknown or already finished: %w: %s", domain.ErrAuthenticationFailure, err.Error())
+		}
+		return nil, err
+	}
+	if now.After(login.ExpiresAt) {
+		err = fmt.Errorf("login expired: %w", domain.ErrAuthenticationFailure)
+		span.RecordError(err)
+		return nil, err
+	}
+	if callback.Error != "" {
+		err = fmt.Errorf("identity provider denied login: %w: %s %s", domain.ErrAuthenticationFailure, callback.Error, callback.ErrorDescription)
+		span.RecordError(err)
+		return nil, err
+	}
+	if callback.Code == "" {
+		err = fmt.Errorf("authorization code is missing: %w", domain.ErrBadParamInput)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	token, err := uc.provider.Exchange(ctx, callback.Code, login.Verifier, login.Nonce)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	var u *domain.User
+	if login.UserID != "" {
+		u, err = uc.link(ctx, now, login.UserID, token)
+	} else {
+		u, err = uc.user(ctx, now, token)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("userid", u.ID.Hex()))
+
+	if u.IsSuspended() {
+		err = fmt.Errorf("user %s is suspended: %w", u.ID.Hex(), domain.ErrForbidden)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return auth.NewClaims(u.ID.Hex(), u.Roles, now, auth.AccessTokenTTL), nil
+}
+
+// user returns user of identity, the identity is linked to user with the
+// same email or new user is created. Email must be verified by provider, so
+// nobody can take over account by signing in with somebody else's email.
+// Accounts with password are linked only if their email is verified too,
+// otherwise whoever registered the email first could sign in to the account
+// after its owner links identity to it, such accounts are linked by Link.
+func (uc *oidcUsecase) user(ctx context.Context, now time.Time, token *domain.IDToken) (*domain.User, error) {
+	u, err := uc.userRepo.GetByIdentity(ctx, token.Issuer, token.Subject)
+	if err == nil {
+		return u, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	if token.Email == "" || !token.EmailVerified {
+		return nil, fmt.Errorf("identity %s has no verified email: %w", token.Subject, domain.ErrAuthenticationFailure)
+	}
+
+	identity := domain.Identity{
+		Issuer:   token.Issuer,
+		Subject:  token.Subject,
+		LinkedAt: now.Truncate(time.Millisecond).UTC(),
+	}
+
+	u, err = uc.userRepo.GetByEmail(ctx, token.Email)
+	if errors.Is(err, domain.ErrNotFound) {
+		return uc.create(ctx, token, identity)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if u.HashedPassword != "" && u.EmailVerifiedAt == nil {
+		return nil, fmt.Errorf("user with email %s exists, sign in with password to link identity: %w", token.Email, domain.ErrConflict)
+	}
+
+	return uc.addIdentity(ctx, now, u, identity)
+}
+
+// link links identity to user with the given id, it's used by logins
+// started by Link, so email of identity doesn't matter
+func (uc *oidcUsecase) link(ctx context.Context, now time.Time, userID string, token *domain.IDToken) (*domain.User, error) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user ID is not valid ObjectID: %w: %s", domain.ErrBadParamInput, err.Error())
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	linked, err := uc.userRepo.GetByIdentity(ctx, token.Issuer, token.Subject)
+	if err == nil {
+		if linked.ID == u.ID {
+			return u, nil
+		}
+		return nil, fmt.Errorf("identity %s is linked to another user: %w", token.Subject, domain.ErrConflict)
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	identity := domain.Identity{
+		Issuer:   token.Issuer,
+		Subject:  token.Subject,
+		LinkedAt: now.Truncate(time.Millisecond).UTC(),
+	}
+
+	return uc.addIdentity(ctx, now, u, identity)
+}
+
+func (uc *oidcUsecase) addIdentity(ctx context.Context, now time.Time, u *domain.User, identity domain.Identity) (*domain.User, error) {
+	before := *u
+	u.Identities = append(u.Identities, identity)
+	u.UpdatedAt = identity.LinkedAt
+
+	claims := auth.NewClaims(u.ID.Hex(), u.Roles, now, auth.AccessTokenTTL)
+	if err := uc.record(ctx, claims, domain.AuditLinkIdentity, &before, u); err != nil {
+		return nil, err
+	}
+
+	if err := uc.userRepo.AddIdentity(ctx, u.ID, identity); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// create creates user of identity, user has no password and can sign in
+// only at identity provider
+func (uc *oidcUsecase) create(ctx context.Context, token *domain.IDToken, identity domain.Identity) (*domain.User, error) {
+	name := []rune(token.Name)
+	if len(name) > maxFullNameLen {
+		name = name[:maxFullNameLen]
+	}
+
+	u := &domain.User{
+		ID:              primitive.NewObjectID(),
+		FullName:        string(name),
+		Email:           token.Email,
+		Roles:           []string{auth.RoleUser},
+		Identities:      []domain.Identity{identity},
+		EmailVerifiedAt: &identity.LinkedAt,
+		CreatedAt:       identity.LinkedAt,
+		UpdatedAt:       identity.LinkedAt,
+	}
+
+	// concurrent callbacks of the same identity are rejected by unique indexes
+	if err := uc.userRepo.Create(ctx, u); err != nil {
+		return nil, err
+	}
+
+	claims := auth.NewClaims(u.ID.Hex(), u.Roles, identity.LinkedAt, auth.AccessTokenTTL)
+	if err := uc.record(ctx, claims, domain.AuditCreateUser, nil, u); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+func (uc *oidcUsecase) record(ctx context.Context, claims *auth.Claims, action string, before, after *domain.User) error {
+	e := audit.NewEntry(ctx, claims, action, after.ID.Hex())
+
+	var err error
+	if e.Changes, err = audit.Diff(before, after); err != nil {
+		return err
+	}
+
+	return uc.auditRepo.Store(ctx, e)
+}
+
+// randomString returns 256 random bits encoded as base64url, it's long
+// enough to be used as PKCE code verifier
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,336 @@
+package usecase_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	auditMock "github.com/semka95/shortener/backend/audit/mock"
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/oidc/mock"
+	"github.com/semka95/shortener/backend/oidc/usecase"
+	"github.com/semka95/shortener/backend/tests"
+	userMock "github.com/semka95/shortener/backend/user/mock"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+func newLogin() *domain.OIDCLogin {
+	return &domain.OIDCLogin{
+		State:     "af0ifjsldkj",
+		Nonce:     "n-0S6_WzA2Mj",
+		Verifier:  "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk",
+		ExpiresAt: time.Now().Add(10 * time.Minute),
+	}
+}
+
+func newIDToken() *domain.IDToken {
+	return &domain.IDToken{
+		Issuer:        "https://idp.example.com",
+		Subject:       subject,
+		Email:         "jane@example.com",
+		EmailVerified: true,
+		Name:          "Jane Doe",
+	}
+}
+
+func TestOIDCUsecase_Login(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	loginRepo := mock.NewMockOIDCLoginRepository(controller)
+	provider := mock.NewMockOIDCProvider(controller)
+	uc := usecase.NewOIDCUsecase(loginRepo, userMock.NewMockUserRepository(controller), auditMock.NewMockAuditRepository(controller), provider, 10*time.Second, tracer)
+
+	var stored *domain.OIDCLogin
+	loginRepo.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, l *domain.OIDCLogin) error {
+		stored = l
+		return nil
+	})
+	provider.EXPECT().AuthCodeURL(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, state, nonce, c string) (string, error) {
+		assert.Equal(t, stored.State, state)
+		assert.Equal(t, stored.Nonce, nonce)
+		assert.Equal(t, challenge(stored.Verifier), c)
+		return "https://idp.example.com/authorize?state=" + state, nil
+	})
+
+	login, authURL, err := uc.Login(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, stored, login)
+	assert.Len(t, login.Verifier, 43)
+	assert.NotEqual(t, login.State, login.Nonce)
+	assert.Equal(t, "https://idp.example.com/authorize?state="+login.State, authURL)
+}
+
+func TestOIDCUsecase_Callback(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	loginRepo := mock.NewMockOIDCLoginRepository(controller)
+	userRepo := userMock.NewMockUserRepository(controller)
+	auditRepo := auditMock.NewMockAuditRepository(controller)
+	provider := mock.NewMockOIDCProvider(controller)
+	uc := usecase.NewOIDCUsecase(loginRepo, userRepo, auditRepo, provider, 10*time.Second, tracer)
+
+	tLogin := newLogin()
+	callback := domain.OIDCCallback{State: tLogin.State, Code: "code"}
+	token := newIDToken()
+
+	cases := []struct {
+		description string
+		token       *domain.IDToken
+		mockCalls   func()
+		err         error
+	}{
+		{
+			description: "linked identity",
+			token:       token,
+			mockCalls: func() {
+				userRepo.EXPECT().GetByIdentity(gomock.Any(), token.Issuer, token.Subject).Return(tests.NewUser(), nil)
+			},
+		},
+		{
+			description: "link by verified email",
+			token:       token,
+			mockCalls: func() {
+				u := tests.NewUser()
+				verifiedAt := time.Now()
+				u.EmailVerifiedAt = &verifiedAt
+				userRepo.EXPECT().GetByIdentity(gomock.Any(), token.Issuer, token.Subject).Return(nil, domain.ErrNotFound)
+				userRepo.EXPECT().GetByEmail(gomock.Any(), token.Email).Return(u, nil)
+				auditRepo.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.AuditEntry) error {
+					assert.Equal(t, domain.AuditLinkIdentity, e.Action)
+					assert.Equal(t, tests.NewUser().ID.Hex(), e.Actor)
+					assert.Contains(t, e.Changes, "identities")
+					return nil
+				})
+				userRepo.EXPECT().AddIdentity(gomock.Any(), tests.NewUser().ID, gomock.Any()).DoAndReturn(func(_ context.Context, _ interface{}, i domain.Identity) error {
+					assert.Equal(t, token.Issuer, i.Issuer)
+					assert.Equal(t, token.Subject, i.Subject)
+					return nil
+				})
+			},
+		},
+		{
+			description: "link account without password",
+			token:       token,
+			mockCalls: func() {
+				u := tests.NewUser()
+				u.HashedPassword = ""
+				userRepo.EXPECT().GetByIdentity(gomock.Any(), token.Issuer, token.Subject).Return(nil, domain.ErrNotFound)
+				userRepo.EXPECT().GetByEmail(gomock.Any(), token.Email).Return(u, nil)
+				auditRepo.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+				userRepo.EXPECT().AddIdentity(gomock.Any(), u.ID, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			// somebody could register the email with their password before its owner signs in
+			description: "account with password and unverified email",
+			token:       token,
+			mockCalls: func() {
+				userRepo.EXPECT().GetByIdentity(gomock.Any(), token.Issuer, token.Subject).Return(nil, domain.ErrNotFound)
+				userRepo.EXPECT().GetByEmail(gomock.Any(), token.Email).Return(tests.NewUser(), nil)
+			},
+			err: domain.ErrConflict,
+		},
+		{
+			description: "create user",
+			token:       token,
+			mockCalls: func() {
+				userRepo.EXPECT().GetByIdentity(gomock.Any(), token.Issuer, token.Subject).Return(nil, domain.ErrNotFound)
+				userRepo.EXPECT().GetByEmail(gomock.Any(), token.Email).Return(nil, domain.ErrNotFound)
+				userRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, u *domain.User) error {
+					assert.Equal(t, token.Email, u.Email)
+					assert.Equal(t, token.Name, u.FullName)
+					assert.Empty(t, u.HashedPassword)
+					assert.Len(t, u.Identities, 1)
+					assert.NotNil(t, u.EmailVerifiedAt)
+					return nil
+				})
+				auditRepo.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.AuditEntry) error {
+					assert.Equal(t, domain.AuditCreateUser, e.Action)
+					return nil
+				})
+			},
+		},
+		{
+			description: "email not verified",
+			token:       &domain.IDToken{Issuer: token.Issuer, Subject: token.Subject, Email: token.Email},
+			mockCalls: func() {
+				userRepo.EXPECT().GetByIdentity(gomock.Any(), token.Issuer, token.Subject).Return(nil, domain.ErrNotFound)
+			},
+			err: domain.ErrAuthenticationFailure,
+		},
+		{
+			description: "suspended user",
+			token:       token,
+			mockCalls: func() {
+				u := tests.NewUser()
+				suspendedAt := time.Now()
+				u.SuspendedAt = &suspendedAt
+				userRepo.EXPECT().GetByIdentity(gomock.Any(), token.Issuer, token.Subject).Return(u, nil)
+			},
+			err: domain.ErrForbidden,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			loginRepo.EXPECT().Take(gomock.Any(), tLogin.State).Return(newLogin(), nil)
+			provider.EXPECT().Exchange(gomock.Any(), "code", tLogin.Verifier, tLogin.Nonce).Return(tc.token, nil)
+			tc.mockCalls()
+
+			claims, err := uc.Callback(context.Background(), time.Now(), callback)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				assert.Nil(t, claims)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, claims.Subject)
+		})
+	}
+
+	t.Run("unknown state", func(t *testing.T) {
+		loginRepo.EXPECT().Take(gomock.Any(), tLogin.State).Return(nil, domain.ErrNotFound)
+
+		claims, err := uc.Callback(context.Background(), time.Now(), callback)
+		assert.ErrorIs(t, err, domain.ErrAuthenticationFailure)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("expired login", func(t *testing.T) {
+		loginRepo.EXPECT().Take(gomock.Any(), tLogin.State).Return(newLogin(), nil)
+
+		claims, err := uc.Callback(context.Background(), time.Now().Add(time.Hour), callback)
+		assert.ErrorIs(t, err, domain.ErrAuthenticationFailure)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("access denied", func(t *testing.T) {
+		loginRepo.EXPECT().Take(gomock.Any(), tLogin.State).Return(newLogin(), nil)
+		denied := domain.OIDCCallback{State: tLogin.State, Error: "access_denied"}
+
+		claims, err := uc.Callback(context.Background(), time.Now(), denied)
+		assert.ErrorIs(t, err, domain.ErrAuthenticationFailure)
+		assert.Nil(t, claims)
+	})
+}
+
+func TestOIDCUsecase_Link(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	loginRepo := mock.NewMockOIDCLoginRepository(controller)
+	userRepo := userMock.NewMockUserRepository(controller)
+	auditRepo := auditMock.NewMockAuditRepository(controller)
+	provider := mock.NewMockOIDCProvider(controller)
+	uc := usecase.NewOIDCUsecase(loginRepo, userRepo, auditRepo, provider, 10*time.Second, tracer)
+
+	tUser := tests.NewUser()
+	token := newIDToken()
+	token.Email = "other@example.com"
+	token.EmailVerified = false
+	claims := auth.NewClaims(tUser.ID.Hex(), tUser.Roles, time.Now(), time.Minute)
+
+	t.Run("login is bound to user", func(t *testing.T) {
+		loginRepo.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, l *domain.OIDCLogin) error {
+			assert.Equal(t, tUser.ID.Hex(), l.UserID)
+			return nil
+		})
+		provider.EXPECT().AuthCodeURL(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return("https://idp.example.com/authorize", nil)
+
+		login, authURL, err := uc.Link(context.Background(), claims)
+		require.NoError(t, err)
+		assert.Equal(t, tUser.ID.Hex(), login.UserID)
+		assert.Equal(t, "https://idp.example.com/authorize", authURL)
+	})
+
+	cases := []struct {
+		description string
+		mockCalls   func()
+		err         error
+	}{
+		{
+			description: "identity is linked to user regardless of email",
+			mockCalls: func() {
+				userRepo.EXPECT().GetByIdentity(gomock.Any(), token.Issuer, token.Subject).Return(nil, domain.ErrNotFound)
+				auditRepo.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+				userRepo.EXPECT().AddIdentity(gomock.Any(), tUser.ID, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			description: "identity is already linked to user",
+			mockCalls: func() {
+				userRepo.EXPECT().GetByIdentity(gomock.Any(), token.Issuer, token.Subject).Return(tests.NewUser(), nil)
+			},
+		},
+		{
+			description: "identity is linked to another user",
+			mockCalls: func() {
+				other := tests.NewUser()
+				other.ID = primitive.NewObjectID()
+				userRepo.EXPECT().GetByIdentity(gomock.Any(), token.Issuer, token.Subject).Return(other, nil)
+			},
+			err: domain.ErrConflict,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			login := newLogin()
+			login.UserID = tUser.ID.Hex()
+			loginRepo.EXPECT().Take(gomock.Any(), login.State).Return(login, nil)
+			provider.EXPECT().Exchange(gomock.Any(), "code", login.Verifier, login.Nonce).Return(token, nil)
+			userRepo.EXPECT().GetByID(gomock.Any(), tUser.ID).Return(tests.NewUser(), nil)
+			tc.mockCalls()
+
+			result, err := uc.Callback(context.Background(), time.Now(), domain.OIDCCallback{State: login.State, Code: "code"})
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				assert.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tUser.ID.Hex(), result.Subject)
+		})
+	}
+}
+
+func TestOIDCUsecase_FakeIdP(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	idp := newFakeIdP(t)
+	loginRepo := mock.NewMockOIDCLoginRepository(controller)
+	userRepo := userMock.NewMockUserRepository(controller)
+	auditRepo := auditMock.NewMockAuditRepository(controller)
+	uc := usecase.NewOIDCUsecase(loginRepo, userRepo, auditRepo, newProvider(idp), 10*time.Second, tracer)
+
+	var stored *domain.OIDCLogin
+	loginRepo.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, l *domain.OIDCLogin) error {
+		stored = l
+		return nil
+	})
+	_, authURL, err := uc.Login(context.Background())
+	require.NoError(t, err)
+
+	code := signIn(t, authURL)
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+
+	loginRepo.EXPECT().Take(gomock.Any(), stored.State).Return(stored, nil)
+	userRepo.EXPECT().GetByIdentity(gomock.Any(), idp.URL, subject).Return(nil, domain.ErrNotFound)
+	userRepo.EXPECT().GetByEmail(gomock.Any(), "jane@example.com").Return(nil, domain.ErrNotFound)
+	userRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	auditRepo.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+
+	claims, err := uc.Callback(context.Background(), time.Now(), domain.OIDCCallback{State: u.Query().Get("state"), Code: code})
+	require.NoError(t, err)
+	assert.NotEmpty(t, claims.Subject)
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v4"
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/web"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+// ReportHandler represent the http handler for abuse reports
+type ReportHandler struct {
+	reportUsecase domain.AbuseReportUsecase
+	authenticator *auth.Authenticator
+	validator     *web.AppValidator
+	logger        *zap.Logger
+	tracer        trace.Tracer
+}
+
+// NewReportHandler will initialize the report resources endpoint
+func NewReportHandler(rs domain.AbuseReportUsecase, authenticator *auth.Authenticator, v *web.AppValidator, logger *zap.Logger, tracer trace.Tracer) *ReportHandler {
+	return &ReportHandler{
+		reportUsecase: rs,
+		authenticator: authenticator,
+		validator:     v,
+		logger:        logger,
+		tracer:        tracer,
+	}
+}
+
+// RegisterRoutes registers routes for a path with matching handler
+func (rh *ReportHandler) RegisterRoutes(e *echo.Echo) {
+	e.POST("/v1/url/:id/report", rh.Create, echojwt.WithConfig(rh.authenticator.OptionalJWTConfig))
+}
+
+// Create will store abuse report of URL, authentication is optional,
+// reporter is recorded when token is present
+func (rh *ReportHandler) Create(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := rh.tracer.Start(
+		ctx,
+		"http Create",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	r := new(domain.CreateAbuseReport)
+	if err := c.Bind(r); err != nil {
+		span.RecordError(err)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
+	}
+
+	if err := c.Validate(r); err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(rh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	r.ReporterID = ""
+	if token, ok := c.Get("user").(*jwt.Token); ok && token != nil {
+		if user, ok := token.Claims.(*auth.Claims); ok {
+			r.ReporterID = user.Subject
+		}
+	}
+
+	report, err := rh.reportUsecase.Create(ctx, *r)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, rh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("urlid", r.URLID),
+	)
+	span.SetStatus(codes.Ok, "success")
+
+	return c.JSON(http.StatusCreated, report)
+}
@@ -0,0 +1,143 @@
+package http_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+	reportHttp "github.com/semka95/shortener/backend/report/delivery/http"
+	"github.com/semka95/shortener/backend/report/mock"
+	"github.com/semka95/shortener/backend/tests"
+	"github.com/semka95/shortener/backend/web"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+func TestReportHTTP(t *testing.T) {
+	tUser := tests.NewUser()
+	tReport := tests.NewAbuseReport()
+	claims := auth.NewClaims(tUser.ID.Hex(), tUser.Roles, time.Now(), time.Hour)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	kid := "4754d86b-7a6d-4df5-9c65-224741361492"
+	kf := auth.NewSimpleKeyLookupFunc(kid, key.Public().(*rsa.PublicKey))
+	authenticator, err := auth.NewAuthenticator(key, kid, "RS256", kf)
+	require.NoError(t, err)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	uc := mock.NewMockAbuseReportUsecase(controller)
+
+	tracer := sdktrace.NewTracerProvider().Tracer("")
+	v, err := web.NewAppValidator()
+	require.NoError(t, err)
+
+	handler := reportHttp.NewReportHandler(uc, authenticator, v, zap.NewNop(), tracer)
+
+	e := echo.New()
+	e.Validator = v
+	req := new(http.Request)
+	c := e.NewContext(req, nil)
+
+	// Test ReportHandler.Create
+	cases := []struct {
+		description   string
+		mockCalls     func()
+		reqBody       string
+		token         *jwt.Token
+		checkResponse func(rec *httptest.ResponseRecorder)
+	}{
+		{
+			description: "Create anonymous report",
+			mockCalls: func() {
+				uc.EXPECT().Create(gomock.Any(), domain.CreateAbuseReport{
+					URLID:   tReport.URLID,
+					Reason:  tReport.Reason,
+					Details: tReport.Details,
+				}).Return(tReport, nil)
+			},
+			reqBody: `{"reason":"phishing","details":"asks for bank credentials"}`,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.AbuseReport)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.EqualValues(t, tReport, body)
+				assert.Equal(t, http.StatusCreated, rec.Code)
+			},
+		},
+		{
+			description: "Create report of authenticated user",
+			mockCalls: func() {
+				uc.EXPECT().Create(gomock.Any(), domain.CreateAbuseReport{
+					URLID:      tReport.URLID,
+					Reason:     "spam",
+					ReporterID: tUser.ID.Hex(),
+				}).Return(tReport, nil)
+			},
+			reqBody: `{"reason":"spam","reporter_id":"someone else"}`,
+			token:   jwt.NewWithClaims(jwt.SigningMethodHS256, claims),
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, rec.Code)
+			},
+		},
+		{
+			description: "Create validation error",
+			mockCalls:   func() {},
+			reqBody:     `{"reason":"dislike"}`,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Contains(t, body.Fields, "CreateAbuseReport.reason")
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			description: "Create URL not found",
+			mockCalls: func() {
+				uc.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, domain.ErrNotFound)
+			},
+			reqBody: `{"reason":"malware"}`,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, rec.Code)
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls()
+			req = httptest.NewRequest(echo.POST, "/v1/url/"+tReport.URLID+"/report", strings.NewReader(tc.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+			c.SetPath("/v1/url/:id/report")
+			c.SetParamNames("id")
+			c.SetParamValues(tReport.URLID)
+			if tc.token != nil {
+				c.Set("user", tc.token)
+			}
+
+			err = handler.Create(c)
+			require.NoError(t, err)
+
+			tc.checkResponse(rec)
+		})
+	}
+}
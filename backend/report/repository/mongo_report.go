@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+)
+
+const reportCollection = "abuse_report"
+
+type mongoReportRepository struct {
+	Conn   *mongo.Database
+	logger *zap.Logger
+	tracer trace.Tracer
+}
+
+// NewMongoReportRepository will create an object that represent the report.Repository interface
+func NewMongoReportRepository(c *mongo.Client, db string, logger *zap.Logger, tracer trace.Tracer) domain.AbuseReportRepository {
+	return &mongoReportRepository{
+		Conn:   c.Database(db),
+		logger: logger,
+		tracer: tracer,
+	}
+}
+
+func (m *mongoReportRepository) Store(ctx context.Context, r *domain.AbuseReport) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository Store",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("urlid", r.URLID)),
+	)
+	defer span.End()
+
+	_, err := m.Conn.Collection(reportCollection).InsertOne(ctx, r)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("abuse report store error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (m *mongoReportRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.AbuseReport, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository GetByID",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("reportid", id.Hex())),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "_id", Value: id}}
+
+	r := new(domain.AbuseReport)
+	err := m.Conn.Collection(reportCollection).FindOne(ctx, filter).Decode(r)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.RecordError(err)
+		return nil, fmt.Errorf("abuse report was not found: %w", domain.ErrNotFound)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("abuse report get error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return r, nil
+}
+
+func (m *mongoReportRepository) Update(ctx context.Context, r *domain.AbuseReport) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository Update",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("reportid", r.ID.Hex())),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "_id", Value: r.ID}}
+
+	res, err := m.Conn.Collection(reportCollection).ReplaceOne(ctx, filter, r)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("abuse report update error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	if res.MatchedCount == 0 {
+		err = fmt.Errorf("abuse report was not updated: %w", domain.ErrNoAffected)
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// List returns page of reports sorted by id, so newest reports are first,
+// the cursor is id of the last report on the page
+func (m *mongoReportRepository) List(ctx context.Context, query domain.AbuseReportListQuery) (*domain.AbuseReportList, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository List",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	filter := bson.D{}
+	if query.Status != "" {
+		filter = append(filter, primitive.E{Key: "status", Value: query.Status})
+	}
+	if query.URLID != "" {
+		filter = append(filter, primitive.E{Key: "url_id", Value: query.URLID})
+	}
+	if query.Cursor != "" {
+		cursor, err := primitive.ObjectIDFromHex(query.Cursor)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("can't decode cursor: %w: %s", domain.ErrBadParamInput, err.Error())
+		}
+		filter = append(filter, primitive.E{Key: "_id", Value: bson.D{primitive.E{Key: "$lt", Value: cursor}}})
+	}
+
+	// one extra document is fetched to find out whether there is a next page
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(int64(query.Limit + 1))
+
+	cur, err := m.Conn.Collection(reportCollection).Find(ctx, filter, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("abuse report list error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	list := make([]*domain.AbuseReport, 0)
+	if err = cur.All(ctx, &list); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("can't decode abuse reports: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	result := &domain.AbuseReportList{Items: list}
+	if len(list) > query.Limit {
+		result.Items = list[:query.Limit]
+		result.NextCursor = result.Items[len(result.Items)-1].ID.Hex()
+	}
+
+	return result, nil
+}
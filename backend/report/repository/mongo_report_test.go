@@ -0,0 +1,154 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/report/repository"
+	"github.com/semka95/shortener/backend/tests"
+)
+
+var tracer = sdktrace.NewTracerProvider().Tracer("")
+var noopCtx = context.Background()
+
+const tableName = "shortener.abuse_report"
+
+func reportBsonD(r *domain.AbuseReport) bson.D {
+	return bson.D{
+		{Key: "_id", Value: r.ID},
+		{Key: "url_id", Value: r.URLID},
+		{Key: "link", Value: r.Link},
+		{Key: "reason", Value: r.Reason},
+		{Key: "details", Value: r.Details},
+		{Key: "status", Value: r.Status},
+		{Key: "created_at", Value: r.CreatedAt},
+	}
+}
+
+func TestMongoReportRepository_Store(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tReport := tests.NewAbuseReport()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		r := repository.NewMongoReportRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Store(noopCtx, tReport)
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   1,
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoReportRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Store(noopCtx, tReport)
+
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoReportRepository_GetByID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tReport := tests.NewAbuseReport()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, reportBsonD(tReport)))
+		r := repository.NewMongoReportRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetByID(noopCtx, tReport.ID)
+
+		require.NoError(mt, err)
+		assert.EqualValues(mt, tReport, result)
+	})
+
+	mt.Run("not exists", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch))
+		r := repository.NewMongoReportRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetByID(noopCtx, tReport.ID)
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrNotFound)
+	})
+}
+
+func TestMongoReportRepository_Update(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tReport := tests.NewAbuseReport()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+		r := repository.NewMongoReportRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Update(noopCtx, tReport)
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("not exists", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+		r := repository.NewMongoReportRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Update(noopCtx, tReport)
+
+		assert.ErrorIs(mt, err, domain.ErrNoAffected)
+	})
+}
+
+func TestMongoReportRepository_List(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tReport := tests.NewAbuseReport()
+	older := tests.NewAbuseReport()
+	older.ID = primitive.NewObjectIDFromTimestamp(tReport.CreatedAt.AddDate(0, 0, -1))
+
+	mt.Run("success with next page", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, reportBsonD(tReport), reportBsonD(older)))
+		r := repository.NewMongoReportRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.List(noopCtx, domain.AbuseReportListQuery{Limit: 1, Status: domain.ReportStatusOpen})
+
+		require.NoError(mt, err)
+		require.Len(mt, result.Items, 1)
+		assert.EqualValues(mt, tReport, result.Items[0])
+		assert.Equal(mt, tReport.ID.Hex(), result.NextCursor)
+	})
+
+	mt.Run("wrong cursor", func(mt *mtest.T) {
+		r := repository.NewMongoReportRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.List(noopCtx, domain.AbuseReportListQuery{Limit: 1, Cursor: "wrong"})
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrBadParamInput)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoReportRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.List(noopCtx, domain.AbuseReportListQuery{Limit: 10})
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/semka95/shortener/backend/domain"
+)
+
+type reportUsecase struct {
+	reportRepo     domain.AbuseReportRepository
+	urlRepo        domain.URLRepository
+	contextTimeout time.Duration
+	tracer         trace.Tracer
+}
+
+// NewReportUsecase will create new a reportUsecase object representation of report.Usecase interface
+func NewReportUsecase(r domain.AbuseReportRepository, u domain.URLRepository, timeout time.Duration, tracer trace.Tracer) domain.AbuseReportUsecase {
+	return &reportUsecase{
+		reportRepo:     r,
+		urlRepo:        u,
+		contextTimeout: timeout,
+		tracer:         tracer,
+	}
+}
+
+// Create stores abuse report of URL, destination link is kept in report, so
+// admins see what was reported even if link is changed later
+func (uc *reportUsecase) Create(c context.Context, create domain.CreateAbuseReport) (*domain.AbuseReport, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Create",
+		trace.WithAttributes(
+			attribute.String("urlid", create.URLID)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	u, err := uc.urlRepo.GetByID(ctx, create.URLID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	r := &domain.AbuseReport{
+		ID:         primitive.NewObjectID(),
+		URLID:      u.ID,
+		Link:       u.Link,
+		Reason:     create.Reason,
+		Details:    create.Details,
+		ReporterID: create.ReporterID,
+		Status:     domain.ReportStatusOpen,
+		CreatedAt:  time.Now().Truncate(time.Millisecond).UTC(),
+	}
+
+	if err = uc.reportRepo.Store(ctx, r); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return r, nil
+}
@@ -0,0 +1,68 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/report/mock"
+	"github.com/semka95/shortener/backend/report/usecase"
+	"github.com/semka95/shortener/backend/tests"
+	urlMock "github.com/semka95/shortener/backend/url/mock"
+)
+
+var tracer = sdktrace.NewTracerProvider().Tracer("")
+
+func TestReportUsecase_Create(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tURL := tests.NewURL()
+	create := domain.CreateAbuseReport{
+		URLID:      tURL.ID,
+		Reason:     "phishing",
+		Details:    "asks for bank credentials",
+		ReporterID: "507f191e810c19729de860ea",
+	}
+
+	repository := mock.NewMockAbuseReportRepository(controller)
+	urlRepository := urlMock.NewMockURLRepository(controller)
+	uc := usecase.NewReportUsecase(repository, urlRepository, 10*time.Second, tracer)
+
+	t.Run("success", func(t *testing.T) {
+		urlRepository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+
+		result, err := uc.Create(context.Background(), create)
+		require.NoError(t, err)
+		assert.Equal(t, tURL.ID, result.URLID)
+		assert.Equal(t, tURL.Link, result.Link)
+		assert.Equal(t, create.Reason, result.Reason)
+		assert.Equal(t, create.ReporterID, result.ReporterID)
+		assert.Equal(t, domain.ReportStatusOpen, result.Status)
+		assert.False(t, result.ID.IsZero())
+	})
+
+	t.Run("url not found", func(t *testing.T) {
+		urlRepository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(nil, domain.ErrNotFound)
+
+		result, err := uc.Create(context.Background(), create)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		urlRepository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(domain.ErrInternalServerError)
+
+		result, err := uc.Create(context.Background(), create)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+		assert.Nil(t, result)
+	})
+}
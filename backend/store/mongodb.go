@@ -104,3 +104,8 @@ func StructToDoc(v interface{}) (doc *bson.D, err error) {
 	err = bson.Unmarshal(data, &doc)
 	return doc, err
 }
+
+// IsDuplicateKeyCode reports whether MongoDB write error code means unique index violation
+func IsDuplicateKeyCode(code int) bool {
+	return code == 11000 || code == 11001 || code == 12582
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/semka95/shortener/backend/domain"
+)
+
+type mongoTransactor struct {
+	client *mongo.Client
+	tracer trace.Tracer
+}
+
+// NewMongoTransactor will create an object that represent the domain.Transactor interface,
+// MongoDB transactions are supported only by replica sets
+func NewMongoTransactor(c *mongo.Client, tracer trace.Tracer) domain.Transactor {
+	return &mongoTransactor{
+		client: c,
+		tracer: tracer,
+	}
+}
+
+// WithTransaction runs fn in a session transaction, fn may be called again if
+// transaction fails with transient error, so it must not have side effects
+// outside of the database
+func (t *mongoTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	ctx, span := t.tracer.Start(
+		ctx,
+		"store WithTransaction",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	session, err := t.client.StartSession()
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("can't start session: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	var fnErr error
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		fnErr = fn(sc)
+		return nil, fnErr
+	})
+	if fnErr != nil {
+		span.RecordError(fnErr)
+		return fnErr
+	}
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("transaction error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
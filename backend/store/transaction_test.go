@@ -0,0 +1,41 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/store"
+)
+
+func TestMongoTransactor_WithTransaction(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tracer := sdktrace.NewTracerProvider().Tracer("")
+
+	mt.Run("success", func(mt *mtest.T) {
+		tx := store.NewMongoTransactor(mt.Client, tracer)
+
+		err := tx.WithTransaction(context.Background(), func(ctx context.Context) error {
+			assert.NotNil(mt, mongo.SessionFromContext(ctx))
+			return nil
+		})
+
+		assert.NoError(mt, err)
+	})
+
+	mt.Run("function error", func(mt *mtest.T) {
+		tx := store.NewMongoTransactor(mt.Client, tracer)
+
+		err := tx.WithTransaction(context.Background(), func(ctx context.Context) error {
+			return domain.ErrNotFound
+		})
+
+		assert.ErrorIs(mt, err, domain.ErrNotFound)
+	})
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"net/http"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -76,6 +77,7 @@ func NewURL() *domain.URL {
 		Link:           "http://www.example.org",
 		ExpirationDate: time.Now().Add(time.Hour).Truncate(time.Millisecond).UTC(),
 		UserID:         "507f191e810c19729de860ea",
+		RedirectType:   http.StatusMovedPermanently,
 		CreatedAt:      time.Now().Truncate(time.Millisecond).UTC(),
 		UpdatedAt:      time.Now().Truncate(time.Millisecond).UTC(),
 	}
@@ -95,7 +97,8 @@ func NewCreateURL() domain.CreateURL {
 func NewUpdateURL() domain.UpdateURL {
 	return domain.UpdateURL{
 		ID:             "test123",
-		ExpirationDate: time.Now().Add(time.Hour).Truncate(time.Millisecond).UTC(),
+		Link:           StringPointer("http://www.example.com"),
+		ExpirationDate: DatePointer(time.Now().Add(time.Hour).Truncate(time.Millisecond).UTC()),
 	}
 }
 
@@ -106,7 +109,110 @@ func NewURLBsonD() bson.D {
 		{Key: "link", Value: "http://www.example.org"},
 		{Key: "expiration_date", Value: time.Now().Add(time.Hour).Truncate(time.Millisecond).UTC()},
 		{Key: "user_id", Value: "507f191e810c19729de860ea"},
+		{Key: "redirect_type", Value: http.StatusMovedPermanently},
 		{Key: "created_at", Value: time.Now().Truncate(time.Millisecond).UTC()},
 		{Key: "updated_at", Value: time.Now().Truncate(time.Millisecond).UTC()},
 	}
 }
+
+// NewClick creates instance of Click model
+func NewClick() *domain.Click {
+	return &domain.Click{
+		ID:        primitive.NewObjectID(),
+		URLID:     "test123",
+		OwnerID:   "507f191e810c19729de860ea",
+		Timestamp: time.Now().Truncate(time.Millisecond).UTC(),
+		Referrer:  "https://www.example.com/",
+		UserAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/110.0",
+		Browser:   "Firefox",
+		OS:        "Linux",
+		Device:    "desktop",
+		Country:   "NL",
+		VisitorID: "4f1a5d6e9c3b2a10",
+	}
+}
+
+// NewRefreshToken creates instance of RefreshToken model
+func NewRefreshToken() *domain.RefreshToken {
+	userID, _ := primitive.ObjectIDFromHex("507f191e810c19729de860ea")
+	return &domain.RefreshToken{
+		ID:        primitive.NewObjectID(),
+		Hash:      "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", // test
+		UserID:    userID,
+		FamilyID:  primitive.NewObjectID(),
+		ExpiresAt: time.Now().Add(time.Hour).Truncate(time.Millisecond).UTC(),
+		CreatedAt: time.Now().Truncate(time.Millisecond).UTC(),
+	}
+}
+
+// NewURLRevision creates instance of URLRevision model
+func NewURLRevision() *domain.URLRevision {
+	id, _ := primitive.ObjectIDFromHex("640f1c2e9b1e8a3d5c7b9a01")
+	return &domain.URLRevision{
+		ID:             id,
+		URLID:          "test123",
+		Link:           "http://www.example.net",
+		Title:          "Example",
+		RedirectType:   http.StatusFound,
+		ExpirationDate: time.Now().Add(time.Hour).Truncate(time.Millisecond).UTC(),
+		ChangedBy:      "507f191e810c19729de860ea",
+		CreatedAt:      time.Now().Add(-time.Hour).Truncate(time.Millisecond).UTC(),
+	}
+}
+
+// NewAbuseReport creates instance of AbuseReport model
+func NewAbuseReport() *domain.AbuseReport {
+	id, _ := primitive.ObjectIDFromHex("640f1c2e9b1e8a3d5c7b9a02")
+	return &domain.AbuseReport{
+		ID:        id,
+		URLID:     "test123",
+		Link:      "http://www.example.org",
+		Reason:    "phishing",
+		Details:   "asks for bank credentials",
+		Status:    domain.ReportStatusOpen,
+		CreatedAt: time.Now().Truncate(time.Millisecond).UTC(),
+	}
+}
+
+// NewAPIKey creates instance of APIKey model, Hash is hash of "shk_test"
+func NewAPIKey() *domain.APIKey {
+	id, _ := primitive.ObjectIDFromHex("640f1c2e9b1e8a3d5c7b9a03")
+	userID, _ := primitive.ObjectIDFromHex("507f191e810c19729de860ea")
+	return &domain.APIKey{
+		ID:        id,
+		UserID:    userID,
+		Name:      "notifications",
+		Prefix:    "shk_test",
+		Hash:      "28b27e9cec67499d72f15423cd22f95406edbeff3bb3a4e79cb51980f75dd1ef",
+		Scopes:    []string{auth.ScopeURLCreate},
+		CreatedAt: time.Now().Truncate(time.Millisecond).UTC(),
+	}
+}
+
+// NewWorkspace creates instance of Workspace model, the user of NewUser is its owner
+func NewWorkspace() *domain.Workspace {
+	id, _ := primitive.ObjectIDFromHex("640f1c2e9b1e8a3d5c7b9a04")
+	now := time.Now().Truncate(time.Millisecond).UTC()
+	return &domain.Workspace{
+		ID:   id,
+		Name: "marketing",
+		Members: []domain.WorkspaceMember{
+			{UserID: "507f191e810c19729de860ea", Role: auth.WorkspaceOwner, AddedAt: now},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// NewCustomDomain creates instance of CustomDomain model which is not verified yet,
+// it belongs to workspace of NewWorkspace
+func NewCustomDomain() *domain.CustomDomain {
+	id, _ := primitive.ObjectIDFromHex("640f1c2e9b1e8a3d5c7b9a05")
+	return &domain.CustomDomain{
+		ID:          id,
+		Host:        "go.example.com",
+		WorkspaceID: "640f1c2e9b1e8a3d5c7b9a04",
+		Token:       "0123456789abcdef0123456789abcdef",
+		CreatedAt:   time.Now().Truncate(time.Millisecond).UTC(),
+	}
+}
@@ -0,0 +1,250 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./domain/token.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/semka95/shortener/backend/domain"
+	auth "github.com/semka95/shortener/backend/web/auth"
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockTokenUsecase is a mock of TokenUsecase interface.
+type MockTokenUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenUsecaseMockRecorder
+}
+
+// MockTokenUsecaseMockRecorder is the mock recorder for MockTokenUsecase.
+type MockTokenUsecaseMockRecorder struct {
+	mock *MockTokenUsecase
+}
+
+// NewMockTokenUsecase creates a new mock instance.
+func NewMockTokenUsecase(ctrl *gomock.Controller) *MockTokenUsecase {
+	mock := &MockTokenUsecase{ctrl: ctrl}
+	mock.recorder = &MockTokenUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenUsecase) EXPECT() *MockTokenUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CheckRevoked mocks base method.
+func (m *MockTokenUsecase) CheckRevoked(ctx context.Context, claims *auth.Claims) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckRevoked", ctx, claims)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckRevoked indicates an expected call of CheckRevoked.
+func (mr *MockTokenUsecaseMockRecorder) CheckRevoked(ctx, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckRevoked", reflect.TypeOf((*MockTokenUsecase)(nil).CheckRevoked), ctx, claims)
+}
+
+// Issue mocks base method.
+func (m *MockTokenUsecase) Issue(ctx context.Context, claims *auth.Claims) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Issue", ctx, claims)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Issue indicates an expected call of Issue.
+func (mr *MockTokenUsecaseMockRecorder) Issue(ctx, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MockTokenUsecase)(nil).Issue), ctx, claims)
+}
+
+// Logout mocks base method.
+func (m *MockTokenUsecase) Logout(ctx context.Context, refreshToken string, claims *auth.Claims) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, refreshToken, claims)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockTokenUsecaseMockRecorder) Logout(ctx, refreshToken, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockTokenUsecase)(nil).Logout), ctx, refreshToken, claims)
+}
+
+// LogoutAll mocks base method.
+func (m *MockTokenUsecase) LogoutAll(ctx context.Context, claims *auth.Claims) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogoutAll", ctx, claims)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogoutAll indicates an expected call of LogoutAll.
+func (mr *MockTokenUsecaseMockRecorder) LogoutAll(ctx, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutAll", reflect.TypeOf((*MockTokenUsecase)(nil).LogoutAll), ctx, claims)
+}
+
+// Refresh mocks base method.
+func (m *MockTokenUsecase) Refresh(ctx context.Context, now time.Time, refreshToken string) (*auth.Claims, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, now, refreshToken)
+	ret0, _ := ret[0].(*auth.Claims)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockTokenUsecaseMockRecorder) Refresh(ctx, now, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockTokenUsecase)(nil).Refresh), ctx, now, refreshToken)
+}
+
+// RevokeAll mocks base method.
+func (m *MockTokenUsecase) RevokeAll(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAll", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAll indicates an expected call of RevokeAll.
+func (mr *MockTokenUsecaseMockRecorder) RevokeAll(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAll", reflect.TypeOf((*MockTokenUsecase)(nil).RevokeAll), ctx, userID)
+}
+
+// MockTokenRepository is a mock of TokenRepository interface.
+type MockTokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenRepositoryMockRecorder
+}
+
+// MockTokenRepositoryMockRecorder is the mock recorder for MockTokenRepository.
+type MockTokenRepositoryMockRecorder struct {
+	mock *MockTokenRepository
+}
+
+// NewMockTokenRepository creates a new mock instance.
+func NewMockTokenRepository(ctrl *gomock.Controller) *MockTokenRepository {
+	mock := &MockTokenRepository{ctrl: ctrl}
+	mock.recorder = &MockTokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenRepository) EXPECT() *MockTokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetByHash mocks base method.
+func (m *MockTokenRepository) GetByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", ctx, hash)
+	ret0, _ := ret[0].(*domain.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash.
+func (mr *MockTokenRepositoryMockRecorder) GetByHash(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockTokenRepository)(nil).GetByHash), ctx, hash)
+}
+
+// IsAccessRevoked mocks base method.
+func (m *MockTokenRepository) IsAccessRevoked(ctx context.Context, jti, subject string, issuedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAccessRevoked", ctx, jti, subject, issuedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsAccessRevoked indicates an expected call of IsAccessRevoked.
+func (mr *MockTokenRepositoryMockRecorder) IsAccessRevoked(ctx, jti, subject, issuedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAccessRevoked", reflect.TypeOf((*MockTokenRepository)(nil).IsAccessRevoked), ctx, jti, subject, issuedAt)
+}
+
+// RevokeAccess mocks base method.
+func (m *MockTokenRepository) RevokeAccess(ctx context.Context, token *domain.RevokedToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccess", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccess indicates an expected call of RevokeAccess.
+func (mr *MockTokenRepositoryMockRecorder) RevokeAccess(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccess", reflect.TypeOf((*MockTokenRepository)(nil).RevokeAccess), ctx, token)
+}
+
+// RevokeFamily mocks base method.
+func (m *MockTokenRepository) RevokeFamily(ctx context.Context, familyID primitive.ObjectID, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeFamily", ctx, familyID, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeFamily indicates an expected call of RevokeFamily.
+func (mr *MockTokenRepositoryMockRecorder) RevokeFamily(ctx, familyID, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeFamily", reflect.TypeOf((*MockTokenRepository)(nil).RevokeFamily), ctx, familyID, now)
+}
+
+// RevokeUser mocks base method.
+func (m *MockTokenRepository) RevokeUser(ctx context.Context, userID primitive.ObjectID, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUser", ctx, userID, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUser indicates an expected call of RevokeUser.
+func (mr *MockTokenRepositoryMockRecorder) RevokeUser(ctx, userID, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUser", reflect.TypeOf((*MockTokenRepository)(nil).RevokeUser), ctx, userID, now)
+}
+
+// Store mocks base method.
+func (m *MockTokenRepository) Store(ctx context.Context, token *domain.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store.
+func (mr *MockTokenRepositoryMockRecorder) Store(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockTokenRepository)(nil).Store), ctx, token)
+}
+
+// Use mocks base method.
+func (m *MockTokenRepository) Use(ctx context.Context, hash string, now time.Time) (*domain.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Use", ctx, hash, now)
+	ret0, _ := ret[0].(*domain.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Use indicates an expected call of Use.
+func (mr *MockTokenRepositoryMockRecorder) Use(ctx, hash, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Use", reflect.TypeOf((*MockTokenRepository)(nil).Use), ctx, hash, now)
+}
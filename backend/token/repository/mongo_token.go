@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+)
+
+const (
+	refreshTokenCollection = "refresh_token"
+	revokedTokenCollection = "revoked_token"
+)
+
+type mongoTokenRepository struct {
+	Conn   *mongo.Database
+	logger *zap.Logger
+	tracer trace.Tracer
+}
+
+// NewMongoTokenRepository will create an object that represent the token.Repository interface
+func NewMongoTokenRepository(c *mongo.Client, db string, logger *zap.Logger, tracer trace.Tracer) domain.TokenRepository {
+	return &mongoTokenRepository{
+		Conn:   c.Database(db),
+		logger: logger,
+		tracer: tracer,
+	}
+}
+
+func (m *mongoTokenRepository) Store(ctx context.Context, token *domain.RefreshToken) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository Store",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("userid", token.UserID.Hex())),
+	)
+	defer span.End()
+
+	_, err := m.Conn.Collection(refreshTokenCollection).InsertOne(ctx, token)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("refresh token store error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (m *mongoTokenRepository) GetByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository GetByHash",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "hash", Value: hash}}
+
+	token := new(domain.RefreshToken)
+	err := m.Conn.Collection(refreshTokenCollection).FindOne(ctx, filter).Decode(token)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.RecordError(domain.ErrNotFound)
+		return nil, fmt.Errorf("refresh token was not found: %w", domain.ErrNotFound)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("refresh token get error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return token, nil
+}
+
+// Use atomically marks token as used, only tokens which were neither used nor
+// revoked can be used, so concurrent requests can't rotate the same token twice
+func (m *mongoTokenRepository) Use(ctx context.Context, hash string, now time.Time) (*domain.RefreshToken, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository Use",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	filter := bson.D{
+		primitive.E{Key: "hash", Value: hash},
+		primitive.E{Key: "used_at", Value: nil},
+		primitive.E{Key: "revoked_at", Value: nil},
+	}
+	update := bson.D{primitive.E{Key: "$set", Value: bson.D{primitive.E{Key: "used_at", Value: now}}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	token := new(domain.RefreshToken)
+	err := m.Conn.Collection(refreshTokenCollection).FindOneAndUpdate(ctx, filter, update, opts).Decode(token)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.RecordError(domain.ErrNotFound)
+		return nil, fmt.Errorf("active refresh token was not found: %w", domain.ErrNotFound)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("refresh token use error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return token, nil
+}
+
+func (m *mongoTokenRepository) RevokeFamily(ctx context.Context, familyID primitive.ObjectID, now time.Time) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository RevokeFamily",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("familyid", familyID.Hex())),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "family_id", Value: familyID}}
+
+	if err := m.revoke(ctx, filter, now); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoTokenRepository) RevokeUser(ctx context.Context, userID primitive.ObjectID, now time.Time) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository RevokeUser",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("userid", userID.Hex())),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "user_id", Value: userID}}
+
+	if err := m.revoke(ctx, filter, now); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoTokenRepository) revoke(ctx context.Context, filter bson.D, now time.Time) error {
+	filter = append(filter, primitive.E{Key: "revoked_at", Value: nil})
+	update := bson.D{primitive.E{Key: "$set", Value: bson.D{primitive.E{Key: "revoked_at", Value: now}}}}
+
+	_, err := m.Conn.Collection(refreshTokenCollection).UpdateMany(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("refresh token revoke error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (m *mongoTokenRepository) RevokeAccess(ctx context.Context, token *domain.RevokedToken) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository RevokeAccess",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("tokenid", token.ID)),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "_id", Value: token.ID}}
+	opts := options.Replace().SetUpsert(true)
+
+	_, err := m.Conn.Collection(revokedTokenCollection).ReplaceOne(ctx, filter, token, opts)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("access token revoke error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// IsAccessRevoked reports whether the token was revoked by its id or by
+// revocation of all tokens of the subject at or after the given issue time.
+// Both times have milliseconds precision, so only tokens issued after
+// revocation, e.g. when user signs in again, are valid.
+func (m *mongoTokenRepository) IsAccessRevoked(ctx context.Context, jti, subject string, issuedAt time.Time) (bool, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository IsAccessRevoked",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("tokenid", jti),
+			attribute.String("userid", subject)),
+	)
+	defer span.End()
+
+	userID := "user:" + subject
+	ids := bson.A{userID}
+	if jti != "" {
+		ids = append(ids, jti)
+	}
+	filter := bson.D{primitive.E{Key: "_id", Value: bson.D{primitive.E{Key: "$in", Value: ids}}}}
+
+	cur, err := m.Conn.Collection(revokedTokenCollection).Find(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("revoked token get error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	list := make([]*domain.RevokedToken, 0)
+	if err = cur.All(ctx, &list); err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("can't decode revoked tokens: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	issuedAt = issuedAt.Truncate(time.Millisecond)
+	for _, t := range list {
+		if t.ID != userID || !issuedAt.After(t.RevokedAt) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,223 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/tests"
+	"github.com/semka95/shortener/backend/token/repository"
+)
+
+var tracer = sdktrace.NewTracerProvider().Tracer("")
+var noopCtx = context.Background()
+
+const tableName = "shortener.refresh_token"
+
+func refreshTokenBsonD(t *domain.RefreshToken) bson.D {
+	return bson.D{
+		{Key: "_id", Value: t.ID},
+		{Key: "hash", Value: t.Hash},
+		{Key: "user_id", Value: t.UserID},
+		{Key: "family_id", Value: t.FamilyID},
+		{Key: "expires_at", Value: t.ExpiresAt},
+		{Key: "created_at", Value: t.CreatedAt},
+		{Key: "used_at", Value: nil},
+		{Key: "revoked_at", Value: nil},
+	}
+}
+
+func TestMongoTokenRepository_Store(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tToken := tests.NewRefreshToken()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		r := repository.NewMongoTokenRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Store(noopCtx, tToken)
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   1,
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoTokenRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Store(noopCtx, tToken)
+
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoTokenRepository_GetByHash(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tToken := tests.NewRefreshToken()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, refreshTokenBsonD(tToken)))
+		r := repository.NewMongoTokenRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetByHash(noopCtx, tToken.Hash)
+
+		require.NoError(mt, err)
+		assert.EqualValues(mt, tToken, result)
+	})
+
+	mt.Run("not exists", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch))
+		r := repository.NewMongoTokenRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetByHash(noopCtx, tToken.Hash)
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrNotFound)
+	})
+}
+
+func TestMongoTokenRepository_Use(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tToken := tests.NewRefreshToken()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{
+			{Key: "ok", Value: 1},
+			{Key: "value", Value: refreshTokenBsonD(tToken)},
+		})
+		r := repository.NewMongoTokenRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.Use(noopCtx, tToken.Hash, time.Now())
+
+		require.NoError(mt, err)
+		assert.Equal(mt, tToken.FamilyID, result.FamilyID)
+	})
+
+	mt.Run("already used", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{
+			{Key: "ok", Value: 1},
+			{Key: "value", Value: nil},
+		})
+		r := repository.NewMongoTokenRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.Use(noopCtx, tToken.Hash, time.Now())
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrNotFound)
+	})
+}
+
+func TestMongoTokenRepository_Revoke(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tToken := tests.NewRefreshToken()
+
+	mt.Run("revoke family", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 2}, {Key: "nModified", Value: 2}})
+		r := repository.NewMongoTokenRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.RevokeFamily(noopCtx, tToken.FamilyID, time.Now())
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("revoke user", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoTokenRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.RevokeUser(noopCtx, tToken.UserID, time.Now())
+
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+
+	mt.Run("revoke access", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 0}})
+		r := repository.NewMongoTokenRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.RevokeAccess(noopCtx, &domain.RevokedToken{ID: "jti", RevokedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)})
+
+		require.NoError(mt, err)
+	})
+}
+
+func TestMongoTokenRepository_IsAccessRevoked(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	ns := "shortener.revoked_token"
+
+	mt.Run("revoked", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "_id", Value: "jti"}}))
+		r := repository.NewMongoTokenRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		revoked, err := r.IsAccessRevoked(noopCtx, "jti", "user", time.Now())
+
+		require.NoError(mt, err)
+		assert.True(mt, revoked)
+	})
+
+	mt.Run("not revoked", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch))
+		r := repository.NewMongoTokenRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		revoked, err := r.IsAccessRevoked(noopCtx, "jti", "user", time.Now())
+
+		require.NoError(mt, err)
+		assert.False(mt, revoked)
+	})
+
+	mt.Run("revoked user", func(mt *mtest.T) {
+		revokedAt := time.Date(2023, 3, 1, 12, 0, 0, 500*int(time.Millisecond), time.UTC)
+		cases := []struct {
+			description string
+			issuedAt    time.Time
+			revoked     bool
+		}{
+			{description: "issued before cutoff in the same second", issuedAt: revokedAt.Add(-300 * time.Millisecond), revoked: true},
+			{description: "issued at cutoff", issuedAt: revokedAt, revoked: true},
+			{description: "issued after cutoff in the same second", issuedAt: revokedAt.Add(time.Millisecond), revoked: false},
+			{description: "issued with seconds precision in cutoff second", issuedAt: revokedAt.Truncate(time.Second), revoked: true},
+		}
+
+		for _, tc := range cases {
+			mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+				{Key: "_id", Value: "user:user"},
+				{Key: "revoked_at", Value: revokedAt},
+				{Key: "expires_at", Value: revokedAt.Add(time.Hour)},
+			}))
+			r := repository.NewMongoTokenRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+			revoked, err := r.IsAccessRevoked(noopCtx, "jti", "user", tc.issuedAt)
+
+			require.NoError(mt, err, tc.description)
+			assert.Equal(mt, tc.revoked, revoked, tc.description)
+		}
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoTokenRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		_, err := r.IsAccessRevoked(noopCtx, "jti", "user", time.Now())
+
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
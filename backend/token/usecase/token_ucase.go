@@ -0,0 +1,301 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+type tokenUsecase struct {
+	tokenRepo      domain.TokenRepository
+	userRepo       domain.UserRepository
+	contextTimeout time.Duration
+	refreshTTL     time.Duration
+	logger         *zap.Logger
+	tracer         trace.Tracer
+}
+
+// NewTokenUsecase will create new a tokenUsecase object representation of token.Usecase interface
+func NewTokenUsecase(t domain.TokenRepository, u domain.UserRepository, timeout, refreshTTL time.Duration, logger *zap.Logger, tracer trace.Tracer) domain.TokenUsecase {
+	return &tokenUsecase{
+		tokenRepo:      t,
+		userRepo:       u,
+		contextTimeout: timeout,
+		refreshTTL:     refreshTTL,
+		logger:         logger,
+		tracer:         tracer,
+	}
+}
+
+// Issue creates refresh token for the user authenticated with the given claims,
+// it starts a new token family
+func (uc *tokenUsecase) Issue(c context.Context, claims *auth.Claims) (string, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Issue",
+		trace.WithAttributes(
+			attribute.String("userid", claims.Subject)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	userID, err := primitive.ObjectIDFromHex(claims.Subject)
+	if err != nil {
+		span.RecordError(err)
+		return "", fmt.Errorf("user ID is not valid ObjectID: %w: %s", domain.ErrBadParamInput, err.Error())
+	}
+
+	return uc.store(ctx, userID, primitive.NewObjectID(), time.Now())
+}
+
+// Refresh rotates refresh token and returns claims for the new access token.
+// Presenting already rotated or revoked token means it was leaked, so the
+// whole token family is revoked.
+func (uc *tokenUsecase) Refresh(c context.Context, now time.Time, refreshToken string) (*auth.Claims, string, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Refresh",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	hash := hashToken(refreshToken)
+	t, err := uc.tokenRepo.Use(ctx, hash, now)
+	if errors.Is(err, domain.ErrNotFound) {
+		uc.detectReuse(ctx, hash, now)
+		span.RecordError(err)
+		return nil, "", fmt.Errorf("refresh token is not valid: %w", domain.ErrAuthenticationFailure)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, "", err
+	}
+	span.SetAttributes(attribute.String("userid", t.UserID.Hex()))
+
+	if !t.ExpiresAt.After(now) {
+		err = fmt.Errorf("refresh token has expired: %w", domain.ErrAuthenticationFailure)
+		span.RecordError(err)
+		return nil, "", err
+	}
+
+	u, err := uc.userRepo.GetByID(ctx, t.UserID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, "", fmt.Errorf("%w: %s", domain.ErrAuthenticationFailure, err.Error())
+	}
+
+	if u.IsSuspended() {
+		err = fmt.Errorf("user %s is suspended: %w", u.ID.Hex(), domain.ErrForbidden)
+		span.RecordError(err)
+		return nil, "", err
+	}
+
+	newToken, err := uc.store(ctx, u.ID, t.FamilyID, now)
+	if err != nil {
+		span.RecordError(err)
+		return nil, "", err
+	}
+
+	claims := auth.NewClaims(u.ID.Hex(), u.Roles, now, auth.AccessTokenTTL)
+	return claims, newToken, nil
+}
+
+// Logout revokes access token with the given claims and, if provided,
+// refresh token family of the same session
+func (uc *tokenUsecase) Logout(c context.Context, refreshToken string, claims *auth.Claims) error {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Logout",
+		trace.WithAttributes(
+			attribute.String("userid", claims.Subject)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	now := time.Now()
+
+	if refreshToken != "" {
+		t, err := uc.tokenRepo.GetByHash(ctx, hashToken(refreshToken))
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			// nothing to revoke, logout is idempotent
+		case err != nil:
+			span.RecordError(err)
+			return err
+		case t.UserID.Hex() != claims.Subject:
+			span.RecordError(domain.ErrForbidden)
+			return domain.ErrForbidden
+		default:
+			if err = uc.tokenRepo.RevokeFamily(ctx, t.FamilyID, now); err != nil {
+				span.RecordError(err)
+				return err
+			}
+		}
+	}
+
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+
+	err := uc.tokenRepo.RevokeAccess(ctx, &domain.RevokedToken{
+		ID:        claims.ID,
+		RevokedAt: now.UTC(),
+		ExpiresAt: claims.ExpiresAt.Time,
+	})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// LogoutAll revokes all refresh and access tokens of the user
+func (uc *tokenUsecase) LogoutAll(c context.Context, claims *auth.Claims) error {
+	return uc.RevokeAll(c, claims.Subject)
+}
+
+// RevokeAll revokes all refresh and access tokens of the user with the given
+// id, e.g. when user is suspended or their roles are changed
+func (uc *tokenUsecase) RevokeAll(c context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase RevokeAll",
+		trace.WithAttributes(
+			attribute.String("userid", id)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	userID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("user ID is not valid ObjectID: %w: %s", domain.ErrBadParamInput, err.Error())
+	}
+
+	now := time.Now()
+	if err = uc.tokenRepo.RevokeUser(ctx, userID, now); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	// cutoff has milliseconds precision like issue time of tokens, access
+	// tokens live no longer than AccessTokenTTL, so cutoff can be dropped after it
+	revokedAt := now.Truncate(time.Millisecond).UTC()
+	err = uc.tokenRepo.RevokeAccess(ctx, &domain.RevokedToken{
+		ID:        "user:" + id,
+		RevokedAt: revokedAt,
+		ExpiresAt: revokedAt.Add(auth.AccessTokenTTL),
+	})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// CheckRevoked returns error if access token with the given claims was revoked,
+// it is used as auth.RevocationCheckFunc
+func (uc *tokenUsecase) CheckRevoked(c context.Context, claims *auth.Claims) error {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase CheckRevoked",
+		trace.WithAttributes(
+			attribute.String("userid", claims.Subject)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	revoked, err := uc.tokenRepo.IsAccessRevoked(ctx, claims.ID, claims.Subject, claims.IssuedTime())
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if revoked {
+		err = fmt.Errorf("access token was revoked: %w", domain.ErrAuthenticationFailure)
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// detectReuse revokes token family if the token was already rotated or revoked
+func (uc *tokenUsecase) detectReuse(ctx context.Context, hash string, now time.Time) {
+	t, err := uc.tokenRepo.GetByHash(ctx, hash)
+	if err != nil {
+		return
+	}
+
+	uc.logger.Warn("refresh token reuse detected, revoking token family",
+		zap.String("userid", t.UserID.Hex()), zap.String("familyid", t.FamilyID.Hex()))
+
+	if err = uc.tokenRepo.RevokeFamily(ctx, t.FamilyID, now); err != nil {
+		uc.logger.Error("can't revoke refresh token family: ", zap.Error(err))
+	}
+}
+
+func (uc *tokenUsecase) store(ctx context.Context, userID, familyID primitive.ObjectID, now time.Time) (string, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", fmt.Errorf("can't generate refresh token: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	t := &domain.RefreshToken{
+		ID:        primitive.NewObjectID(),
+		Hash:      hashToken(token),
+		UserID:    userID,
+		FamilyID:  familyID,
+		ExpiresAt: now.Add(uc.refreshTTL).Truncate(time.Millisecond).UTC(),
+		CreatedAt: now.Truncate(time.Millisecond).UTC(),
+	}
+
+	if err = uc.tokenRepo.Store(ctx, t); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,301 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/tests"
+	"github.com/semka95/shortener/backend/token/mock"
+	"github.com/semka95/shortener/backend/token/usecase"
+	userMock "github.com/semka95/shortener/backend/user/mock"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+var tracer = sdktrace.NewTracerProvider().Tracer("")
+
+func TestTokenUsecase_Issue(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tUser := tests.NewUser()
+	repository := mock.NewMockTokenRepository(controller)
+	uc := usecase.NewTokenUsecase(repository, nil, 10*time.Second, time.Hour, zap.NewNop(), tracer)
+
+	t.Run("success", func(t *testing.T) {
+		claims := auth.NewClaims(tUser.ID.Hex(), tUser.Roles, time.Now(), time.Minute)
+		var stored *domain.RefreshToken
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, rt *domain.RefreshToken) error {
+			stored = rt
+			return nil
+		})
+
+		token, err := uc.Issue(context.Background(), claims)
+		require.NoError(t, err)
+		assert.NotEmpty(t, token)
+		assert.NotEqual(t, token, stored.Hash)
+		assert.Equal(t, tUser.ID, stored.UserID)
+		assert.False(t, stored.FamilyID.IsZero())
+		assert.WithinDuration(t, time.Now().Add(time.Hour), stored.ExpiresAt, time.Minute)
+	})
+
+	t.Run("wrong user id", func(t *testing.T) {
+		claims := auth.NewClaims("wrong id", tUser.Roles, time.Now(), time.Minute)
+
+		_, err := uc.Issue(context.Background(), claims)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		claims := auth.NewClaims(tUser.ID.Hex(), tUser.Roles, time.Now(), time.Minute)
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(domain.ErrInternalServerError)
+
+		_, err := uc.Issue(context.Background(), claims)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestTokenUsecase_Refresh(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tUser := tests.NewUser()
+	tToken := tests.NewRefreshToken()
+	now := time.Now()
+
+	repository := mock.NewMockTokenRepository(controller)
+	userRepository := userMock.NewMockUserRepository(controller)
+	uc := usecase.NewTokenUsecase(repository, userRepository, 10*time.Second, time.Hour, zap.NewNop(), tracer)
+
+	t.Run("success", func(t *testing.T) {
+		repository.EXPECT().Use(gomock.Any(), tToken.Hash, now).Return(tToken, nil)
+		userRepository.EXPECT().GetByID(gomock.Any(), tUser.ID).Return(tUser, nil)
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, rt *domain.RefreshToken) error {
+			assert.Equal(t, tToken.FamilyID, rt.FamilyID)
+			assert.NotEqual(t, tToken.Hash, rt.Hash)
+			return nil
+		})
+
+		claims, token, err := uc.Refresh(context.Background(), now, "test")
+		require.NoError(t, err)
+		assert.NotEmpty(t, token)
+		assert.Equal(t, tUser.ID.Hex(), claims.Subject)
+		assert.Equal(t, tUser.Roles, claims.Roles)
+	})
+
+	t.Run("reuse of rotated token revokes family", func(t *testing.T) {
+		used := tests.NewRefreshToken()
+		used.UsedAt = &now
+		repository.EXPECT().Use(gomock.Any(), tToken.Hash, now).Return(nil, domain.ErrNotFound)
+		repository.EXPECT().GetByHash(gomock.Any(), tToken.Hash).Return(used, nil)
+		repository.EXPECT().RevokeFamily(gomock.Any(), used.FamilyID, now).Return(nil)
+
+		_, _, err := uc.Refresh(context.Background(), now, "test")
+		assert.ErrorIs(t, err, domain.ErrAuthenticationFailure)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		repository.EXPECT().Use(gomock.Any(), tToken.Hash, now).Return(nil, domain.ErrNotFound)
+		repository.EXPECT().GetByHash(gomock.Any(), tToken.Hash).Return(nil, domain.ErrNotFound)
+
+		_, _, err := uc.Refresh(context.Background(), now, "test")
+		assert.ErrorIs(t, err, domain.ErrAuthenticationFailure)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		expired := tests.NewRefreshToken()
+		expired.ExpiresAt = now.Add(-time.Minute)
+		repository.EXPECT().Use(gomock.Any(), tToken.Hash, now).Return(expired, nil)
+
+		_, _, err := uc.Refresh(context.Background(), now, "test")
+		assert.ErrorIs(t, err, domain.ErrAuthenticationFailure)
+	})
+
+	t.Run("suspended user", func(t *testing.T) {
+		suspended := tests.NewUser()
+		suspended.SuspendedAt = &now
+		repository.EXPECT().Use(gomock.Any(), tToken.Hash, now).Return(tToken, nil)
+		userRepository.EXPECT().GetByID(gomock.Any(), tUser.ID).Return(suspended, nil)
+
+		_, _, err := uc.Refresh(context.Background(), now, "test")
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		repository.EXPECT().Use(gomock.Any(), tToken.Hash, now).Return(tToken, nil)
+		userRepository.EXPECT().GetByID(gomock.Any(), tUser.ID).Return(nil, domain.ErrNotFound)
+
+		_, _, err := uc.Refresh(context.Background(), now, "test")
+		assert.ErrorIs(t, err, domain.ErrAuthenticationFailure)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repository.EXPECT().Use(gomock.Any(), tToken.Hash, now).Return(nil, domain.ErrInternalServerError)
+
+		_, _, err := uc.Refresh(context.Background(), now, "test")
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestTokenUsecase_Logout(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tUser := tests.NewUser()
+	tToken := tests.NewRefreshToken()
+	claims := auth.NewClaims(tUser.ID.Hex(), tUser.Roles, time.Now(), time.Minute)
+
+	repository := mock.NewMockTokenRepository(controller)
+	uc := usecase.NewTokenUsecase(repository, nil, 10*time.Second, time.Hour, zap.NewNop(), tracer)
+
+	t.Run("success", func(t *testing.T) {
+		repository.EXPECT().GetByHash(gomock.Any(), tToken.Hash).Return(tToken, nil)
+		repository.EXPECT().RevokeFamily(gomock.Any(), tToken.FamilyID, gomock.Any()).Return(nil)
+		repository.EXPECT().RevokeAccess(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, rt *domain.RevokedToken) error {
+			assert.Equal(t, claims.ID, rt.ID)
+			assert.Equal(t, claims.ExpiresAt.Time, rt.ExpiresAt)
+			return nil
+		})
+
+		err := uc.Logout(context.Background(), "test", claims)
+		require.NoError(t, err)
+	})
+
+	t.Run("without refresh token", func(t *testing.T) {
+		repository.EXPECT().RevokeAccess(gomock.Any(), gomock.Any()).Return(nil)
+
+		err := uc.Logout(context.Background(), "", claims)
+		require.NoError(t, err)
+	})
+
+	t.Run("unknown refresh token", func(t *testing.T) {
+		repository.EXPECT().GetByHash(gomock.Any(), tToken.Hash).Return(nil, domain.ErrNotFound)
+		repository.EXPECT().RevokeAccess(gomock.Any(), gomock.Any()).Return(nil)
+
+		err := uc.Logout(context.Background(), "test", claims)
+		require.NoError(t, err)
+	})
+
+	t.Run("refresh token of another user", func(t *testing.T) {
+		other := auth.NewClaims("507f191e810c19729de860eb", tUser.Roles, time.Now(), time.Minute)
+		repository.EXPECT().GetByHash(gomock.Any(), tToken.Hash).Return(tToken, nil)
+
+		err := uc.Logout(context.Background(), "test", other)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repository.EXPECT().RevokeAccess(gomock.Any(), gomock.Any()).Return(domain.ErrInternalServerError)
+
+		err := uc.Logout(context.Background(), "", claims)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestTokenUsecase_LogoutAll(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tUser := tests.NewUser()
+	claims := auth.NewClaims(tUser.ID.Hex(), tUser.Roles, time.Now(), time.Minute)
+
+	repository := mock.NewMockTokenRepository(controller)
+	uc := usecase.NewTokenUsecase(repository, nil, 10*time.Second, time.Hour, zap.NewNop(), tracer)
+
+	t.Run("success", func(t *testing.T) {
+		repository.EXPECT().RevokeUser(gomock.Any(), tUser.ID, gomock.Any()).Return(nil)
+		repository.EXPECT().RevokeAccess(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, rt *domain.RevokedToken) error {
+			assert.Equal(t, "user:"+tUser.ID.Hex(), rt.ID)
+			assert.False(t, rt.RevokedAt.Before(claims.IssuedAt.Time))
+			assert.Equal(t, rt.RevokedAt.Add(auth.AccessTokenTTL), rt.ExpiresAt)
+			return nil
+		})
+
+		err := uc.LogoutAll(context.Background(), claims)
+		require.NoError(t, err)
+	})
+
+	t.Run("cutoff has milliseconds precision", func(t *testing.T) {
+		repository.EXPECT().RevokeUser(gomock.Any(), tUser.ID, gomock.Any()).Return(nil)
+		repository.EXPECT().RevokeAccess(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, rt *domain.RevokedToken) error {
+			assert.Equal(t, rt.RevokedAt.Truncate(time.Millisecond), rt.RevokedAt)
+			assert.False(t, rt.RevokedAt.Before(claims.IssuedTime()))
+			return nil
+		})
+
+		err := uc.LogoutAll(context.Background(), claims)
+		require.NoError(t, err)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repository.EXPECT().RevokeUser(gomock.Any(), tUser.ID, gomock.Any()).Return(domain.ErrInternalServerError)
+
+		err := uc.LogoutAll(context.Background(), claims)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestTokenUsecase_RevokeAll(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tUser := tests.NewUser()
+
+	repository := mock.NewMockTokenRepository(controller)
+	uc := usecase.NewTokenUsecase(repository, nil, 10*time.Second, time.Hour, zap.NewNop(), tracer)
+
+	t.Run("success", func(t *testing.T) {
+		repository.EXPECT().RevokeUser(gomock.Any(), tUser.ID, gomock.Any()).Return(nil)
+		repository.EXPECT().RevokeAccess(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, rt *domain.RevokedToken) error {
+			assert.Equal(t, "user:"+tUser.ID.Hex(), rt.ID)
+			return nil
+		})
+
+		err := uc.RevokeAll(context.Background(), tUser.ID.Hex())
+		require.NoError(t, err)
+	})
+
+	t.Run("wrong user id", func(t *testing.T) {
+		err := uc.RevokeAll(context.Background(), "wrong id")
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	})
+}
+
+func TestTokenUsecase_CheckRevoked(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tUser := tests.NewUser()
+	claims := auth.NewClaims(tUser.ID.Hex(), tUser.Roles, time.Now(), time.Minute)
+
+	repository := mock.NewMockTokenRepository(controller)
+	uc := usecase.NewTokenUsecase(repository, nil, 10*time.Second, time.Hour, zap.NewNop(), tracer)
+
+	t.Run("not revoked", func(t *testing.T) {
+		repository.EXPECT().IsAccessRevoked(gomock.Any(), claims.ID, claims.Subject, claims.IssuedTime()).Return(false, nil)
+
+		err := uc.CheckRevoked(context.Background(), claims)
+		require.NoError(t, err)
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		repository.EXPECT().IsAccessRevoked(gomock.Any(), claims.ID, claims.Subject, claims.IssuedTime()).Return(true, nil)
+
+		err := uc.CheckRevoked(context.Background(), claims)
+		assert.ErrorIs(t, err, domain.ErrAuthenticationFailure)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repository.EXPECT().IsAccessRevoked(gomock.Any(), claims.ID, claims.Subject, claims.IssuedTime()).Return(false, domain.ErrInternalServerError)
+
+		err := uc.CheckRevoked(context.Background(), claims)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+	})
+}
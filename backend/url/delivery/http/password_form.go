@@ -0,0 +1,130 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/semka95/shortener/backend/domain"
+)
+
+var passwordFormTemplate = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>Protected link</title>
+</head>
+<body>
+<h1>This link is protected</h1>
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+<form method="post" action="/{{.ID}}{{if .Confirmed}}?{{.Confirm}}=true{{end}}">
+<label for="password">Password</label>
+<input type="password" id="password" name="password" required autofocus>
+<button type="submit">Open</button>
+</form>
+</body>
+</html>
+`))
+
+// wantsHTML reports whether request came from browser rather than API client
+func wantsHTML(c echo.Context) bool {
+	return strings.Contains(c.Request().Header.Get(echo.HeaderAccept), echo.MIMETextHTML)
+}
+
+// passwordChallenge responds with password form to browsers and with JSON
+// error to API clients, msg is shown in the form if not empty. Confirmation
+// of flagged link is passed on to Unlock by the form.
+func (uh *URLHandler) passwordChallenge(c echo.Context, id string, status int, msg string) error {
+	c.Response().Header().Set(echo.HeaderCacheControl, "private, no-store")
+
+	if !wantsHTML(c) {
+		if msg == "" {
+			msg = domain.ErrPasswordRequired.Error()
+		}
+		return c.JSON(status, domain.ResponseError{Error: msg})
+	}
+
+	buf := new(bytes.Buffer)
+	err := passwordFormTemplate.Execute(buf, struct {
+		ID        string
+		Error     string
+		Confirmed bool
+		Confirm   string
+	}{ID: id, Error: msg, Confirmed: isConfirmed(c), Confirm: confirmParam})
+	if err != nil {
+		return err
+	}
+
+	return c.HTMLBlob(status, buf.Bytes())
+}
+
+// Unlock will redirect to password protected link if the right password is
+// posted, flagged link must be confirmed on the warning page like on Redirect
+func (uh *URLHandler) Unlock(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := uh.tracer.Start(
+		ctx,
+		"http Unlock",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	unlock := new(domain.URLUnlock)
+	if err := c.Bind(unlock); err != nil {
+		span.RecordError(err)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
+	}
+	unlock.Host = requestHost(c)
+
+	if err := c.Validate(unlock); err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(uh.validator.Translator)
+		if _, ok := fields["URLUnlock.password"]; ok && len(fields) == 1 {
+			return uh.passwordChallenge(c, unlock.ID, http.StatusUnauthorized, fields["URLUnlock.password"])
+		}
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	u, err := uh.urlUsecase.Unlock(ctx, *unlock)
+	switch {
+	case errors.Is(err, domain.ErrAuthenticationFailure):
+		span.RecordError(err)
+		return uh.passwordChallenge(c, unlock.ID, http.StatusUnauthorized, "wrong password")
+	case errors.Is(err, domain.ErrTooManyRequests):
+		span.RecordError(err)
+		return uh.passwordChallenge(c, unlock.ID, http.StatusTooManyRequests, domain.ErrTooManyRequests.Error())
+	case err != nil:
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(attribute.String("urlid", u.ID))
+
+	if u.Flagged && !isConfirmed(c) {
+		return uh.flaggedWarning(c, u)
+	}
+
+	u, err = uh.consume(ctx, c, u)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	// 303 makes browser follow the link with GET, whatever redirect type of URL is
+	c.Response().Header().Set(echo.HeaderCacheControl, "private, no-store")
+	span.SetStatus(codes.Ok, "success")
+	return c.Redirect(http.StatusSeeOther, u.Link)
+}
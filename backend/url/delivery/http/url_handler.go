@@ -3,8 +3,10 @@ package http
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"regexp"
+	"time"
 
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
@@ -24,6 +26,7 @@ import (
 // URLHandler represent the http handler for url
 type URLHandler struct {
 	urlUsecase    domain.URLUsecase
+	clickUsecase  domain.ClickUsecase
 	authenticator *auth.Authenticator
 	validator     *web.AppValidator
 	logger        *zap.Logger
@@ -31,9 +34,10 @@ type URLHandler struct {
 }
 
 // NewURLHandler will initialize the url/ resources endpoint
-func NewURLHandler(us domain.URLUsecase, authenticator *auth.Authenticator, v *web.AppValidator, logger *zap.Logger, tracer trace.Tracer) (*URLHandler, error) {
+func NewURLHandler(us domain.URLUsecase, cs domain.ClickUsecase, authenticator *auth.Authenticator, v *web.AppValidator, logger *zap.Logger, tracer trace.Tracer) (*URLHandler, error) {
 	handler := &URLHandler{
 		urlUsecase:    us,
+		clickUsecase:  cs,
 		authenticator: authenticator,
 		validator:     v,
 		logger:        logger,
@@ -48,13 +52,25 @@ func NewURLHandler(us domain.URLUsecase, authenticator *auth.Authenticator, v *w
 	return handler, nil
 }
 
-// RegisterRoutes registers routes for a path with matching handler
+// RegisterRoutes registers routes for a path with matching handler, routes
+// using scoped config are also available to API keys with the scope
 func (uh *URLHandler) RegisterRoutes(e *echo.Echo) {
+	optionalRead := uh.authenticator.ScopedJWTConfig(auth.ScopeURLRead)
+	optionalRead.Skipper = uh.authenticator.OptionalJWTConfig.Skipper
+
 	e.POST("/v1/url/create", uh.Store)
-	e.POST("/v1/user/url/create", uh.StoreUserURL, echojwt.WithConfig(uh.authenticator.JWTConfig))
+	e.POST("/v1/user/url/create", uh.StoreUserURL, echojwt.WithConfig(uh.authenticator.ScopedJWTConfig(auth.ScopeURLCreate)))
+	e.POST("/v1/user/url/bulk", uh.BulkStore, echojwt.WithConfig(uh.authenticator.ScopedJWTConfig(auth.ScopeURLCreate)))
+	e.POST("/v1/user/url/bulk/delete", uh.BulkDelete, echojwt.WithConfig(uh.authenticator.JWTConfig))
+	e.GET("/v1/user/url", uh.List, echojwt.WithConfig(uh.authenticator.ScopedJWTConfig(auth.ScopeURLRead)))
 	e.GET("/:id", uh.Redirect)
-	e.GET("/v1/url/:id", uh.GetByID)
+	e.POST("/:id", uh.Unlock)
+	e.GET("/v1/url/:id", uh.GetByID, echojwt.WithConfig(optionalRead))
+	e.GET("/v1/url/:id/stats", uh.Stats, echojwt.WithConfig(uh.authenticator.ScopedJWTConfig(auth.ScopeStatsRead)))
+	e.GET("/v1/url/:id/history", uh.History, echojwt.WithConfig(uh.authenticator.JWTConfig))
+	e.POST("/v1/url/:id/rollback", uh.Rollback, echojwt.WithConfig(uh.authenticator.JWTConfig))
 	e.DELETE("/v1/url/:id", uh.Delete, echojwt.WithConfig(uh.authenticator.JWTConfig))
+	e.POST("/v1/url/:id/restore", uh.Restore, echojwt.WithConfig(uh.authenticator.JWTConfig))
 	e.PUT("/v1/url", uh.Update, echojwt.WithConfig(uh.authenticator.JWTConfig))
 
 }
@@ -76,7 +92,17 @@ func (uh *URLHandler) RegisterValidation() error {
 		return err
 	}
 
-	return nil
+	err = uh.validator.V.RegisterValidation("urlid", checkURLID)
+	if err != nil {
+		return err
+	}
+
+	return uh.validator.V.RegisterTranslation("urlid", uh.validator.Translator, func(ut ut.Translator) error {
+		return ut.Add("urlid", "{0} must contain only a-z, A-Z, 0-9, _, - characters", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("urlid", fe.Field())
+		return t
+	})
 }
 
 func checkURL(fl validator.FieldLevel) bool {
@@ -84,7 +110,14 @@ func checkURL(fl validator.FieldLevel) bool {
 	return r.MatchString(fl.Field().String())
 }
 
-// Redirect will redirect to link by given id
+// checkURLID accepts linkid and ids of custom domain URLs, which are used
+// only to manage URLs, not to open them
+func checkURLID(fl validator.FieldLevel) bool {
+	r := regexp.MustCompile(`^~?[A-Za-z0-9_-]+$`)
+	return r.MatchString(fl.Field().String())
+}
+
+// Redirect will redirect to link by given id, on custom domains id is slug of URL
 func (uh *URLHandler) Redirect(c echo.Context) error {
 	ctx := c.Request().Context()
 	if ctx == nil {
@@ -97,19 +130,113 @@ func (uh *URLHandler) Redirect(c echo.Context) error {
 	)
 	defer span.End()
 
-	u, err := uh.getByID(ctx, c)
+	u, err := uh.resolve(ctx, c)
 	if err != nil {
 		span.RecordError(err)
 		return err
 	}
 
 	if u != nil {
+		if u.Flagged && !isConfirmed(c) {
+			return uh.flaggedWarning(c, u)
+		}
+
+		if u.IsProtected() {
+			return uh.passwordChallenge(c, u.ShortID(), http.StatusUnauthorized, "")
+		}
+
+		u, err = uh.consume(ctx, c, u)
+		if err != nil {
+			span.RecordError(err)
+			return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
+		}
+
+		setRedirectCacheHeaders(c, u, time.Now())
 		span.SetStatus(codes.Ok, "success")
-		return c.Redirect(http.StatusMovedPermanently, u.Link)
+		return c.Redirect(redirectStatus(u), u.Link)
 	}
 	return nil
 }
 
+// resolve returns URL opened by id on host of request
+func (uh *URLHandler) resolve(ctx context.Context, c echo.Context) (*domain.URL, error) {
+	id := c.Param("id")
+
+	err := uh.validator.V.Var(id, "required,linkid,max=20")
+	if err != nil {
+		fields := err.(validator.ValidationErrors).Translate(uh.validator.Translator)
+		return nil, c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	u, err := uh.urlUsecase.Resolve(ctx, requestHost(c), id)
+	if err != nil {
+		return nil, c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	return u, nil
+}
+
+// requestHost returns host name of request without port
+func requestHost(c echo.Context) string {
+	host := c.Request().Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return host
+}
+
+// consume uses one redirect of click limited URL and records the click
+func (uh *URLHandler) consume(ctx context.Context, c echo.Context, u *domain.URL) (*domain.URL, error) {
+	u, err := uh.urlUsecase.Consume(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	uh.clickUsecase.Record(ctx, &domain.Click{
+		URLID:     u.ID,
+		OwnerID:   u.UserID,
+		Timestamp: time.Now().UTC(),
+		Referrer:  c.Request().Referer(),
+		UserAgent: c.Request().UserAgent(),
+		IP:        net.ParseIP(c.RealIP()),
+	})
+
+	return u, nil
+}
+
+// maxRedirectCacheAge limits how long clients can cache permanent redirects,
+// so link changes eventually reach them
+const maxRedirectCacheAge = 24 * time.Hour
+
+func redirectStatus(u *domain.URL) int {
+	if !domain.ValidRedirectType(u.RedirectType) {
+		return http.StatusMovedPermanently
+	}
+	return u.RedirectType
+}
+
+// setRedirectCacheHeaders allows caching of permanent redirects until link
+// expiration, temporary, click limited and flagged redirects must reach server
+// every time
+func setRedirectCacheHeaders(c echo.Context, u *domain.URL, now time.Time) {
+	h := c.Response().Header()
+	if !u.IsPermanentRedirect() || u.IsClickLimited() || u.Flagged {
+		h.Set(echo.HeaderCacheControl, "private, no-store")
+		return
+	}
+
+	maxAge := u.ExpirationDate.Sub(now)
+	if maxAge > maxRedirectCacheAge {
+		maxAge = maxRedirectCacheAge
+	}
+	if maxAge < 0 {
+		maxAge = 0
+	}
+
+	h.Set(echo.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	h.Set("Expires", now.Add(maxAge).UTC().Format(http.TimeFormat))
+}
+
 // GetByID will get url by given id
 func (uh *URLHandler) GetByID(c echo.Context) error {
 	ctx := c.Request().Context()
@@ -123,20 +250,32 @@ func (uh *URLHandler) GetByID(c echo.Context) error {
 	)
 	defer span.End()
 
-	u, err := uh.getByID(ctx, c)
+	// authentication is optional here, claims are used to show expired URL to its owner
+	var user *auth.Claims
+	if token, ok := c.Get("user").(*jwt.Token); ok && token != nil {
+		user, _ = token.Claims.(*auth.Claims)
+	}
+
+	u, err := uh.getByID(ctx, c, user)
 	if err != nil {
 		span.RecordError(err)
 		return err
 	}
 
 	if u != nil {
+		// link of protected URL is shown only to its owner, members of its
+		// workspace and users allowed to read any URL
+		if u.IsProtected() && uh.urlUsecase.Authorize(ctx, u, user, auth.ActionURLRead) != nil {
+			return uh.passwordChallenge(c, u.ID, http.StatusUnauthorized, "")
+		}
+
 		span.SetStatus(codes.Ok, "success")
 		return c.JSON(http.StatusOK, u)
 	}
 	return nil
 }
 
-func (uh *URLHandler) getByID(ctx context.Context, c echo.Context) (*domain.URL, error) {
+func (uh *URLHandler) getByID(ctx context.Context, c echo.Context, user *auth.Claims) (*domain.URL, error) {
 	id := c.Param("id")
 
 	ctx, span := uh.tracer.Start(
@@ -146,14 +285,14 @@ func (uh *URLHandler) getByID(ctx context.Context, c echo.Context) (*domain.URL,
 	)
 	defer span.End()
 
-	err := uh.validator.V.Var(id, "required,linkid,max=20")
+	err := uh.validator.V.Var(id, "required,urlid,max=21")
 	if err != nil {
 		span.RecordError(err)
 		fields := err.(validator.ValidationErrors).Translate(uh.validator.Translator)
 		return nil, c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
 	}
 
-	u, err := uh.urlUsecase.GetByID(ctx, id)
+	u, err := uh.urlUsecase.GetByID(ctx, id, user)
 	if err != nil {
 		span.RecordError(err)
 		return nil, c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
@@ -165,6 +304,105 @@ func (uh *URLHandler) getByID(ctx context.Context, c echo.Context) (*domain.URL,
 	return u, nil
 }
 
+// Stats will get click statistics of URL by given id and query params
+func (uh *URLHandler) Stats(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := uh.tracer.Start(
+		ctx,
+		"http Stats",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	q := new(domain.StatsQuery)
+	if err := c.Bind(q); err != nil {
+		span.RecordError(err)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
+	}
+
+	if err := c.Validate(q); err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(uh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok || token == nil {
+		span.RecordError(domain.ErrForbidden)
+		return c.JSON(http.StatusForbidden, domain.ResponseError{Error: domain.ErrForbidden.Error()})
+	}
+	user, ok := token.Claims.(*auth.Claims)
+	if !ok {
+		span.RecordError(domain.ErrInternalServerError)
+		return fmt.Errorf("%w can't convert jwt.Claims to auth.Claims", domain.ErrInternalServerError)
+	}
+
+	stats, err := uh.clickUsecase.Stats(ctx, *q, user)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("userid", user.ID),
+		attribute.String("urlid", q.URLID),
+	)
+
+	return c.JSON(http.StatusOK, stats)
+}
+
+// List will get URLs of authenticated user by given query params
+func (uh *URLHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := uh.tracer.Start(
+		ctx,
+		"http List",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	q := new(domain.URLListQuery)
+	if err := c.Bind(q); err != nil {
+		span.RecordError(err)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
+	}
+
+	if err := c.Validate(q); err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(uh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok || token == nil {
+		span.RecordError(domain.ErrForbidden)
+		return c.JSON(http.StatusForbidden, domain.ResponseError{Error: domain.ErrForbidden.Error()})
+	}
+	user, ok := token.Claims.(*auth.Claims)
+	if !ok {
+		span.RecordError(domain.ErrInternalServerError)
+		return fmt.Errorf("%w can't convert jwt.Claims to auth.Claims", domain.ErrInternalServerError)
+	}
+
+	list, err := uh.urlUsecase.List(ctx, *q, user)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("userid", user.ID),
+	)
+
+	return c.JSON(http.StatusOK, list)
+}
+
 // Store will store the URL by given request body
 func (uh *URLHandler) Store(c echo.Context) error {
 	ctx := c.Request().Context()
@@ -263,7 +501,7 @@ func (uh *URLHandler) Delete(c echo.Context) error {
 	)
 	defer span.End()
 
-	err := uh.validator.V.Var(id, "required,linkid,max=20")
+	err := uh.validator.V.Var(id, "required,urlid,max=21")
 	if err != nil {
 		span.RecordError(err)
 		fields := err.(validator.ValidationErrors).Translate(uh.validator.Translator)
@@ -291,7 +529,54 @@ func (uh *URLHandler) Delete(c echo.Context) error {
 		attribute.String("urlid", id),
 	)
 
-	return c.JSON(http.StatusNoContent, nil)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Restore will restore deleted URL by given id
+func (uh *URLHandler) Restore(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := uh.tracer.Start(
+		ctx,
+		"http Restore",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	err := uh.validator.V.Var(id, "required,urlid,max=21")
+	if err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(uh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok || token == nil {
+		span.RecordError(domain.ErrForbidden)
+		return c.JSON(http.StatusForbidden, domain.ResponseError{Error: domain.ErrForbidden.Error()})
+	}
+	user, ok := token.Claims.(*auth.Claims)
+	if !ok {
+		span.RecordError(domain.ErrInternalServerError)
+		return fmt.Errorf("%w can't convert jwt.Claims to auth.Claims", domain.ErrInternalServerError)
+	}
+
+	u, err := uh.urlUsecase.Restore(ctx, id, user)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("userid", user.ID),
+		attribute.String("urlid", id),
+	)
+
+	return c.JSON(http.StatusOK, u)
 }
 
 // Update will update the URL by given request body
@@ -340,5 +625,245 @@ func (uh *URLHandler) Update(c echo.Context) error {
 		attribute.String("urlid", u.ID),
 	)
 
-	return c.JSON(http.StatusNoContent, nil)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// History will get revisions of URL by given id
+func (uh *URLHandler) History(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := uh.tracer.Start(
+		ctx,
+		"http History",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	err := uh.validator.V.Var(id, "required,urlid,max=21")
+	if err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(uh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok || token == nil {
+		span.RecordError(domain.ErrForbidden)
+		return c.JSON(http.StatusForbidden, domain.ResponseError{Error: domain.ErrForbidden.Error()})
+	}
+	user, ok := token.Claims.(*auth.Claims)
+	if !ok {
+		span.RecordError(domain.ErrInternalServerError)
+		return fmt.Errorf("%w can't convert jwt.Claims to auth.Claims", domain.ErrInternalServerError)
+	}
+
+	revisions, err := uh.urlUsecase.History(ctx, id, user)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("userid", user.ID),
+		attribute.String("urlid", id),
+	)
+
+	return c.JSON(http.StatusOK, revisions)
+}
+
+// Rollback will restore URL to the revision given in request body
+func (uh *URLHandler) Rollback(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := uh.tracer.Start(
+		ctx,
+		"http Rollback",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	r := new(domain.URLRollback)
+	if err := c.Bind(r); err != nil {
+		span.RecordError(err)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
+	}
+
+	if err := c.Validate(r); err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(uh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok || token == nil {
+		span.RecordError(domain.ErrForbidden)
+		return c.JSON(http.StatusForbidden, domain.ResponseError{Error: domain.ErrForbidden.Error()})
+	}
+	user, ok := token.Claims.(*auth.Claims)
+	if !ok {
+		span.RecordError(domain.ErrInternalServerError)
+		return fmt.Errorf("%w can't convert jwt.Claims to auth.Claims", domain.ErrInternalServerError)
+	}
+
+	u, err := uh.urlUsecase.Rollback(ctx, *r, user)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("userid", user.ID),
+		attribute.String("urlid", r.URLID),
+	)
+
+	return c.JSON(http.StatusOK, u)
+}
+
+// BulkStore will store URLs of authenticated user by given request body,
+// each item is validated and stored independently
+func (uh *URLHandler) BulkStore(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := uh.tracer.Start(
+		ctx,
+		"http BulkStore",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	b := new(domain.BulkCreateURL)
+	if err := c.Bind(b); err != nil {
+		span.RecordError(err)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
+	}
+
+	if err := c.Validate(b); err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(uh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok || token == nil {
+		span.RecordError(domain.ErrForbidden)
+		return c.JSON(http.StatusForbidden, domain.ResponseError{Error: domain.ErrForbidden.Error()})
+	}
+	user, ok := token.Claims.(*auth.Claims)
+	if !ok {
+		span.RecordError(domain.ErrInternalServerError)
+		return fmt.Errorf("%w can't convert jwt.Claims to auth.Claims", domain.ErrInternalServerError)
+	}
+
+	results := make([]domain.BulkResult, len(b.Items))
+	valid := make([]domain.CreateURL, 0, len(b.Items))
+	positions := make([]int, 0, len(b.Items))
+	for i := range b.Items {
+		item := b.Items[i]
+		item.UserID = user.Subject
+		if err := c.Validate(&item); err != nil {
+			results[i] = domain.BulkResult{
+				Index:  i,
+				Status: http.StatusBadRequest,
+				Error:  "validation error",
+				Fields: err.(validator.ValidationErrors).Translate(uh.validator.Translator),
+			}
+			continue
+		}
+		valid = append(valid, item)
+		positions = append(positions, i)
+	}
+
+	if len(valid) > 0 {
+		stored, err := uh.urlUsecase.BulkStore(ctx, valid)
+		if err != nil {
+			span.RecordError(err)
+			return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
+		}
+		for j, r := range stored {
+			r.Index = positions[j]
+			results[r.Index] = uh.bulkResult(r, http.StatusCreated)
+		}
+	}
+
+	span.SetAttributes(
+		attribute.String("userid", user.ID),
+		attribute.Int("urls", len(b.Items)),
+	)
+
+	return c.JSON(http.StatusOK, domain.BulkResponse{Results: results})
+}
+
+// BulkDelete will delete URLs by given ids
+func (uh *URLHandler) BulkDelete(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := uh.tracer.Start(
+		ctx,
+		"http BulkDelete",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	b := new(domain.BulkDeleteURL)
+	if err := c.Bind(b); err != nil {
+		span.RecordError(err)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
+	}
+
+	if err := c.Validate(b); err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(uh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok || token == nil {
+		span.RecordError(domain.ErrForbidden)
+		return c.JSON(http.StatusForbidden, domain.ResponseError{Error: domain.ErrForbidden.Error()})
+	}
+	user, ok := token.Claims.(*auth.Claims)
+	if !ok {
+		span.RecordError(domain.ErrInternalServerError)
+		return fmt.Errorf("%w can't convert jwt.Claims to auth.Claims", domain.ErrInternalServerError)
+	}
+
+	deleted, err := uh.urlUsecase.BulkDelete(ctx, b.IDs, user)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	results := make([]domain.BulkResult, len(deleted))
+	for i, r := range deleted {
+		results[i] = uh.bulkResult(r, http.StatusNoContent)
+	}
+
+	span.SetAttributes(
+		attribute.String("userid", user.ID),
+		attribute.Int("urls", len(b.IDs)),
+	)
+
+	return c.JSON(http.StatusOK, domain.BulkResponse{Results: results})
+}
+
+// bulkResult sets status and error message of bulk operation item result
+func (uh *URLHandler) bulkResult(r domain.BulkResult, successStatus int) domain.BulkResult {
+	if r.Err == nil {
+		r.Status = successStatus
+		return r
+	}
+
+	r.Status = domain.GetStatusCode(r.Err, uh.logger)
+	r.Error = r.Err.Error()
+	return r
 }
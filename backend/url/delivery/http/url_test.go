@@ -2,9 +2,11 @@ package http_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,6 +18,7 @@ import (
 	"github.com/stretchr/testify/require"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 
+	clickMock "github.com/semka95/shortener/backend/click/mock"
 	"github.com/semka95/shortener/backend/domain"
 	"github.com/semka95/shortener/backend/tests"
 	urlHttp "github.com/semka95/shortener/backend/url/delivery/http"
@@ -31,12 +34,13 @@ func TestURLHTTP(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
 	uc := mock.NewMockURLUsecase(controller)
+	cuc := clickMock.NewMockClickUsecase(controller)
 
 	tracer := sdktrace.NewTracerProvider().Tracer("")
 	v, err := web.NewAppValidator()
 	require.NoError(t, err)
 
-	handler, err := urlHttp.NewURLHandler(uc, nil, v, nil, tracer)
+	handler, err := urlHttp.NewURLHandler(uc, cuc, nil, v, nil, tracer)
 	require.NoError(t, err)
 
 	e := echo.New()
@@ -46,18 +50,67 @@ func TestURLHTTP(t *testing.T) {
 
 	// Test URLHandler.GetByID and Redirect
 	tURL := tests.NewURL()
+	tExpiredURL := tests.NewURL()
+	tExpiredURL.ExpirationDate = time.Now().Add(-time.Hour).Truncate(time.Millisecond).UTC()
+	tExpiredURL.Expired = true
+	tLimitedURL := tests.NewURL()
+	tLimitedURL.MaxClicks = 1
+	tLimitedURL.RemainingClicks = 1
+	tProtectedURL := tests.NewURL()
+	tProtectedURL.HashedPassword = "$2a$10$hashedpassword"
+	tFlaggedURL := tests.NewURL()
+	tFlaggedURL.Flagged = true
+	tFlaggedURL.FlagReason = "link domain is blocked"
+	tDomainURL := tests.NewURL()
+	tDomainURL.Domain = "go.example.com"
+	tDomainURL.Slug = "promo"
+	tDomainURL.ID = domain.CustomDomainURLID(tDomainURL.Domain, tDomainURL.Slug)
+	tDomainURL.HashedPassword = "$2a$10$hashedpassword"
 
 	casesGet := []struct {
 		description   string
 		mockCalls     func(muc *mock.MockURLUsecase)
 		param         string
+		query         string
+		accept        string
+		host          string
+		auth          bool
 		handler       func(t *testing.T, c echo.Context)
 		checkResponse func(rec *httptest.ResponseRecorder)
 	}{
 		{
 			description: "Redirect success",
 			mockCalls: func(muc *mock.MockURLUsecase) {
-				uc.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+				uc.EXPECT().Resolve(gomock.Any(), "example.com", tURL.ID).Return(tURL, nil)
+				uc.EXPECT().Consume(gomock.Any(), tURL).Return(tURL, nil)
+				cuc.EXPECT().Record(gomock.Any(), gomock.Any()).Do(func(_ context.Context, click *domain.Click) {
+					assert.Equal(t, tURL.ID, click.URLID)
+					assert.Equal(t, tURL.UserID, click.OwnerID)
+					assert.Equal(t, "https://www.example.com/", click.Referrer)
+					assert.Equal(t, "test-agent", click.UserAgent)
+					assert.Equal(t, "192.0.2.1", click.IP.String())
+				})
+			},
+			param: tURL.ID,
+			handler: func(t *testing.T, c echo.Context) {
+				err = handler.Redirect(c)
+				require.NoError(t, err)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, tURL.Link, rec.Header().Get("Location"))
+				assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+				assert.Regexp(t, `^public, max-age=35\d\d$`, rec.Header().Get(echo.HeaderCacheControl))
+				assert.NotEmpty(t, rec.Header().Get("Expires"))
+			},
+		},
+		{
+			description: "Redirect temporary",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				tempURL := tests.NewURL()
+				tempURL.RedirectType = http.StatusTemporaryRedirect
+				uc.EXPECT().Resolve(gomock.Any(), "example.com", tURL.ID).Return(tempURL, nil)
+				uc.EXPECT().Consume(gomock.Any(), tempURL).Return(tempURL, nil)
+				cuc.EXPECT().Record(gomock.Any(), gomock.Any())
 			},
 			param: tURL.ID,
 			handler: func(t *testing.T, c echo.Context) {
@@ -66,13 +119,51 @@ func TestURLHTTP(t *testing.T) {
 			},
 			checkResponse: func(rec *httptest.ResponseRecorder) {
 				assert.Equal(t, tURL.Link, rec.Header().Get("Location"))
+				assert.Equal(t, http.StatusTemporaryRedirect, rec.Code)
+				assert.Equal(t, "private, no-store", rec.Header().Get(echo.HeaderCacheControl))
+				assert.Empty(t, rec.Header().Get("Expires"))
+			},
+		},
+		{
+			description: "Redirect click limited",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Resolve(gomock.Any(), "example.com", tLimitedURL.ID).Return(tLimitedURL, nil)
+				consumed := *tLimitedURL
+				consumed.RemainingClicks = 0
+				uc.EXPECT().Consume(gomock.Any(), tLimitedURL).Return(&consumed, nil)
+				cuc.EXPECT().Record(gomock.Any(), gomock.Any())
+			},
+			param: tLimitedURL.ID,
+			handler: func(t *testing.T, c echo.Context) {
+				err = handler.Redirect(c)
+				require.NoError(t, err)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, tLimitedURL.Link, rec.Header().Get("Location"))
 				assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+				assert.Equal(t, "private, no-store", rec.Header().Get(echo.HeaderCacheControl))
+			},
+		},
+		{
+			description: "Redirect click limit reached",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Resolve(gomock.Any(), "example.com", tLimitedURL.ID).Return(tLimitedURL, nil)
+				uc.EXPECT().Consume(gomock.Any(), tLimitedURL).Return(nil, domain.ErrGone)
+			},
+			param: tLimitedURL.ID,
+			handler: func(t *testing.T, c echo.Context) {
+				err = handler.Redirect(c)
+				require.NoError(t, err)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Empty(t, rec.Header().Get("Location"))
+				assert.Equal(t, http.StatusGone, rec.Code)
 			},
 		},
 		{
 			description: "Redirect not found",
 			mockCalls: func(muc *mock.MockURLUsecase) {
-				uc.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(nil, domain.ErrNotFound)
+				uc.EXPECT().Resolve(gomock.Any(), "example.com", tURL.ID).Return(nil, domain.ErrNotFound)
 			},
 			param: tURL.ID,
 			handler: func(t *testing.T, c echo.Context) {
@@ -87,6 +178,24 @@ func TestURLHTTP(t *testing.T) {
 				assert.Equal(t, http.StatusNotFound, rec.Code)
 			},
 		},
+		{
+			description: "Redirect expired",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Resolve(gomock.Any(), "example.com", tURL.ID).Return(nil, domain.ErrGone)
+			},
+			param: tURL.ID,
+			handler: func(t *testing.T, c echo.Context) {
+				err = handler.Redirect(c)
+				require.NoError(t, err)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, domain.ErrGone.Error(), body.Error)
+				assert.Equal(t, http.StatusGone, rec.Code)
+			},
+		},
 		{
 			description: "Redirect validation error",
 			mockCalls:   func(muc *mock.MockURLUsecase) {},
@@ -107,7 +216,7 @@ func TestURLHTTP(t *testing.T) {
 		{
 			description: "GetByID success",
 			mockCalls: func(muc *mock.MockURLUsecase) {
-				uc.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+				uc.EXPECT().GetByID(gomock.Any(), tURL.ID, nil).Return(tURL, nil)
 			},
 			param: tURL.ID,
 			handler: func(t *testing.T, c echo.Context) {
@@ -122,18 +231,214 @@ func TestURLHTTP(t *testing.T) {
 				assert.Equal(t, http.StatusOK, rec.Code)
 			},
 		},
+		{
+			description: "GetByID expired by owner",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().GetByID(gomock.Any(), tExpiredURL.ID, claims).Return(tExpiredURL, nil)
+			},
+			param: tExpiredURL.ID,
+			auth:  true,
+			handler: func(t *testing.T, c echo.Context) {
+				err = handler.GetByID(c)
+				require.NoError(t, err)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.URL)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.True(t, body.Expired)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			description: "GetByID expired",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().GetByID(gomock.Any(), tExpiredURL.ID, nil).Return(nil, domain.ErrGone)
+			},
+			param: tExpiredURL.ID,
+			handler: func(t *testing.T, c echo.Context) {
+				err = handler.GetByID(c)
+				require.NoError(t, err)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, domain.ErrGone.Error(), body.Error)
+				assert.Equal(t, http.StatusGone, rec.Code)
+			},
+		},
+		{
+			description: "Redirect protected",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Resolve(gomock.Any(), "example.com", tProtectedURL.ID).Return(tProtectedURL, nil)
+			},
+			param: tProtectedURL.ID,
+			handler: func(t *testing.T, c echo.Context) {
+				err = handler.Redirect(c)
+				require.NoError(t, err)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, domain.ErrPasswordRequired.Error(), body.Error)
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+				assert.Empty(t, rec.Header().Get("Location"))
+				assert.Equal(t, "private, no-store", rec.Header().Get(echo.HeaderCacheControl))
+			},
+		},
+		{
+			description: "Redirect flagged",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Resolve(gomock.Any(), "example.com", tFlaggedURL.ID).Return(tFlaggedURL, nil)
+			},
+			param: tFlaggedURL.ID,
+			handler: func(t *testing.T, c echo.Context) {
+				err = handler.Redirect(c)
+				require.NoError(t, err)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, tFlaggedURL.FlagReason, body.Error)
+				assert.Equal(t, http.StatusForbidden, rec.Code)
+				assert.Empty(t, rec.Header().Get("Location"))
+				assert.Equal(t, "private, no-store", rec.Header().Get(echo.HeaderCacheControl))
+			},
+		},
+		{
+			description: "Redirect flagged html",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Resolve(gomock.Any(), "example.com", tFlaggedURL.ID).Return(tFlaggedURL, nil)
+			},
+			param:  tFlaggedURL.ID,
+			accept: "text/html,application/xhtml+xml",
+			handler: func(t *testing.T, c echo.Context) {
+				err = handler.Redirect(c)
+				require.NoError(t, err)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Contains(t, rec.Header().Get(echo.HeaderContentType), echo.MIMETextHTML)
+				assert.Contains(t, rec.Body.String(), tFlaggedURL.FlagReason)
+				assert.Contains(t, rec.Body.String(), `href="/`+tFlaggedURL.ID+`?confirm=true"`)
+				assert.Empty(t, rec.Header().Get("Location"))
+			},
+		},
+		{
+			description: "Redirect flagged confirmed",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Resolve(gomock.Any(), "example.com", tFlaggedURL.ID).Return(tFlaggedURL, nil)
+				uc.EXPECT().Consume(gomock.Any(), tFlaggedURL).Return(tFlaggedURL, nil)
+				cuc.EXPECT().Record(gomock.Any(), gomock.Any())
+			},
+			param: tFlaggedURL.ID,
+			query: "?confirm=true",
+			handler: func(t *testing.T, c echo.Context) {
+				err = handler.Redirect(c)
+				require.NoError(t, err)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, tFlaggedURL.Link, rec.Header().Get("Location"))
+				assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+				assert.Equal(t, "private, no-store", rec.Header().Get(echo.HeaderCacheControl))
+			},
+		},
+		{
+			description: "GetByID protected by another user",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().GetByID(gomock.Any(), tProtectedURL.ID, nil).Return(tProtectedURL, nil)
+				uc.EXPECT().Authorize(gomock.Any(), tProtectedURL, nil, auth.ActionURLRead).Return(domain.ErrForbidden)
+			},
+			param: tProtectedURL.ID,
+			handler: func(t *testing.T, c echo.Context) {
+				err = handler.GetByID(c)
+				require.NoError(t, err)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.NotContains(t, rec.Body.String(), tProtectedURL.Link)
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			},
+		},
+		{
+			description: "GetByID protected by owner",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().GetByID(gomock.Any(), tProtectedURL.ID, claims).Return(tProtectedURL, nil)
+				uc.EXPECT().Authorize(gomock.Any(), tProtectedURL, claims, auth.ActionURLRead).Return(nil)
+			},
+			param: tProtectedURL.ID,
+			auth:  true,
+			handler: func(t *testing.T, c echo.Context) {
+				err = handler.GetByID(c)
+				require.NoError(t, err)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.URL)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, tProtectedURL.Link, body.Link)
+				assert.NotContains(t, rec.Body.String(), tProtectedURL.HashedPassword)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			description: "Redirect protected on custom domain",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Resolve(gomock.Any(), "go.example.com", "promo").Return(tDomainURL, nil)
+			},
+			param:  "promo",
+			accept: "text/html",
+			host:   "go.example.com:443",
+			handler: func(t *testing.T, c echo.Context) {
+				err = handler.Redirect(c)
+				require.NoError(t, err)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Contains(t, rec.Body.String(), `action="/promo"`)
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			},
+		},
+		{
+			description: "Redirect custom domain URL id on own host",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			param:       tDomainURL.ID,
+			handler: func(t *testing.T, c echo.Context) {
+				err = handler.Redirect(c)
+				require.NoError(t, err)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, "validation error", body.Error)
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
 	}
 
 	for _, tc := range casesGet {
 		t.Run(tc.description, func(t *testing.T) {
 			tc.mockCalls(uc)
-			req = httptest.NewRequest(echo.GET, "/"+tc.param, nil)
+			req = httptest.NewRequest(echo.GET, "/"+tc.param+tc.query, nil)
+			req.Header.Set("Referer", "https://www.example.com/")
+			req.Header.Set("User-Agent", "test-agent")
+			if tc.accept != "" {
+				req.Header.Set(echo.HeaderAccept, tc.accept)
+			}
+			if tc.host != "" {
+				req.Host = tc.host
+			}
 
 			rec := httptest.NewRecorder()
 			c.Reset(req, rec)
 			c.SetPath("/:id")
 			c.SetParamNames("id")
 			c.SetParamValues(tc.param)
+			if tc.auth {
+				c.Set("user", token)
+			}
 
 			tc.handler(t, c)
 
@@ -154,6 +459,10 @@ func TestURLHTTP(t *testing.T) {
 	require.NoError(t, err)
 	tCreateURLBadIDB, err := json.Marshal(tCreateURLBadID)
 	require.NoError(t, err)
+	tCreateURLDomainID := tests.NewCreateURL()
+	tCreateURLDomainID.ID = tests.StringPointer(tDomainURL.ID)
+	tCreateURLDomainIDB, err := json.Marshal(tCreateURLDomainID)
+	require.NoError(t, err)
 	createUserURLB, err := json.Marshal(tCreateUserURL)
 	require.NoError(t, err)
 
@@ -257,6 +566,24 @@ func TestURLHTTP(t *testing.T) {
 				assert.Equal(t, http.StatusBadRequest, rec.Code)
 			},
 		},
+		{
+			description: "Store custom domain URL id",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			reqBody:     bytes.NewBuffer(tCreateURLDomainIDB),
+			auth:        false,
+			handler: func(t *testing.T, c echo.Context) {
+				err = handler.Store(c)
+				require.NoError(t, err)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, "validation error", body.Error)
+				assert.Equal(t, "id must contain only a-z, A-Z, 0-9, _, - characters", body.Fields["CreateURL.id"])
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
 		{
 			description: "Store bad request data",
 			mockCalls:   func(muc *mock.MockURLUsecase) {},
@@ -380,6 +707,65 @@ func TestURLHTTP(t *testing.T) {
 		})
 	}
 
+	// Test URLHandler.Restore
+	casesRestore := []struct {
+		description   string
+		mockCalls     func(muc *mock.MockURLUsecase)
+		id            string
+		checkResponse func(rec *httptest.ResponseRecorder)
+	}{
+		{
+			description: "Restore success",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Restore(gomock.Any(), tURL.ID, claims).Return(tURL, nil)
+			},
+			id: tURL.ID,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.URL)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, tURL.ID, body.ID)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			description: "Restore after grace period",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Restore(gomock.Any(), tURL.ID, claims).Return(nil, domain.ErrGone)
+			},
+			id: tURL.ID,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusGone, rec.Code)
+			},
+		},
+		{
+			description: "Restore validation error",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			id:          "te!t",
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+	}
+	for _, tc := range casesRestore {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls(uc)
+			req = httptest.NewRequest(echo.POST, "/"+tc.id+"/restore", nil)
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+			c.SetPath("/v1/url/:id/restore")
+			c.SetParamNames("id")
+			c.SetParamValues(tc.id)
+			c.Set("user", token)
+
+			err = handler.Restore(c)
+			require.NoError(t, err)
+
+			tc.checkResponse(rec)
+		})
+	}
+
 	// Test URLHandler.Update
 	tUpdateURL := tests.NewUpdateURL()
 	tUpdateURLBadID := tests.NewUpdateURL()
@@ -483,25 +869,763 @@ func TestURLHTTP(t *testing.T) {
 		})
 	}
 
-	// Test validation for models.CreateURL and models.UpdateURL structs
-	casesCreateURL := []struct {
-		description string
-		fieldName   string
-		data        domain.CreateURL
-		want        string
+	// Test URLHandler.BulkStore
+	tBulkItem := tests.NewCreateURL()
+	tBulkItem.UserID = claims.Subject
+
+	casesBulkStore := []struct {
+		description   string
+		mockCalls     func(muc *mock.MockURLUsecase)
+		reqBody       string
+		auth          bool
+		checkResponse func(rec *httptest.ResponseRecorder)
 	}{
 		{
-			description: "validate CreateURL id not valid format",
-			fieldName:   "CreateURL.id",
-			data:        domain.CreateURL{ID: tests.StringPointer("test1/,!")},
-			want:        "id must contain only a-z, A-Z, 0-9, _, - characters",
-		},
-		{
-			description: "validate CreateURL id too short",
-			fieldName:   "CreateURL.id",
-			data:        domain.CreateURL{ID: tests.StringPointer("tes")},
-			want:        "id must be at least 7 characters in length",
-		},
+			description: "BulkStore success with invalid items",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().BulkStore(gomock.Any(), []domain.CreateURL{tBulkItem, tBulkItem}).Return([]domain.BulkResult{
+					{Index: 0, ID: *tBulkItem.ID},
+					{Index: 1, ID: *tBulkItem.ID, Err: domain.ErrConflict},
+				}, nil)
+			},
+			reqBody: `{"items":[` +
+				`{"id":"test123","link":"http://www.example.org","expiration_date":"` + tBulkItem.ExpirationDate.Format(time.RFC3339Nano) + `"},` +
+				`{"link":"not url"},` +
+				`{"id":"test123","link":"http://www.example.org","expiration_date":"` + tBulkItem.ExpirationDate.Format(time.RFC3339Nano) + `"}]}`,
+			auth: true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.BulkResponse)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				require.Len(t, body.Results, 3)
+				assert.Equal(t, http.StatusCreated, body.Results[0].Status)
+				assert.Equal(t, *tBulkItem.ID, body.Results[0].ID)
+				assert.Equal(t, 1, body.Results[1].Index)
+				assert.Equal(t, http.StatusBadRequest, body.Results[1].Status)
+				assert.Equal(t, "link must be a valid URL", body.Results[1].Fields["CreateURL.link"])
+				assert.Equal(t, 2, body.Results[2].Index)
+				assert.Equal(t, http.StatusConflict, body.Results[2].Status)
+				assert.Equal(t, domain.ErrConflict.Error(), body.Results[2].Error)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			description: "BulkStore all items invalid",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			reqBody:     `{"items":[{"link":"not url"}]}`,
+			auth:        true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.BulkResponse)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				require.Len(t, body.Results, 1)
+				assert.Equal(t, http.StatusBadRequest, body.Results[0].Status)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			description: "BulkStore no items",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			reqBody:     `{"items":[]}`,
+			auth:        true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, "items must contain at least 1 item", body.Fields["BulkCreateURL.items"])
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			description: "BulkStore not authorized",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			reqBody:     `{"items":[{"link":"http://www.example.org"}]}`,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+	}
+
+	for _, tc := range casesBulkStore {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls(uc)
+			req = httptest.NewRequest(echo.POST, "/v1/user/url/bulk", strings.NewReader(tc.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+			c.SetPath("/v1/user/url/bulk")
+			if tc.auth {
+				c.Set("user", token)
+			}
+
+			err = handler.BulkStore(c)
+			require.NoError(t, err)
+
+			tc.checkResponse(rec)
+		})
+	}
+
+	// Test URLHandler.BulkDelete
+	casesBulkDelete := []struct {
+		description   string
+		mockCalls     func(muc *mock.MockURLUsecase)
+		reqBody       string
+		auth          bool
+		checkResponse func(rec *httptest.ResponseRecorder)
+	}{
+		{
+			description: "BulkDelete success",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().BulkDelete(gomock.Any(), []string{"test123", "test456"}, claims).Return([]domain.BulkResult{
+					{Index: 0, ID: "test123"},
+					{Index: 1, ID: "test456", Err: domain.ErrForbidden},
+				}, nil)
+			},
+			reqBody: `{"ids":["test123","test456"]}`,
+			auth:    true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.BulkResponse)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				require.Len(t, body.Results, 2)
+				assert.Equal(t, http.StatusNoContent, body.Results[0].Status)
+				assert.Equal(t, http.StatusForbidden, body.Results[1].Status)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			description: "BulkDelete validation error",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			reqBody:     `{"ids":["te!t"]}`,
+			auth:        true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, "ids[0] must contain only a-z, A-Z, 0-9, _, - characters", body.Fields["BulkDeleteURL.ids[0]"])
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			description: "BulkDelete not authorized",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			reqBody:     `{"ids":["test123"]}`,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+	}
+
+	for _, tc := range casesBulkDelete {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls(uc)
+			req = httptest.NewRequest(echo.POST, "/v1/user/url/bulk/delete", strings.NewReader(tc.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+			c.SetPath("/v1/user/url/bulk/delete")
+			if tc.auth {
+				c.Set("user", token)
+			}
+
+			err = handler.BulkDelete(c)
+			require.NoError(t, err)
+
+			tc.checkResponse(rec)
+		})
+	}
+
+	// Test URLHandler.History
+	tRevision := tests.NewURLRevision()
+
+	casesHistory := []struct {
+		description   string
+		mockCalls     func(muc *mock.MockURLUsecase)
+		param         string
+		auth          bool
+		checkResponse func(rec *httptest.ResponseRecorder)
+	}{
+		{
+			description: "History success",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().History(gomock.Any(), tURL.ID, claims).Return([]*domain.URLRevision{tRevision}, nil)
+			},
+			param: tURL.ID,
+			auth:  true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := make([]*domain.URLRevision, 0)
+				err = json.NewDecoder(rec.Body).Decode(&body)
+				require.NoError(t, err)
+				require.Len(t, body, 1)
+				assert.Equal(t, tRevision.ID, body[0].ID)
+				assert.Equal(t, tRevision.Link, body[0].Link)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			description: "History not authorized",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			param:       tURL.ID,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			description: "History wrong user",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().History(gomock.Any(), tURL.ID, claims).Return(nil, domain.ErrForbidden)
+			},
+			param: tURL.ID,
+			auth:  true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, domain.ErrForbidden.Error(), body.Error)
+				assert.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			description: "History validation error",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			param:       "te!t",
+			auth:        true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+	}
+
+	for _, tc := range casesHistory {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls(uc)
+			req = httptest.NewRequest(echo.GET, "/v1/url/"+tc.param+"/history", nil)
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+			c.SetPath("/v1/url/:id/history")
+			c.SetParamNames("id")
+			c.SetParamValues(tc.param)
+			if tc.auth {
+				c.Set("user", token)
+			}
+
+			err = handler.History(c)
+			require.NoError(t, err)
+
+			tc.checkResponse(rec)
+		})
+	}
+
+	// Test URLHandler.Rollback
+	tRollback := domain.URLRollback{URLID: tURL.ID, RevisionID: tRevision.ID.Hex()}
+	tRolledBackURL := tests.NewURL()
+	tRolledBackURL.Link = tRevision.Link
+
+	casesRollback := []struct {
+		description   string
+		mockCalls     func(muc *mock.MockURLUsecase)
+		param         string
+		reqBody       string
+		auth          bool
+		checkResponse func(rec *httptest.ResponseRecorder)
+	}{
+		{
+			description: "Rollback success",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Rollback(gomock.Any(), tRollback, claims).Return(tRolledBackURL, nil)
+			},
+			param:   tURL.ID,
+			reqBody: `{"revision_id":"` + tRevision.ID.Hex() + `"}`,
+			auth:    true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.URL)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, tRevision.Link, body.Link)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			description: "Rollback revision not found",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Rollback(gomock.Any(), tRollback, claims).Return(nil, domain.ErrNotFound)
+			},
+			param:   tURL.ID,
+			reqBody: `{"revision_id":"` + tRevision.ID.Hex() + `"}`,
+			auth:    true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, rec.Code)
+			},
+		},
+		{
+			description: "Rollback not authorized",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			param:       tURL.ID,
+			reqBody:     `{"revision_id":"` + tRevision.ID.Hex() + `"}`,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			description: "Rollback validation error",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			param:       tURL.ID,
+			reqBody:     `{"revision_id":"not-an-id"}`,
+			auth:        true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, "validation error", body.Error)
+				assert.Equal(t, "revision_id must be 24 characters in length", body.Fields["URLRollback.revision_id"])
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+	}
+
+	for _, tc := range casesRollback {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls(uc)
+			req = httptest.NewRequest(echo.POST, "/v1/url/"+tc.param+"/rollback", strings.NewReader(tc.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+			c.SetPath("/v1/url/:id/rollback")
+			c.SetParamNames("id")
+			c.SetParamValues(tc.param)
+			if tc.auth {
+				c.Set("user", token)
+			}
+
+			err = handler.Rollback(c)
+			require.NoError(t, err)
+
+			tc.checkResponse(rec)
+		})
+	}
+
+	// Test URLHandler.Unlock
+	tUnlock := domain.URLUnlock{ID: tProtectedURL.ID, Host: "example.com", Password: "secret"}
+	tFlaggedProtectedURL := *tProtectedURL
+	tFlaggedProtectedURL.Flagged = true
+	tFlaggedProtectedURL.FlagReason = "link domain is blocked"
+
+	casesUnlock := []struct {
+		description   string
+		mockCalls     func(muc *mock.MockURLUsecase)
+		query         string
+		contentType   string
+		accept        string
+		reqBody       string
+		checkResponse func(rec *httptest.ResponseRecorder)
+	}{
+		{
+			description: "Unlock success with form",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Unlock(gomock.Any(), tUnlock).Return(tProtectedURL, nil)
+				uc.EXPECT().Consume(gomock.Any(), tProtectedURL).Return(tProtectedURL, nil)
+				cuc.EXPECT().Record(gomock.Any(), gomock.Any())
+			},
+			contentType: echo.MIMEApplicationForm,
+			accept:      echo.MIMETextHTML,
+			reqBody:     "password=secret",
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, tProtectedURL.Link, rec.Header().Get("Location"))
+				assert.Equal(t, http.StatusSeeOther, rec.Code)
+				assert.Equal(t, "private, no-store", rec.Header().Get(echo.HeaderCacheControl))
+			},
+		},
+		{
+			description: "Unlock success with JSON",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Unlock(gomock.Any(), tUnlock).Return(tProtectedURL, nil)
+				uc.EXPECT().Consume(gomock.Any(), tProtectedURL).Return(tProtectedURL, nil)
+				cuc.EXPECT().Record(gomock.Any(), gomock.Any())
+			},
+			contentType: echo.MIMEApplicationJSON,
+			reqBody:     `{"password":"secret"}`,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, tProtectedURL.Link, rec.Header().Get("Location"))
+				assert.Equal(t, http.StatusSeeOther, rec.Code)
+			},
+		},
+		{
+			description: "Unlock wrong password with form",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Unlock(gomock.Any(), tUnlock).Return(nil, domain.ErrAuthenticationFailure)
+			},
+			contentType: echo.MIMEApplicationForm,
+			accept:      "text/html,application/xhtml+xml",
+			reqBody:     "password=secret",
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+				assert.Contains(t, rec.Header().Get(echo.HeaderContentType), echo.MIMETextHTML)
+				assert.Contains(t, rec.Body.String(), "wrong password")
+				assert.Contains(t, rec.Body.String(), `action="/`+tProtectedURL.ID+`"`)
+				assert.Empty(t, rec.Header().Get("Location"))
+			},
+		},
+		{
+			description: "Unlock wrong password with JSON",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Unlock(gomock.Any(), tUnlock).Return(nil, domain.ErrAuthenticationFailure)
+			},
+			contentType: echo.MIMEApplicationJSON,
+			reqBody:     `{"password":"secret"}`,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, "wrong password", body.Error)
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			},
+		},
+		{
+			description: "Unlock too many attempts",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Unlock(gomock.Any(), tUnlock).Return(nil, domain.ErrTooManyRequests)
+			},
+			contentType: echo.MIMEApplicationJSON,
+			reqBody:     `{"password":"secret"}`,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, domain.ErrTooManyRequests.Error(), body.Error)
+				assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+			},
+		},
+		{
+			description: "Unlock url not found",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Unlock(gomock.Any(), tUnlock).Return(nil, domain.ErrNotFound)
+			},
+			contentType: echo.MIMEApplicationJSON,
+			reqBody:     `{"password":"secret"}`,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, rec.Code)
+			},
+		},
+		{
+			description: "Unlock flagged link without confirmation",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Unlock(gomock.Any(), tUnlock).Return(&tFlaggedProtectedURL, nil)
+			},
+			contentType: echo.MIMEApplicationForm,
+			accept:      echo.MIMETextHTML,
+			reqBody:     "password=secret",
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Contains(t, rec.Body.String(), "link domain is blocked")
+				assert.Empty(t, rec.Header().Get("Location"))
+			},
+		},
+		{
+			description: "Unlock confirmed flagged link",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Unlock(gomock.Any(), tUnlock).Return(&tFlaggedProtectedURL, nil)
+				uc.EXPECT().Consume(gomock.Any(), &tFlaggedProtectedURL).Return(&tFlaggedProtectedURL, nil)
+				cuc.EXPECT().Record(gomock.Any(), gomock.Any())
+			},
+			query:       "?confirm=true",
+			contentType: echo.MIMEApplicationForm,
+			accept:      echo.MIMETextHTML,
+			reqBody:     "password=secret",
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, tFlaggedProtectedURL.Link, rec.Header().Get("Location"))
+				assert.Equal(t, http.StatusSeeOther, rec.Code)
+			},
+		},
+		{
+			description: "Unlock wrong password keeps confirmation",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				uc.EXPECT().Unlock(gomock.Any(), tUnlock).Return(nil, domain.ErrAuthenticationFailure)
+			},
+			query:       "?confirm=true",
+			contentType: echo.MIMEApplicationForm,
+			accept:      echo.MIMETextHTML,
+			reqBody:     "password=secret",
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+				assert.Contains(t, rec.Body.String(), `action="/`+tProtectedURL.ID+`?confirm=true"`)
+			},
+		},
+		{
+			description: "Unlock blank password",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			contentType: echo.MIMEApplicationForm,
+			accept:      echo.MIMETextHTML,
+			reqBody:     "password=",
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+				assert.Contains(t, rec.Body.String(), "password is a required field")
+			},
+		},
+	}
+
+	for _, tc := range casesUnlock {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls(uc)
+			req = httptest.NewRequest(echo.POST, "/"+tProtectedURL.ID+tc.query, strings.NewReader(tc.reqBody))
+			req.Header.Set(echo.HeaderContentType, tc.contentType)
+			if tc.accept != "" {
+				req.Header.Set(echo.HeaderAccept, tc.accept)
+			}
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+			c.SetPath("/:id")
+			c.SetParamNames("id")
+			c.SetParamValues(tProtectedURL.ID)
+
+			err = handler.Unlock(c)
+			require.NoError(t, err)
+
+			tc.checkResponse(rec)
+		})
+	}
+
+	// Test URLHandler.Stats
+	tStats := &domain.ClickStats{URLID: tURL.ID, Interval: domain.StatsIntervalHour, TotalClicks: 3, UniqueVisitors: 2}
+	from := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	casesStats := []struct {
+		description   string
+		mockCalls     func(muc *mock.MockURLUsecase)
+		param         string
+		query         string
+		auth          bool
+		checkResponse func(rec *httptest.ResponseRecorder)
+	}{
+		{
+			description: "Stats success",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				q := domain.StatsQuery{URLID: tURL.ID, From: from, To: from.Add(24 * time.Hour), Interval: domain.StatsIntervalHour}
+				cuc.EXPECT().Stats(gomock.Any(), q, claims).Return(tStats, nil)
+			},
+			param: tURL.ID,
+			query: "?from=2023-03-01T00:00:00Z&to=2023-03-02T00:00:00Z&interval=hour",
+			auth:  true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ClickStats)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, tStats.TotalClicks, body.TotalClicks)
+				assert.Equal(t, tStats.UniqueVisitors, body.UniqueVisitors)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			description: "Stats not authorized",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			param:       tURL.ID,
+			auth:        false,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, domain.ErrForbidden.Error(), body.Error)
+				assert.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			description: "Stats wrong user",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				cuc.EXPECT().Stats(gomock.Any(), domain.StatsQuery{URLID: tURL.ID}, claims).Return(nil, domain.ErrForbidden)
+			},
+			param: tURL.ID,
+			auth:  true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, domain.ErrForbidden.Error(), body.Error)
+				assert.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			description: "Stats wrong interval",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			param:       tURL.ID,
+			query:       "?interval=week",
+			auth:        true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, "validation error", body.Error)
+				assert.Equal(t, "interval must be one of [hour day]", body.Fields["StatsQuery.interval"])
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			description: "Stats wrong time format",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			param:       tURL.ID,
+			query:       "?from=yesterday",
+			auth:        true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			description: "Stats validation error",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			param:       "te!t",
+			auth:        true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, "validation error", body.Error)
+				assert.Equal(t, "id must contain only a-z, A-Z, 0-9, _, - characters", body.Fields["StatsQuery.id"])
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+	}
+
+	for _, tc := range casesStats {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls(uc)
+			req = httptest.NewRequest(echo.GET, "/v1/url/"+tc.param+"/stats"+tc.query, nil)
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+			c.SetPath("/v1/url/:id/stats")
+			c.SetParamNames("id")
+			c.SetParamValues(tc.param)
+			if tc.auth {
+				c.Set("user", token)
+			}
+
+			err = handler.Stats(c)
+			require.NoError(t, err)
+
+			tc.checkResponse(rec)
+		})
+	}
+
+	// Test URLHandler.List
+	tList := &domain.URLList{Items: []*domain.URL{tURL}, NextCursor: "next"}
+
+	casesList := []struct {
+		description   string
+		mockCalls     func(muc *mock.MockURLUsecase)
+		query         string
+		auth          bool
+		checkResponse func(rec *httptest.ResponseRecorder)
+	}{
+		{
+			description: "List success",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				q := domain.URLListQuery{Cursor: "abc", Limit: 10, Sort: "expiration_date", Order: "asc", Status: "expired", Search: "example"}
+				muc.EXPECT().List(gomock.Any(), q, claims).Return(tList, nil)
+			},
+			query: "?cursor=abc&limit=10&sort=expiration_date&order=asc&status=expired&search=example",
+			auth:  true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.URLList)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.EqualValues(t, tList, body)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			description: "List not authorized",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			auth:        false,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, domain.ErrForbidden.Error(), body.Error)
+				assert.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			description: "List bad cursor",
+			mockCalls: func(muc *mock.MockURLUsecase) {
+				muc.EXPECT().List(gomock.Any(), domain.URLListQuery{Cursor: "bad"}, claims).Return(nil, domain.ErrBadParamInput)
+			},
+			query: "?cursor=bad",
+			auth:  true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, domain.ErrBadParamInput.Error(), body.Error)
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			description: "List validation error",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			query:       "?limit=1000&sort=link",
+			auth:        true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, "validation error", body.Error)
+				assert.Equal(t, "limit must be 100 or less", body.Fields["URLListQuery.limit"])
+				assert.Equal(t, "sort must be one of [created_at expiration_date]", body.Fields["URLListQuery.sort"])
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			description: "List limit is not a number",
+			mockCalls:   func(muc *mock.MockURLUsecase) {},
+			query:       "?limit=ten",
+			auth:        true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+	}
+
+	for _, tc := range casesList {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls(uc)
+			req = httptest.NewRequest(echo.GET, "/v1/user/url"+tc.query, nil)
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+			c.SetPath("/v1/user/url")
+			if tc.auth {
+				c.Set("user", token)
+			}
+
+			err = handler.List(c)
+			require.NoError(t, err)
+
+			tc.checkResponse(rec)
+		})
+	}
+
+	// Test validation for models.CreateURL and models.UpdateURL structs
+	casesCreateURL := []struct {
+		description string
+		fieldName   string
+		data        domain.CreateURL
+		want        string
+	}{
+		{
+			description: "validate CreateURL id not valid format",
+			fieldName:   "CreateURL.id",
+			data:        domain.CreateURL{ID: tests.StringPointer("test1/,!")},
+			want:        "id must contain only a-z, A-Z, 0-9, _, - characters",
+		},
+		{
+			description: "validate CreateURL id too short",
+			fieldName:   "CreateURL.id",
+			data:        domain.CreateURL{ID: tests.StringPointer("tes")},
+			want:        "id must be at least 7 characters in length",
+		},
 		{
 			description: "validate CreateURL id too long",
 			fieldName:   "CreateURL.id",
@@ -530,6 +1654,16 @@ func TestURLHTTP(t *testing.T) {
 			},
 			want: "expiration_date must be greater than the current Date & Time",
 		},
+		{
+			description: "validate CreateURL redirect type not supported",
+			fieldName:   "CreateURL.redirect_type",
+			data: domain.CreateURL{
+				ID:           tests.StringPointer("test123"),
+				Link:         "https://www.example.org",
+				RedirectType: http.StatusNotModified,
+			},
+			want: "redirect_type must be one of [301 302 307 308]",
+		},
 	}
 
 	casesUpdateURL := []struct {
@@ -556,17 +1690,29 @@ func TestURLHTTP(t *testing.T) {
 			want:        "id must be a maximum of 20 characters in length",
 		},
 		{
-			description: "validate UpdateURL expiration date not set",
-			fieldName:   "UpdateURL.expiration_date",
-			data:        domain.UpdateURL{ID: "test123"},
-			want:        "expiration_date is a required field",
+			description: "validate UpdateURL link has wrong format",
+			fieldName:   "UpdateURL.link",
+			data:        domain.UpdateURL{ID: "test123", Link: tests.StringPointer("not url")},
+			want:        "link must be a valid URL",
+		},
+		{
+			description: "validate UpdateURL title too long",
+			fieldName:   "UpdateURL.title",
+			data:        domain.UpdateURL{ID: "test123", Title: tests.StringPointer(strings.Repeat("a", 201))},
+			want:        "title must be a maximum of 200 characters in length",
+		},
+		{
+			description: "validate UpdateURL redirect type not supported",
+			fieldName:   "UpdateURL.redirect_type",
+			data:        domain.UpdateURL{ID: "test123", RedirectType: intPointer(http.StatusOK)},
+			want:        "redirect_type must be one of [301 302 307 308]",
 		},
 		{
 			description: "validate UpdateURL expiration date has wrong format",
 			fieldName:   "UpdateURL.expiration_date",
 			data: domain.UpdateURL{
 				ID:             "test123",
-				ExpirationDate: time.Now().AddDate(0, 0, -1),
+				ExpirationDate: tests.DatePointer(time.Now().AddDate(0, 0, -1)),
 			},
 			want: "expiration_date must be greater than the current Date & Time"},
 	}
@@ -589,3 +1735,7 @@ func TestURLHTTP(t *testing.T) {
 		})
 	}
 }
+
+func intPointer(i int) *int {
+	return &i
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/semka95/shortener/backend/domain"
+)
+
+// confirmParam is query parameter which confirms redirect to flagged link
+const confirmParam = "confirm"
+
+var warningPageTemplate = template.Must(template.New("warning").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>Suspicious link</title>
+</head>
+<body>
+<h1>This link may be unsafe</h1>
+<p role="alert">{{.Reason}}</p>
+<p>The link leads to <code>{{.Link}}</code>. Don't enter passwords or personal data there unless you trust the site.</p>
+<a href="/{{.ID}}?{{.Confirm}}=true" rel="nofollow noreferrer">Continue anyway</a>
+</body>
+</html>
+`))
+
+// isConfirmed reports whether user chose to follow flagged link on the warning page
+func isConfirmed(c echo.Context) bool {
+	return c.QueryParam(confirmParam) == "true"
+}
+
+// flaggedWarning responds with warning page to browsers and with JSON error
+// to API clients instead of redirect to flagged link
+func (uh *URLHandler) flaggedWarning(c echo.Context, u *domain.URL) error {
+	c.Response().Header().Set(echo.HeaderCacheControl, "private, no-store")
+
+	reason := u.FlagReason
+	if reason == "" {
+		reason = "link was flagged as unsafe"
+	}
+
+	if !wantsHTML(c) {
+		return c.JSON(http.StatusForbidden, domain.ResponseError{Error: reason})
+	}
+
+	buf := new(bytes.Buffer)
+	err := warningPageTemplate.Execute(buf, struct {
+		ID      string
+		Link    string
+		Reason  string
+		Confirm string
+	}{ID: u.ShortID(), Link: u.Link, Reason: reason, Confirm: confirmParam})
+	if err != nil {
+		return err
+	}
+
+	return c.HTMLBlob(http.StatusOK, buf.Bytes())
+}
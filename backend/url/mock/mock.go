@@ -6,11 +6,14 @@ package mock
 
 import (
 	context "context"
+	net "net"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	domain "github.com/semka95/shortener/backend/domain"
 	auth "github.com/semka95/shortener/backend/web/auth"
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // MockURLUsecase is a mock of URLUsecase interface.
@@ -36,6 +39,65 @@ func (m *MockURLUsecase) EXPECT() *MockURLUsecaseMockRecorder {
 	return m.recorder
 }
 
+// Authorize mocks base method.
+func (m *MockURLUsecase) Authorize(ctx context.Context, u *domain.URL, user *auth.Claims, action string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, u, user, action)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockURLUsecaseMockRecorder) Authorize(ctx, u, user, action interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockURLUsecase)(nil).Authorize), ctx, u, user, action)
+}
+
+// BulkDelete mocks base method.
+func (m *MockURLUsecase) BulkDelete(ctx context.Context, ids []string, user *auth.Claims) ([]domain.BulkResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkDelete", ctx, ids, user)
+	ret0, _ := ret[0].([]domain.BulkResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkDelete indicates an expected call of BulkDelete.
+func (mr *MockURLUsecaseMockRecorder) BulkDelete(ctx, ids, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkDelete", reflect.TypeOf((*MockURLUsecase)(nil).BulkDelete), ctx, ids, user)
+}
+
+// BulkStore mocks base method.
+func (m *MockURLUsecase) BulkStore(ctx context.Context, items []domain.CreateURL) ([]domain.BulkResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkStore", ctx, items)
+	ret0, _ := ret[0].([]domain.BulkResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkStore indicates an expected call of BulkStore.
+func (mr *MockURLUsecaseMockRecorder) BulkStore(ctx, items interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkStore", reflect.TypeOf((*MockURLUsecase)(nil).BulkStore), ctx, items)
+}
+
+// Consume mocks base method.
+func (m *MockURLUsecase) Consume(ctx context.Context, u *domain.URL) (*domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consume", ctx, u)
+	ret0, _ := ret[0].(*domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockURLUsecaseMockRecorder) Consume(ctx, u interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockURLUsecase)(nil).Consume), ctx, u)
+}
+
 // Delete mocks base method.
 func (m *MockURLUsecase) Delete(ctx context.Context, id string, user *auth.Claims) error {
 	m.ctrl.T.Helper()
@@ -51,18 +113,108 @@ func (mr *MockURLUsecaseMockRecorder) Delete(ctx, id, user interface{}) *gomock.
 }
 
 // GetByID mocks base method.
-func (m *MockURLUsecase) GetByID(ctx context.Context, id string) (*domain.URL, error) {
+func (m *MockURLUsecase) GetByID(ctx context.Context, id string, user *auth.Claims) (*domain.URL, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret := m.ctrl.Call(m, "GetByID", ctx, id, user)
 	ret0, _ := ret[0].(*domain.URL)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByID indicates an expected call of GetByID.
-func (mr *MockURLUsecaseMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+func (mr *MockURLUsecaseMockRecorder) GetByID(ctx, id, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockURLUsecase)(nil).GetByID), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockURLUsecase)(nil).GetByID), ctx, id, user)
+}
+
+// History mocks base method.
+func (m *MockURLUsecase) History(ctx context.Context, id string, user *auth.Claims) ([]*domain.URLRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, id, user)
+	ret0, _ := ret[0].([]*domain.URLRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockURLUsecaseMockRecorder) History(ctx, id, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockURLUsecase)(nil).History), ctx, id, user)
+}
+
+// List mocks base method.
+func (m *MockURLUsecase) List(ctx context.Context, query domain.URLListQuery, user *auth.Claims) (*domain.URLList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, query, user)
+	ret0, _ := ret[0].(*domain.URLList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockURLUsecaseMockRecorder) List(ctx, query, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockURLUsecase)(nil).List), ctx, query, user)
+}
+
+// Purge mocks base method.
+func (m *MockURLUsecase) Purge(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockURLUsecaseMockRecorder) Purge(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockURLUsecase)(nil).Purge), ctx)
+}
+
+// Resolve mocks base method.
+func (m *MockURLUsecase) Resolve(ctx context.Context, host, id string) (*domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resolve", ctx, host, id)
+	ret0, _ := ret[0].(*domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Resolve indicates an expected call of Resolve.
+func (mr *MockURLUsecaseMockRecorder) Resolve(ctx, host, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockURLUsecase)(nil).Resolve), ctx, host, id)
+}
+
+// Restore mocks base method.
+func (m *MockURLUsecase) Restore(ctx context.Context, id string, user *auth.Claims) (*domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id, user)
+	ret0, _ := ret[0].(*domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockURLUsecaseMockRecorder) Restore(ctx, id, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockURLUsecase)(nil).Restore), ctx, id, user)
+}
+
+// Rollback mocks base method.
+func (m *MockURLUsecase) Rollback(ctx context.Context, rollback domain.URLRollback, user *auth.Claims) (*domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rollback", ctx, rollback, user)
+	ret0, _ := ret[0].(*domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rollback indicates an expected call of Rollback.
+func (mr *MockURLUsecaseMockRecorder) Rollback(ctx, rollback, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockURLUsecase)(nil).Rollback), ctx, rollback, user)
 }
 
 // Store mocks base method.
@@ -80,6 +232,21 @@ func (mr *MockURLUsecaseMockRecorder) Store(ctx, createURL interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockURLUsecase)(nil).Store), ctx, createURL)
 }
 
+// Unlock mocks base method.
+func (m *MockURLUsecase) Unlock(ctx context.Context, unlock domain.URLUnlock) (*domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unlock", ctx, unlock)
+	ret0, _ := ret[0].(*domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unlock indicates an expected call of Unlock.
+func (mr *MockURLUsecaseMockRecorder) Unlock(ctx, unlock interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unlock", reflect.TypeOf((*MockURLUsecase)(nil).Unlock), ctx, unlock)
+}
+
 // Update mocks base method.
 func (m *MockURLUsecase) Update(ctx context.Context, updateURL domain.UpdateURL, user *auth.Claims) error {
 	m.ctrl.T.Helper()
@@ -117,6 +284,21 @@ func (m *MockURLRepository) EXPECT() *MockURLRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ConsumeClick mocks base method.
+func (m *MockURLRepository) ConsumeClick(ctx context.Context, id string) (*domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeClick", ctx, id)
+	ret0, _ := ret[0].(*domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeClick indicates an expected call of ConsumeClick.
+func (mr *MockURLRepositoryMockRecorder) ConsumeClick(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeClick", reflect.TypeOf((*MockURLRepository)(nil).ConsumeClick), ctx, id)
+}
+
 // Delete mocks base method.
 func (m *MockURLRepository) Delete(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -131,6 +313,50 @@ func (mr *MockURLRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockURLRepository)(nil).Delete), ctx, id)
 }
 
+// DeleteByUser mocks base method.
+func (m *MockURLRepository) DeleteByUser(ctx context.Context, userID string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUser", ctx, userID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteByUser indicates an expected call of DeleteByUser.
+func (mr *MockURLRepositoryMockRecorder) DeleteByUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUser", reflect.TypeOf((*MockURLRepository)(nil).DeleteByUser), ctx, userID)
+}
+
+// DeleteMany mocks base method.
+func (m *MockURLRepository) DeleteMany(ctx context.Context, ids []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMany", ctx, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMany indicates an expected call of DeleteMany.
+func (mr *MockURLRepositoryMockRecorder) DeleteMany(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMany", reflect.TypeOf((*MockURLRepository)(nil).DeleteMany), ctx, ids)
+}
+
+// GetByDomain mocks base method.
+func (m *MockURLRepository) GetByDomain(ctx context.Context, host, slug string) (*domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByDomain", ctx, host, slug)
+	ret0, _ := ret[0].(*domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByDomain indicates an expected call of GetByDomain.
+func (mr *MockURLRepositoryMockRecorder) GetByDomain(ctx, host, slug interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByDomain", reflect.TypeOf((*MockURLRepository)(nil).GetByDomain), ctx, host, slug)
+}
+
 // GetByID mocks base method.
 func (m *MockURLRepository) GetByID(ctx context.Context, id string) (*domain.URL, error) {
 	m.ctrl.T.Helper()
@@ -146,6 +372,168 @@ func (mr *MockURLRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockURLRepository)(nil).GetByID), ctx, id)
 }
 
+// GetByIDs mocks base method.
+func (m *MockURLRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDs", ctx, ids)
+	ret0, _ := ret[0].([]*domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDs indicates an expected call of GetByIDs.
+func (mr *MockURLRepositoryMockRecorder) GetByIDs(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDs", reflect.TypeOf((*MockURLRepository)(nil).GetByIDs), ctx, ids)
+}
+
+// GetDeleted mocks base method.
+func (m *MockURLRepository) GetDeleted(ctx context.Context, id string) (*domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeleted", ctx, id)
+	ret0, _ := ret[0].(*domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeleted indicates an expected call of GetDeleted.
+func (mr *MockURLRepositoryMockRecorder) GetDeleted(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeleted", reflect.TypeOf((*MockURLRepository)(nil).GetDeleted), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockURLRepository) List(ctx context.Context, query domain.URLListQuery) (*domain.URLList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, query)
+	ret0, _ := ret[0].(*domain.URLList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockURLRepositoryMockRecorder) List(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockURLRepository)(nil).List), ctx, query)
+}
+
+// ListDeleted mocks base method.
+func (m *MockURLRepository) ListDeleted(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeleted", ctx, before, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeleted indicates an expected call of ListDeleted.
+func (mr *MockURLRepositoryMockRecorder) ListDeleted(ctx, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeleted", reflect.TypeOf((*MockURLRepository)(nil).ListDeleted), ctx, before, limit)
+}
+
+// ListExpired mocks base method.
+func (m *MockURLRepository) ListExpired(ctx context.Context, before time.Time, limit int) ([]*domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpired", ctx, before, limit)
+	ret0, _ := ret[0].([]*domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpired indicates an expected call of ListExpired.
+func (mr *MockURLRepositoryMockRecorder) ListExpired(ctx, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpired", reflect.TypeOf((*MockURLRepository)(nil).ListExpired), ctx, before, limit)
+}
+
+// Purge mocks base method.
+func (m *MockURLRepository) Purge(ctx context.Context, ids []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockURLRepositoryMockRecorder) Purge(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockURLRepository)(nil).Purge), ctx, ids)
+}
+
+// Restore mocks base method.
+func (m *MockURLRepository) Restore(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockURLRepositoryMockRecorder) Restore(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockURLRepository)(nil).Restore), ctx, id)
+}
+
+// SetDisabled mocks base method.
+func (m *MockURLRepository) SetDisabled(ctx context.Context, id string, disabled bool, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDisabled", ctx, id, disabled, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDisabled indicates an expected call of SetDisabled.
+func (mr *MockURLRepositoryMockRecorder) SetDisabled(ctx, id, disabled, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDisabled", reflect.TypeOf((*MockURLRepository)(nil).SetDisabled), ctx, id, disabled, at)
+}
+
+// SetOwner mocks base method.
+func (m *MockURLRepository) SetOwner(ctx context.Context, from, to string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOwner", ctx, from, to)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetOwner indicates an expected call of SetOwner.
+func (mr *MockURLRepositoryMockRecorder) SetOwner(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOwner", reflect.TypeOf((*MockURLRepository)(nil).SetOwner), ctx, from, to)
+}
+
+// SetOwnerSuspended mocks base method.
+func (m *MockURLRepository) SetOwnerSuspended(ctx context.Context, userID string, suspended bool) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOwnerSuspended", ctx, userID, suspended)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetOwnerSuspended indicates an expected call of SetOwnerSuspended.
+func (mr *MockURLRepositoryMockRecorder) SetOwnerSuspended(ctx, userID, suspended interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOwnerSuspended", reflect.TypeOf((*MockURLRepository)(nil).SetOwnerSuspended), ctx, userID, suspended)
+}
+
+// SetWorkspace mocks base method.
+func (m *MockURLRepository) SetWorkspace(ctx context.Context, ids []string, from, to string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWorkspace", ctx, ids, from, to)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetWorkspace indicates an expected call of SetWorkspace.
+func (mr *MockURLRepositoryMockRecorder) SetWorkspace(ctx, ids, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWorkspace", reflect.TypeOf((*MockURLRepository)(nil).SetWorkspace), ctx, ids, from, to)
+}
+
 // Store mocks base method.
 func (m *MockURLRepository) Store(ctx context.Context, u *domain.URL) error {
 	m.ctrl.T.Helper()
@@ -160,6 +548,21 @@ func (mr *MockURLRepositoryMockRecorder) Store(ctx, u interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockURLRepository)(nil).Store), ctx, u)
 }
 
+// StoreMany mocks base method.
+func (m *MockURLRepository) StoreMany(ctx context.Context, urls []*domain.URL) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreMany", ctx, urls)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StoreMany indicates an expected call of StoreMany.
+func (mr *MockURLRepositoryMockRecorder) StoreMany(ctx, urls interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreMany", reflect.TypeOf((*MockURLRepository)(nil).StoreMany), ctx, urls)
+}
+
 // Update mocks base method.
 func (m *MockURLRepository) Update(ctx context.Context, url *domain.URL) error {
 	m.ctrl.T.Helper()
@@ -173,3 +576,281 @@ func (mr *MockURLRepositoryMockRecorder) Update(ctx, url interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockURLRepository)(nil).Update), ctx, url)
 }
+
+// MockURLCache is a mock of URLCache interface.
+type MockURLCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockURLCacheMockRecorder
+}
+
+// MockURLCacheMockRecorder is the mock recorder for MockURLCache.
+type MockURLCacheMockRecorder struct {
+	mock *MockURLCache
+}
+
+// NewMockURLCache creates a new mock instance.
+func NewMockURLCache(ctrl *gomock.Controller) *MockURLCache {
+	mock := &MockURLCache{ctrl: ctrl}
+	mock.recorder = &MockURLCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockURLCache) EXPECT() *MockURLCacheMockRecorder {
+	return m.recorder
+}
+
+// Invalidate mocks base method.
+func (m *MockURLCache) Invalidate(ctx context.Context, ids ...string) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range ids {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Invalidate", varargs...)
+}
+
+// Invalidate indicates an expected call of Invalidate.
+func (mr *MockURLCacheMockRecorder) Invalidate(ctx interface{}, ids ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, ids...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Invalidate", reflect.TypeOf((*MockURLCache)(nil).Invalidate), varargs...)
+}
+
+// MockURLRevisionRepository is a mock of URLRevisionRepository interface.
+type MockURLRevisionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockURLRevisionRepositoryMockRecorder
+}
+
+// MockURLRevisionRepositoryMockRecorder is the mock recorder for MockURLRevisionRepository.
+type MockURLRevisionRepositoryMockRecorder struct {
+	mock *MockURLRevisionRepository
+}
+
+// NewMockURLRevisionRepository creates a new mock instance.
+func NewMockURLRevisionRepository(ctrl *gomock.Controller) *MockURLRevisionRepository {
+	mock := &MockURLRevisionRepository{ctrl: ctrl}
+	mock.recorder = &MockURLRevisionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockURLRevisionRepository) EXPECT() *MockURLRevisionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteByURL mocks base method.
+func (m *MockURLRevisionRepository) DeleteByURL(ctx context.Context, urlIDs ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range urlIDs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteByURL", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByURL indicates an expected call of DeleteByURL.
+func (mr *MockURLRevisionRepositoryMockRecorder) DeleteByURL(ctx interface{}, urlIDs ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, urlIDs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByURL", reflect.TypeOf((*MockURLRevisionRepository)(nil).DeleteByURL), varargs...)
+}
+
+// GetByID mocks base method.
+func (m *MockURLRevisionRepository) GetByID(ctx context.Context, urlID string, id primitive.ObjectID) (*domain.URLRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, urlID, id)
+	ret0, _ := ret[0].(*domain.URLRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockURLRevisionRepositoryMockRecorder) GetByID(ctx, urlID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockURLRevisionRepository)(nil).GetByID), ctx, urlID, id)
+}
+
+// List mocks base method.
+func (m *MockURLRevisionRepository) List(ctx context.Context, urlID string, limit int) ([]*domain.URLRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, urlID, limit)
+	ret0, _ := ret[0].([]*domain.URLRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockURLRevisionRepositoryMockRecorder) List(ctx, urlID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockURLRevisionRepository)(nil).List), ctx, urlID, limit)
+}
+
+// Store mocks base method.
+func (m *MockURLRevisionRepository) Store(ctx context.Context, r *domain.URLRevision) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store.
+func (mr *MockURLRevisionRepositoryMockRecorder) Store(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockURLRevisionRepository)(nil).Store), ctx, r)
+}
+
+// MockTokenGenerator is a mock of TokenGenerator interface.
+type MockTokenGenerator struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenGeneratorMockRecorder
+}
+
+// MockTokenGeneratorMockRecorder is the mock recorder for MockTokenGenerator.
+type MockTokenGeneratorMockRecorder struct {
+	mock *MockTokenGenerator
+}
+
+// NewMockTokenGenerator creates a new mock instance.
+func NewMockTokenGenerator(ctrl *gomock.Controller) *MockTokenGenerator {
+	mock := &MockTokenGenerator{ctrl: ctrl}
+	mock.recorder = &MockTokenGeneratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenGenerator) EXPECT() *MockTokenGeneratorMockRecorder {
+	return m.recorder
+}
+
+// Generate mocks base method.
+func (m *MockTokenGenerator) Generate(ctx context.Context) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Generate", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Generate indicates an expected call of Generate.
+func (mr *MockTokenGeneratorMockRecorder) Generate(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Generate", reflect.TypeOf((*MockTokenGenerator)(nil).Generate), ctx)
+}
+
+// MockLinkScreener is a mock of LinkScreener interface.
+type MockLinkScreener struct {
+	ctrl     *gomock.Controller
+	recorder *MockLinkScreenerMockRecorder
+}
+
+// MockLinkScreenerMockRecorder is the mock recorder for MockLinkScreener.
+type MockLinkScreenerMockRecorder struct {
+	mock *MockLinkScreener
+}
+
+// NewMockLinkScreener creates a new mock instance.
+func NewMockLinkScreener(ctrl *gomock.Controller) *MockLinkScreener {
+	mock := &MockLinkScreener{ctrl: ctrl}
+	mock.recorder = &MockLinkScreenerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLinkScreener) EXPECT() *MockLinkScreenerMockRecorder {
+	return m.recorder
+}
+
+// Screen mocks base method.
+func (m *MockLinkScreener) Screen(ctx context.Context, link string) (domain.ScreenResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Screen", ctx, link)
+	ret0, _ := ret[0].(domain.ScreenResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Screen indicates an expected call of Screen.
+func (mr *MockLinkScreenerMockRecorder) Screen(ctx, link interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Screen", reflect.TypeOf((*MockLinkScreener)(nil).Screen), ctx, link)
+}
+
+// MockIPResolver is a mock of IPResolver interface.
+type MockIPResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockIPResolverMockRecorder
+}
+
+// MockIPResolverMockRecorder is the mock recorder for MockIPResolver.
+type MockIPResolverMockRecorder struct {
+	mock *MockIPResolver
+}
+
+// NewMockIPResolver creates a new mock instance.
+func NewMockIPResolver(ctrl *gomock.Controller) *MockIPResolver {
+	mock := &MockIPResolver{ctrl: ctrl}
+	mock.recorder = &MockIPResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIPResolver) EXPECT() *MockIPResolverMockRecorder {
+	return m.recorder
+}
+
+// LookupIPAddr mocks base method.
+func (m *MockIPResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupIPAddr", ctx, host)
+	ret0, _ := ret[0].([]net.IPAddr)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LookupIPAddr indicates an expected call of LookupIPAddr.
+func (mr *MockIPResolverMockRecorder) LookupIPAddr(ctx, host interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupIPAddr", reflect.TypeOf((*MockIPResolver)(nil).LookupIPAddr), ctx, host)
+}
+
+// MockCounterRepository is a mock of CounterRepository interface.
+type MockCounterRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCounterRepositoryMockRecorder
+}
+
+// MockCounterRepositoryMockRecorder is the mock recorder for MockCounterRepository.
+type MockCounterRepositoryMockRecorder struct {
+	mock *MockCounterRepository
+}
+
+// NewMockCounterRepository creates a new mock instance.
+func NewMockCounterRepository(ctrl *gomock.Controller) *MockCounterRepository {
+	mock := &MockCounterRepository{ctrl: ctrl}
+	mock.recorder = &MockCounterRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCounterRepository) EXPECT() *MockCounterRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Lease mocks base method.
+func (m *MockCounterRepository) Lease(ctx context.Context, name string, size int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lease", ctx, name, size)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lease indicates an expected call of Lease.
+func (mr *MockCounterRepositoryMockRecorder) Lease(ctx, name, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lease", reflect.TypeOf((*MockCounterRepository)(nil).Lease), ctx, name, size)
+}
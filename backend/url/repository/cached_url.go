@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/cache"
+	"github.com/semka95/shortener/backend/domain"
+)
+
+const cacheKeyPrefix = "url:"
+
+type cachedURLRepository struct {
+	repo   domain.URLRepository
+	cache  cache.Cache
+	ttl    time.Duration
+	hits   instrument.Int64Counter
+	misses instrument.Int64Counter
+	logger *zap.Logger
+	tracer trace.Tracer
+}
+
+// NewCachedURLRepository will create an object that represent the url.Repository interface,
+// it serves GetByID from cache and falls back to the given repository on cache miss
+func NewCachedURLRepository(repo domain.URLRepository, c cache.Cache, ttl time.Duration, meter metric.Meter, logger *zap.Logger, tracer trace.Tracer) (domain.URLRepository, error) {
+	hits, err := meter.Int64Counter(
+		"url_cache_hits_total",
+		instrument.WithDescription("How many URL lookups were served from cache."),
+		instrument.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("can't create cache hits counter: %w", err)
+	}
+
+	misses, err := meter.Int64Counter(
+		"url_cache_misses_total",
+		instrument.WithDescription("How many URL lookups were not found in cache."),
+		instrument.WithUnit(unit.Dimensionless),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("can't create cache misses counter: %w", err)
+	}
+
+	return &cachedURLRepository{
+		repo:   repo,
+		cache:  c,
+		ttl:    ttl,
+		hits:   hits,
+		misses: misses,
+		logger: logger,
+		tracer: tracer,
+	}, nil
+}
+
+func (r *cachedURLRepository) GetByID(ctx context.Context, id string) (*domain.URL, error) {
+	ctx, span := r.tracer.Start(
+		ctx,
+		"repository cached GetByID",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("urlid", id)),
+	)
+	defer span.End()
+
+	data, err := r.cache.Get(ctx, cacheKeyPrefix+id)
+	if err == nil {
+		u := new(domain.URL)
+		if err = bson.Unmarshal(data, u); err == nil {
+			span.SetAttributes(attribute.Bool("cache_hit", true))
+			r.hits.Add(ctx, 1)
+			return u, nil
+		}
+	}
+	if err != nil && !errors.Is(err, cache.ErrCacheMiss) {
+		// cache is an optimization, so its failures must not break lookups
+		span.RecordError(err)
+		r.logger.Warn("can't get URL from cache: ", zap.String("urlid", id), zap.Error(err))
+	}
+	span.SetAttributes(attribute.Bool("cache_hit", false))
+	r.misses.Add(ctx, 1)
+
+	u, err := r.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// entry must not outlive the link itself
+	ttl := r.ttl
+	if untilExpiration := time.Until(u.ExpirationDate); untilExpiration < ttl {
+		ttl = untilExpiration
+	}
+	if ttl <= 0 {
+		return u, nil
+	}
+
+	data, err = bson.Marshal(u)
+	if err != nil {
+		span.RecordError(err)
+		r.logger.Warn("can't marshal URL for cache: ", zap.String("urlid", id), zap.Error(err))
+		return u, nil
+	}
+
+	if err = r.cache.Set(ctx, cacheKeyPrefix+id, data, ttl); err != nil {
+		span.RecordError(err)
+		r.logger.Warn("can't store URL in cache: ", zap.String("urlid", id), zap.Error(err))
+	}
+
+	return u, nil
+}
+
+// GetByDomain is served by GetByID, as id of custom domain URL is derived
+// from its host and slug
+func (r *cachedURLRepository) GetByDomain(ctx context.Context, host, slug string) (*domain.URL, error) {
+	u, err := r.GetByID(ctx, domain.CustomDomainURLID(host, slug))
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Domain != host || u.Slug != slug {
+		return nil, fmt.Errorf("URL was not found: %w", domain.ErrNotFound)
+	}
+
+	return u, nil
+}
+
+func (r *cachedURLRepository) Store(ctx context.Context, url *domain.URL) error {
+	return r.repo.Store(ctx, url)
+}
+
+func (r *cachedURLRepository) Update(ctx context.Context, url *domain.URL) error {
+	err := r.repo.Update(ctx, url)
+	r.invalidate(ctx, url.ID)
+
+	return err
+}
+
+func (r *cachedURLRepository) Delete(ctx context.Context, id string) error {
+	err := r.repo.Delete(ctx, id)
+	r.invalidate(ctx, id)
+
+	return err
+}
+
+func (r *cachedURLRepository) ConsumeClick(ctx context.Context, id string) (*domain.URL, error) {
+	u, err := r.repo.ConsumeClick(ctx, id)
+	r.invalidate(ctx, id)
+
+	return u, err
+}
+
+func (r *cachedURLRepository) List(ctx context.Context, query domain.URLListQuery) (*domain.URLList, error) {
+	return r.repo.List(ctx, query)
+}
+
+func (r *cachedURLRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.URL, error) {
+	return r.repo.GetByIDs(ctx, ids)
+}
+
+func (r *cachedURLRepository) StoreMany(ctx context.Context, urls []*domain.URL) ([]error, error) {
+	return r.repo.StoreMany(ctx, urls)
+}
+
+func (r *cachedURLRepository) DeleteMany(ctx context.Context, ids []string) error {
+	err := r.repo.DeleteMany(ctx, ids)
+	for _, id := range ids {
+		r.invalidate(ctx, id)
+	}
+
+	return err
+}
+
+func (r *cachedURLRepository) SetDisabled(ctx context.Context, id string, disabled bool, at time.Time) error {
+	err := r.repo.SetDisabled(ctx, id, disabled, at)
+	r.invalidate(ctx, id)
+
+	return err
+}
+
+func (r *cachedURLRepository) SetOwnerSuspended(ctx context.Context, userID string, suspended bool) ([]string, error) {
+	ids, err := r.repo.SetOwnerSuspended(ctx, userID, suspended)
+	for _, id := range ids {
+		r.invalidate(ctx, id)
+	}
+
+	return ids, err
+}
+
+func (r *cachedURLRepository) DeleteByUser(ctx context.Context, userID string) ([]string, error) {
+	ids, err := r.repo.DeleteByUser(ctx, userID)
+	for _, id := range ids {
+		r.invalidate(ctx, id)
+	}
+
+	return ids, err
+}
+
+func (r *cachedURLRepository) SetOwner(ctx context.Context, from, to string) ([]string, error) {
+	ids, err := r.repo.SetOwner(ctx, from, to)
+	for _, id := range ids {
+		r.invalidate(ctx, id)
+	}
+
+	return ids, err
+}
+
+func (r *cachedURLRepository) SetWorkspace(ctx context.Context, ids []string, from, to string) ([]string, error) {
+	moved, err := r.repo.SetWorkspace(ctx, ids, from, to)
+	for _, id := range moved {
+		r.invalidate(ctx, id)
+	}
+
+	return moved, err
+}
+
+func (r *cachedURLRepository) GetDeleted(ctx context.Context, id string) (*domain.URL, error) {
+	return r.repo.GetDeleted(ctx, id)
+}
+
+func (r *cachedURLRepository) Restore(ctx context.Context, id string) error {
+	err := r.repo.Restore(ctx, id)
+	r.invalidate(ctx, id)
+
+	return err
+}
+
+func (r *cachedURLRepository) ListDeleted(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	return r.repo.ListDeleted(ctx, before, limit)
+}
+
+func (r *cachedURLRepository) ListExpired(ctx context.Context, before time.Time, limit int) ([]*domain.URL, error) {
+	return r.repo.ListExpired(ctx, before, limit)
+}
+
+func (r *cachedURLRepository) Purge(ctx context.Context, ids []string) error {
+	return r.repo.Purge(ctx, ids)
+}
+
+// Invalidate drops URLs from cache
+func (r *cachedURLRepository) Invalidate(ctx context.Context, ids ...string) {
+	for _, id := range ids {
+		r.invalidate(ctx, id)
+	}
+}
+
+func (r *cachedURLRepository) invalidate(ctx context.Context, id string) {
+	if err := r.cache.Delete(ctx, cacheKeyPrefix+id); err != nil {
+		r.logger.Error("can't invalidate cached URL: ", zap.String("urlid", id), zap.Error(err))
+	}
+}
+
+type noopURLCache struct{}
+
+// NewNoopURLCache will create an object that represent the domain.URLCache
+// interface for URL repository without cache
+func NewNoopURLCache() domain.URLCache {
+	return noopURLCache{}
+}
+
+func (noopURLCache) Invalidate(context.Context, ...string) {}
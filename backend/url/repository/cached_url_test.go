@@ -0,0 +1,206 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/cache"
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/tests"
+	"github.com/semka95/shortener/backend/url/mock"
+	"github.com/semka95/shortener/backend/url/repository"
+)
+
+type failingCache struct{}
+
+func (failingCache) Get(context.Context, string) ([]byte, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (failingCache) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("connection refused")
+}
+
+func (failingCache) Delete(context.Context, string) error {
+	return errors.New("connection refused")
+}
+
+func TestCachedURLRepository(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	meter := sdkmetric.NewMeterProvider().Meter("")
+	tURL := tests.NewURL()
+	repo := mock.NewMockURLRepository(controller)
+
+	newRepo := func(t *testing.T, c cache.Cache) domain.URLRepository {
+		r, err := repository.NewCachedURLRepository(repo, c, time.Hour, meter, zap.NewNop(), tracer)
+		require.NoError(t, err)
+		return r
+	}
+
+	t.Run("miss then hit", func(t *testing.T) {
+		r := newRepo(t, cache.NewLRU(10))
+		repo.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil).Times(1)
+
+		result, err := r.GetByID(noopCtx, tURL.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, tURL, result)
+
+		result, err = r.GetByID(noopCtx, tURL.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, tURL, result)
+	})
+
+	t.Run("not found is not cached", func(t *testing.T) {
+		r := newRepo(t, cache.NewLRU(10))
+		repo.EXPECT().GetByID(gomock.Any(), "none").Return(nil, domain.ErrNotFound).Times(2)
+
+		_, err := r.GetByID(noopCtx, "none")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		_, err = r.GetByID(noopCtx, "none")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("ttl is capped by expiration date", func(t *testing.T) {
+		c := cache.NewLRU(10)
+		r := newRepo(t, c)
+		expiring := tests.NewURL()
+		expiring.ExpirationDate = time.Now().Add(10 * time.Millisecond)
+		repo.EXPECT().GetByID(gomock.Any(), expiring.ID).Return(expiring, nil).Times(2)
+
+		_, err := r.GetByID(noopCtx, expiring.ID)
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+		_, err = r.GetByID(noopCtx, expiring.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("update invalidates entry", func(t *testing.T) {
+		r := newRepo(t, cache.NewLRU(10))
+		repo.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil).Times(2)
+		repo.EXPECT().Update(gomock.Any(), tURL).Return(nil)
+
+		_, err := r.GetByID(noopCtx, tURL.ID)
+		require.NoError(t, err)
+		require.NoError(t, r.Update(noopCtx, tURL))
+		_, err = r.GetByID(noopCtx, tURL.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("invalidate drops entries", func(t *testing.T) {
+		r := newRepo(t, cache.NewLRU(10))
+		repo.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil).Times(2)
+
+		_, err := r.GetByID(noopCtx, tURL.ID)
+		require.NoError(t, err)
+		r.(domain.URLCache).Invalidate(noopCtx, tURL.ID)
+		_, err = r.GetByID(noopCtx, tURL.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("disabling invalidates entry", func(t *testing.T) {
+		r := newRepo(t, cache.NewLRU(10))
+		repo.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil).Times(2)
+		repo.EXPECT().SetDisabled(gomock.Any(), tURL.ID, true, gomock.Any()).Return(nil)
+
+		_, err := r.GetByID(noopCtx, tURL.ID)
+		require.NoError(t, err)
+		require.NoError(t, r.SetDisabled(noopCtx, tURL.ID, true, time.Now()))
+		_, err = r.GetByID(noopCtx, tURL.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("owner suspension invalidates entries", func(t *testing.T) {
+		r := newRepo(t, cache.NewLRU(10))
+		repo.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil).Times(2)
+		repo.EXPECT().SetOwnerSuspended(gomock.Any(), tURL.UserID, true).Return([]string{tURL.ID}, nil)
+
+		_, err := r.GetByID(noopCtx, tURL.ID)
+		require.NoError(t, err)
+		_, err = r.SetOwnerSuspended(noopCtx, tURL.UserID, true)
+		require.NoError(t, err)
+		_, err = r.GetByID(noopCtx, tURL.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("workspace transfer invalidates entries", func(t *testing.T) {
+		r := newRepo(t, cache.NewLRU(10))
+		repo.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil).Times(2)
+		repo.EXPECT().SetWorkspace(gomock.Any(), []string{tURL.ID}, "from", "to").Return([]string{tURL.ID}, nil)
+
+		_, err := r.GetByID(noopCtx, tURL.ID)
+		require.NoError(t, err)
+		_, err = r.SetWorkspace(noopCtx, []string{tURL.ID}, "from", "to")
+		require.NoError(t, err)
+		_, err = r.GetByID(noopCtx, tURL.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("custom domain is served by id", func(t *testing.T) {
+		r := newRepo(t, cache.NewLRU(10))
+		branded := tests.NewURL()
+		branded.Domain = "go.example.com"
+		branded.Slug = "promo"
+		branded.ID = domain.CustomDomainURLID(branded.Domain, branded.Slug)
+		repo.EXPECT().GetByID(gomock.Any(), branded.ID).Return(branded, nil).Times(1)
+
+		result, err := r.GetByDomain(noopCtx, branded.Domain, branded.Slug)
+		require.NoError(t, err)
+		assert.EqualValues(t, branded, result)
+
+		result, err = r.GetByDomain(noopCtx, branded.Domain, branded.Slug)
+		require.NoError(t, err)
+		assert.EqualValues(t, branded, result)
+	})
+
+	t.Run("delete invalidates entry", func(t *testing.T) {
+		r := newRepo(t, cache.NewLRU(10))
+		repo.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		repo.EXPECT().Delete(gomock.Any(), tURL.ID).Return(nil)
+		repo.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(nil, domain.ErrNotFound)
+
+		_, err := r.GetByID(noopCtx, tURL.ID)
+		require.NoError(t, err)
+		require.NoError(t, r.Delete(noopCtx, tURL.ID))
+		_, err = r.GetByID(noopCtx, tURL.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("consumed click invalidates entry", func(t *testing.T) {
+		r := newRepo(t, cache.NewLRU(10))
+		limited := tests.NewURL()
+		limited.MaxClicks = 1
+		limited.RemainingClicks = 1
+		consumed := *limited
+		consumed.RemainingClicks = 0
+		repo.EXPECT().GetByID(gomock.Any(), limited.ID).Return(limited, nil)
+		repo.EXPECT().ConsumeClick(gomock.Any(), limited.ID).Return(&consumed, nil)
+		repo.EXPECT().GetByID(gomock.Any(), limited.ID).Return(&consumed, nil)
+
+		_, err := r.GetByID(noopCtx, limited.ID)
+		require.NoError(t, err)
+		_, err = r.ConsumeClick(noopCtx, limited.ID)
+		require.NoError(t, err)
+		result, err := r.GetByID(noopCtx, limited.ID)
+		require.NoError(t, err)
+		assert.True(t, result.IsExhausted())
+	})
+
+	t.Run("cache failure falls back to repository", func(t *testing.T) {
+		r := newRepo(t, failingCache{})
+		repo.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+
+		result, err := r.GetByID(noopCtx, tURL.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, tURL, result)
+	})
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+)
+
+type mongoCounterRepository struct {
+	Conn   *mongo.Database
+	logger *zap.Logger
+	tracer trace.Tracer
+}
+
+// NewMongoCounterRepository will create an object that represent the counter.Repository interface
+func NewMongoCounterRepository(c *mongo.Client, db string, logger *zap.Logger, tracer trace.Tracer) domain.CounterRepository {
+	return &mongoCounterRepository{
+		Conn:   c.Database(db),
+		logger: logger,
+		tracer: tracer,
+	}
+}
+
+// Lease atomically increments counter by size and returns the first value of
+// leased range [start, start+size), counter is created on first lease
+func (m *mongoCounterRepository) Lease(ctx context.Context, name string, size int64) (int64, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository Lease",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("counter", name),
+			attribute.Int64("size", size)),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "_id", Value: name}}
+	update := bson.D{{Key: "$inc", Value: bson.D{{Key: "value", Value: size}}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	counter := struct {
+		Value int64 `bson:"value"`
+	}{}
+	err := m.Conn.Collection("counter").FindOneAndUpdate(ctx, filter, update, opts).Decode(&counter)
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("counter lease error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return counter.Value - size, nil
+}
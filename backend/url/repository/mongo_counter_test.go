@@ -0,0 +1,46 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/url/repository"
+)
+
+func TestMongoCounterRepository_Lease(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{
+			{Key: "ok", Value: 1},
+			{Key: "value", Value: bson.D{
+				{Key: "_id", Value: "url_id"},
+				{Key: "value", Value: int64(2000)},
+			}},
+		})
+		r := repository.NewMongoCounterRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		start, err := r.Lease(noopCtx, "url_id", 1000)
+
+		require.NoError(mt, err)
+		assert.Equal(mt, int64(1000), start)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoCounterRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		_, err := r.Lease(noopCtx, "url_id", 1000)
+
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
@@ -2,11 +2,17 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
@@ -15,6 +21,36 @@ import (
 	"github.com/semka95/shortener/backend/store"
 )
 
+// urlCursor represents position of the last URL on the page, it is
+// used for keyset pagination by sort field and id
+type urlCursor struct {
+	Value time.Time `json:"v"`
+	ID    string    `json:"id"`
+}
+
+func encodeCursor(c urlCursor) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(s string) (*urlCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	c := new(urlCursor)
+	if err = json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// notDeleted filters out URLs marked as deleted
+var notDeleted = primitive.E{Key: "deleted_at", Value: nil}
+
 type mongoURLRepository struct {
 	Conn   *mongo.Database
 	logger *zap.Logger
@@ -84,7 +120,46 @@ func (m *mongoURLRepository) GetByID(ctx context.Context, id string) (*domain.UR
 	command := bson.D{
 		primitive.E{Key: "find", Value: "url"},
 		primitive.E{Key: "limit", Value: 1},
-		primitive.E{Key: "filter", Value: bson.D{primitive.E{Key: "_id", Value: id}}},
+		primitive.E{Key: "filter", Value: bson.D{
+			primitive.E{Key: "_id", Value: id},
+			notDeleted,
+		}},
+	}
+
+	list, err := m.fetch(ctx, command)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("URL get error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	if len(list) == 0 {
+		span.RecordError(domain.ErrNotFound)
+		return nil, fmt.Errorf("URL was not found: %w", domain.ErrNotFound)
+	}
+
+	return list[0], nil
+}
+
+// GetByDomain returns URL with the given slug on custom domain
+func (m *mongoURLRepository) GetByDomain(ctx context.Context, host, slug string) (*domain.URL, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository GetByDomain",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("host", host),
+			attribute.String("slug", slug)),
+	)
+	defer span.End()
+
+	command := bson.D{
+		primitive.E{Key: "find", Value: "url"},
+		primitive.E{Key: "limit", Value: 1},
+		primitive.E{Key: "filter", Value: bson.D{
+			primitive.E{Key: "domain", Value: host},
+			primitive.E{Key: "slug", Value: slug},
+			notDeleted,
+		}},
 	}
 
 	list, err := m.fetch(ctx, command)
@@ -112,6 +187,10 @@ func (m *mongoURLRepository) Store(ctx context.Context, url *domain.URL) error {
 	defer span.End()
 
 	_, err := m.Conn.Collection("url").InsertOne(ctx, url)
+	if mongo.IsDuplicateKeyError(err) {
+		span.RecordError(err)
+		return fmt.Errorf("URL %s already exists: %w", url.ID, domain.ErrConflict)
+	}
 	if err != nil {
 		span.RecordError(err)
 		return fmt.Errorf("URL store error: %w: %s", domain.ErrInternalServerError, err.Error())
@@ -120,6 +199,8 @@ func (m *mongoURLRepository) Store(ctx context.Context, url *domain.URL) error {
 	return nil
 }
 
+// Delete marks URL as deleted, the document is kept until it is purged, so
+// its id can't be taken by another URL meanwhile
 func (m *mongoURLRepository) Delete(ctx context.Context, id string) error {
 	ctx, span := m.tracer.Start(
 		ctx,
@@ -132,15 +213,17 @@ func (m *mongoURLRepository) Delete(ctx context.Context, id string) error {
 
 	filter := bson.D{
 		primitive.E{Key: "_id", Value: id},
+		notDeleted,
 	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "deleted_at", Value: time.Now().Truncate(time.Millisecond).UTC()}}}}
 
-	delRes, err := m.Conn.Collection("url").DeleteOne(ctx, filter)
+	updRes, err := m.Conn.Collection("url").UpdateOne(ctx, filter, update)
 	if err != nil {
 		span.RecordError(err)
 		return fmt.Errorf("URL delete error: %w: %s", domain.ErrInternalServerError, err.Error())
 	}
 
-	if delRes.DeletedCount == 0 {
+	if updRes.ModifiedCount == 0 {
 		err = fmt.Errorf("URL was not deleted: %w", domain.ErrNoAffected)
 		span.RecordError(err)
 		return err
@@ -161,19 +244,292 @@ func (m *mongoURLRepository) Update(ctx context.Context, url *domain.URL) error
 
 	filter := bson.D{
 		primitive.E{Key: "_id", Value: url.ID},
+		notDeleted,
+	}
+
+	// only fields edited by owner are set, clicks, suspension and admin
+	// moderation are changed by their own methods, so changes made after URL
+	// was read are not lost
+	fields := bson.D{
+		primitive.E{Key: "link", Value: url.Link},
+		primitive.E{Key: "title", Value: url.Title},
+		primitive.E{Key: "notes", Value: url.Notes},
+		primitive.E{Key: "expiration_date", Value: url.ExpirationDate},
+		primitive.E{Key: "redirect_type", Value: url.RedirectType},
+		primitive.E{Key: "flagged", Value: url.Flagged},
+		primitive.E{Key: "flag_reason", Value: url.FlagReason},
+		primitive.E{Key: "updated_at", Value: url.UpdatedAt},
+	}
+	update := bson.D{primitive.E{Key: "$set", Value: fields}}
+
+	updRes, err := m.Conn.Collection("url").UpdateOne(ctx, filter, update)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("URL update error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	if updRes.ModifiedCount == 0 {
+		err = fmt.Errorf("URL was not updated: %w", domain.ErrNoAffected)
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+func (m *mongoURLRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.URL, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository GetByIDs",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.Int("urls", len(ids))),
+	)
+	defer span.End()
+
+	command := bson.D{
+		primitive.E{Key: "find", Value: "url"},
+		primitive.E{Key: "filter", Value: bson.D{
+			primitive.E{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}},
+			notDeleted,
+		}},
+	}
+
+	list, err := m.fetch(ctx, command)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("URLs get error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return list, nil
+}
+
+// StoreMany inserts URLs in one unordered batch, so failed URLs don't prevent
+// others from being stored. Returned slice holds error of each URL, nil if it was stored.
+func (m *mongoURLRepository) StoreMany(ctx context.Context, urls []*domain.URL) ([]error, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository StoreMany",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.Int("urls", len(urls))),
+	)
+	defer span.End()
+
+	errs := make([]error, len(urls))
+	if len(urls) == 0 {
+		return errs, nil
+	}
+
+	docs := make([]interface{}, len(urls))
+	for i, u := range urls {
+		docs[i] = u
+	}
+
+	_, err := m.Conn.Collection("url").InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return errs, nil
+	}
+
+	var bwe mongo.BulkWriteException
+	if !errors.As(err, &bwe) || bwe.WriteConcernError != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("URLs store error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	for _, we := range bwe.WriteErrors {
+		if we.Index < 0 || we.Index >= len(urls) {
+			continue
+		}
+		if store.IsDuplicateKeyCode(we.Code) {
+			errs[we.Index] = fmt.Errorf("URL %s already exists: %w", urls[we.Index].ID, domain.ErrConflict)
+			continue
+		}
+		errs[we.Index] = fmt.Errorf("URL store error: %w: %s", domain.ErrInternalServerError, we.Message)
+	}
+
+	return errs, nil
+}
+
+func (m *mongoURLRepository) DeleteMany(ctx context.Context, ids []string) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository DeleteMany",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.Int("urls", len(ids))),
+	)
+	defer span.End()
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}},
+		notDeleted,
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "deleted_at", Value: time.Now().Truncate(time.Millisecond).UTC()}}}}
+
+	_, err := m.Conn.Collection("url").UpdateMany(ctx, filter, update)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("URLs delete error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (m *mongoURLRepository) List(ctx context.Context, query domain.URLListQuery) (*domain.URLList, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository List",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("userid", query.UserID)),
+	)
+	defer span.End()
+
+	order, cmp := -1, "$lt"
+	if query.Order == "asc" {
+		order, cmp = 1, "$gt"
+	}
+
+	filter := bson.D{notDeleted}
+	if query.UserID != "" {
+		filter = append(filter, primitive.E{Key: "user_id", Value: query.UserID})
+	}
+	if query.WorkspaceID != "" {
+		filter = append(filter, primitive.E{Key: "workspace_id", Value: query.WorkspaceID})
+	}
+	if query.Flagged {
+		filter = append(filter, primitive.E{Key: "flagged", Value: true})
+	}
+	if query.Disabled {
+		filter = append(filter, primitive.E{Key: "disabled", Value: true})
+	}
+
+	now := time.Now().UTC()
+	switch query.Status {
+	case domain.URLStatusActive:
+		filter = append(filter, primitive.E{Key: "expiration_date", Value: bson.D{primitive.E{Key: "$gt", Value: now}}})
+	case domain.URLStatusExpired:
+		filter = append(filter, primitive.E{Key: "expiration_date", Value: bson.D{primitive.E{Key: "$lte", Value: now}}})
+	}
+
+	if query.Search != "" {
+		filter = append(filter, primitive.E{Key: "link", Value: primitive.Regex{Pattern: regexp.QuoteMeta(query.Search), Options: "i"}})
+	}
+
+	if query.Cursor != "" {
+		c, err := decodeCursor(query.Cursor)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("can't decode cursor: %w: %s", domain.ErrBadParamInput, err.Error())
+		}
+		filter = append(filter, primitive.E{Key: "$or", Value: bson.A{
+			bson.D{primitive.E{Key: query.Sort, Value: bson.D{primitive.E{Key: cmp, Value: c.Value}}}},
+			bson.D{
+				primitive.E{Key: query.Sort, Value: c.Value},
+				primitive.E{Key: "_id", Value: bson.D{primitive.E{Key: cmp, Value: c.ID}}},
+			},
+		}})
+	}
+
+	// one extra document is fetched to find out whether there is a next page
+	command := bson.D{
+		primitive.E{Key: "find", Value: "url"},
+		primitive.E{Key: "filter", Value: filter},
+		primitive.E{Key: "sort", Value: bson.D{
+			primitive.E{Key: query.Sort, Value: order},
+			primitive.E{Key: "_id", Value: order},
+		}},
+		primitive.E{Key: "limit", Value: query.Limit + 1},
+	}
+
+	list, err := m.fetch(ctx, command)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("URL list error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	result := &domain.URLList{Items: list}
+	if len(list) <= query.Limit {
+		return result, nil
 	}
 
-	doc, err := store.StructToDoc(&url)
+	result.Items = list[:query.Limit]
+	last := result.Items[len(result.Items)-1]
+	c := urlCursor{Value: last.CreatedAt, ID: last.ID}
+	if query.Sort == "expiration_date" {
+		c.Value = last.ExpirationDate
+	}
+
+	result.NextCursor, err = encodeCursor(c)
 	if err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("can't convert URL to bson.D: %w, %s", domain.ErrInternalServerError, err.Error())
+		return nil, fmt.Errorf("can't encode cursor: %w: %s", domain.ErrInternalServerError, err.Error())
 	}
-	update := bson.D{primitive.E{Key: "$set", Value: doc}}
+
+	return result, nil
+}
+
+// ConsumeClick atomically decrements remaining clicks of click limited URL and
+// returns updated URL, URL without remaining clicks is reported as gone
+func (m *mongoURLRepository) ConsumeClick(ctx context.Context, id string) (*domain.URL, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository ConsumeClick",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("urlid", id)),
+	)
+	defer span.End()
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: id},
+		primitive.E{Key: "remaining_clicks", Value: bson.D{{Key: "$gt", Value: 0}}},
+		notDeleted,
+	}
+	update := bson.D{{Key: "$inc", Value: bson.D{{Key: "remaining_clicks", Value: -1}}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	u := new(domain.URL)
+	err := m.Conn.Collection("url").FindOneAndUpdate(ctx, filter, update, opts).Decode(u)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		err = fmt.Errorf("URL %s click limit is reached: %w", id, domain.ErrGone)
+		span.RecordError(err)
+		return nil, err
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("URL click consume error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return u, nil
+}
+
+// SetDisabled disables or enables URL by admin
+func (m *mongoURLRepository) SetDisabled(ctx context.Context, id string, disabled bool, at time.Time) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository SetDisabled",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("urlid", id),
+			attribute.Bool("disabled", disabled)),
+	)
+	defer span.End()
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: id},
+		notDeleted,
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "disabled", Value: disabled},
+		{Key: "updated_at", Value: at},
+	}}}
 
 	updRes, err := m.Conn.Collection("url").UpdateOne(ctx, filter, update)
 	if err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("URL update error: %w: %s", domain.ErrInternalServerError, err.Error())
+		return fmt.Errorf("URL disable error: %w: %s", domain.ErrInternalServerError, err.Error())
 	}
 
 	if updRes.ModifiedCount == 0 {
@@ -184,3 +540,343 @@ func (m *mongoURLRepository) Update(ctx context.Context, url *domain.URL) error
 
 	return nil
 }
+
+// SetOwnerSuspended marks all URLs of user as suspended or active and returns
+// their ids
+func (m *mongoURLRepository) SetOwnerSuspended(ctx context.Context, userID string, suspended bool) ([]string, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository SetOwnerSuspended",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("userid", userID),
+			attribute.Bool("suspended", suspended)),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "user_id", Value: userID}}
+	update := bson.D{{Key: "$unset", Value: bson.D{{Key: "owner_suspended", Value: ""}}}}
+	if suspended {
+		update = bson.D{{Key: "$set", Value: bson.D{{Key: "owner_suspended", Value: true}}}}
+	}
+
+	_, err := m.Conn.Collection("url").UpdateMany(ctx, filter, update)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("URLs owner suspension error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	command := bson.D{
+		primitive.E{Key: "find", Value: "url"},
+		primitive.E{Key: "filter", Value: filter},
+		primitive.E{Key: "projection", Value: bson.D{primitive.E{Key: "_id", Value: 1}}},
+	}
+
+	list, err := m.fetch(ctx, command)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("URL get error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	ids := make([]string, len(list))
+	for i, u := range list {
+		ids[i] = u.ID
+	}
+
+	return ids, nil
+}
+
+// DeleteByUser marks all URLs of user as deleted and returns their ids
+func (m *mongoURLRepository) DeleteByUser(ctx context.Context, userID string) ([]string, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository DeleteByUser",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("userid", userID)),
+	)
+	defer span.End()
+
+	ids, err := m.listIDs(ctx, bson.D{primitive.E{Key: "user_id", Value: userID}, notDeleted})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	filter := bson.D{primitive.E{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "deleted_at", Value: time.Now().Truncate(time.Millisecond).UTC()}}}}
+
+	_, err = m.Conn.Collection("url").UpdateMany(ctx, filter, update)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("URLs delete error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return ids, nil
+}
+
+// SetOwner moves all URLs of user, including deleted ones, to another user and
+// returns their ids. If to is empty, URLs become ownerless.
+func (m *mongoURLRepository) SetOwner(ctx context.Context, from, to string) ([]string, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository SetOwner",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("userid", from),
+			attribute.String("to", to)),
+	)
+	defer span.End()
+
+	ids, err := m.listIDs(ctx, bson.D{primitive.E{Key: "user_id", Value: from}})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	// suspension of previous owner doesn't apply to new one
+	filter := bson.D{primitive.E{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "user_id", Value: to}}},
+		{Key: "$unset", Value: bson.D{{Key: "owner_suspended", Value: ""}}},
+	}
+
+	_, err = m.Conn.Collection("url").UpdateMany(ctx, filter, update)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("URLs owner update error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return ids, nil
+}
+
+// SetWorkspace moves URLs of workspace from to workspace to and returns ids
+// of moved URLs, URLs of other workspaces are not changed. URLs of custom
+// domains stay in workspace which owns the domain.
+func (m *mongoURLRepository) SetWorkspace(ctx context.Context, ids []string, from, to string) ([]string, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository SetWorkspace",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("workspaceid", from),
+			attribute.String("to", to)),
+	)
+	defer span.End()
+
+	moved, err := m.listIDs(ctx, bson.D{
+		notDeleted,
+		primitive.E{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}},
+		primitive.E{Key: "workspace_id", Value: from},
+		primitive.E{Key: "domain", Value: bson.D{{Key: "$exists", Value: false}}},
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if len(moved) == 0 {
+		return moved, nil
+	}
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: bson.D{{Key: "$in", Value: moved}}},
+		primitive.E{Key: "workspace_id", Value: from},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "workspace_id", Value: to},
+		{Key: "updated_at", Value: time.Now().Truncate(time.Millisecond).UTC()},
+	}}}
+
+	_, err = m.Conn.Collection("url").UpdateMany(ctx, filter, update)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("URLs workspace update error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return moved, nil
+}
+
+func (m *mongoURLRepository) listIDs(ctx context.Context, filter bson.D) ([]string, error) {
+	command := bson.D{
+		primitive.E{Key: "find", Value: "url"},
+		primitive.E{Key: "filter", Value: filter},
+		primitive.E{Key: "projection", Value: bson.D{primitive.E{Key: "_id", Value: 1}}},
+	}
+
+	list, err := m.fetch(ctx, command)
+	if err != nil {
+		return nil, fmt.Errorf("URL get error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	ids := make([]string, len(list))
+	for i, u := range list {
+		ids[i] = u.ID
+	}
+
+	return ids, nil
+}
+
+// GetDeleted returns URL marked as deleted, so it can be restored
+func (m *mongoURLRepository) GetDeleted(ctx context.Context, id string) (*domain.URL, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository GetDeleted",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("urlid", id)),
+	)
+	defer span.End()
+
+	command := bson.D{
+		primitive.E{Key: "find", Value: "url"},
+		primitive.E{Key: "limit", Value: 1},
+		primitive.E{Key: "filter", Value: bson.D{
+			primitive.E{Key: "_id", Value: id},
+			primitive.E{Key: "deleted_at", Value: bson.D{primitive.E{Key: "$ne", Value: nil}}},
+		}},
+	}
+
+	list, err := m.fetch(ctx, command)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("URL get error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	if len(list) == 0 {
+		span.RecordError(domain.ErrNotFound)
+		return nil, fmt.Errorf("deleted URL was not found: %w", domain.ErrNotFound)
+	}
+
+	return list[0], nil
+}
+
+// Restore removes deletion mark of URL
+func (m *mongoURLRepository) Restore(ctx context.Context, id string) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository Restore",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("urlid", id)),
+	)
+	defer span.End()
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: id},
+		primitive.E{Key: "deleted_at", Value: bson.D{primitive.E{Key: "$ne", Value: nil}}},
+	}
+	update := bson.D{{Key: "$unset", Value: bson.D{{Key: "deleted_at", Value: ""}}}}
+
+	updRes, err := m.Conn.Collection("url").UpdateOne(ctx, filter, update)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("URL restore error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	if updRes.ModifiedCount == 0 {
+		err = fmt.Errorf("URL was not restored: %w", domain.ErrNoAffected)
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// ListDeleted returns ids of URLs deleted before the given time, oldest first
+func (m *mongoURLRepository) ListDeleted(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository ListDeleted",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	command := bson.D{
+		primitive.E{Key: "find", Value: "url"},
+		primitive.E{Key: "filter", Value: bson.D{
+			primitive.E{Key: "deleted_at", Value: bson.D{
+				primitive.E{Key: "$ne", Value: nil},
+				primitive.E{Key: "$lte", Value: before.UTC()},
+			}},
+		}},
+		primitive.E{Key: "sort", Value: bson.D{primitive.E{Key: "deleted_at", Value: 1}}},
+		primitive.E{Key: "projection", Value: bson.D{primitive.E{Key: "_id", Value: 1}}},
+		primitive.E{Key: "limit", Value: limit},
+	}
+
+	list, err := m.fetch(ctx, command)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("deleted URLs list error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	ids := make([]string, len(list))
+	for i, u := range list {
+		ids[i] = u.ID
+	}
+
+	return ids, nil
+}
+
+// ListExpired returns URLs which are not deleted and expired before the
+// given time, the earliest expired first
+func (m *mongoURLRepository) ListExpired(ctx context.Context, before time.Time, limit int) ([]*domain.URL, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository ListExpired",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	command := bson.D{
+		primitive.E{Key: "find", Value: "url"},
+		primitive.E{Key: "filter", Value: bson.D{
+			primitive.E{Key: "expiration_date", Value: bson.D{
+				primitive.E{Key: "$lte", Value: before.UTC()},
+			}},
+			notDeleted,
+		}},
+		primitive.E{Key: "sort", Value: bson.D{primitive.E{Key: "expiration_date", Value: 1}}},
+		primitive.E{Key: "limit", Value: limit},
+	}
+
+	list, err := m.fetch(ctx, command)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("expired URLs list error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return list, nil
+}
+
+// Purge removes URLs marked as deleted for good, URLs which are not marked
+// are kept
+func (m *mongoURLRepository) Purge(ctx context.Context, ids []string) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository Purge",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.Int("urls", len(ids))),
+	)
+	defer span.End()
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}},
+		primitive.E{Key: "deleted_at", Value: bson.D{primitive.E{Key: "$ne", Value: nil}}},
+	}
+
+	_, err := m.Conn.Collection("url").DeleteMany(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("URLs purge error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
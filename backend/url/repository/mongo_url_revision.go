@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+)
+
+const urlRevisionCollection = "url_revision"
+
+type mongoURLRevisionRepository struct {
+	Conn   *mongo.Database
+	logger *zap.Logger
+	tracer trace.Tracer
+}
+
+// NewMongoURLRevisionRepository will create an object that represent the url.RevisionRepository interface
+func NewMongoURLRevisionRepository(c *mongo.Client, db string, logger *zap.Logger, tracer trace.Tracer) domain.URLRevisionRepository {
+	return &mongoURLRevisionRepository{
+		Conn:   c.Database(db),
+		logger: logger,
+		tracer: tracer,
+	}
+}
+
+func (m *mongoURLRevisionRepository) Store(ctx context.Context, r *domain.URLRevision) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository StoreRevision",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("urlid", r.URLID)),
+	)
+	defer span.End()
+
+	_, err := m.Conn.Collection(urlRevisionCollection).InsertOne(ctx, r)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("URL revision store error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (m *mongoURLRevisionRepository) GetByID(ctx context.Context, urlID string, id primitive.ObjectID) (*domain.URLRevision, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository GetRevisionByID",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("urlid", urlID)),
+	)
+	defer span.End()
+
+	// revision is looked up together with URL id, so revisions of other URLs can't be used
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: id},
+		primitive.E{Key: "url_id", Value: urlID},
+	}
+
+	r := new(domain.URLRevision)
+	err := m.Conn.Collection(urlRevisionCollection).FindOne(ctx, filter).Decode(r)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		span.RecordError(domain.ErrNotFound)
+		return nil, fmt.Errorf("URL revision was not found: %w", domain.ErrNotFound)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("URL revision get error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return r, nil
+}
+
+// List returns the latest revisions of URL, newest first
+func (m *mongoURLRevisionRepository) List(ctx context.Context, urlID string, limit int) ([]*domain.URLRevision, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository ListRevisions",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("urlid", urlID)),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "url_id", Value: urlID}}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cur, err := m.Conn.Collection(urlRevisionCollection).Find(ctx, filter, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("URL revision list error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	result := make([]*domain.URLRevision, 0)
+	if err = cur.All(ctx, &result); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("can't decode URL revisions: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return result, nil
+}
+
+func (m *mongoURLRevisionRepository) DeleteByURL(ctx context.Context, urlIDs ...string) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository DeleteRevisions",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.StringSlice("urlids", urlIDs)),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "url_id", Value: bson.D{{Key: "$in", Value: urlIDs}}}}
+
+	_, err := m.Conn.Collection(urlRevisionCollection).DeleteMany(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("URL revisions delete error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/tests"
+	"github.com/semka95/shortener/backend/url/repository"
+)
+
+const revisionTableName = "shortener.url_revision"
+
+func urlRevisionBsonD(r *domain.URLRevision) bson.D {
+	return bson.D{
+		{Key: "_id", Value: r.ID},
+		{Key: "url_id", Value: r.URLID},
+		{Key: "link", Value: r.Link},
+		{Key: "title", Value: r.Title},
+		{Key: "redirect_type", Value: r.RedirectType},
+		{Key: "expiration_date", Value: r.ExpirationDate},
+		{Key: "changed_by", Value: r.ChangedBy},
+		{Key: "created_at", Value: r.CreatedAt},
+	}
+}
+
+func TestMongoURLRevisionRepository_Store(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tRevision := tests.NewURLRevision()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		r := repository.NewMongoURLRevisionRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Store(noopCtx, tRevision)
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   1,
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoURLRevisionRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Store(noopCtx, tRevision)
+
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoURLRevisionRepository_GetByID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tRevision := tests.NewURLRevision()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, revisionTableName, mtest.FirstBatch, urlRevisionBsonD(tRevision)))
+		r := repository.NewMongoURLRevisionRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetByID(noopCtx, tRevision.URLID, tRevision.ID)
+
+		require.NoError(mt, err)
+		assert.EqualValues(mt, tRevision, result)
+	})
+
+	mt.Run("not exists", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, revisionTableName, mtest.FirstBatch))
+		r := repository.NewMongoURLRevisionRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetByID(noopCtx, tRevision.URLID, tRevision.ID)
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrNotFound)
+	})
+}
+
+func TestMongoURLRevisionRepository_List(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tRevision := tests.NewURLRevision()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, revisionTableName, mtest.FirstBatch, urlRevisionBsonD(tRevision)))
+		r := repository.NewMongoURLRevisionRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.List(noopCtx, tRevision.URLID, 10)
+
+		require.NoError(mt, err)
+		require.Len(mt, result, 1)
+		assert.EqualValues(mt, tRevision, result[0])
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoURLRevisionRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.List(noopCtx, tRevision.URLID, 10)
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoURLRevisionRepository_DeleteByURL(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 3}})
+		r := repository.NewMongoURLRevisionRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.DeleteByURL(noopCtx, "test123")
+
+		require.NoError(mt, err)
+	})
+}
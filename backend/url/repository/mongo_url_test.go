@@ -3,6 +3,7 @@ package repository_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -93,6 +94,19 @@ func TestMongoURLRepository_Store(t *testing.T) {
 
 		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
 	})
+
+	mt.Run("duplicate id", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    11000,
+			Message: "duplicate key error",
+		}))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Store(noopCtx, tURL)
+
+		assert.ErrorIs(mt, err, domain.ErrConflict)
+	})
 }
 
 func TestMongoURLRepository_Delete(t *testing.T) {
@@ -121,6 +135,7 @@ func TestMongoURLRepository_Delete(t *testing.T) {
 				{Key: "ok", Value: 1},
 				{Key: "acknowledged", Value: true},
 				{Key: "n", Value: 1},
+				{Key: "nModified", Value: 1},
 			},
 		)
 		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
@@ -175,6 +190,45 @@ func TestMongoURLRepository_Update(t *testing.T) {
 		require.NoError(mt, err)
 	})
 
+	mt.Run("clear title and notes", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{
+			{Key: "ok", Value: 1},
+			{Key: "nModified", Value: 1},
+		})
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+		cleared := tests.NewURL()
+		cleared.Title = ""
+		cleared.Notes = ""
+
+		err := r.Update(noopCtx, cleared)
+
+		require.NoError(mt, err)
+		set := mt.GetStartedEvent().Command.Lookup("updates", "0", "u", "$set").Document()
+		title, err := set.LookupErr("title")
+		require.NoError(mt, err)
+		assert.Equal(mt, "", title.StringValue())
+		notes, err := set.LookupErr("notes")
+		require.NoError(mt, err)
+		assert.Equal(mt, "", notes.StringValue())
+	})
+
+	mt.Run("moderation is kept", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{
+			{Key: "ok", Value: 1},
+			{Key: "nModified", Value: 1},
+		})
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Update(noopCtx, tURL)
+
+		require.NoError(mt, err)
+		set := mt.GetStartedEvent().Command.Lookup("updates", "0", "u", "$set").Document()
+		for _, key := range []string{"disabled", "owner_suspended", "remaining_clicks", "deleted_at"} {
+			_, err = set.LookupErr(key)
+			assert.Error(mt, err, key)
+		}
+	})
+
 	mt.Run("server error", func(mt *mtest.T) {
 		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
 			Index:   1,
@@ -188,3 +242,562 @@ func TestMongoURLRepository_Update(t *testing.T) {
 		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
 	})
 }
+
+func TestMongoURLRepository_List(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tURL := tests.NewURL()
+	tURLBsonD := tests.NewURLBsonD()
+	query := domain.URLListQuery{
+		Limit:  1,
+		Sort:   "created_at",
+		Order:  "desc",
+		Status: domain.URLStatusActive,
+		Search: "example.org",
+		UserID: tURL.UserID,
+	}
+
+	mt.Run("success with next page", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, tableName, mtest.FirstBatch, tURLBsonD, tURLBsonD),
+			mtest.CreateCursorResponse(0, tableName, mtest.NextBatch),
+		)
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.List(noopCtx, query)
+
+		require.NoError(mt, err)
+		require.Len(mt, result.Items, 1)
+		assert.EqualValues(mt, tURL, result.Items[0])
+		assert.NotEmpty(mt, result.NextCursor)
+
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, tableName, mtest.FirstBatch, tURLBsonD),
+			mtest.CreateCursorResponse(0, tableName, mtest.NextBatch),
+		)
+		next := query
+		next.Cursor = result.NextCursor
+
+		result, err = r.List(noopCtx, next)
+
+		require.NoError(mt, err)
+		assert.Len(mt, result.Items, 1)
+		assert.Empty(mt, result.NextCursor)
+	})
+
+	mt.Run("bad cursor", func(mt *mtest.T) {
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+		next := query
+		next.Cursor = "not a cursor"
+
+		result, err := r.List(noopCtx, next)
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrBadParamInput)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.List(noopCtx, query)
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoURLRepository_StoreMany(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	first := tests.NewURL()
+	second := tests.NewURL()
+	second.ID = "test456"
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		errs, err := r.StoreMany(noopCtx, []*domain.URL{first, second})
+
+		require.NoError(mt, err)
+		assert.Equal(mt, []error{nil, nil}, errs)
+	})
+
+	mt.Run("duplicate key", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   1,
+			Code:    11000,
+			Message: "duplicate key error",
+		}))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		errs, err := r.StoreMany(noopCtx, []*domain.URL{first, second})
+
+		require.NoError(mt, err)
+		require.Len(mt, errs, 2)
+		assert.NoError(mt, errs[0])
+		assert.ErrorIs(mt, errs[1], domain.ErrConflict)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		errs, err := r.StoreMany(noopCtx, []*domain.URL{first, second})
+
+		assert.Nil(mt, errs)
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoURLRepository_GetByDomain(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tURL := tests.NewURL()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, tests.NewURLBsonD()))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetByDomain(noopCtx, "go.example.com", "promo")
+
+		require.NoError(mt, err)
+		assert.Equal(mt, tURL.ID, result.ID)
+	})
+
+	mt.Run("not exists", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetByDomain(noopCtx, "go.example.com", "promo")
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrNotFound)
+	})
+}
+
+func TestMongoURLRepository_GetByIDs(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tURL := tests.NewURL()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, tests.NewURLBsonD()))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetByIDs(noopCtx, []string{tURL.ID, "missing"})
+
+		require.NoError(mt, err)
+		require.Len(mt, result, 1)
+		assert.Equal(mt, tURL.ID, result[0].ID)
+	})
+}
+
+func TestMongoURLRepository_DeleteMany(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 2}})
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.DeleteMany(noopCtx, []string{"test123", "test456"})
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.DeleteMany(noopCtx, []string{"test123"})
+
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoURLRepository_ConsumeClick(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tURL := tests.NewURL()
+	tURL.MaxClicks = 3
+	tURL.RemainingClicks = 2
+
+	mt.Run("success", func(mt *mtest.T) {
+		doc := append(tests.NewURLBsonD(),
+			bson.E{Key: "max_clicks", Value: int64(3)},
+			bson.E{Key: "remaining_clicks", Value: int64(2)},
+		)
+		mt.AddMockResponses(bson.D{
+			{Key: "ok", Value: 1},
+			{Key: "value", Value: doc},
+		})
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.ConsumeClick(noopCtx, tURL.ID)
+
+		require.NoError(mt, err)
+		assert.EqualValues(mt, tURL, result)
+	})
+
+	mt.Run("click limit reached", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{
+			{Key: "ok", Value: 1},
+			{Key: "value", Value: nil},
+		})
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.ConsumeClick(noopCtx, tURL.ID)
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrGone)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.ConsumeClick(noopCtx, tURL.ID)
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoURLRepository_SetDisabled(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tURL := tests.NewURL()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.SetDisabled(noopCtx, tURL.ID, true, time.Now())
+
+		require.NoError(mt, err)
+		set := mt.GetStartedEvent().Command.Lookup("updates", "0", "u", "$set").Document()
+		elems, err := set.Elements()
+		require.NoError(mt, err)
+		assert.Len(mt, elems, 2)
+		assert.True(mt, set.Lookup("disabled").Boolean())
+	})
+
+	mt.Run("not exists", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.SetDisabled(noopCtx, tURL.ID, false, time.Now())
+
+		assert.ErrorIs(mt, err, domain.ErrNoAffected)
+	})
+}
+
+func TestMongoURLRepository_SetOwnerSuspended(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tURL := tests.NewURL()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}},
+			mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, bson.D{{Key: "_id", Value: tURL.ID}}),
+		)
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		ids, err := r.SetOwnerSuspended(noopCtx, tURL.UserID, true)
+
+		require.NoError(mt, err)
+		assert.Equal(mt, []string{tURL.ID}, ids)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		ids, err := r.SetOwnerSuspended(noopCtx, tURL.UserID, false)
+
+		assert.Nil(mt, ids)
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoURLRepository_DeleteByUser(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tURL := tests.NewURL()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, bson.D{{Key: "_id", Value: tURL.ID}}),
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}},
+		)
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		ids, err := r.DeleteByUser(noopCtx, tURL.UserID)
+
+		require.NoError(mt, err)
+		assert.Equal(mt, []string{tURL.ID}, ids)
+	})
+
+	mt.Run("no URLs", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		ids, err := r.DeleteByUser(noopCtx, tURL.UserID)
+
+		require.NoError(mt, err)
+		assert.Empty(mt, ids)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, bson.D{{Key: "_id", Value: tURL.ID}}),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{
+				Code:    123,
+				Message: "server error",
+			}),
+		)
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		ids, err := r.DeleteByUser(noopCtx, tURL.UserID)
+
+		assert.Nil(mt, ids)
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoURLRepository_SetOwner(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tURL := tests.NewURL()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, bson.D{{Key: "_id", Value: tURL.ID}}),
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}},
+		)
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		ids, err := r.SetOwner(noopCtx, tURL.UserID, "")
+
+		require.NoError(mt, err)
+		assert.Equal(mt, []string{tURL.ID}, ids)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		ids, err := r.SetOwner(noopCtx, tURL.UserID, "507f191e810c19729de860eb")
+
+		assert.Nil(mt, ids)
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoURLRepository_SetWorkspace(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tURL := tests.NewURL()
+	from, to := "507f191e810c19729de860ea", "507f191e810c19729de860eb"
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, bson.D{{Key: "_id", Value: tURL.ID}}),
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}},
+		)
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		ids, err := r.SetWorkspace(noopCtx, []string{tURL.ID, "other"}, from, to)
+
+		require.NoError(mt, err)
+		assert.Equal(mt, []string{tURL.ID}, ids)
+	})
+
+	mt.Run("nothing to move", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		ids, err := r.SetWorkspace(noopCtx, []string{tURL.ID}, from, to)
+
+		require.NoError(mt, err)
+		assert.Empty(mt, ids)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, bson.D{{Key: "_id", Value: tURL.ID}}),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{
+				Code:    123,
+				Message: "server error",
+			}),
+		)
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		ids, err := r.SetWorkspace(noopCtx, []string{tURL.ID}, from, to)
+
+		assert.Nil(mt, ids)
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoURLRepository_GetDeleted(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	deletedAt := time.Now().Truncate(time.Millisecond).UTC()
+	tURL := tests.NewURL()
+	tURL.DeletedAt = &deletedAt
+	tURLBsonD := append(tests.NewURLBsonD(), bson.E{Key: "deleted_at", Value: deletedAt})
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, tURLBsonD))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetDeleted(noopCtx, tURL.ID)
+
+		require.NoError(mt, err)
+		assert.EqualValues(mt, tURL, result)
+	})
+
+	mt.Run("not deleted", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetDeleted(noopCtx, tURL.ID)
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrNotFound)
+	})
+}
+
+func TestMongoURLRepository_Restore(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Restore(noopCtx, "test123")
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("not deleted", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Restore(noopCtx, "test123")
+
+		assert.ErrorIs(mt, err, domain.ErrNoAffected)
+	})
+}
+
+func TestMongoURLRepository_ListDeleted(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: "test123"}},
+			bson.D{{Key: "_id", Value: "test456"}},
+		))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		ids, err := r.ListDeleted(noopCtx, time.Now(), 10)
+
+		require.NoError(mt, err)
+		assert.Equal(mt, []string{"test123", "test456"}, ids)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		ids, err := r.ListDeleted(noopCtx, time.Now(), 10)
+
+		assert.Nil(mt, ids)
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoURLRepository_ListExpired(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tURL := tests.NewURL()
+	tURLBsonD := tests.NewURLBsonD()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, tURLBsonD))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		urls, err := r.ListExpired(noopCtx, time.Now(), 10)
+
+		require.NoError(mt, err)
+		require.Len(mt, urls, 1)
+		assert.EqualValues(mt, tURL, urls[0])
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		urls, err := r.ListExpired(noopCtx, time.Now(), 10)
+
+		assert.Nil(mt, urls)
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoURLRepository_Purge(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 2}})
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Purge(noopCtx, []string{"test123", "test456"})
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoURLRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Purge(noopCtx, []string{"test123"})
+
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
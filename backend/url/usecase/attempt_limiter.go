@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/semka95/shortener/backend/middleware/ratelimit"
+)
+
+// attemptLimiter limits failed attempts per key, every attempt takes token
+// from bucket of rate limit store which refills max tokens per window, and
+// successful attempts give it back. Token is taken before attempt is made,
+// so concurrent attempts can't get past the limit. Attempts are limited by
+// all instances together if store is shared.
+type attemptLimiter struct {
+	store  ratelimit.Store
+	prefix string
+	rate   float64
+	max    int
+}
+
+func newAttemptLimiter(store ratelimit.Store, prefix string, max int, window time.Duration) *attemptLimiter {
+	return &attemptLimiter{
+		store:  store,
+		prefix: prefix,
+		rate:   float64(max) / window.Seconds(),
+		max:    max,
+	}
+}
+
+// allow takes token for attempt for key and reports whether attempt can be made
+func (l *attemptLimiter) allow(ctx context.Context, key string, now time.Time) (bool, error) {
+	_, allowed, err := l.store.Take(ctx, l.prefix+key, l.rate, l.max, now)
+	return allowed, err
+}
+
+// succeed gives back token of successful attempt for key
+func (l *attemptLimiter) succeed(ctx context.Context, key string, now time.Time) error {
+	return l.store.Refund(ctx, l.prefix+key, l.rate, l.max, now)
+}
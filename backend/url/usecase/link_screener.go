@@ -0,0 +1,337 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+)
+
+type noopLinkScreener struct{}
+
+// NewNoopLinkScreener will create LinkScreener which never blocks links
+func NewNoopLinkScreener() domain.LinkScreener {
+	return noopLinkScreener{}
+}
+
+func (noopLinkScreener) Screen(context.Context, string) (domain.ScreenResult, error) {
+	return domain.ScreenResult{}, nil
+}
+
+// DomainList holds blocked and allowed destination domains loaded from file,
+// domain matches its subdomains too. The file is reloaded by Watch when it
+// changes, so lists can be updated without restart.
+type DomainList struct {
+	path    string
+	mu      sync.RWMutex
+	block   map[string]struct{}
+	allow   map[string]struct{}
+	modTime time.Time
+}
+
+// NewDomainList will load DomainList from file, where each line contains
+// domain and action separated by comma, action is either block or allow:
+//
+//	phishing.example,block
+//	trusted.example,allow
+//
+// Empty lines and lines starting with # are ignored.
+func NewDomainList(path string) (*DomainList, error) {
+	l := &DomainList{path: path}
+	if _, err := l.Reload(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Reload loads file again if it was modified since the last load, lists
+// are kept unchanged if the file can't be loaded
+func (l *DomainList) Reload() (bool, error) {
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return false, fmt.Errorf("can't stat domain list file: %w", err)
+	}
+
+	l.mu.RLock()
+	modified := !info.ModTime().Equal(l.modTime)
+	l.mu.RUnlock()
+	if !modified {
+		return false, nil
+	}
+
+	block, allow, err := readDomainList(l.path)
+	if err != nil {
+		return false, err
+	}
+
+	l.mu.Lock()
+	l.block, l.allow, l.modTime = block, allow, info.ModTime()
+	l.mu.Unlock()
+
+	return true, nil
+}
+
+// Watch reloads file every interval until ctx is done
+func (l *DomainList) Watch(ctx context.Context, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := l.Reload()
+			if err != nil {
+				logger.Error("can't reload domain list", zap.String("path", l.path), zap.Error(err))
+				continue
+			}
+			if reloaded {
+				logger.Info("domain list reloaded", zap.String("path", l.path))
+			}
+		}
+	}
+}
+
+// match returns action of the closest listed parent domain of host,
+// allow wins if domain is in both lists
+func (l *DomainList) match(host string) (blocked, allowed bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for d := host; d != ""; {
+		if _, ok := l.allow[d]; ok {
+			return false, true
+		}
+		if _, ok := l.block[d]; ok {
+			return true, false
+		}
+
+		_, parent, found := strings.Cut(d, ".")
+		if !found {
+			break
+		}
+		d = parent
+	}
+
+	return false, false
+}
+
+func readDomainList(path string) (block, allow map[string]struct{}, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't open domain list file: %w", err)
+	}
+	defer f.Close()
+
+	block = make(map[string]struct{})
+	allow = make(map[string]struct{})
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, action, found := strings.Cut(line, ",")
+		if !found {
+			return nil, nil, fmt.Errorf("domain list line %d: missing action", n)
+		}
+		name = normalizeHost(name)
+		if name == "" {
+			return nil, nil, fmt.Errorf("domain list line %d: missing domain", n)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(action)) {
+		case "block":
+			block[name] = struct{}{}
+		case "allow":
+			allow[name] = struct{}{}
+		default:
+			return nil, nil, fmt.Errorf("domain list line %d: unknown action %q", n, action)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("can't read domain list file: %w", err)
+	}
+
+	return block, allow, nil
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+type linkScreener struct {
+	ownHosts   map[string]struct{}
+	list       *DomainList
+	reputation domain.LinkScreener
+	resolver   domain.IPResolver
+	logger     *zap.Logger
+	tracer     trace.Tracer
+}
+
+// NewLinkScreener will create LinkScreener which blocks links to the
+// shortener's own hosts, as they make redirect loops, links to loopback and
+// private addresses and links to blocked domains. Links to allowed domains
+// are not checked by reputation, which is an optional external check. list
+// and reputation can be nil. Domains are resolved by resolver, so domains
+// pointing to private addresses are blocked too.
+func NewLinkScreener(ownHosts []string, list *DomainList, reputation domain.LinkScreener, resolver domain.IPResolver, logger *zap.Logger, tracer trace.Tracer) domain.LinkScreener {
+	hosts := make(map[string]struct{}, len(ownHosts))
+	for _, h := range ownHosts {
+		hosts[normalizeHost(h)] = struct{}{}
+	}
+
+	return &linkScreener{
+		ownHosts:   hosts,
+		list:       list,
+		reputation: reputation,
+		resolver:   resolver,
+		logger:     logger,
+		tracer:     tracer,
+	}
+}
+
+func (s *linkScreener) Screen(ctx context.Context, link string) (domain.ScreenResult, error) {
+	ctx, span := s.tracer.Start(
+		ctx,
+		"usecase Screen",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	u, err := url.Parse(link)
+	if err != nil {
+		span.RecordError(err)
+		return domain.ScreenResult{}, fmt.Errorf("can't parse link: %w: %s", domain.ErrBadParamInput, err.Error())
+	}
+	host := normalizeHost(u.Hostname())
+	span.SetAttributes(attribute.String("host", host))
+
+	if _, ok := s.ownHosts[host]; ok {
+		return domain.ScreenResult{Blocked: true, Reason: "link points to the shortener itself"}, nil
+	}
+
+	if s.isLocalHost(ctx, host) {
+		return domain.ScreenResult{Blocked: true, Reason: "link points to local or private network"}, nil
+	}
+
+	if s.list != nil {
+		blocked, allowed := s.list.match(host)
+		if blocked {
+			return domain.ScreenResult{Blocked: true, Reason: "link domain is blocked"}, nil
+		}
+		if allowed {
+			return domain.ScreenResult{}, nil
+		}
+	}
+
+	if s.reputation == nil {
+		return domain.ScreenResult{}, nil
+	}
+
+	// reputation service must not stop link creation, so its failures are ignored
+	res, err := s.reputation.Screen(ctx, link)
+	if err != nil {
+		span.RecordError(err)
+		s.logger.Error("link reputation check failed", zap.String("host", host), zap.Error(err))
+		return domain.ScreenResult{}, nil
+	}
+
+	return res, nil
+}
+
+// isLocalHost reports whether host is loopback or private network address
+// or domain which resolves to such address. Domains which can't be resolved
+// are not local, they may be registered later.
+func (s *linkScreener) isLocalHost(ctx context.Context, host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+
+	if ip := parseIP(host); ip != nil {
+		return isLocalIP(ip)
+	}
+
+	addrs, err := s.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		s.logger.Warn("can't resolve link host", zap.String("host", host), zap.Error(err))
+		return false
+	}
+	for _, a := range addrs {
+		if isLocalIP(a.IP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isLocalIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil && ip4[0] == 0 {
+		return true
+	}
+
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast()
+}
+
+// parseIP parses IP address like browsers do, IPv4 address may have less
+// than four parts and its parts may be octal or hexadecimal, e.g. 127.1,
+// 0x7f.1 and 2130706433 are all 127.0.0.1
+func parseIP(host string) net.IP {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip
+	}
+
+	parts := strings.Split(host, ".")
+	if len(parts) > 4 {
+		return nil
+	}
+
+	nums := make([]uint64, len(parts))
+	for i, p := range parts {
+		base := 10
+		switch {
+		case strings.HasPrefix(p, "0x") || strings.HasPrefix(p, "0X"):
+			p, base = p[2:], 16
+		case len(p) > 1 && p[0] == '0':
+			p, base = p[1:], 8
+		}
+		n, err := strconv.ParseUint(p, base, 32)
+		if err != nil {
+			return nil
+		}
+		nums[i] = n
+	}
+
+	// the last part fills the rest of address, the others are single bytes
+	var addr uint64
+	for _, n := range nums[:len(nums)-1] {
+		if n > 0xff {
+			return nil
+		}
+		addr = addr<<8 | n
+	}
+	last := nums[len(nums)-1]
+	if last >= 1<<(8*(5-len(nums))) {
+		return nil
+	}
+	addr = addr<<(8*(5-len(nums))) | last
+
+	return net.IPv4(byte(addr>>24), byte(addr>>16), byte(addr>>8), byte(addr))
+}
@@ -0,0 +1,168 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/url/mock"
+	"github.com/semka95/shortener/backend/url/usecase"
+)
+
+func writeDomainList(t *testing.T, path, content string, modTime time.Time) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+// fakeResolver resolves hosts to listed addresses, other hosts don't exist
+type fakeResolver map[string][]string
+
+func (r fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	addrs := make([]net.IPAddr, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+	return addrs, nil
+}
+
+func TestLinkScreener(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	path := filepath.Join(t.TempDir(), "domains.csv")
+	writeDomainList(t, path, "# test list\nphishing.example,block\n\nsafe.phishing.example,allow\ntrusted.example,allow\n", time.Now())
+	list, err := usecase.NewDomainList(path)
+	require.NoError(t, err)
+
+	reputation := mock.NewMockLinkScreener(controller)
+	resolver := fakeResolver{
+		"www.example.com":       {"93.184.216.34"},
+		"internal.example.com":  {"10.0.0.5"},
+		"metadata.example.com":  {"169.254.169.254"},
+		"mixed.example.com":     {"93.184.216.34", "127.0.0.1"},
+		"rebind.example.com":    {"::1"},
+		"malware.example.org":   {"198.51.100.7"},
+		"www.example.net":       {"203.0.113.9"},
+		"bank.phishing.example": {"198.51.100.8"},
+	}
+	screener := usecase.NewLinkScreener([]string{"Short.Example"}, list, reputation, resolver, zap.NewNop(), tracer)
+
+	cases := []struct {
+		description string
+		link        string
+		mockCalls   func()
+		blocked     bool
+	}{
+		{"own host", "https://short.example/abcdef", func() {}, true},
+		{"localhost", "http://localhost:8080/admin", func() {}, true},
+		{"loopback ip", "http://127.0.0.1/", func() {}, true},
+		{"ipv6 loopback", "http://[::1]:9000/", func() {}, true},
+		{"private ip", "http://192.168.1.1/router", func() {}, true},
+		{"decimal ip", "http://2130706433/", func() {}, true},
+		{"hexadecimal ip", "http://0x7f.1/", func() {}, true},
+		{"short ip", "http://127.1/", func() {}, true},
+		{"octal ip", "http://0177.0.0.01/", func() {}, true},
+		{"hexadecimal private ip", "http://0xa000001/", func() {}, true},
+		{"ipv4 mapped ipv6 loopback", "http://[::ffff:127.0.0.1]/", func() {}, true},
+		{"this network ip", "http://0.0.0.0:8080/", func() {}, true},
+		{"domain resolves to private ip", "http://internal.example.com/", func() {}, true},
+		{"domain resolves to link local ip", "http://metadata.example.com/latest/meta-data/", func() {}, true},
+		{"domain resolves to loopback among other ips", "http://mixed.example.com/", func() {}, true},
+		{"domain resolves to ipv6 loopback", "http://rebind.example.com/", func() {}, true},
+		{"public ip", "http://93.184.216.34/", func() {
+			reputation.EXPECT().Screen(gomock.Any(), "http://93.184.216.34/").Return(domain.ScreenResult{}, nil)
+		}, false},
+		{"unresolved domain", "https://unregistered.example.com/", func() {
+			reputation.EXPECT().Screen(gomock.Any(), "https://unregistered.example.com/").Return(domain.ScreenResult{}, nil)
+		}, false},
+		{"blocked domain", "https://phishing.example/login", func() {}, true},
+		{"blocked subdomain", "https://bank.phishing.example/login", func() {}, true},
+		{"allowed subdomain of blocked domain", "https://safe.phishing.example/", func() {}, false},
+		{"allowed domain skips reputation", "https://trusted.example/", func() {}, false},
+		{"reputation allows", "https://www.example.com/", func() {
+			reputation.EXPECT().Screen(gomock.Any(), "https://www.example.com/").Return(domain.ScreenResult{}, nil)
+		}, false},
+		{"reputation blocks", "https://malware.example.org/", func() {
+			reputation.EXPECT().Screen(gomock.Any(), "https://malware.example.org/").Return(domain.ScreenResult{Blocked: true, Reason: "malware"}, nil)
+		}, true},
+		{"reputation failure lets link through", "https://www.example.net/", func() {
+			reputation.EXPECT().Screen(gomock.Any(), "https://www.example.net/").Return(domain.ScreenResult{}, errors.New("timeout"))
+		}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls()
+
+			res, err := screener.Screen(context.Background(), tc.link)
+			require.NoError(t, err)
+			assert.Equal(t, tc.blocked, res.Blocked)
+			if tc.blocked {
+				assert.NotEmpty(t, res.Reason)
+			}
+		})
+	}
+}
+
+func TestDomainList_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "domains.csv")
+	modTime := time.Now().Add(-time.Hour)
+	writeDomainList(t, path, "phishing.example,block\n", modTime)
+
+	list, err := usecase.NewDomainList(path)
+	require.NoError(t, err)
+	screener := usecase.NewLinkScreener(nil, list, nil, fakeResolver{}, zap.NewNop(), tracer)
+
+	screen := func(link string) bool {
+		res, err := screener.Screen(context.Background(), link)
+		require.NoError(t, err)
+		return res.Blocked
+	}
+	assert.True(t, screen("https://phishing.example/"))
+
+	t.Run("unchanged file", func(t *testing.T) {
+		reloaded, err := list.Reload()
+		require.NoError(t, err)
+		assert.False(t, reloaded)
+	})
+
+	t.Run("changed file", func(t *testing.T) {
+		modTime = modTime.Add(time.Minute)
+		writeDomainList(t, path, "scam.example,block\n", modTime)
+
+		reloaded, err := list.Reload()
+		require.NoError(t, err)
+		assert.True(t, reloaded)
+		assert.False(t, screen("https://phishing.example/"))
+		assert.True(t, screen("https://scam.example/"))
+	})
+
+	t.Run("invalid file keeps lists", func(t *testing.T) {
+		modTime = modTime.Add(time.Minute)
+		writeDomainList(t, path, "scam.example,ban\n", modTime)
+
+		_, err := list.Reload()
+		assert.Error(t, err)
+		assert.True(t, screen("https://scam.example/"))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := usecase.NewDomainList(filepath.Join(t.TempDir(), "missing.csv"))
+		assert.Error(t, err)
+	})
+}
@@ -1,33 +1,164 @@
 package usecase
 
 import (
-	"math/rand"
-	"strings"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/semka95/shortener/backend/domain"
 )
 
 const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_"
+const base62Bytes = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+type randomTokenGenerator struct {
+	length int
+}
+
+// NewRandomTokenGenerator creates generator of crypto-random base64URL ids of
+// the given length. With length 6 there are 2^36 ids, so collisions are rare
+// and resolved by storing URL with another id.
+func NewRandomTokenGenerator(length int) domain.TokenGenerator {
+	return &randomTokenGenerator{length: length}
+}
+
+func (g *randomTokenGenerator) Generate(_ context.Context) (string, error) {
+	b := make([]byte, g.length)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("can't read random bytes: %w", err)
+	}
+
+	// alphabet has 64 letters, so every 6 bits of random byte map to a letter uniformly
+	for i := range b {
+		b[i] = letterBytes[b[i]&63]
+	}
+
+	return string(b), nil
+}
+
+type counterTokenGenerator struct {
+	repo      domain.CounterRepository
+	name      string
+	rangeSize int64
+
+	mu   sync.Mutex
+	next int64
+	end  int64
+}
+
+// NewCounterTokenGenerator creates generator of base62 encoded sequential ids.
+// Ranges of rangeSize ids are leased from the counter repository, so instances
+// don't share ids and the repository is hit once per range. Ids of a range
+// which is not used up are lost on restart. Sequential ids can be enumerated,
+// so they must not be used for private links.
+func NewCounterTokenGenerator(repo domain.CounterRepository, name string, rangeSize int64) domain.TokenGenerator {
+	return &counterTokenGenerator{
+		repo:      repo,
+		name:      name,
+		rangeSize: rangeSize,
+	}
+}
+
+func (g *counterTokenGenerator) Generate(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.next >= g.end {
+		start, err := g.repo.Lease(ctx, g.name, g.rangeSize)
+		if err != nil {
+			return "", err
+		}
+		g.next, g.end = start, start+g.rangeSize
+	}
+
+	v := g.next
+	g.next++
+
+	return encodeBase62(v), nil
+}
+
 const (
-	letterIdxBits = 6                    // 6 bits to represent a letter index
-	letterIdxMask = 1<<letterIdxBits - 1 // All 1-bits, as many as letterIdxBits
-	letterIdxMax  = 63 / letterIdxBits   // # of letter indices fitting in 63 bits
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	snowflakeMaxNode      = 1<<snowflakeNodeBits - 1
+	snowflakeSequenceMask = 1<<snowflakeSequenceBits - 1
 )
 
-// GenerateURLToken generates random base64URL string by given length
-// taken from https://stackoverflow.com/a/31832326
-func GenerateURLToken(n int, src rand.Source) string {
-	sb := strings.Builder{}
-	sb.Grow(n)
-	for i, cache, remain := n-1, src.Int63(), letterIdxMax; i >= 0; {
-		if remain == 0 {
-			cache, remain = src.Int63(), letterIdxMax
-		}
-		if idx := int(cache & letterIdxMask); idx < len(letterBytes) {
-			sb.WriteByte(letterBytes[idx])
-			i--
+// snowflakeEpoch is the start of snowflake timestamps, it must never change
+var snowflakeEpoch = time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+type snowflakeTokenGenerator struct {
+	node int64
+	now  func() time.Time
+
+	mu       sync.Mutex
+	lastTime int64
+	sequence int64
+}
+
+// NewSnowflakeTokenGenerator creates generator of base62 encoded Snowflake ids:
+// milliseconds since epoch, node id and per-millisecond sequence. Ids are unique
+// without coordination as long as every instance has its own node id.
+func NewSnowflakeTokenGenerator(node int64) (domain.TokenGenerator, error) {
+	if node < 0 || node > snowflakeMaxNode {
+		return nil, fmt.Errorf("snowflake node id must be in range [0, %d]", snowflakeMaxNode)
+	}
+
+	return &snowflakeTokenGenerator{
+		node: node,
+		now:  time.Now,
+	}, nil
+}
+
+func (g *snowflakeTokenGenerator) Generate(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// if clock moves backwards, last timestamp is reused until clock catches up
+	ts := g.timestamp()
+	if ts < g.lastTime {
+		ts = g.lastTime
+	}
+
+	if ts == g.lastTime {
+		g.sequence = (g.sequence + 1) & snowflakeSequenceMask
+		if g.sequence == 0 {
+			// sequence is exhausted, wait for the next millisecond
+			for ts <= g.lastTime {
+				if err := ctx.Err(); err != nil {
+					return "", err
+				}
+				time.Sleep(100 * time.Microsecond)
+				ts = g.timestamp()
+			}
 		}
-		cache >>= letterIdxBits
-		remain--
+	} else {
+		g.sequence = 0
+	}
+	g.lastTime = ts
+
+	id := ts<<(snowflakeNodeBits+snowflakeSequenceBits) | g.node<<snowflakeSequenceBits | g.sequence
+	return encodeBase62(id), nil
+}
+
+func (g *snowflakeTokenGenerator) timestamp() int64 {
+	return g.now().Sub(snowflakeEpoch).Milliseconds()
+}
+
+func encodeBase62(v int64) string {
+	if v == 0 {
+		return base62Bytes[:1]
+	}
+
+	var b [11]byte
+	i := len(b)
+	for v > 0 {
+		i--
+		b[i] = base62Bytes[v%62]
+		v /= 62
 	}
 
-	return sb.String()
+	return string(b[i:])
 }
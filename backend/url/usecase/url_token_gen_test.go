@@ -0,0 +1,98 @@
+package usecase_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/url/mock"
+	"github.com/semka95/shortener/backend/url/usecase"
+)
+
+func TestRandomTokenGenerator(t *testing.T) {
+	gen := usecase.NewRandomTokenGenerator(8)
+	seen := make(map[string]bool)
+
+	for i := 0; i < 1000; i++ {
+		id, err := gen.Generate(context.Background())
+		require.NoError(t, err)
+		assert.Regexp(t, regexp.MustCompile(`^[a-zA-Z0-9-_]{8}$`), id)
+		assert.False(t, seen[id], "duplicate id %s", id)
+		seen[id] = true
+	}
+}
+
+func TestCounterTokenGenerator(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repository := mock.NewMockCounterRepository(controller)
+	gen := usecase.NewCounterTokenGenerator(repository, "url_id", 2)
+
+	t.Run("success", func(t *testing.T) {
+		gomock.InOrder(
+			repository.EXPECT().Lease(gomock.Any(), "url_id", int64(2)).Return(int64(60), nil),
+			repository.EXPECT().Lease(gomock.Any(), "url_id", int64(2)).Return(int64(124), nil),
+		)
+
+		ids := make([]string, 0, 4)
+		for i := 0; i < 4; i++ {
+			id, err := gen.Generate(context.Background())
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+
+		assert.Equal(t, []string{"Y", "Z", "20", "21"}, ids)
+	})
+
+	t.Run("lease error", func(t *testing.T) {
+		gen := usecase.NewCounterTokenGenerator(repository, "url_id", 2)
+		repository.EXPECT().Lease(gomock.Any(), "url_id", int64(2)).Return(int64(0), domain.ErrInternalServerError)
+
+		id, err := gen.Generate(context.Background())
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+		assert.Empty(t, id)
+	})
+}
+
+func TestSnowflakeTokenGenerator(t *testing.T) {
+	t.Run("unique ids", func(t *testing.T) {
+		gen, err := usecase.NewSnowflakeTokenGenerator(1)
+		require.NoError(t, err)
+
+		seen := make(map[string]bool)
+		for i := 0; i < 10000; i++ {
+			id, err := gen.Generate(context.Background())
+			require.NoError(t, err)
+			assert.Regexp(t, regexp.MustCompile(`^[a-zA-Z0-9]+$`), id)
+			require.False(t, seen[id], "duplicate id %s", id)
+			seen[id] = true
+		}
+	})
+
+	t.Run("different nodes", func(t *testing.T) {
+		first, err := usecase.NewSnowflakeTokenGenerator(1)
+		require.NoError(t, err)
+		second, err := usecase.NewSnowflakeTokenGenerator(2)
+		require.NoError(t, err)
+
+		firstID, err := first.Generate(context.Background())
+		require.NoError(t, err)
+		secondID, err := second.Generate(context.Background())
+		require.NoError(t, err)
+		assert.NotEqual(t, firstID, secondID)
+	})
+
+	t.Run("invalid node", func(t *testing.T) {
+		_, err := usecase.NewSnowflakeTokenGenerator(1024)
+		assert.Error(t, err)
+
+		_, err = usecase.NewSnowflakeTokenGenerator(-1)
+		assert.Error(t, err)
+	})
+}
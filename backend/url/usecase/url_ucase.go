@@ -2,35 +2,135 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math/rand"
+	"strings"
 	"time"
 
+	"github.com/golang-jwt/jwt/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/crypto/bcrypt"
 
+	"github.com/semka95/shortener/backend/audit"
 	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/middleware/ratelimit"
 	"github.com/semka95/shortener/backend/web/auth"
 )
 
+// defaultListLimit is the page size used when URL list query has no limit
+const defaultListLimit = 20
+
+// historyLimit is the number of the latest URL revisions returned in history
+const historyLimit = 100
+
+// maxStoreAttempts is the number of generated ids tried before storing URL fails
+const maxStoreAttempts = 5
+
+// purgeBatchSize is the number of deleted URLs removed at once by Purge
+const purgeBatchSize = 500
+
+const (
+	// maxUnlockAttempts is the number of wrong passwords allowed per URL in unlockWindow,
+	// one more attempt is allowed every unlockWindow/maxUnlockAttempts after that
+	maxUnlockAttempts = 5
+	unlockWindow      = 15 * time.Minute
+)
+
 type urlUsecase struct {
-	urlRepo        domain.URLRepository
-	contextTimeout time.Duration
-	tracer         trace.Tracer
-	urlExpiration  int
+	urlRepo             domain.URLRepository
+	revisionRepo        domain.URLRevisionRepository
+	auditRepo           domain.AuditRepository
+	workspaceRepo       domain.WorkspaceRepository
+	customDomainRepo    domain.CustomDomainRepository
+	tokenGen            domain.TokenGenerator
+	screener            domain.LinkScreener
+	policy              *auth.Policy
+	contextTimeout      time.Duration
+	deletionGrace       time.Duration
+	expiredRetention    time.Duration
+	tracer              trace.Tracer
+	urlExpiration       int
+	defaultRedirectType int
+	unlockLimiter       *attemptLimiter
+	hosts               map[string]bool
+	customDomains       bool
+}
+
+// Deps holds repositories and services used by URL usecase
+type Deps struct {
+	URLRepo      domain.URLRepository
+	RevisionRepo domain.URLRevisionRepository
+	// AuditRepo records every change of URLs
+	AuditRepo domain.AuditRepository
+	// WorkspaceRepo grants access to URLs by workspace membership
+	WorkspaceRepo domain.WorkspaceRepository
+	// CustomDomainRepo holds custom domains of workspaces
+	CustomDomainRepo domain.CustomDomainRepository
+	// TokenGen generates ids of URLs created without custom id
+	TokenGen domain.TokenGenerator
+	// Screener checks links of created and updated URLs
+	Screener domain.LinkScreener
+	// Policy grants permissions of roles
+	Policy *auth.Policy
+	// UnlockStore keeps wrong password attempts of protected URLs, they are
+	// kept in memory of instance if UnlockStore is nil
+	UnlockStore ratelimit.Store
+	Tracer      trace.Tracer
+}
+
+// Config holds settings of URL usecase
+type Config struct {
+	Timeout time.Duration
+	// DeletionGrace is the time deleted URLs can be restored during
+	DeletionGrace time.Duration
+	// ExpiredRetention is the time expired URLs are kept before they are deleted
+	ExpiredRetention time.Duration
+	// URLExpiration is the lifetime of URLs in years
+	URLExpiration int
+	// DefaultRedirectType is used for URLs created without redirect type
+	DefaultRedirectType int
+	// Hosts the shortener is served on, URLs are opened by id on them and by
+	// slug of custom domain on other hosts. Custom domains are disabled and
+	// URLs are opened by id on every host if Hosts is empty
+	Hosts []string
 }
 
 // NewURLUsecase will create new an urlUsecase object representation of url.Usecase interface
-func NewURLUsecase(u domain.URLRepository, timeout time.Duration, tracer trace.Tracer, urlExpiration int) domain.URLUsecase {
+func NewURLUsecase(deps Deps, cfg Config) domain.URLUsecase {
+	unlockStore := deps.UnlockStore
+	if unlockStore == nil {
+		unlockStore = ratelimit.NewMemoryStore()
+	}
+
+	own := make(map[string]bool, len(cfg.Hosts))
+	for _, h := range cfg.Hosts {
+		own[strings.ToLower(h)] = true
+	}
+
 	return &urlUsecase{
-		urlRepo:        u,
-		contextTimeout: timeout,
-		tracer:         tracer,
-		urlExpiration:  urlExpiration,
+		urlRepo:             deps.URLRepo,
+		revisionRepo:        deps.RevisionRepo,
+		auditRepo:           deps.AuditRepo,
+		workspaceRepo:       deps.WorkspaceRepo,
+		customDomainRepo:    deps.CustomDomainRepo,
+		tokenGen:            deps.TokenGen,
+		screener:            deps.Screener,
+		policy:              deps.Policy,
+		contextTimeout:      cfg.Timeout,
+		deletionGrace:       cfg.DeletionGrace,
+		expiredRetention:    cfg.ExpiredRetention,
+		tracer:              deps.Tracer,
+		urlExpiration:       cfg.URLExpiration,
+		defaultRedirectType: cfg.DefaultRedirectType,
+		unlockLimiter:       newAttemptLimiter(unlockStore, "unlock:", maxUnlockAttempts, unlockWindow),
+		hosts:               own,
+		customDomains:       len(own) > 0,
 	}
 }
 
-func (uc *urlUsecase) GetByID(c context.Context, id string) (*domain.URL, error) {
+func (uc *urlUsecase) GetByID(c context.Context, id string, user *auth.Claims) (*domain.URL, error) {
 	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
 	defer cancel()
 
@@ -49,9 +149,134 @@ func (uc *urlUsecase) GetByID(c context.Context, id string) (*domain.URL, error)
 		return nil, err
 	}
 
+	if err = uc.checkVisible(ctx, u, user); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// Resolve returns URL opened by id on host, on hosts the shortener is served
+// on id is global and on other hosts it is slug of custom domain URL
+func (uc *urlUsecase) Resolve(c context.Context, host, id string) (*domain.URL, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Resolve",
+		trace.WithAttributes(
+			attribute.String("host", host),
+			attribute.String("urlid", id)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	var u *domain.URL
+	var err error
+	if !uc.customDomains || uc.isOwnHost(host) {
+		u, err = uc.urlRepo.GetByID(ctx, id)
+		// URLs of custom domains are opened only on their domain
+		if err == nil && u.Domain != "" {
+			err = fmt.Errorf("url %s is served on custom domain: %w", id, domain.ErrNotFound)
+		}
+	} else {
+		u, err = uc.urlRepo.GetByDomain(ctx, strings.ToLower(host), id)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err = uc.checkVisible(ctx, u, nil); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
 	return u, nil
 }
 
+// isOwnHost reports whether the shortener is served on host
+func (uc *urlUsecase) isOwnHost(host string) bool {
+	return host == "" || uc.hosts[strings.ToLower(host)]
+}
+
+// checkVisible returns error if user can't see URL, missing redirect type is
+// set to default. Inactive, disabled, exhausted and expired URLs are visible
+// only to their owner, members of their workspace and users allowed to read
+// any URL.
+func (uc *urlUsecase) checkVisible(ctx context.Context, u *domain.URL, user *auth.Claims) error {
+	if u.RedirectType == 0 {
+		u.RedirectType = uc.defaultRedirectType
+	}
+
+	now := time.Now()
+	owner := false
+	if user != nil {
+		resource, err := uc.resource(ctx, u, user)
+		if err != nil {
+			return err
+		}
+		owner = uc.policy.Authorize(user, auth.ActionURLRead, resource) == nil
+	}
+
+	if !u.IsActive(now) && !owner {
+		return fmt.Errorf("URL %s is not active yet: %w", u.ID, domain.ErrNotFound)
+	}
+
+	if (u.Disabled || u.OwnerSuspended) && !owner {
+		return fmt.Errorf("URL %s was disabled by admin: %w", u.ID, domain.ErrGone)
+	}
+
+	if u.IsExhausted() && !owner {
+		return fmt.Errorf("URL %s click limit is reached: %w", u.ID, domain.ErrGone)
+	}
+
+	if !u.IsExpired(now) {
+		return nil
+	}
+
+	if !owner {
+		return fmt.Errorf("URL %s has expired: %w", u.ID, domain.ErrGone)
+	}
+	u.Expired = true
+
+	return nil
+}
+
+// Consume uses one redirect of click limited URL, concurrent redirects can't
+// exceed the limit. URLs without limit are returned as is.
+func (uc *urlUsecase) Consume(c context.Context, u *domain.URL) (*domain.URL, error) {
+	if !u.IsClickLimited() {
+		return u, nil
+	}
+
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Consume",
+		trace.WithAttributes(
+			attribute.String("urlid", u.ID)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	consumed, err := uc.urlRepo.ConsumeClick(ctx, u.ID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if consumed.RedirectType == 0 {
+		consumed.RedirectType = uc.defaultRedirectType
+	}
+
+	return consumed, nil
+}
+
 func (uc *urlUsecase) Update(c context.Context, updateURL domain.UpdateURL, user *auth.Claims) error {
 	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
 	defer cancel()
@@ -70,19 +295,59 @@ func (uc *urlUsecase) Update(c context.Context, updateURL domain.UpdateURL, user
 	}
 	span.SetAttributes(attribute.String("urlid", updateURL.ID))
 
-	if u.UserID == "" {
-		err = fmt.Errorf("this url was created by unauthorized user: %w", domain.ErrForbidden)
+	if err = uc.Authorize(ctx, u, user, auth.ActionURLUpdate); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if err = uc.checkDisabled(u, user); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if updateURL.Link == nil && updateURL.Title == nil && updateURL.Notes == nil &&
+		updateURL.RedirectType == nil && updateURL.ExpirationDate == nil {
+		err = fmt.Errorf("nothing to update: %w", domain.ErrBadParamInput)
+		span.RecordError(err)
+		return err
+	}
+
+	now := time.Now()
+	before := *u
+	if updateURL.Link != nil && *updateURL.Link != u.Link {
+		if err = uc.screen(ctx, u, *updateURL.Link); err != nil {
+			span.RecordError(err)
+			return err
+		}
+	}
+	if updateURL.Title != nil {
+		u.Title = *updateURL.Title
+	}
+	if updateURL.Notes != nil {
+		u.Notes = *updateURL.Notes
+	}
+	if updateURL.RedirectType != nil {
+		u.RedirectType = *updateURL.RedirectType
+	}
+	if updateURL.ExpirationDate != nil {
+		u.ExpirationDate = *updateURL.ExpirationDate
+	}
+	if u.ValidFrom != nil && !u.ValidFrom.Before(u.ExpirationDate) {
+		err = fmt.Errorf("valid from date must be before expiration date: %w", domain.ErrBadParamInput)
 		span.RecordError(err)
 		return err
 	}
+	u.UpdatedAt = now.Truncate(time.Millisecond).UTC()
 
-	if !user.HasRole(auth.RoleAdmin) && u.UserID != user.Subject {
-		span.RecordError(domain.ErrForbidden)
-		return domain.ErrForbidden
+	if err = uc.storeRevision(ctx, &before, user, now); err != nil {
+		span.RecordError(err)
+		return err
 	}
 
-	u.ExpirationDate = updateURL.ExpirationDate
-	u.UpdatedAt = time.Now().Truncate(time.Millisecond).UTC()
+	if err = uc.record(ctx, user, domain.AuditUpdateURL, &before, u, nil); err != nil {
+		span.RecordError(err)
+		return err
+	}
 
 	err = uc.urlRepo.Update(ctx, u)
 	if err != nil {
@@ -93,114 +358,952 @@ func (uc *urlUsecase) Update(c context.Context, updateURL domain.UpdateURL, user
 	return nil
 }
 
-func (uc *urlUsecase) Store(c context.Context, createURL domain.CreateURL) (*domain.URL, error) {
+// History returns the latest revisions of URL, newest first, only owner
+// and admins can see them
+func (uc *urlUsecase) History(c context.Context, id string, user *auth.Claims) ([]*domain.URLRevision, error) {
 	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
 	defer cancel()
 
 	ctx, span := uc.tracer.Start(
 		ctx,
-		"usecase Store",
+		"usecase History",
+		trace.WithAttributes(
+			attribute.String("urlid", id)),
 		trace.WithSpanKind(trace.SpanKindServer),
 	)
 	defer span.End()
 
-	id, err := uc.getURLToken(ctx, createURL.ID)
+	u, err := uc.urlRepo.GetByID(ctx, id)
 	if err != nil {
 		span.RecordError(err)
-		return nil, fmt.Errorf("can't get %s user: %w", *createURL.ID, err)
-	}
-
-	if createURL.ExpirationDate == nil {
-		expDate := time.Now().AddDate(uc.urlExpiration, 0, 0)
-		createURL.ExpirationDate = &expDate
+		return nil, err
 	}
 
-	span.SetAttributes(attribute.String("urlid", id))
-
-	u := &domain.URL{
-		ID:             id,
-		Link:           createURL.Link,
-		ExpirationDate: *createURL.ExpirationDate,
-		UserID:         createURL.UserID,
-		CreatedAt:      time.Now().Truncate(time.Millisecond).UTC(),
-		UpdatedAt:      time.Now().Truncate(time.Millisecond).UTC(),
+	if err = uc.Authorize(ctx, u, user, auth.ActionURLRead); err != nil {
+		span.RecordError(err)
+		return nil, err
 	}
 
-	err = uc.urlRepo.Store(ctx, u)
+	revisions, err := uc.revisionRepo.List(ctx, id, historyLimit)
 	if err != nil {
 		span.RecordError(err)
 		return nil, err
 	}
 
-	return u, nil
+	return revisions, nil
 }
 
-func (uc *urlUsecase) Delete(c context.Context, id string, user *auth.Claims) error {
+// Rollback restores link, title, notes and redirect type of URL from the given
+// revision, expiration date is not changed. Current state is kept as a new
+// revision, so rollback can be undone.
+func (uc *urlUsecase) Rollback(c context.Context, rollback domain.URLRollback, user *auth.Claims) (*domain.URL, error) {
 	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
 	defer cancel()
 
 	ctx, span := uc.tracer.Start(
 		ctx,
-		"usecase Delete",
+		"usecase Rollback",
 		trace.WithAttributes(
-			attribute.String("urlid", id)),
+			attribute.String("urlid", rollback.URLID)),
 		trace.WithSpanKind(trace.SpanKindServer),
 	)
 	defer span.End()
 
-	u, err := uc.urlRepo.GetByID(ctx, id)
+	revisionID, err := primitive.ObjectIDFromHex(rollback.RevisionID)
 	if err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("can't get %s user: %w", id, err)
+		return nil, fmt.Errorf("revision ID is not valid ObjectID: %w: %s", domain.ErrBadParamInput, err.Error())
+	}
+
+	u, err := uc.urlRepo.GetByID(ctx, rollback.URLID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
 	}
 
-	if u.UserID == "" {
-		err = fmt.Errorf("this url was created by unauthorized user: %w", domain.ErrForbidden)
+	if err = uc.Authorize(ctx, u, user, auth.ActionURLUpdate); err != nil {
 		span.RecordError(err)
-		return err
+		return nil, err
+	}
+
+	if err = uc.checkDisabled(u, user); err != nil {
+		span.RecordError(err)
+		return nil, err
 	}
 
-	if !user.HasRole(auth.RoleAdmin) && u.UserID != user.Subject {
-		span.RecordError(domain.ErrForbidden)
-		return domain.ErrForbidden
+	r, err := uc.revisionRepo.GetByID(ctx, rollback.URLID, revisionID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
 	}
 
-	err = uc.urlRepo.Delete(ctx, id)
+	now := time.Now()
+	// old link is screened again, as it could be blocked after revision was made
+	before := *u
+	if err = uc.screen(ctx, u, r.Link); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err = uc.storeRevision(ctx, &before, user, now); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	u.Title = r.Title
+	u.Notes = r.Notes
+	u.RedirectType = r.RedirectType
+	u.UpdatedAt = now.Truncate(time.Millisecond).UTC()
+
+	err = uc.record(ctx, user, domain.AuditUpdateURL, &before, u, map[string]string{
+		"revision": rollback.RevisionID,
+	})
 	if err != nil {
 		span.RecordError(err)
+		return nil, err
+	}
+
+	if err = uc.urlRepo.Update(ctx, u); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if u.RedirectType == 0 {
+		u.RedirectType = uc.defaultRedirectType
+	}
+	u.Expired = u.IsExpired(now)
+
+	return u, nil
+}
+
+// storeRevision keeps state of URL before it is changed. Revision is stored
+// after the change is checked, but before it is written, so a failed update
+// leaves an extra revision instead of a lost one.
+func (uc *urlUsecase) storeRevision(ctx context.Context, u *domain.URL, user *auth.Claims, now time.Time) error {
+	return uc.revisionRepo.Store(ctx, &domain.URLRevision{
+		ID:             primitive.NewObjectID(),
+		URLID:          u.ID,
+		Link:           u.Link,
+		Title:          u.Title,
+		Notes:          u.Notes,
+		RedirectType:   u.RedirectType,
+		ExpirationDate: u.ExpirationDate,
+		ChangedBy:      user.Subject,
+		CreatedAt:      now.Truncate(time.Millisecond).UTC(),
+	})
+}
+
+// record stores audit entry of URL change. Updates and deletes are recorded
+// before the change like revisions, creates are recorded after URL is
+// stored, as its id is known only then.
+func (uc *urlUsecase) record(ctx context.Context, user *auth.Claims, action string, before, after *domain.URL, details map[string]string) error {
+	e, err := uc.newAuditEntry(ctx, user, action, before, after)
+	if err != nil {
+		return err
+	}
+	e.Details = details
+
+	return uc.auditRepo.Store(ctx, e)
+}
+
+func (uc *urlUsecase) newAuditEntry(ctx context.Context, user *auth.Claims, action string, before, after *domain.URL) (*domain.AuditEntry, error) {
+	target := ""
+	if after != nil {
+		target = after.ID
+	} else if before != nil {
+		target = before.ID
+	}
+
+	e := audit.NewEntry(ctx, user, action, target)
+
+	var err error
+	if e.Changes, err = audit.Diff(before, after); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Authorize returns error if user is not allowed to perform action on URL,
+// URLs created by unauthorized users outside of workspaces have no owner, they
+// can be read only with permissions and can't be changed
+func (uc *urlUsecase) Authorize(ctx context.Context, u *domain.URL, user *auth.Claims, action string) error {
+	if u.UserID == "" && u.WorkspaceID == "" && action != auth.ActionURLRead {
+		return fmt.Errorf("this url was created by unauthorized user: %w", domain.ErrForbidden)
+	}
+
+	resource, err := uc.resource(ctx, u, user)
+	if err != nil {
 		return err
 	}
 
+	if err = uc.policy.Authorize(user, action, resource); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrForbidden, err.Error())
+	}
+
+	return nil
+}
+
+// resource returns URL as authorization resource, role of user in workspace
+// of URL is looked up only for workspace URLs
+func (uc *urlUsecase) resource(ctx context.Context, u *domain.URL, user *auth.Claims) (*auth.Resource, error) {
+	resource := &auth.Resource{Owner: u.UserID}
+	if u.WorkspaceID == "" || user == nil {
+		return resource, nil
+	}
+
+	role, err := uc.workspaceRole(ctx, u.WorkspaceID, user.Subject)
+	if err != nil {
+		return nil, err
+	}
+	resource.WorkspaceRole = role
+
+	return resource, nil
+}
+
+// workspaceRole returns role of user in workspace, it is empty if user is not
+// a member or workspace doesn't exist
+func (uc *urlUsecase) workspaceRole(ctx context.Context, workspaceID, userID string) (string, error) {
+	id, err := primitive.ObjectIDFromHex(workspaceID)
+	if err != nil {
+		return "", fmt.Errorf("workspace ID is not valid ObjectID: %w: %s", domain.ErrBadParamInput, err.Error())
+	}
+
+	w, err := uc.workspaceRepo.GetByID(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return w.Role(userID), nil
+}
+
+// checkWorkspace returns error if creator of URL is not allowed to create URLs
+// in its workspace, roles keeps roles of the creator which are already known
+func (uc *urlUsecase) checkWorkspace(ctx context.Context, createURL domain.CreateURL, roles map[string]string) error {
+	if createURL.WorkspaceID == "" {
+		return nil
+	}
+	if createURL.UserID == "" {
+		return fmt.Errorf("only workspace members can create URLs in workspace: %w", domain.ErrForbidden)
+	}
+
+	role, ok := roles[createURL.WorkspaceID]
+	if !ok {
+		var err error
+		if role, err = uc.workspaceRole(ctx, createURL.WorkspaceID, createURL.UserID); err != nil {
+			return err
+		}
+		roles[createURL.WorkspaceID] = role
+	}
+
+	creator := &auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: createURL.UserID}}
+	if err := uc.policy.Authorize(creator, auth.ActionURLCreate, &auth.Resource{WorkspaceRole: role}); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrForbidden, err.Error())
+	}
+
+	return nil
+}
+
+// checkDomain returns error if custom domain of URL is not verified by its
+// workspace, domains keeps workspaces of hosts which are already known
+func (uc *urlUsecase) checkDomain(ctx context.Context, createURL domain.CreateURL, domains map[string]string) error {
+	if createURL.Domain == "" {
+		return nil
+	}
+	if createURL.WorkspaceID == "" {
+		return fmt.Errorf("only workspace URLs can use custom domain: %w", domain.ErrBadParamInput)
+	}
+
+	workspaceID, ok := domains[createURL.Domain]
+	if !ok {
+		d, err := uc.customDomainRepo.GetByHost(ctx, createURL.Domain)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+		if d != nil {
+			workspaceID = d.WorkspaceID
+		}
+		domains[createURL.Domain] = workspaceID
+	}
+
+	if workspaceID != createURL.WorkspaceID {
+		return fmt.Errorf("domain %s is not verified by workspace: %w", createURL.Domain, domain.ErrBadParamInput)
+	}
+
 	return nil
 }
 
-func (uc *urlUsecase) getURLToken(ctx context.Context, createID *string) (id string, err error) {
+// checkDisabled returns error if URL was disabled by admin, so only users
+// allowed to moderate URLs can change it
+func (uc *urlUsecase) checkDisabled(u *domain.URL, user *auth.Claims) error {
+	if u.Disabled && uc.policy.Authorize(user, auth.PermURLModerate, nil) != nil {
+		return fmt.Errorf("URL %s was disabled by admin: %w", u.ID, domain.ErrForbidden)
+	}
+
+	return nil
+}
+
+func (uc *urlUsecase) Store(c context.Context, createURL domain.CreateURL) (*domain.URL, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
 	ctx, span := uc.tracer.Start(
 		ctx,
-		"usecase getURLToken",
+		"usecase Store",
 		trace.WithSpanKind(trace.SpanKindServer),
 	)
 	defer span.End()
 
-	if createID != nil {
-		_, err = uc.GetByID(ctx, *createID)
-		if err == nil {
-			span.RecordError(err)
-			return "", fmt.Errorf("can't store URL, already exists: %w", domain.ErrConflict)
-		}
+	now := time.Now()
 
-		return *createID, nil
+	if err := uc.checkSchedule(createURL, now); err != nil {
+		span.RecordError(err)
+		return nil, err
 	}
 
-	for {
-		src := rand.NewSource(time.Now().UnixNano())
-		id = GenerateURLToken(6, src)
+	if err := uc.checkWorkspace(ctx, createURL, make(map[string]string)); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
 
-		_, err = uc.GetByID(ctx, id)
-		if err != nil {
-			break
-		}
+	createURL.Domain = strings.ToLower(createURL.Domain)
+	if err := uc.checkDomain(ctx, createURL, make(map[string]string)); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	screened, err := uc.screener.Screen(ctx, createURL.Link)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
 	}
 
-	return id, nil
+	hashedPwd, err := hashPassword(createURL.Password)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// custom id is not checked beforehand, repository rejects existing id with ErrConflict
+	if createURL.ID != nil {
+		span.SetAttributes(attribute.String("urlid", *createURL.ID))
+		u := uc.newURL(*createURL.ID, createURL, hashedPwd, screened, now)
+		if err := uc.urlRepo.Store(ctx, u); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		if err := uc.record(ctx, nil, domain.AuditCreateURL, nil, u, nil); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		return u, nil
+	}
+
+	// generated id may collide with existing one, in this case another id is generated
+	for attempt := 0; attempt < maxStoreAttempts; attempt++ {
+		id, err := uc.tokenGen.Generate(ctx)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("can't generate URL id: %w: %s", domain.ErrInternalServerError, err.Error())
+		}
+
+		u := uc.newURL(id, createURL, hashedPwd, screened, now)
+		err = uc.urlRepo.Store(ctx, u)
+		if errors.Is(err, domain.ErrConflict) {
+			continue
+		}
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+
+		span.SetAttributes(attribute.String("urlid", id))
+		if err = uc.record(ctx, nil, domain.AuditCreateURL, nil, u, nil); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		return u, nil
+	}
+
+	err = fmt.Errorf("can't generate unique URL id after %d attempts: %w", maxStoreAttempts, domain.ErrInternalServerError)
+	span.RecordError(err)
+	return nil, err
+}
+
+// BulkStore stores URLs in one batch, result of each URL is returned in the
+// same order as items. Existence of custom ids is not checked beforehand,
+// such URLs are rejected by the repository with ErrConflict.
+func (uc *urlUsecase) BulkStore(c context.Context, items []domain.CreateURL) ([]domain.BulkResult, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase BulkStore",
+		trace.WithAttributes(
+			attribute.Int("urls", len(items))),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	now := time.Now()
+	urls := make([]*domain.URL, len(items))
+	errs := make([]error, len(items))
+	screened := make([]domain.ScreenResult, len(items))
+	pending := make([]int, 0, len(items))
+	roles := make(map[string]string)
+	domains := make(map[string]string)
+	for i := range items {
+		items[i].Domain = strings.ToLower(items[i].Domain)
+		item := items[i]
+		if errs[i] = uc.checkSchedule(item, now); errs[i] != nil {
+			continue
+		}
+		if errs[i] = uc.checkWorkspace(ctx, item, roles); errs[i] != nil {
+			continue
+		}
+		if errs[i] = uc.checkDomain(ctx, item, domains); errs[i] != nil {
+			continue
+		}
+		if screened[i], errs[i] = uc.screener.Screen(ctx, item.Link); errs[i] != nil {
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	// hashing is slow, so the same password of several items is hashed once
+	hashes := make(map[string]string)
+	hashedPwds := make([]string, len(items))
+	for i, item := range items {
+		if item.Password == "" || errs[i] != nil {
+			continue
+		}
+		if _, ok := hashes[item.Password]; !ok {
+			hash, err := hashPassword(item.Password)
+			if err != nil {
+				span.RecordError(err)
+				return nil, err
+			}
+			hashes[item.Password] = hash
+		}
+		hashedPwds[i] = hashes[item.Password]
+	}
+
+	// URLs with generated ids which collided with existing ones are stored
+	// again with new ids, custom ids are stored only once
+	for attempt := 0; attempt < maxStoreAttempts && len(pending) > 0; attempt++ {
+		batch := make([]*domain.URL, len(pending))
+		for j, i := range pending {
+			id := ""
+			if items[i].ID != nil {
+				id = *items[i].ID
+			} else {
+				var err error
+				id, err = uc.tokenGen.Generate(ctx)
+				if err != nil {
+					span.RecordError(err)
+					return nil, fmt.Errorf("can't generate URL id: %w: %s", domain.ErrInternalServerError, err.Error())
+				}
+			}
+			urls[i] = uc.newURL(id, items[i], hashedPwds[i], screened[i], now)
+			batch[j] = urls[i]
+		}
+
+		batchErrs, err := uc.urlRepo.StoreMany(ctx, batch)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+
+		retry := pending[:0]
+		for j, i := range pending {
+			errs[i] = batchErrs[j]
+			if items[i].ID == nil && errors.Is(batchErrs[j], domain.ErrConflict) {
+				retry = append(retry, i)
+			}
+		}
+		pending = retry
+	}
+
+	for _, i := range pending {
+		errs[i] = fmt.Errorf("can't generate unique URL id after %d attempts: %w", maxStoreAttempts, domain.ErrInternalServerError)
+	}
+
+	results := make([]domain.BulkResult, len(urls))
+	entries := make([]*domain.AuditEntry, 0, len(urls))
+	for i, u := range urls {
+		results[i] = domain.BulkResult{Index: i, Err: errs[i]}
+		if u != nil {
+			results[i].ID = u.ID
+		}
+		if u != nil && errs[i] == nil {
+			e, err := uc.newAuditEntry(ctx, nil, domain.AuditCreateURL, nil, u)
+			if err != nil {
+				span.RecordError(err)
+				return nil, err
+			}
+			entries = append(entries, e)
+		}
+	}
+
+	if len(entries) > 0 {
+		if err := uc.auditRepo.StoreMany(ctx, entries); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// BulkDelete deletes URLs which user is allowed to delete, the same rules
+// as in Delete are applied to each URL
+func (uc *urlUsecase) BulkDelete(c context.Context, ids []string, user *auth.Claims) ([]domain.BulkResult, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase BulkDelete",
+		trace.WithAttributes(
+			attribute.String("userid", user.Subject),
+			attribute.Int("urls", len(ids))),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	urls, err := uc.urlRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	found := make(map[string]*domain.URL, len(urls))
+	for _, u := range urls {
+		found[u.ID] = u
+	}
+
+	results := make([]domain.BulkResult, len(ids))
+	allowed := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		results[i] = domain.BulkResult{Index: i, ID: id}
+
+		u, ok := found[id]
+		if !ok {
+			results[i].Err = fmt.Errorf("URL %s was not found: %w", id, domain.ErrNotFound)
+			continue
+		}
+		if err = uc.Authorize(ctx, u, user, auth.ActionURLDelete); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		// the same id can be requested several times, it is deleted once
+		if !seen[id] {
+			seen[id] = true
+			allowed = append(allowed, id)
+		}
+	}
+
+	if len(allowed) == 0 {
+		return results, nil
+	}
+
+	entries := make([]*domain.AuditEntry, len(allowed))
+	for i, id := range allowed {
+		if entries[i], err = uc.newAuditEntry(ctx, user, domain.AuditDeleteURL, found[id], nil); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+	if err = uc.auditRepo.StoreMany(ctx, entries); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err = uc.urlRepo.DeleteMany(ctx, allowed); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// screen sets link of URL and flags URL if the link is blocked
+func (uc *urlUsecase) screen(ctx context.Context, u *domain.URL, link string) error {
+	screened, err := uc.screener.Screen(ctx, link)
+	if err != nil {
+		return err
+	}
+
+	u.Link = link
+	u.Flagged = screened.Blocked
+	u.FlagReason = screened.Reason
+
+	return nil
+}
+
+// checkSchedule checks that URL activates before it expires
+func (uc *urlUsecase) checkSchedule(createURL domain.CreateURL, now time.Time) error {
+	if createURL.ValidFrom == nil {
+		return nil
+	}
+
+	expirationDate := now.AddDate(uc.urlExpiration, 0, 0)
+	if createURL.ExpirationDate != nil {
+		expirationDate = *createURL.ExpirationDate
+	}
+
+	if !createURL.ValidFrom.Before(expirationDate) {
+		return fmt.Errorf("valid from date must be before expiration date: %w", domain.ErrBadParamInput)
+	}
+
+	return nil
+}
+
+// newURL creates URL from request data, missing expiration date and redirect
+// type are set to defaults. On custom domain id becomes slug of URL.
+func (uc *urlUsecase) newURL(id string, createURL domain.CreateURL, hashedPwd string, screened domain.ScreenResult, now time.Time) *domain.URL {
+	expirationDate := now.AddDate(uc.urlExpiration, 0, 0)
+	if createURL.ExpirationDate != nil {
+		expirationDate = *createURL.ExpirationDate
+	}
+
+	redirectType := createURL.RedirectType
+	if redirectType == 0 {
+		redirectType = uc.defaultRedirectType
+	}
+
+	u := &domain.URL{
+		ID:              id,
+		Link:            createURL.Link,
+		Title:           createURL.Title,
+		Notes:           createURL.Notes,
+		ExpirationDate:  expirationDate,
+		UserID:          createURL.UserID,
+		WorkspaceID:     createURL.WorkspaceID,
+		RedirectType:    redirectType,
+		HashedPassword:  hashedPwd,
+		MaxClicks:       createURL.MaxClicks,
+		RemainingClicks: createURL.MaxClicks,
+		ValidFrom:       createURL.ValidFrom,
+		Flagged:         screened.Blocked,
+		FlagReason:      screened.Reason,
+		CreatedAt:       now.Truncate(time.Millisecond).UTC(),
+		UpdatedAt:       now.Truncate(time.Millisecond).UTC(),
+	}
+	if createURL.Domain != "" {
+		u.ID = domain.CustomDomainURLID(createURL.Domain, id)
+		u.Domain = createURL.Domain
+		u.Slug = id
+	}
+
+	return u
+}
+
+func (uc *urlUsecase) Delete(c context.Context, id string, user *auth.Claims) error {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Delete",
+		trace.WithAttributes(
+			attribute.String("urlid", id)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	u, err := uc.urlRepo.GetByID(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("can't get %s user: %w", id, err)
+	}
+
+	if err = uc.Authorize(ctx, u, user, auth.ActionURLDelete); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if err = uc.record(ctx, user, domain.AuditDeleteURL, u, nil, nil); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	// history is kept, so restored URL has it, it is removed when URL is purged
+	err = uc.urlRepo.Delete(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+func (uc *urlUsecase) List(c context.Context, query domain.URLListQuery, user *auth.Claims) (*domain.URLList, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase List",
+		trace.WithAttributes(
+			attribute.String("userid", user.Subject)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	// URLs of workspace are listed regardless of their creator
+	query.UserID = user.Subject
+	if query.WorkspaceID != "" {
+		role, err := uc.workspaceRole(ctx, query.WorkspaceID, user.Subject)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		if err = uc.policy.Authorize(user, auth.ActionURLRead, &auth.Resource{WorkspaceRole: role}); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("%w: %s", domain.ErrForbidden, err.Error())
+		}
+		query.UserID = ""
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultListLimit
+	}
+	if query.Sort == "" {
+		query.Sort = "created_at"
+	}
+	if query.Order == "" {
+		query.Order = "desc"
+	}
+
+	list, err := uc.urlRepo.List(ctx, query)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, u := range list.Items {
+		u.Expired = u.IsExpired(now)
+		if u.RedirectType == 0 {
+			u.RedirectType = uc.defaultRedirectType
+		}
+	}
+
+	return list, nil
+}
+
+// Unlock returns password protected URL if password is right, URLs without
+// password are returned as is. Wrong passwords are limited per URL.
+func (uc *urlUsecase) Unlock(c context.Context, unlock domain.URLUnlock) (*domain.URL, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Unlock",
+		trace.WithAttributes(
+			attribute.String("urlid", unlock.ID)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	u, err := uc.Resolve(ctx, unlock.Host, unlock.ID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if !u.IsProtected() {
+		return u, nil
+	}
+
+	now := time.Now()
+	allowed, err := uc.unlockLimiter.allow(ctx, u.ID, now)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("can't check password attempts of URL %s: %w: %s", u.ID, domain.ErrInternalServerError, err.Error())
+	}
+	if !allowed {
+		err = fmt.Errorf("too many wrong passwords for URL %s: %w", u.ID, domain.ErrTooManyRequests)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(u.HashedPassword), []byte(unlock.Password)); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("compare password error: %w: %s", domain.ErrAuthenticationFailure, err.Error())
+	}
+
+	// only wrong passwords count, so visitors of popular URLs aren't locked out
+	if err = uc.unlockLimiter.succeed(ctx, u.ID, now); err != nil {
+		span.RecordError(err)
+	}
+
+	return u, nil
+}
+
+// hashPassword hashes password of protected URL, empty password means URL is not protected
+func hashPassword(password string) (string, error) {
+	if password == "" {
+		return "", nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("can't hash URL password: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return string(hash), nil
+}
+
+// Restore restores deleted URL with its history, only owner and admins can
+// restore URL and only until its deletion grace period ends
+func (uc *urlUsecase) Restore(c context.Context, id string, user *auth.Claims) (*domain.URL, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Restore",
+		trace.WithAttributes(
+			attribute.String("urlid", id)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	u, err := uc.urlRepo.GetDeleted(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err = uc.Authorize(ctx, u, user, auth.ActionURLDelete); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	now := time.Now()
+	if !u.DeletedAt.Add(uc.deletionGrace).After(now) {
+		err = fmt.Errorf("deletion grace period of URL %s has ended: %w", id, domain.ErrGone)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	before := *u
+	u.DeletedAt = nil
+	if err = uc.record(ctx, user, domain.AuditRestoreURL, &before, u, nil); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err = uc.urlRepo.Restore(ctx, id); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if u.RedirectType == 0 {
+		u.RedirectType = uc.defaultRedirectType
+	}
+	u.Expired = u.IsExpired(now)
+
+	return u, nil
+}
+
+// Purge deletes URLs expired longer than expiredRetention ago, then removes
+// URLs whose deletion grace period has ended together with their history and
+// returns number of removed URLs. Expired URLs are deleted like any other, so
+// they are recorded in audit log and can be restored during grace period.
+func (uc *urlUsecase) Purge(c context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Purge",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	now := time.Now()
+	expired, err := uc.deleteExpired(ctx, now.Add(-uc.expiredRetention))
+	span.SetAttributes(attribute.Int("expired", expired))
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	before := now.Add(-uc.deletionGrace)
+	purged := 0
+	for {
+		ids, err := uc.urlRepo.ListDeleted(ctx, before, purgeBatchSize)
+		if err != nil {
+			span.RecordError(err)
+			return purged, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		// history is removed first, so it can't be inherited by a new URL with the same id
+		if err = uc.revisionRepo.DeleteByURL(ctx, ids...); err != nil {
+			span.RecordError(err)
+			return purged, err
+		}
+
+		if err = uc.urlRepo.Purge(ctx, ids); err != nil {
+			span.RecordError(err)
+			return purged, err
+		}
+
+		purged += len(ids)
+		if len(ids) < purgeBatchSize {
+			break
+		}
+	}
+
+	span.SetAttributes(attribute.Int("purged", purged))
+	return purged, nil
+}
+
+// deleteExpired marks URLs expired before the given time as deleted and
+// returns their number
+func (uc *urlUsecase) deleteExpired(ctx context.Context, before time.Time) (int, error) {
+	deleted := 0
+	for {
+		urls, err := uc.urlRepo.ListExpired(ctx, before, purgeBatchSize)
+		if err != nil {
+			return deleted, err
+		}
+		if len(urls) == 0 {
+			return deleted, nil
+		}
+
+		ids := make([]string, len(urls))
+		entries := make([]*domain.AuditEntry, len(urls))
+		for i, u := range urls {
+			ids[i] = u.ID
+			if entries[i], err = uc.newAuditEntry(ctx, nil, domain.AuditDeleteURL, u, nil); err != nil {
+				return deleted, err
+			}
+			entries[i].Details = map[string]string{"reason": "expired"}
+		}
+		if err = uc.auditRepo.StoreMany(ctx, entries); err != nil {
+			return deleted, err
+		}
+
+		if err = uc.urlRepo.DeleteMany(ctx, ids); err != nil {
+			return deleted, err
+		}
+
+		deleted += len(ids)
+		if len(ids) < purgeBatchSize {
+			return deleted, nil
+		}
+	}
 }
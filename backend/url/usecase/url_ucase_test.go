@@ -2,24 +2,51 @@ package usecase_test
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"regexp"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"golang.org/x/crypto/bcrypt"
 
+	auditMock "github.com/semka95/shortener/backend/audit/mock"
+	customDomainMock "github.com/semka95/shortener/backend/customdomain/mock"
 	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/middleware/ratelimit"
 	"github.com/semka95/shortener/backend/tests"
 	"github.com/semka95/shortener/backend/url/mock"
 	"github.com/semka95/shortener/backend/url/usecase"
+	"github.com/semka95/shortener/backend/web"
 	"github.com/semka95/shortener/backend/web/auth"
+	workspaceMock "github.com/semka95/shortener/backend/workspace/mock"
 )
 
 var tracer = sdktrace.NewTracerProvider().Tracer("")
 
+// testConfig is config of URL usecase used by tests, all hosts are own
+var testConfig = usecase.Config{
+	Timeout:             10 * time.Second,
+	DeletionGrace:       24 * time.Hour,
+	URLExpiration:       1,
+	DefaultRedirectType: http.StatusFound,
+}
+
+// newAuditRepository returns audit repository which accepts any entries,
+// it is used by tests which don't check audit log
+func newAuditRepository(controller *gomock.Controller) *auditMock.MockAuditRepository {
+	r := auditMock.NewMockAuditRepository(controller)
+	r.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	r.EXPECT().StoreMany(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	return r
+}
+
 func TestURLUsecase_GetByID(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
@@ -27,21 +54,198 @@ func TestURLUsecase_GetByID(t *testing.T) {
 	tURL := tests.NewURL()
 
 	repository := mock.NewMockURLRepository(controller)
-	uc := usecase.NewURLUsecase(repository, 10*time.Second, tracer, 1)
+	revisionRepository := mock.NewMockURLRevisionRepository(controller)
+	auditRepository := newAuditRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
 
 	t.Run("url not found", func(t *testing.T) {
 		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(nil, domain.ErrNotFound)
-		result, err := uc.GetByID(context.Background(), tURL.ID)
+		result, err := uc.GetByID(context.Background(), tURL.ID, nil)
 		assert.Error(t, err, domain.ErrNotFound)
 		assert.Nil(t, result)
 	})
 
 	t.Run("success", func(t *testing.T) {
 		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
-		result, err := uc.GetByID(context.Background(), tURL.ID)
+		result, err := uc.GetByID(context.Background(), tURL.ID, nil)
 		require.NoError(t, err)
 		assert.EqualValues(t, tURL, result)
 	})
+
+	t.Run("default redirect type", func(t *testing.T) {
+		legacyURL := tests.NewURL()
+		legacyURL.RedirectType = 0
+		repository.EXPECT().GetByID(gomock.Any(), legacyURL.ID).Return(legacyURL, nil)
+		result, err := uc.GetByID(context.Background(), legacyURL.ID, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusFound, result.RedirectType)
+	})
+
+	tExpiredURL := tests.NewURL()
+	tExpiredURL.ExpirationDate = time.Now().Add(-time.Hour)
+	claims := auth.NewClaims("507f191e810c19729de860ea", []string{auth.RoleUser}, time.Now(), time.Minute)
+
+	t.Run("url expired", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tExpiredURL.ID).Return(tExpiredURL, nil)
+		result, err := uc.GetByID(context.Background(), tExpiredURL.ID, nil)
+		assert.ErrorIs(t, err, domain.ErrGone)
+		assert.Nil(t, result)
+	})
+
+	t.Run("expired url by owner", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tExpiredURL.ID).Return(tExpiredURL, nil)
+		result, err := uc.GetByID(context.Background(), tExpiredURL.ID, claims)
+		require.NoError(t, err)
+		assert.True(t, result.Expired)
+	})
+
+	t.Run("expired url by wrong user", func(t *testing.T) {
+		wrongClaims := auth.NewClaims("wrong user", []string{auth.RoleUser}, time.Now(), time.Minute)
+		repository.EXPECT().GetByID(gomock.Any(), tExpiredURL.ID).Return(tExpiredURL, nil)
+		result, err := uc.GetByID(context.Background(), tExpiredURL.ID, wrongClaims)
+		assert.ErrorIs(t, err, domain.ErrGone)
+		assert.Nil(t, result)
+	})
+
+	t.Run("expired url by admin", func(t *testing.T) {
+		adminClaims := auth.NewClaims("admin", []string{auth.RoleAdmin}, time.Now(), time.Minute)
+		repository.EXPECT().GetByID(gomock.Any(), tExpiredURL.ID).Return(tExpiredURL, nil)
+		result, err := uc.GetByID(context.Background(), tExpiredURL.ID, adminClaims)
+		require.NoError(t, err)
+		assert.True(t, result.Expired)
+	})
+
+	t.Run("expired url by support", func(t *testing.T) {
+		supportClaims := auth.NewClaims("support", []string{auth.RoleSupport}, time.Now(), time.Minute)
+		repository.EXPECT().GetByID(gomock.Any(), tExpiredURL.ID).Return(tExpiredURL, nil)
+		result, err := uc.GetByID(context.Background(), tExpiredURL.ID, supportClaims)
+		require.NoError(t, err)
+		assert.True(t, result.Expired)
+	})
+
+	tDisabledURL := tests.NewURL()
+	tDisabledURL.Disabled = true
+
+	t.Run("disabled url", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tDisabledURL.ID).Return(tDisabledURL, nil)
+		result, err := uc.GetByID(context.Background(), tDisabledURL.ID, nil)
+		assert.ErrorIs(t, err, domain.ErrGone)
+		assert.Nil(t, result)
+	})
+
+	t.Run("disabled url by owner", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tDisabledURL.ID).Return(tDisabledURL, nil)
+		result, err := uc.GetByID(context.Background(), tDisabledURL.ID, claims)
+		require.NoError(t, err)
+		assert.True(t, result.Disabled)
+	})
+
+	t.Run("url of suspended owner", func(t *testing.T) {
+		suspendedURL := tests.NewURL()
+		suspendedURL.OwnerSuspended = true
+		repository.EXPECT().GetByID(gomock.Any(), suspendedURL.ID).Return(suspendedURL, nil)
+		result, err := uc.GetByID(context.Background(), suspendedURL.ID, nil)
+		assert.ErrorIs(t, err, domain.ErrGone)
+		assert.Nil(t, result)
+	})
+}
+
+func TestURLUsecase_GetByID_Schedule(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repository := mock.NewMockURLRepository(controller)
+	revisionRepository := mock.NewMockURLRevisionRepository(controller)
+	auditRepository := newAuditRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
+	claims := auth.NewClaims("507f191e810c19729de860ea", []string{auth.RoleUser}, time.Now(), time.Minute)
+
+	validFrom := time.Now().Add(time.Hour)
+	tPendingURL := tests.NewURL()
+	tPendingURL.ValidFrom = &validFrom
+
+	tExhaustedURL := tests.NewURL()
+	tExhaustedURL.MaxClicks = 1
+
+	t.Run("not active yet", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tPendingURL.ID).Return(tPendingURL, nil)
+		result, err := uc.GetByID(context.Background(), tPendingURL.ID, nil)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("not active yet by owner", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tPendingURL.ID).Return(tPendingURL, nil)
+		result, err := uc.GetByID(context.Background(), tPendingURL.ID, claims)
+		require.NoError(t, err)
+		assert.Equal(t, tPendingURL.ID, result.ID)
+	})
+
+	t.Run("active", func(t *testing.T) {
+		activeURL := tests.NewURL()
+		validFrom := time.Now().Add(-time.Hour)
+		activeURL.ValidFrom = &validFrom
+		repository.EXPECT().GetByID(gomock.Any(), activeURL.ID).Return(activeURL, nil)
+		result, err := uc.GetByID(context.Background(), activeURL.ID, nil)
+		require.NoError(t, err)
+		assert.Equal(t, activeURL.ID, result.ID)
+	})
+
+	t.Run("click limit reached", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tExhaustedURL.ID).Return(tExhaustedURL, nil)
+		result, err := uc.GetByID(context.Background(), tExhaustedURL.ID, nil)
+		assert.ErrorIs(t, err, domain.ErrGone)
+		assert.Nil(t, result)
+	})
+
+	t.Run("click limit reached by owner", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tExhaustedURL.ID).Return(tExhaustedURL, nil)
+		result, err := uc.GetByID(context.Background(), tExhaustedURL.ID, claims)
+		require.NoError(t, err)
+		assert.True(t, result.IsExhausted())
+	})
+}
+
+func TestURLUsecase_Consume(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repository := mock.NewMockURLRepository(controller)
+	revisionRepository := mock.NewMockURLRevisionRepository(controller)
+	auditRepository := newAuditRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
+
+	tLimitedURL := tests.NewURL()
+	tLimitedURL.MaxClicks = 2
+	tLimitedURL.RemainingClicks = 2
+
+	t.Run("not limited", func(t *testing.T) {
+		tURL := tests.NewURL()
+		result, err := uc.Consume(context.Background(), tURL)
+		require.NoError(t, err)
+		assert.Equal(t, tURL, result)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		consumed := *tLimitedURL
+		consumed.RemainingClicks = 1
+		repository.EXPECT().ConsumeClick(gomock.Any(), tLimitedURL.ID).Return(&consumed, nil)
+
+		result, err := uc.Consume(context.Background(), tLimitedURL)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), result.RemainingClicks)
+	})
+
+	t.Run("click limit reached", func(t *testing.T) {
+		repository.EXPECT().ConsumeClick(gomock.Any(), tLimitedURL.ID).Return(nil, domain.ErrGone)
+
+		result, err := uc.Consume(context.Background(), tLimitedURL)
+		assert.ErrorIs(t, err, domain.ErrGone)
+		assert.Nil(t, result)
+	})
 }
 
 func TestURLUsecase_Store(t *testing.T) {
@@ -51,12 +255,14 @@ func TestURLUsecase_Store(t *testing.T) {
 	tCreateURL := tests.NewCreateURL()
 
 	repository := mock.NewMockURLRepository(controller)
-	uc := usecase.NewURLUsecase(repository, 10*time.Second, tracer, 1)
+	revisionRepository := mock.NewMockURLRevisionRepository(controller)
+	auditRepository := newAuditRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
 
 	t.Run("success empty url ID", func(t *testing.T) {
 		tCreateURL.ID = nil
 
-		repository.EXPECT().GetByID(gomock.Any(), gomock.Any()).Return(nil, domain.ErrNotFound)
 		repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
 
 		result, err := uc.Store(context.Background(), tCreateURL)
@@ -65,12 +271,60 @@ func TestURLUsecase_Store(t *testing.T) {
 		assert.Regexp(t, regexp.MustCompile(`^[a-zA-Z0-9-_]{6}$`), result.ID)
 		assert.Equal(t, tCreateURL.Link, result.Link)
 		assert.Equal(t, *tCreateURL.ExpirationDate, result.ExpirationDate)
+		assert.Equal(t, http.StatusFound, result.RedirectType)
+	})
+
+	t.Run("success with redirect type", func(t *testing.T) {
+		createURL := tests.NewCreateURL()
+		createURL.RedirectType = http.StatusPermanentRedirect
+
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+
+		result, err := uc.Store(context.Background(), createURL)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusPermanentRedirect, result.RedirectType)
+	})
+
+	t.Run("success with password", func(t *testing.T) {
+		createURL := tests.NewCreateURL()
+		createURL.Password = "secret"
+
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+
+		result, err := uc.Store(context.Background(), createURL)
+		require.NoError(t, err)
+		assert.True(t, result.IsProtected())
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(result.HashedPassword), []byte("secret")))
+	})
+
+	t.Run("success with click limit and schedule", func(t *testing.T) {
+		createURL := tests.NewCreateURL()
+		validFrom := time.Now().Add(30 * time.Minute)
+		createURL.MaxClicks = 3
+		createURL.ValidFrom = &validFrom
+
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+
+		result, err := uc.Store(context.Background(), createURL)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), result.MaxClicks)
+		assert.Equal(t, int64(3), result.RemainingClicks)
+		assert.Equal(t, &validFrom, result.ValidFrom)
+	})
+
+	t.Run("valid from after expiration", func(t *testing.T) {
+		createURL := tests.NewCreateURL()
+		validFrom := createURL.ExpirationDate.Add(time.Hour)
+		createURL.ValidFrom = &validFrom
+
+		result, err := uc.Store(context.Background(), createURL)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+		assert.Nil(t, result)
 	})
 
 	t.Run("success filled url ID", func(t *testing.T) {
 		tCreateURL.ID = tests.StringPointer("test123456")
 
-		repository.EXPECT().GetByID(gomock.Any(), *tCreateURL.ID).Return(nil, domain.ErrNotFound)
 		repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
 
 		result, err := uc.Store(context.Background(), tCreateURL)
@@ -83,23 +337,58 @@ func TestURLUsecase_Store(t *testing.T) {
 
 	t.Run("url already exists", func(t *testing.T) {
 		tCreateURL.ID = tests.StringPointer("test123456")
-		tURL := &domain.URL{}
 
-		repository.EXPECT().GetByID(gomock.Any(), gomock.Any()).Return(tURL, nil)
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(domain.ErrConflict)
 
 		result, err := uc.Store(context.Background(), tCreateURL)
-		assert.Error(t, err, domain.ErrConflict)
+		assert.ErrorIs(t, err, domain.ErrConflict)
 		assert.Empty(t, result)
 	})
 
 	t.Run("repository internal error", func(t *testing.T) {
-		repository.EXPECT().GetByID(gomock.Any(), gomock.Any()).Return(nil, domain.ErrNotFound)
 		repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(domain.ErrInternalServerError)
 
 		result, err := uc.Store(context.Background(), tCreateURL)
-		assert.Error(t, err, domain.ErrInternalServerError)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
 		assert.Empty(t, result)
 	})
+
+	gen := mock.NewMockTokenGenerator(controller)
+	genUC := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: gen, Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
+	generated := tests.NewCreateURL()
+	generated.ID = nil
+
+	t.Run("generated id collision", func(t *testing.T) {
+		gomock.InOrder(
+			gen.EXPECT().Generate(gomock.Any()).Return("taken1", nil),
+			gen.EXPECT().Generate(gomock.Any()).Return("free12", nil),
+		)
+		gomock.InOrder(
+			repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(domain.ErrConflict),
+			repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil),
+		)
+
+		result, err := genUC.Store(context.Background(), generated)
+		require.NoError(t, err)
+		assert.Equal(t, "free12", result.ID)
+	})
+
+	t.Run("generated id attempts exhausted", func(t *testing.T) {
+		gen.EXPECT().Generate(gomock.Any()).Return("taken1", nil).Times(5)
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(domain.ErrConflict).Times(5)
+
+		result, err := genUC.Store(context.Background(), generated)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+		assert.Nil(t, result)
+	})
+
+	t.Run("generator error", func(t *testing.T) {
+		gen.EXPECT().Generate(gomock.Any()).Return("", domain.ErrInternalServerError)
+
+		result, err := genUC.Store(context.Background(), generated)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+		assert.Nil(t, result)
+	})
 }
 
 func TestURLUsecase_Update(t *testing.T) {
@@ -110,17 +399,55 @@ func TestURLUsecase_Update(t *testing.T) {
 	tURL := tests.NewURL()
 
 	repository := mock.NewMockURLRepository(controller)
-	uc := usecase.NewURLUsecase(repository, 10*time.Second, tracer, 1)
+	revisionRepository := mock.NewMockURLRevisionRepository(controller)
+	auditRepository := newAuditRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
 	claims := auth.NewClaims("507f191e810c19729de860ea", []string{auth.RoleUser}, time.Now(), time.Minute)
 
 	t.Run("success", func(t *testing.T) {
+		oldLink := tURL.Link
 		repository.EXPECT().GetByID(gomock.Any(), tUpdateURL.ID).Return(tURL, nil)
-		repository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+		revisionRepository.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, r *domain.URLRevision) error {
+			assert.Equal(t, tURL.ID, r.URLID)
+			assert.Equal(t, oldLink, r.Link)
+			assert.Equal(t, claims.Subject, r.ChangedBy)
+			return nil
+		})
+		repository.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, u *domain.URL) error {
+			assert.Equal(t, *tUpdateURL.Link, u.Link)
+			assert.Equal(t, *tUpdateURL.ExpirationDate, u.ExpirationDate)
+			return nil
+		})
 
 		err := uc.Update(context.Background(), tUpdateURL, claims)
 		require.NoError(t, err)
 	})
 
+	t.Run("nothing to update", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tUpdateURL.ID).Return(tests.NewURL(), nil)
+
+		err := uc.Update(context.Background(), domain.UpdateURL{ID: tUpdateURL.ID}, claims)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	})
+
+	t.Run("revision store error", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tUpdateURL.ID).Return(tests.NewURL(), nil)
+		revisionRepository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(domain.ErrInternalServerError)
+
+		err := uc.Update(context.Background(), tUpdateURL, claims)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+	})
+
+	t.Run("expiration before valid from", func(t *testing.T) {
+		scheduled := tests.NewURL()
+		scheduled.ValidFrom = tests.DatePointer(tUpdateURL.ExpirationDate.Add(time.Hour))
+		repository.EXPECT().GetByID(gomock.Any(), tUpdateURL.ID).Return(scheduled, nil)
+
+		err := uc.Update(context.Background(), tUpdateURL, claims)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	})
+
 	t.Run("url not found", func(t *testing.T) {
 		repository.EXPECT().GetByID(gomock.Any(), tUpdateURL.ID).Return(nil, domain.ErrNotFound)
 
@@ -128,6 +455,15 @@ func TestURLUsecase_Update(t *testing.T) {
 		assert.Error(t, err, domain.ErrNotFound)
 	})
 
+	t.Run("url disabled by admin", func(t *testing.T) {
+		disabledURL := tests.NewURL()
+		disabledURL.Disabled = true
+		repository.EXPECT().GetByID(gomock.Any(), tUpdateURL.ID).Return(disabledURL, nil)
+
+		err := uc.Update(context.Background(), tUpdateURL, claims)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
 	t.Run("user not authorized", func(t *testing.T) {
 		claims.Subject = "wrong user"
 		repository.EXPECT().GetByID(gomock.Any(), tUpdateURL.ID).Return(tURL, nil)
@@ -136,9 +472,18 @@ func TestURLUsecase_Update(t *testing.T) {
 		assert.Error(t, domain.ErrForbidden, err)
 	})
 
+	t.Run("wrong user with support role", func(t *testing.T) {
+		supportClaims := auth.NewClaims("support", []string{auth.RoleSupport}, time.Now(), time.Minute)
+		repository.EXPECT().GetByID(gomock.Any(), tUpdateURL.ID).Return(tURL, nil)
+
+		err := uc.Update(context.Background(), tUpdateURL, supportClaims)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
 	t.Run("success by wrong user, but with admin role", func(t *testing.T) {
 		claims.Roles = append(claims.Roles, auth.RoleAdmin)
 		repository.EXPECT().GetByID(gomock.Any(), tUpdateURL.ID).Return(tURL, nil)
+		revisionRepository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
 		repository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
 
 		err := uc.Update(context.Background(), tUpdateURL, claims)
@@ -154,6 +499,187 @@ func TestURLUsecase_Update(t *testing.T) {
 	})
 }
 
+func TestURLUsecase_Screening(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repository := mock.NewMockURLRepository(controller)
+	revisionRepository := mock.NewMockURLRevisionRepository(controller)
+	auditRepository := newAuditRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	screener := mock.NewMockLinkScreener(controller)
+	uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: screener, Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
+	claims := auth.NewClaims("507f191e810c19729de860ea", []string{auth.RoleUser}, time.Now(), time.Minute)
+	blocked := domain.ScreenResult{Blocked: true, Reason: "link domain is blocked"}
+
+	t.Run("store flags blocked link", func(t *testing.T) {
+		createURL := tests.NewCreateURL()
+		screener.EXPECT().Screen(gomock.Any(), createURL.Link).Return(blocked, nil)
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, u *domain.URL) error {
+			assert.True(t, u.Flagged)
+			assert.Equal(t, blocked.Reason, u.FlagReason)
+			return nil
+		})
+
+		result, err := uc.Store(context.Background(), createURL)
+		require.NoError(t, err)
+		assert.True(t, result.Flagged)
+	})
+
+	t.Run("store screen error", func(t *testing.T) {
+		createURL := tests.NewCreateURL()
+		screener.EXPECT().Screen(gomock.Any(), createURL.Link).Return(domain.ScreenResult{}, domain.ErrBadParamInput)
+
+		_, err := uc.Store(context.Background(), createURL)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	})
+
+	t.Run("update flags changed link", func(t *testing.T) {
+		updateURL := tests.NewUpdateURL()
+		repository.EXPECT().GetByID(gomock.Any(), updateURL.ID).Return(tests.NewURL(), nil)
+		revisionRepository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		screener.EXPECT().Screen(gomock.Any(), *updateURL.Link).Return(blocked, nil)
+		repository.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, u *domain.URL) error {
+			assert.Equal(t, *updateURL.Link, u.Link)
+			assert.True(t, u.Flagged)
+			return nil
+		})
+
+		err := uc.Update(context.Background(), updateURL, claims)
+		require.NoError(t, err)
+	})
+
+	t.Run("update clears flag of safe link", func(t *testing.T) {
+		updateURL := tests.NewUpdateURL()
+		flagged := tests.NewURL()
+		flagged.Flagged = true
+		flagged.FlagReason = blocked.Reason
+		repository.EXPECT().GetByID(gomock.Any(), updateURL.ID).Return(flagged, nil)
+		revisionRepository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		screener.EXPECT().Screen(gomock.Any(), *updateURL.Link).Return(domain.ScreenResult{}, nil)
+		repository.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, u *domain.URL) error {
+			assert.False(t, u.Flagged)
+			assert.Empty(t, u.FlagReason)
+			return nil
+		})
+
+		err := uc.Update(context.Background(), updateURL, claims)
+		require.NoError(t, err)
+	})
+
+	t.Run("update screen error leaves no revision", func(t *testing.T) {
+		updateURL := tests.NewUpdateURL()
+		repository.EXPECT().GetByID(gomock.Any(), updateURL.ID).Return(tests.NewURL(), nil)
+		screener.EXPECT().Screen(gomock.Any(), *updateURL.Link).Return(domain.ScreenResult{}, domain.ErrBadParamInput)
+
+		err := uc.Update(context.Background(), updateURL, claims)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	})
+
+	t.Run("update without link change is not screened", func(t *testing.T) {
+		title := "new title"
+		updateURL := domain.UpdateURL{ID: tests.NewURL().ID, Title: &title}
+		repository.EXPECT().GetByID(gomock.Any(), updateURL.ID).Return(tests.NewURL(), nil)
+		revisionRepository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		repository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+		err := uc.Update(context.Background(), updateURL, claims)
+		require.NoError(t, err)
+	})
+
+	t.Run("bulk store", func(t *testing.T) {
+		safe := tests.NewCreateURL()
+		safe.ID = nil
+		invalid := tests.NewCreateURL()
+		invalid.ID = nil
+		invalid.Link = "http://invalid.example/"
+		screener.EXPECT().Screen(gomock.Any(), safe.Link).Return(domain.ScreenResult{}, nil)
+		screener.EXPECT().Screen(gomock.Any(), invalid.Link).Return(domain.ScreenResult{}, domain.ErrBadParamInput)
+		repository.EXPECT().StoreMany(gomock.Any(), gomock.Len(1)).Return([]error{nil}, nil)
+
+		results, err := uc.BulkStore(context.Background(), []domain.CreateURL{safe, invalid})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.NoError(t, results[0].Err)
+		assert.ErrorIs(t, results[1].Err, domain.ErrBadParamInput)
+		assert.Empty(t, results[1].ID)
+	})
+}
+
+func TestURLUsecase_Audit(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	claims := auth.NewClaims("507f191e810c19729de860ea", []string{auth.RoleUser}, time.Now(), time.Minute)
+	ctx := web.WithRequestInfo(context.Background(), web.RequestInfo{ID: "request-id", IP: "192.0.2.1"})
+
+	repository := mock.NewMockURLRepository(controller)
+	revisionRepository := mock.NewMockURLRevisionRepository(controller)
+	auditRepository := auditMock.NewMockAuditRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
+
+	t.Run("store records created URL", func(t *testing.T) {
+		create := tests.NewCreateURL()
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		auditRepository.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.AuditEntry) error {
+			assert.Equal(t, domain.AuditCreateURL, e.Action)
+			assert.Equal(t, *create.ID, e.Target)
+			assert.Equal(t, "request-id", e.RequestID)
+			assert.Equal(t, "192.0.2.1", e.IP)
+			assert.Nil(t, e.Changes["link"].Before)
+			assert.Equal(t, create.Link, e.Changes["link"].After)
+			return nil
+		})
+
+		_, err := uc.Store(ctx, create)
+		require.NoError(t, err)
+	})
+
+	t.Run("update records diff", func(t *testing.T) {
+		tURL := tests.NewURL()
+		update := tests.NewUpdateURL()
+		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		revisionRepository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		auditRepository.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.AuditEntry) error {
+			assert.Equal(t, domain.AuditUpdateURL, e.Action)
+			assert.Equal(t, claims.Subject, e.Actor)
+			assert.Equal(t, claims.Roles, e.ActorRoles)
+			assert.Equal(t, domain.AuditChange{Before: "http://www.example.org", After: *update.Link}, e.Changes["link"])
+			assert.NotContains(t, e.Changes, "user_id")
+			return nil
+		})
+		repository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+		err := uc.Update(ctx, update, claims)
+		require.NoError(t, err)
+	})
+
+	t.Run("delete is not performed if audit fails", func(t *testing.T) {
+		tURL := tests.NewURL()
+		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		auditRepository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(domain.ErrInternalServerError)
+
+		err := uc.Delete(ctx, tURL.ID, claims)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+	})
+
+	t.Run("bulk delete records every URL", func(t *testing.T) {
+		tURL := tests.NewURL()
+		repository.EXPECT().GetByIDs(gomock.Any(), []string{tURL.ID, "none"}).Return([]*domain.URL{tURL}, nil)
+		auditRepository.EXPECT().StoreMany(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, entries []*domain.AuditEntry) error {
+			require.Len(t, entries, 1)
+			assert.Equal(t, domain.AuditDeleteURL, entries[0].Action)
+			assert.Equal(t, tURL.ID, entries[0].Target)
+			return nil
+		})
+		repository.EXPECT().DeleteMany(gomock.Any(), []string{tURL.ID}).Return(nil)
+
+		_, err := uc.BulkDelete(ctx, []string{tURL.ID, "none"}, claims)
+		require.NoError(t, err)
+	})
+}
+
 func TestURLUsecase_Delete(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
@@ -161,7 +687,10 @@ func TestURLUsecase_Delete(t *testing.T) {
 	tURL := tests.NewURL()
 
 	repository := mock.NewMockURLRepository(controller)
-	uc := usecase.NewURLUsecase(repository, 10*time.Second, tracer, 1)
+	revisionRepository := mock.NewMockURLRevisionRepository(controller)
+	auditRepository := newAuditRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
 	claims := auth.NewClaims("507f191e810c19729de860ea", []string{auth.RoleUser}, time.Now(), time.Minute)
 
 	t.Run("success", func(t *testing.T) {
@@ -202,3 +731,820 @@ func TestURLUsecase_Delete(t *testing.T) {
 		assert.Error(t, domain.ErrForbidden, err)
 	})
 }
+
+func TestURLUsecase_Workspace(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repository := mock.NewMockURLRepository(controller)
+	revisionRepository := mock.NewMockURLRevisionRepository(controller)
+	auditRepository := newAuditRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
+
+	editor := auth.NewClaims("507f191e810c19729de860eb", []string{auth.RoleUser}, time.Now(), time.Minute)
+	viewer := auth.NewClaims("507f191e810c19729de860ec", []string{auth.RoleUser}, time.Now(), time.Minute)
+	stranger := auth.NewClaims("507f191e810c19729de860ed", []string{auth.RoleUser}, time.Now(), time.Minute)
+	tWorkspace := tests.NewWorkspace()
+	tWorkspace.Members = append(tWorkspace.Members,
+		domain.WorkspaceMember{UserID: editor.Subject, Role: auth.WorkspaceEditor},
+		domain.WorkspaceMember{UserID: viewer.Subject, Role: auth.WorkspaceViewer},
+	)
+	tURL := tests.NewURL()
+	tURL.WorkspaceID = tWorkspace.ID.Hex()
+	title := "campaign"
+
+	t.Run("update by editor", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+		revisionRepository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		repository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+		err := uc.Update(context.Background(), domain.UpdateURL{ID: tURL.ID, Title: &title}, editor)
+		require.NoError(t, err)
+	})
+
+	t.Run("update by viewer", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+
+		err := uc.Update(context.Background(), domain.UpdateURL{ID: tURL.ID, Title: &title}, viewer)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("history by viewer", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+		revisionRepository.EXPECT().List(gomock.Any(), tURL.ID, gomock.Any()).Return([]*domain.URLRevision{}, nil)
+
+		_, err := uc.History(context.Background(), tURL.ID, viewer)
+		require.NoError(t, err)
+	})
+
+	t.Run("delete by user outside of workspace", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+
+		err := uc.Delete(context.Background(), tURL.ID, stranger)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("store by editor", func(t *testing.T) {
+		create := tests.NewCreateURL()
+		create.UserID = editor.Subject
+		create.WorkspaceID = tWorkspace.ID.Hex()
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+
+		result, err := uc.Store(context.Background(), create)
+		require.NoError(t, err)
+		assert.Equal(t, create.WorkspaceID, result.WorkspaceID)
+	})
+
+	t.Run("store by viewer", func(t *testing.T) {
+		create := tests.NewCreateURL()
+		create.UserID = viewer.Subject
+		create.WorkspaceID = tWorkspace.ID.Hex()
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+
+		_, err := uc.Store(context.Background(), create)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("store by unauthorized user", func(t *testing.T) {
+		create := tests.NewCreateURL()
+		create.UserID = ""
+		create.WorkspaceID = tWorkspace.ID.Hex()
+
+		_, err := uc.Store(context.Background(), create)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("bulk store looks up workspace once", func(t *testing.T) {
+		create := tests.NewCreateURL()
+		create.ID = nil
+		create.UserID = editor.Subject
+		create.WorkspaceID = tWorkspace.ID.Hex()
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+		repository.EXPECT().StoreMany(gomock.Any(), gomock.Len(2)).Return(make([]error, 2), nil)
+
+		results, err := uc.BulkStore(context.Background(), []domain.CreateURL{create, create})
+		require.NoError(t, err)
+		for _, r := range results {
+			assert.NoError(t, r.Err)
+		}
+	})
+
+	t.Run("list by member", func(t *testing.T) {
+		want := domain.URLListQuery{
+			Limit:       20,
+			Sort:        "created_at",
+			Order:       "desc",
+			WorkspaceID: tWorkspace.ID.Hex(),
+		}
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+		repository.EXPECT().List(gomock.Any(), want).Return(&domain.URLList{Items: []*domain.URL{tURL}}, nil)
+
+		list, err := uc.List(context.Background(), domain.URLListQuery{WorkspaceID: tWorkspace.ID.Hex()}, viewer)
+		require.NoError(t, err)
+		assert.Len(t, list.Items, 1)
+	})
+
+	t.Run("list by user outside of workspace", func(t *testing.T) {
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+
+		_, err := uc.List(context.Background(), domain.URLListQuery{WorkspaceID: tWorkspace.ID.Hex()}, stranger)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+}
+
+func TestURLUsecase_List(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tURL := tests.NewURL()
+	tExpiredURL := tests.NewURL()
+	tExpiredURL.ExpirationDate = time.Now().Add(-time.Hour)
+
+	repository := mock.NewMockURLRepository(controller)
+	revisionRepository := mock.NewMockURLRevisionRepository(controller)
+	auditRepository := newAuditRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
+	claims := auth.NewClaims("507f191e810c19729de860ea", []string{auth.RoleUser}, time.Now(), time.Minute)
+
+	t.Run("success with defaults", func(t *testing.T) {
+		want := domain.URLListQuery{
+			Limit:  20,
+			Sort:   "created_at",
+			Order:  "desc",
+			UserID: claims.Subject,
+		}
+		repository.EXPECT().List(gomock.Any(), want).Return(&domain.URLList{Items: []*domain.URL{tURL, tExpiredURL}}, nil)
+
+		list, err := uc.List(context.Background(), domain.URLListQuery{}, claims)
+		require.NoError(t, err)
+		require.Len(t, list.Items, 2)
+		assert.False(t, list.Items[0].Expired)
+		assert.True(t, list.Items[1].Expired)
+	})
+
+	t.Run("user id can't be overridden", func(t *testing.T) {
+		want := domain.URLListQuery{
+			Limit:  5,
+			Sort:   "expiration_date",
+			Order:  "asc",
+			UserID: claims.Subject,
+		}
+		repository.EXPECT().List(gomock.Any(), want).Return(&domain.URLList{}, nil)
+
+		q := domain.URLListQuery{Limit: 5, Sort: "expiration_date", Order: "asc", UserID: "another user"}
+		_, err := uc.List(context.Background(), q, claims)
+		require.NoError(t, err)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repository.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, domain.ErrInternalServerError)
+
+		_, err := uc.List(context.Background(), domain.URLListQuery{}, claims)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestURLUsecase_History(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tURL := tests.NewURL()
+	tRevision := tests.NewURLRevision()
+
+	repository := mock.NewMockURLRepository(controller)
+	revisionRepository := mock.NewMockURLRevisionRepository(controller)
+	auditRepository := newAuditRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
+	claims := auth.NewClaims("507f191e810c19729de860ea", []string{auth.RoleUser}, time.Now(), time.Minute)
+
+	t.Run("success", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		revisionRepository.EXPECT().List(gomock.Any(), tURL.ID, gomock.Any()).Return([]*domain.URLRevision{tRevision}, nil)
+
+		result, err := uc.History(context.Background(), tURL.ID, claims)
+		require.NoError(t, err)
+		assert.Equal(t, []*domain.URLRevision{tRevision}, result)
+	})
+
+	t.Run("wrong user", func(t *testing.T) {
+		wrongClaims := auth.NewClaims("wrong user", []string{auth.RoleUser}, time.Now(), time.Minute)
+		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+
+		result, err := uc.History(context.Background(), tURL.ID, wrongClaims)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, result)
+	})
+
+	t.Run("url not found", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(nil, domain.ErrNotFound)
+
+		result, err := uc.History(context.Background(), tURL.ID, claims)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+func TestURLUsecase_Rollback(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tRevision := tests.NewURLRevision()
+	rollback := domain.URLRollback{URLID: tRevision.URLID, RevisionID: tRevision.ID.Hex()}
+
+	repository := mock.NewMockURLRepository(controller)
+	revisionRepository := mock.NewMockURLRevisionRepository(controller)
+	auditRepository := newAuditRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
+	claims := auth.NewClaims("507f191e810c19729de860ea", []string{auth.RoleUser}, time.Now(), time.Minute)
+
+	t.Run("success", func(t *testing.T) {
+		tURL := tests.NewURL()
+		expirationDate := tURL.ExpirationDate
+		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		revisionRepository.EXPECT().GetByID(gomock.Any(), tURL.ID, tRevision.ID).Return(tRevision, nil)
+		revisionRepository.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, r *domain.URLRevision) error {
+			assert.Equal(t, "http://www.example.org", r.Link)
+			return nil
+		})
+		repository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+		result, err := uc.Rollback(context.Background(), rollback, claims)
+		require.NoError(t, err)
+		assert.Equal(t, tRevision.Link, result.Link)
+		assert.Equal(t, tRevision.Title, result.Title)
+		assert.Equal(t, tRevision.RedirectType, result.RedirectType)
+		assert.Equal(t, expirationDate, result.ExpirationDate)
+	})
+
+	t.Run("empty title and notes", func(t *testing.T) {
+		tURL := tests.NewURL()
+		tURL.Title = "Current"
+		tURL.Notes = "current notes"
+		empty := tests.NewURLRevision()
+		empty.Title = ""
+		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+		revisionRepository.EXPECT().GetByID(gomock.Any(), tURL.ID, empty.ID).Return(empty, nil)
+		revisionRepository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		repository.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, u *domain.URL) error {
+			assert.Empty(t, u.Title)
+			assert.Empty(t, u.Notes)
+			return nil
+		})
+
+		result, err := uc.Rollback(context.Background(), rollback, claims)
+		require.NoError(t, err)
+		assert.Empty(t, result.Title)
+		assert.Empty(t, result.Notes)
+	})
+
+	t.Run("revision not found", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tRevision.URLID).Return(tests.NewURL(), nil)
+		revisionRepository.EXPECT().GetByID(gomock.Any(), tRevision.URLID, tRevision.ID).Return(nil, domain.ErrNotFound)
+
+		result, err := uc.Rollback(context.Background(), rollback, claims)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("wrong user", func(t *testing.T) {
+		wrongClaims := auth.NewClaims("wrong user", []string{auth.RoleUser}, time.Now(), time.Minute)
+		repository.EXPECT().GetByID(gomock.Any(), tRevision.URLID).Return(tests.NewURL(), nil)
+
+		result, err := uc.Rollback(context.Background(), rollback, wrongClaims)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, result)
+	})
+
+	t.Run("bad revision id", func(t *testing.T) {
+		result, err := uc.Rollback(context.Background(), domain.URLRollback{URLID: tRevision.URLID, RevisionID: "zzz"}, claims)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+		assert.Nil(t, result)
+	})
+}
+
+func TestURLUsecase_BulkStore(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repository := mock.NewMockURLRepository(controller)
+	revisionRepository := mock.NewMockURLRevisionRepository(controller)
+	auditRepository := newAuditRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
+
+	custom := tests.NewCreateURL()
+	generated := tests.NewCreateURL()
+	generated.ID = nil
+
+	t.Run("success with partial failure", func(t *testing.T) {
+		repository.EXPECT().StoreMany(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, urls []*domain.URL) ([]error, error) {
+			require.Len(t, urls, 2)
+			assert.Equal(t, *custom.ID, urls[0].ID)
+			assert.Regexp(t, regexp.MustCompile(`^[a-zA-Z0-9-_]{6}$`), urls[1].ID)
+			assert.Equal(t, http.StatusFound, urls[1].RedirectType)
+			return []error{domain.ErrConflict, nil}, nil
+		})
+
+		results, err := uc.BulkStore(context.Background(), []domain.CreateURL{custom, generated})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, 0, results[0].Index)
+		assert.ErrorIs(t, results[0].Err, domain.ErrConflict)
+		assert.Equal(t, 1, results[1].Index)
+		assert.NoError(t, results[1].Err)
+		assert.NotEmpty(t, results[1].ID)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repository.EXPECT().StoreMany(gomock.Any(), gomock.Any()).Return(nil, domain.ErrInternalServerError)
+
+		results, err := uc.BulkStore(context.Background(), []domain.CreateURL{custom})
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+		assert.Nil(t, results)
+	})
+
+	t.Run("generated id collision", func(t *testing.T) {
+		gen := mock.NewMockTokenGenerator(controller)
+		genUC := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: gen, Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
+
+		gomock.InOrder(
+			gen.EXPECT().Generate(gomock.Any()).Return("taken1", nil),
+			gen.EXPECT().Generate(gomock.Any()).Return("free12", nil),
+		)
+		gomock.InOrder(
+			repository.EXPECT().StoreMany(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, urls []*domain.URL) ([]error, error) {
+				require.Len(t, urls, 2)
+				return []error{domain.ErrConflict, domain.ErrConflict}, nil
+			}),
+			repository.EXPECT().StoreMany(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, urls []*domain.URL) ([]error, error) {
+				require.Len(t, urls, 1)
+				assert.Equal(t, "free12", urls[0].ID)
+				return []error{nil}, nil
+			}),
+		)
+
+		results, err := genUC.BulkStore(context.Background(), []domain.CreateURL{custom, generated})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.ErrorIs(t, results[0].Err, domain.ErrConflict)
+		assert.NoError(t, results[1].Err)
+		assert.Equal(t, "free12", results[1].ID)
+	})
+}
+
+func TestURLUsecase_BulkDelete(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repository := mock.NewMockURLRepository(controller)
+	revisionRepository := mock.NewMockURLRevisionRepository(controller)
+	auditRepository := newAuditRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
+	claims := auth.NewClaims("507f191e810c19729de860ea", []string{auth.RoleUser}, time.Now(), time.Minute)
+
+	owned := tests.NewURL()
+	foreign := tests.NewURL()
+	foreign.ID = "foreign1"
+	foreign.UserID = "507f191e810c19729de860eb"
+	anonymous := tests.NewURL()
+	anonymous.ID = "anonym1"
+	anonymous.UserID = ""
+
+	t.Run("success with partial failure", func(t *testing.T) {
+		ids := []string{owned.ID, foreign.ID, anonymous.ID, "missing", owned.ID}
+		repository.EXPECT().GetByIDs(gomock.Any(), ids).Return([]*domain.URL{owned, foreign, anonymous}, nil)
+		repository.EXPECT().DeleteMany(gomock.Any(), []string{owned.ID}).Return(nil)
+
+		results, err := uc.BulkDelete(context.Background(), ids, claims)
+		require.NoError(t, err)
+		require.Len(t, results, 5)
+		assert.NoError(t, results[0].Err)
+		assert.ErrorIs(t, results[1].Err, domain.ErrForbidden)
+		assert.ErrorIs(t, results[2].Err, domain.ErrForbidden)
+		assert.ErrorIs(t, results[3].Err, domain.ErrNotFound)
+		assert.NoError(t, results[4].Err)
+	})
+
+	t.Run("nothing allowed", func(t *testing.T) {
+		repository.EXPECT().GetByIDs(gomock.Any(), []string{foreign.ID}).Return([]*domain.URL{foreign}, nil)
+
+		results, err := uc.BulkDelete(context.Background(), []string{foreign.ID}, claims)
+		require.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, domain.ErrForbidden)
+	})
+
+	t.Run("admin", func(t *testing.T) {
+		adminClaims := auth.NewClaims("admin", []string{auth.RoleAdmin}, time.Now(), time.Minute)
+		repository.EXPECT().GetByIDs(gomock.Any(), []string{foreign.ID}).Return([]*domain.URL{foreign}, nil)
+		repository.EXPECT().DeleteMany(gomock.Any(), []string{foreign.ID}).Return(nil)
+
+		results, err := uc.BulkDelete(context.Background(), []string{foreign.ID}, adminClaims)
+		require.NoError(t, err)
+		assert.NoError(t, results[0].Err)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repository.EXPECT().GetByIDs(gomock.Any(), []string{owned.ID}).Return(nil, domain.ErrInternalServerError)
+
+		results, err := uc.BulkDelete(context.Background(), []string{owned.ID}, claims)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+		assert.Nil(t, results)
+	})
+}
+
+func TestURLUsecase_Restore(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repository := mock.NewMockURLRepository(controller)
+	revisionRepository := mock.NewMockURLRevisionRepository(controller)
+	auditRepository := newAuditRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
+	claims := auth.NewClaims("507f191e810c19729de860ea", []string{auth.RoleUser}, time.Now(), time.Minute)
+
+	deletedURL := func(ago time.Duration) *domain.URL {
+		u := tests.NewURL()
+		deletedAt := time.Now().Add(-ago)
+		u.DeletedAt = &deletedAt
+		return u
+	}
+
+	t.Run("success", func(t *testing.T) {
+		tURL := deletedURL(time.Hour)
+		repository.EXPECT().GetDeleted(gomock.Any(), tURL.ID).Return(tURL, nil)
+		repository.EXPECT().Restore(gomock.Any(), tURL.ID).Return(nil)
+
+		result, err := uc.Restore(context.Background(), tURL.ID, claims)
+		require.NoError(t, err)
+		assert.Nil(t, result.DeletedAt)
+	})
+
+	t.Run("grace period ended", func(t *testing.T) {
+		tURL := deletedURL(25 * time.Hour)
+		repository.EXPECT().GetDeleted(gomock.Any(), tURL.ID).Return(tURL, nil)
+
+		result, err := uc.Restore(context.Background(), tURL.ID, claims)
+		assert.ErrorIs(t, err, domain.ErrGone)
+		assert.Nil(t, result)
+	})
+
+	t.Run("wrong user", func(t *testing.T) {
+		tURL := deletedURL(time.Hour)
+		tURL.UserID = "507f191e810c19729de860eb"
+		repository.EXPECT().GetDeleted(gomock.Any(), tURL.ID).Return(tURL, nil)
+
+		result, err := uc.Restore(context.Background(), tURL.ID, claims)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, result)
+	})
+
+	t.Run("not deleted", func(t *testing.T) {
+		repository.EXPECT().GetDeleted(gomock.Any(), "test123").Return(nil, domain.ErrNotFound)
+
+		result, err := uc.Restore(context.Background(), "test123", claims)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+func TestURLUsecase_Purge(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repository := mock.NewMockURLRepository(controller)
+	revisionRepository := mock.NewMockURLRevisionRepository(controller)
+	auditRepository := auditMock.NewMockAuditRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	cfg := testConfig
+	cfg.ExpiredRetention = 30 * 24 * time.Hour
+	uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, cfg)
+
+	t.Run("success", func(t *testing.T) {
+		ids := []string{"test123", "test456"}
+		repository.EXPECT().ListExpired(gomock.Any(), gomock.Any(), 500).Return(nil, nil)
+		repository.EXPECT().ListDeleted(gomock.Any(), gomock.Any(), 500).DoAndReturn(func(_ context.Context, before time.Time, _ int) ([]string, error) {
+			assert.WithinDuration(t, time.Now().Add(-24*time.Hour), before, time.Minute)
+			return ids, nil
+		})
+		gomock.InOrder(
+			revisionRepository.EXPECT().DeleteByURL(gomock.Any(), "test123", "test456").Return(nil),
+			repository.EXPECT().Purge(gomock.Any(), ids).Return(nil),
+		)
+
+		n, err := uc.Purge(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+	})
+
+	t.Run("nothing to purge", func(t *testing.T) {
+		repository.EXPECT().ListExpired(gomock.Any(), gomock.Any(), 500).Return(nil, nil)
+		repository.EXPECT().ListDeleted(gomock.Any(), gomock.Any(), 500).Return([]string{}, nil)
+
+		n, err := uc.Purge(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("history delete error", func(t *testing.T) {
+		repository.EXPECT().ListExpired(gomock.Any(), gomock.Any(), 500).Return(nil, nil)
+		repository.EXPECT().ListDeleted(gomock.Any(), gomock.Any(), 500).Return([]string{"test123"}, nil)
+		revisionRepository.EXPECT().DeleteByURL(gomock.Any(), "test123").Return(domain.ErrInternalServerError)
+
+		n, err := uc.Purge(context.Background())
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+		assert.Zero(t, n)
+	})
+
+	t.Run("expired URLs are deleted", func(t *testing.T) {
+		expired := tests.NewURL()
+		expired.ExpirationDate = time.Now().Add(-31 * 24 * time.Hour).Truncate(time.Millisecond).UTC()
+		gomock.InOrder(
+			repository.EXPECT().ListExpired(gomock.Any(), gomock.Any(), 500).DoAndReturn(func(_ context.Context, before time.Time, _ int) ([]*domain.URL, error) {
+				assert.WithinDuration(t, time.Now().Add(-30*24*time.Hour), before, time.Minute)
+				return []*domain.URL{expired}, nil
+			}),
+			auditRepository.EXPECT().StoreMany(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, entries []*domain.AuditEntry) error {
+				require.Len(t, entries, 1)
+				assert.Equal(t, domain.AuditDeleteURL, entries[0].Action)
+				assert.Equal(t, expired.ID, entries[0].Target)
+				assert.Empty(t, entries[0].Actor)
+				assert.Equal(t, "expired", entries[0].Details["reason"])
+				return nil
+			}),
+			repository.EXPECT().DeleteMany(gomock.Any(), []string{expired.ID}).Return(nil),
+			repository.EXPECT().ListDeleted(gomock.Any(), gomock.Any(), 500).Return([]string{}, nil),
+		)
+
+		n, err := uc.Purge(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("expired URLs list error", func(t *testing.T) {
+		repository.EXPECT().ListExpired(gomock.Any(), gomock.Any(), 500).Return(nil, domain.ErrInternalServerError)
+
+		n, err := uc.Purge(context.Background())
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+		assert.Zero(t, n)
+	})
+}
+
+func TestURLUsecase_Unlock(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+	tURL := tests.NewURL()
+	tURL.HashedPassword = string(hash)
+
+	repository := mock.NewMockURLRepository(controller)
+	revisionRepository := mock.NewMockURLRevisionRepository(controller)
+	auditRepository := newAuditRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
+
+	t.Run("success", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+
+		result, err := uc.Unlock(context.Background(), domain.URLUnlock{ID: tURL.ID, Password: "secret"})
+		require.NoError(t, err)
+		assert.Equal(t, tURL.Link, result.Link)
+	})
+
+	t.Run("not protected", func(t *testing.T) {
+		openURL := tests.NewURL()
+		repository.EXPECT().GetByID(gomock.Any(), openURL.ID).Return(openURL, nil)
+
+		result, err := uc.Unlock(context.Background(), domain.URLUnlock{ID: openURL.ID, Password: "anything"})
+		require.NoError(t, err)
+		assert.Equal(t, openURL.Link, result.Link)
+	})
+
+	t.Run("url not found", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), "missing").Return(nil, domain.ErrNotFound)
+
+		result, err := uc.Unlock(context.Background(), domain.URLUnlock{ID: "missing", Password: "secret"})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("wrong password is limited", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil).Times(7)
+
+		for i := 0; i < 5; i++ {
+			result, err := uc.Unlock(context.Background(), domain.URLUnlock{ID: tURL.ID, Password: "wrong"})
+			assert.ErrorIs(t, err, domain.ErrAuthenticationFailure)
+			assert.Nil(t, result)
+		}
+
+		result, err := uc.Unlock(context.Background(), domain.URLUnlock{ID: tURL.ID, Password: "wrong"})
+		assert.ErrorIs(t, err, domain.ErrTooManyRequests)
+		assert.Nil(t, result)
+
+		// the right password doesn't help until the window passes
+		result, err = uc.Unlock(context.Background(), domain.URLUnlock{ID: tURL.ID, Password: "secret"})
+		assert.ErrorIs(t, err, domain.ErrTooManyRequests)
+		assert.Nil(t, result)
+	})
+
+	t.Run("wrong password is limited by shared store", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+		deps := usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), UnlockStore: store, Tracer: tracer}
+		instances := []domain.URLUsecase{usecase.NewURLUsecase(deps, testConfig), usecase.NewURLUsecase(deps, testConfig)}
+		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil).Times(6)
+
+		for i := 0; i < 5; i++ {
+			result, err := instances[i%2].Unlock(context.Background(), domain.URLUnlock{ID: tURL.ID, Password: "wrong"})
+			assert.ErrorIs(t, err, domain.ErrAuthenticationFailure)
+			assert.Nil(t, result)
+		}
+
+		result, err := instances[1].Unlock(context.Background(), domain.URLUnlock{ID: tURL.ID, Password: "secret"})
+		assert.ErrorIs(t, err, domain.ErrTooManyRequests)
+		assert.Nil(t, result)
+	})
+
+	t.Run("concurrent wrong passwords are limited", func(t *testing.T) {
+		deps := usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), UnlockStore: ratelimit.NewMemoryStore(), Tracer: tracer}
+		uc := usecase.NewURLUsecase(deps, testConfig)
+		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil).Times(20)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 20)
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := uc.Unlock(context.Background(), domain.URLUnlock{ID: tURL.ID, Password: "wrong"})
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		failed := 0
+		for err := range errs {
+			if errors.Is(err, domain.ErrAuthenticationFailure) {
+				failed++
+				continue
+			}
+			assert.ErrorIs(t, err, domain.ErrTooManyRequests)
+		}
+		assert.Equal(t, 5, failed)
+	})
+
+	t.Run("right passwords are not limited", func(t *testing.T) {
+		deps := usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), UnlockStore: ratelimit.NewMemoryStore(), Tracer: tracer}
+		uc := usecase.NewURLUsecase(deps, testConfig)
+		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil).Times(12)
+
+		for i := 0; i < 10; i++ {
+			_, err := uc.Unlock(context.Background(), domain.URLUnlock{ID: tURL.ID, Password: "secret"})
+			require.NoError(t, err)
+		}
+
+		_, err := uc.Unlock(context.Background(), domain.URLUnlock{ID: tURL.ID, Password: "wrong"})
+		assert.ErrorIs(t, err, domain.ErrAuthenticationFailure)
+		_, err = uc.Unlock(context.Background(), domain.URLUnlock{ID: tURL.ID, Password: "secret"})
+		assert.NoError(t, err)
+	})
+}
+
+func TestURLUsecase_CustomDomain(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repository := mock.NewMockURLRepository(controller)
+	revisionRepository := mock.NewMockURLRevisionRepository(controller)
+	auditRepository := newAuditRepository(controller)
+	workspaceRepository := workspaceMock.NewMockWorkspaceRepository(controller)
+	customDomainRepository := customDomainMock.NewMockCustomDomainRepository(controller)
+	cfg := testConfig
+	cfg.Hosts = []string{"Short.ly"}
+	uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, CustomDomainRepo: customDomainRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, cfg)
+
+	tWorkspace := tests.NewWorkspace()
+	tDomain := tests.NewCustomDomain()
+	verifiedAt := tDomain.CreatedAt
+	tDomain.VerifiedAt = &verifiedAt
+	tURL := tests.NewURL()
+
+	t.Run("resolve on own host", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+
+		result, err := uc.Resolve(context.Background(), "short.ly", tURL.ID)
+		require.NoError(t, err)
+		assert.Equal(t, tURL, result)
+	})
+
+	t.Run("custom domain URL on own host", func(t *testing.T) {
+		branded := tests.NewURL()
+		branded.Domain = tDomain.Host
+		branded.Slug = "promo"
+		branded.ID = domain.CustomDomainURLID(branded.Domain, branded.Slug)
+		repository.EXPECT().GetByID(gomock.Any(), branded.ID).Return(branded, nil)
+
+		result, err := uc.Resolve(context.Background(), "short.ly", branded.ID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("resolve on custom domain", func(t *testing.T) {
+		repository.EXPECT().GetByDomain(gomock.Any(), tDomain.Host, "promo").Return(tURL, nil)
+
+		result, err := uc.Resolve(context.Background(), "GO.example.com", "promo")
+		require.NoError(t, err)
+		assert.Equal(t, tURL, result)
+	})
+
+	t.Run("resolve by id without hosts", func(t *testing.T) {
+		uc := usecase.NewURLUsecase(usecase.Deps{URLRepo: repository, RevisionRepo: revisionRepository, AuditRepo: auditRepository, WorkspaceRepo: workspaceRepository, CustomDomainRepo: customDomainRepository, TokenGen: usecase.NewRandomTokenGenerator(6), Screener: usecase.NewNoopLinkScreener(), Policy: auth.DefaultPolicy(), Tracer: tracer}, testConfig)
+		repository.EXPECT().GetByID(gomock.Any(), tURL.ID).Return(tURL, nil)
+
+		result, err := uc.Resolve(context.Background(), "go.example.com", tURL.ID)
+		require.NoError(t, err)
+		assert.Equal(t, tURL, result)
+	})
+
+	t.Run("store on verified domain", func(t *testing.T) {
+		create := tests.NewCreateURL()
+		create.WorkspaceID = tWorkspace.ID.Hex()
+		create.Domain = "Go.Example.com"
+		slug := "summer-sale"
+		create.ID = &slug
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+		customDomainRepository.EXPECT().GetByHost(gomock.Any(), tDomain.Host).Return(tDomain, nil)
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+
+		result, err := uc.Store(context.Background(), create)
+		require.NoError(t, err)
+		assert.Equal(t, tDomain.Host, result.Domain)
+		assert.Equal(t, slug, result.Slug)
+		assert.Equal(t, domain.CustomDomainURLID(tDomain.Host, slug), result.ID)
+		assert.Equal(t, slug, result.ShortID())
+	})
+
+	t.Run("store on domain of another workspace", func(t *testing.T) {
+		other := tests.NewWorkspace()
+		other.ID, _ = primitive.ObjectIDFromHex("640f1c2e9b1e8a3d5c7b9a06")
+		create := tests.NewCreateURL()
+		create.WorkspaceID = other.ID.Hex()
+		create.Domain = tDomain.Host
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), other.ID).Return(other, nil)
+		customDomainRepository.EXPECT().GetByHost(gomock.Any(), tDomain.Host).Return(tDomain, nil)
+
+		_, err := uc.Store(context.Background(), create)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	})
+
+	t.Run("store on unverified domain", func(t *testing.T) {
+		create := tests.NewCreateURL()
+		create.WorkspaceID = tWorkspace.ID.Hex()
+		create.Domain = "links.example.com"
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+		customDomainRepository.EXPECT().GetByHost(gomock.Any(), "links.example.com").Return(nil, domain.ErrNotFound)
+
+		_, err := uc.Store(context.Background(), create)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	})
+
+	t.Run("store on domain without workspace", func(t *testing.T) {
+		create := tests.NewCreateURL()
+		create.Domain = tDomain.Host
+
+		_, err := uc.Store(context.Background(), create)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	})
+
+	t.Run("bulk store looks up domain once", func(t *testing.T) {
+		items := []domain.CreateURL{tests.NewCreateURL(), tests.NewCreateURL()}
+		for i := range items {
+			items[i].WorkspaceID = tWorkspace.ID.Hex()
+			items[i].Domain = tDomain.Host
+		}
+		workspaceRepository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+		customDomainRepository.EXPECT().GetByHost(gomock.Any(), tDomain.Host).Return(tDomain, nil)
+		repository.EXPECT().StoreMany(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, urls []*domain.URL) ([]error, error) {
+			for _, u := range urls {
+				assert.Equal(t, domain.CustomDomainURLID(u.Domain, u.Slug), u.ID)
+			}
+			return make([]error, len(urls)), nil
+		})
+
+		results, err := uc.BulkStore(context.Background(), items)
+		require.NoError(t, err)
+		assert.Len(t, results, 2)
+	})
+}
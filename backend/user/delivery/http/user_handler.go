@@ -20,20 +20,30 @@ import (
 	"github.com/semka95/shortener/backend/web/auth"
 )
 
+// tokenResponse represents issued access and refresh tokens
+type tokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
 // UserHandler represent the http handler for user
 type UserHandler struct {
 	userUsecase   domain.UserUsecase
+	tokenUsecase  domain.TokenUsecase
 	authenticator *auth.Authenticator
+	policy        *auth.Policy
 	validator     *web.AppValidator
 	logger        *zap.Logger
 	tracer        trace.Tracer
 }
 
 // NewUserHandler will initialize the user/ resources endpoint
-func NewUserHandler(us domain.UserUsecase, authenticator *auth.Authenticator, v *web.AppValidator, logger *zap.Logger, tracer trace.Tracer) *UserHandler {
+func NewUserHandler(us domain.UserUsecase, ts domain.TokenUsecase, authenticator *auth.Authenticator, policy *auth.Policy, v *web.AppValidator, logger *zap.Logger, tracer trace.Tracer) *UserHandler {
 	return &UserHandler{
 		userUsecase:   us,
+		tokenUsecase:  ts,
 		authenticator: authenticator,
+		policy:        policy,
 		validator:     v,
 		logger:        logger,
 		tracer:        tracer,
@@ -42,15 +52,20 @@ func NewUserHandler(us domain.UserUsecase, authenticator *auth.Authenticator, v
 
 // RegisterRoutes registers routes for a path with matching handler
 func (uh *UserHandler) RegisterRoutes(e *echo.Echo) {
-	myMiddl := _MyMiddleware.InitMiddleware(uh.logger)
+	myMiddl := _MyMiddleware.InitMiddleware(uh.logger, uh.policy)
 	e.POST("/v1/user/create", uh.Create)
 	e.GET("/v1/user/:id", uh.GetByID, echojwt.WithConfig(uh.authenticator.JWTConfig))
 	e.GET("v1/user/token", uh.Token)
-	e.DELETE("/v1/user/:id", uh.Delete, echojwt.WithConfig(uh.authenticator.JWTConfig), myMiddl.HasRole(auth.RoleAdmin))
+	e.POST("/v1/user/token/refresh", uh.Refresh)
+	e.POST("/v1/user/logout", uh.Logout, echojwt.WithConfig(uh.authenticator.JWTConfig))
+	e.POST("/v1/user/logout/all", uh.LogoutAll, echojwt.WithConfig(uh.authenticator.JWTConfig))
+	e.DELETE("/v1/user/:id", uh.Delete, echojwt.WithConfig(uh.authenticator.JWTConfig), myMiddl.HasPermission(auth.PermUserDelete))
+	e.POST("/v1/user/:id/restore", uh.Restore, echojwt.WithConfig(uh.authenticator.JWTConfig), myMiddl.HasPermission(auth.PermUserRestore))
 	e.PUT("/v1/user", uh.Update, echojwt.WithConfig(uh.authenticator.JWTConfig))
 }
 
-// GetByID will get user by given id
+// GetByID will get user by given id, users can get only themselves unless
+// their roles allow to read any user
 func (uh *UserHandler) GetByID(c echo.Context) error {
 	id := c.Param("id")
 
@@ -64,6 +79,23 @@ func (uh *UserHandler) GetByID(c echo.Context) error {
 	)
 	defer span.End()
 
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok || token == nil {
+		span.RecordError(domain.ErrForbidden)
+		return c.JSON(http.StatusForbidden, domain.ResponseError{Error: domain.ErrForbidden.Error()})
+	}
+	claims, ok := token.Claims.(*auth.Claims)
+	if !ok {
+		span.RecordError(domain.ErrInternalServerError)
+		return fmt.Errorf("%w can't convert jwt.Claims to auth.Claims", domain.ErrInternalServerError)
+	}
+
+	if err := uh.policy.Authorize(claims, auth.ActionUserRead, &auth.Resource{Owner: id}); err != nil {
+		err = fmt.Errorf("%w: %s", domain.ErrForbidden, err.Error())
+		span.RecordError(err)
+		return c.JSON(http.StatusForbidden, domain.ResponseError{Error: err.Error()})
+	}
+
 	u, err := uh.userUsecase.GetByID(ctx, id)
 	if err != nil {
 		span.RecordError(err)
@@ -112,8 +144,41 @@ func (uh *UserHandler) Create(c echo.Context) error {
 	return c.JSON(http.StatusCreated, u)
 }
 
-// Delete will delete User by given id
+// Delete will delete User by given id and apply link policy to their URLs
 func (uh *UserHandler) Delete(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := uh.tracer.Start(
+		ctx,
+		"http Delete",
+	)
+	defer span.End()
+
+	req := new(domain.DeleteUser)
+	if err := c.Bind(req); err != nil {
+		span.RecordError(domain.ErrBadParamInput)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
+	}
+
+	if err := c.Validate(req); err != nil {
+		span.RecordError(domain.ErrBadParamInput)
+		fields := err.(validator.ValidationErrors).Translate(uh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	res, err := uh.userUsecase.Delete(ctx, *req)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// Restore will restore deleted user by given id
+func (uh *UserHandler) Restore(c echo.Context) error {
 	id := c.Param("id")
 
 	ctx := c.Request().Context()
@@ -122,16 +187,17 @@ func (uh *UserHandler) Delete(c echo.Context) error {
 	}
 	ctx, span := uh.tracer.Start(
 		ctx,
-		"http Delete",
+		"http Restore",
 	)
 	defer span.End()
 
-	if err := uh.userUsecase.Delete(ctx, id); err != nil {
+	u, err := uh.userUsecase.Restore(ctx, id)
+	if err != nil {
 		span.RecordError(err)
 		return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
 	}
 
-	return c.JSON(http.StatusNoContent, nil)
+	return c.JSON(http.StatusOK, u)
 }
 
 // Update will update the User by given request body
@@ -174,7 +240,7 @@ func (uh *UserHandler) Update(c echo.Context) error {
 		return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
 	}
 
-	return c.JSON(http.StatusNoContent, nil)
+	return c.NoContent(http.StatusNoContent)
 }
 
 // Token will return jwt token by given credentials
@@ -201,9 +267,56 @@ func (uh *UserHandler) Token(c echo.Context) error {
 		return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
 	}
 
-	var tkn struct {
-		Token string `json:"token"`
+	tkn := new(tokenResponse)
+	tkn.Token, err = uh.authenticator.GenerateToken(claims)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	tkn.RefreshToken, err = uh.tokenUsecase.Issue(ctx, claims)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, tkn)
+}
+
+// Refresh will return new jwt and refresh tokens by given refresh token
+func (uh *UserHandler) Refresh(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := uh.tracer.Start(
+		ctx,
+		"http Refresh",
+	)
+	defer span.End()
+
+	r := new(domain.RefreshTokenRequest)
+	if err := c.Bind(r); err != nil {
+		span.RecordError(err)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
 	}
+
+	if err := c.Validate(r); err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(uh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	claims, refreshToken, err := uh.tokenUsecase.Refresh(ctx, time.Now(), r.RefreshToken)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
+	}
+	span.SetAttributes(
+		attribute.String("userid", claims.Subject),
+	)
+
+	tkn := &tokenResponse{RefreshToken: refreshToken}
 	tkn.Token, err = uh.authenticator.GenerateToken(claims)
 	if err != nil {
 		span.RecordError(err)
@@ -212,3 +325,72 @@ func (uh *UserHandler) Token(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, tkn)
 }
+
+// Logout will revoke current jwt token and, if given in request body, refresh token
+func (uh *UserHandler) Logout(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := uh.tracer.Start(
+		ctx,
+		"http Logout",
+	)
+	defer span.End()
+
+	// refresh token is optional here, so request is not validated
+	r := new(domain.RefreshTokenRequest)
+	if err := c.Bind(r); err != nil {
+		span.RecordError(err)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
+	}
+
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok || token == nil {
+		span.RecordError(domain.ErrForbidden)
+		return c.JSON(http.StatusForbidden, domain.ResponseError{Error: domain.ErrForbidden.Error()})
+	}
+	claims, ok := token.Claims.(*auth.Claims)
+	if !ok {
+		span.RecordError(domain.ErrInternalServerError)
+		return fmt.Errorf("%w can't convert jwt.Claims to auth.Claims", domain.ErrInternalServerError)
+	}
+
+	if err := uh.tokenUsecase.Logout(ctx, r.RefreshToken, claims); err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// LogoutAll will revoke all jwt and refresh tokens of authenticated user
+func (uh *UserHandler) LogoutAll(c echo.Context) error {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := uh.tracer.Start(
+		ctx,
+		"http LogoutAll",
+	)
+	defer span.End()
+
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok || token == nil {
+		span.RecordError(domain.ErrForbidden)
+		return c.JSON(http.StatusForbidden, domain.ResponseError{Error: domain.ErrForbidden.Error()})
+	}
+	claims, ok := token.Claims.(*auth.Claims)
+	if !ok {
+		span.RecordError(domain.ErrInternalServerError)
+		return fmt.Errorf("%w can't convert jwt.Claims to auth.Claims", domain.ErrInternalServerError)
+	}
+
+	if err := uh.tokenUsecase.LogoutAll(ctx, claims); err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, uh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
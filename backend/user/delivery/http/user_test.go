@@ -21,6 +21,7 @@ import (
 
 	"github.com/semka95/shortener/backend/domain"
 	"github.com/semka95/shortener/backend/tests"
+	tokenMock "github.com/semka95/shortener/backend/token/mock"
 	userHttp "github.com/semka95/shortener/backend/user/delivery/http"
 	"github.com/semka95/shortener/backend/user/mock"
 	"github.com/semka95/shortener/backend/web"
@@ -47,12 +48,13 @@ func TestUserHTTP(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
 	uc := mock.NewMockUserUsecase(controller)
+	tuc := tokenMock.NewMockTokenUsecase(controller)
 
 	tracer := sdktrace.NewTracerProvider().Tracer("")
 	v, err := web.NewAppValidator()
 	require.NoError(t, err)
 
-	handler := userHttp.NewUserHandler(uc, authenticator, v, zap.NewNop(), tracer)
+	handler := userHttp.NewUserHandler(uc, tuc, authenticator, auth.DefaultPolicy(), v, zap.NewNop(), tracer)
 
 	e := echo.New()
 	e.Validator = v
@@ -62,9 +64,13 @@ func TestUserHTTP(t *testing.T) {
 	// Test UserHandler.GetByID
 	reqTarget := "/" + tUser.ID.Hex()
 
+	otherClaims := auth.NewClaims("507f191e810c19729de860eb", []string{auth.RoleUser}, time.Now(), time.Hour)
+	supportClaims := auth.NewClaims("507f191e810c19729de860ec", []string{auth.RoleSupport}, time.Now(), time.Hour)
+
 	casesGet := []struct {
 		description   string
 		mockCalls     func(muc *mock.MockUserUsecase)
+		claims        *auth.Claims
 		checkResponse func(rec *httptest.ResponseRecorder)
 	}{
 		{
@@ -94,6 +100,32 @@ func TestUserHTTP(t *testing.T) {
 				assert.Equal(t, http.StatusNotFound, rec.Code)
 			},
 		},
+		{
+			description: "GetByID another user",
+			mockCalls:   func(muc *mock.MockUserUsecase) {},
+			claims:      otherClaims,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Contains(t, body.Error, domain.ErrForbidden.Error())
+				assert.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			description: "GetByID by support",
+			mockCalls: func(muc *mock.MockUserUsecase) {
+				uc.EXPECT().GetByID(gomock.Any(), tUser.ID.Hex()).Return(tUser, nil)
+			},
+			claims: supportClaims,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.User)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, tUser.ID, body.ID)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
 	}
 
 	for _, tc := range casesGet {
@@ -106,6 +138,11 @@ func TestUserHTTP(t *testing.T) {
 			c.SetPath("/:id")
 			c.SetParamNames("id")
 			c.SetParamValues(tUser.ID.Hex())
+			if tc.claims != nil {
+				c.Set("user", jwt.NewWithClaims(jwt.SigningMethodHS256, tc.claims))
+			} else {
+				c.Set("user", token)
+			}
 
 			err = handler.GetByID(c)
 			require.NoError(t, err)
@@ -160,6 +197,20 @@ func TestUserHTTP(t *testing.T) {
 				assert.Equal(t, http.StatusInternalServerError, rec.Code)
 			},
 		},
+		{
+			description: "Create email already exists",
+			mockCalls: func(muc *mock.MockUserUsecase) {
+				uc.EXPECT().Create(gomock.Any(), tCreateUser).Return(nil, domain.ErrConflict)
+			},
+			reqBody: bytes.NewBuffer(createUserB),
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, domain.ErrConflict.Error(), body.Error)
+				assert.Equal(t, http.StatusConflict, rec.Code)
+			},
+		},
 		{
 			description: "Create validation error",
 			mockCalls:   func(muc *mock.MockUserUsecase) {},
@@ -205,24 +256,32 @@ func TestUserHTTP(t *testing.T) {
 	}
 
 	// Test UserHandler.Delete
+	tDeleteResult := &domain.DeleteUserResult{ID: tUser.ID.Hex(), Links: domain.LinkPolicyDelete, URLs: 2}
+
 	casesDelete := []struct {
 		description   string
+		query         string
 		mockCalls     func(muc *mock.MockUserUsecase)
 		checkResponse func(rec *httptest.ResponseRecorder)
 	}{
 		{
 			description: "Delete success",
+			query:       "?links=delete",
 			mockCalls: func(muc *mock.MockUserUsecase) {
-				uc.EXPECT().Delete(gomock.Any(), tUser.ID.Hex()).Return(nil)
+				uc.EXPECT().Delete(gomock.Any(), domain.DeleteUser{ID: tUser.ID.Hex(), Links: domain.LinkPolicyDelete}).Return(tDeleteResult, nil)
 			},
 			checkResponse: func(rec *httptest.ResponseRecorder) {
-				assert.Equal(t, http.StatusNoContent, rec.Code)
+				body := new(domain.DeleteUserResult)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, tDeleteResult, body)
+				assert.Equal(t, http.StatusOK, rec.Code)
 			},
 		},
 		{
 			description: "Delete existed user",
 			mockCalls: func(muc *mock.MockUserUsecase) {
-				uc.EXPECT().Delete(gomock.Any(), tUser.ID.Hex()).Return(domain.ErrNoAffected)
+				uc.EXPECT().Delete(gomock.Any(), domain.DeleteUser{ID: tUser.ID.Hex()}).Return(nil, domain.ErrNoAffected)
 			},
 			checkResponse: func(rec *httptest.ResponseRecorder) {
 				body := new(domain.ResponseError)
@@ -232,12 +291,24 @@ func TestUserHTTP(t *testing.T) {
 				assert.Equal(t, http.StatusNotFound, rec.Code)
 			},
 		},
+		{
+			description: "Delete unknown link policy",
+			query:       "?links=keep",
+			mockCalls:   func(muc *mock.MockUserUsecase) {},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Contains(t, body.Fields, "DeleteUser.links")
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
 	}
 
 	for _, tc := range casesDelete {
 		t.Run(tc.description, func(t *testing.T) {
 			tc.mockCalls(uc)
-			req = httptest.NewRequest(echo.DELETE, "/user/"+tUser.ID.Hex(), nil)
+			req = httptest.NewRequest(echo.DELETE, "/user/"+tUser.ID.Hex()+tc.query, nil)
 
 			rec := httptest.NewRecorder()
 			c.Reset(req, rec)
@@ -252,6 +323,54 @@ func TestUserHTTP(t *testing.T) {
 		})
 	}
 
+	// Test UserHandler.Restore
+	casesRestore := []struct {
+		description   string
+		mockCalls     func(muc *mock.MockUserUsecase)
+		checkResponse func(rec *httptest.ResponseRecorder)
+	}{
+		{
+			description: "Restore success",
+			mockCalls: func(muc *mock.MockUserUsecase) {
+				uc.EXPECT().Restore(gomock.Any(), tUser.ID.Hex()).Return(tUser, nil)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.User)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, tUser.ID, body.ID)
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			description: "Restore not deleted user",
+			mockCalls: func(muc *mock.MockUserUsecase) {
+				uc.EXPECT().Restore(gomock.Any(), tUser.ID.Hex()).Return(nil, domain.ErrNotFound)
+			},
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, rec.Code)
+			},
+		},
+	}
+
+	for _, tc := range casesRestore {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls(uc)
+			req = httptest.NewRequest(echo.POST, "/user/"+tUser.ID.Hex()+"/restore", nil)
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+			c.SetPath("/user/:id/restore")
+			c.SetParamNames("id")
+			c.SetParamValues(tUser.ID.Hex())
+
+			err = handler.Restore(c)
+			require.NoError(t, err)
+
+			tc.checkResponse(rec)
+		})
+	}
+
 	// Test UserHandler.Update
 	tUpdateUser := tests.NewUpdateUser()
 	tUpdateUserWrongEmail := tests.NewUpdateUser()
@@ -366,6 +485,7 @@ func TestUserHTTP(t *testing.T) {
 			description: "Token success",
 			mockCalls: func(muc *mock.MockUserUsecase) {
 				uc.EXPECT().Authenticate(gomock.Any(), gomock.Any(), tUser.Email, password).Return(claims, nil)
+				tuc.EXPECT().Issue(gomock.Any(), claims).Return("refresh", nil)
 			},
 			auth: true,
 			checkResponse: func(rec *httptest.ResponseRecorder) {
@@ -373,9 +493,21 @@ func TestUserHTTP(t *testing.T) {
 				err = json.NewDecoder(rec.Body).Decode(&body)
 				require.NoError(t, err)
 				assert.Equal(t, tokenStr, body["token"])
+				assert.Equal(t, "refresh", body["refresh_token"])
 				assert.Equal(t, http.StatusOK, rec.Code)
 			},
 		},
+		{
+			description: "Token refresh token issue failure",
+			mockCalls: func(muc *mock.MockUserUsecase) {
+				uc.EXPECT().Authenticate(gomock.Any(), gomock.Any(), tUser.Email, password).Return(claims, nil)
+				tuc.EXPECT().Issue(gomock.Any(), claims).Return("", domain.ErrInternalServerError)
+			},
+			auth: true,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, rec.Code)
+			},
+		},
 		{
 			description: "Token no credentials",
 			mockCalls:   func(muc *mock.MockUserUsecase) {},
@@ -423,6 +555,186 @@ func TestUserHTTP(t *testing.T) {
 		})
 	}
 
+	// Test UserHandler.Refresh
+	casesRefresh := []struct {
+		description   string
+		mockCalls     func(mtuc *tokenMock.MockTokenUsecase)
+		reqBody       string
+		checkResponse func(rec *httptest.ResponseRecorder)
+	}{
+		{
+			description: "Refresh success",
+			mockCalls: func(mtuc *tokenMock.MockTokenUsecase) {
+				mtuc.EXPECT().Refresh(gomock.Any(), gomock.Any(), "old").Return(claims, "new", nil)
+			},
+			reqBody: `{"refresh_token":"old"}`,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := make(map[string]string)
+				err = json.NewDecoder(rec.Body).Decode(&body)
+				require.NoError(t, err)
+				assert.Equal(t, tokenStr, body["token"])
+				assert.Equal(t, "new", body["refresh_token"])
+				assert.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			description: "Refresh invalid token",
+			mockCalls: func(mtuc *tokenMock.MockTokenUsecase) {
+				mtuc.EXPECT().Refresh(gomock.Any(), gomock.Any(), "old").Return(nil, "", domain.ErrAuthenticationFailure)
+			},
+			reqBody: `{"refresh_token":"old"}`,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, domain.ErrAuthenticationFailure.Error(), body.Error)
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			},
+		},
+		{
+			description: "Refresh validation error",
+			mockCalls:   func(mtuc *tokenMock.MockTokenUsecase) {},
+			reqBody:     `{}`,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				body := new(domain.ResponseError)
+				err = json.NewDecoder(rec.Body).Decode(body)
+				require.NoError(t, err)
+				assert.Equal(t, "validation error", body.Error)
+				assert.Equal(t, "refresh_token is a required field", body.Fields["RefreshTokenRequest.refresh_token"])
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			description: "Refresh bad request body",
+			mockCalls:   func(mtuc *tokenMock.MockTokenUsecase) {},
+			reqBody:     `{"refresh_token":`,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+	}
+
+	for _, tc := range casesRefresh {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls(tuc)
+			req = httptest.NewRequest(echo.POST, "/v1/user/token/refresh", bytes.NewBufferString(tc.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+			c.SetPath("/v1/user/token/refresh")
+
+			err = handler.Refresh(c)
+			require.NoError(t, err)
+
+			tc.checkResponse(rec)
+		})
+	}
+
+	// Test UserHandler.Logout and LogoutAll
+	casesLogout := []struct {
+		description   string
+		mockCalls     func(mtuc *tokenMock.MockTokenUsecase)
+		reqBody       string
+		auth          bool
+		handler       func(c echo.Context) error
+		checkResponse func(rec *httptest.ResponseRecorder)
+	}{
+		{
+			description: "Logout success",
+			mockCalls: func(mtuc *tokenMock.MockTokenUsecase) {
+				mtuc.EXPECT().Logout(gomock.Any(), "refresh", claims).Return(nil)
+			},
+			reqBody: `{"refresh_token":"refresh"}`,
+			auth:    true,
+			handler: handler.Logout,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, rec.Code)
+			},
+		},
+		{
+			description: "Logout without refresh token",
+			mockCalls: func(mtuc *tokenMock.MockTokenUsecase) {
+				mtuc.EXPECT().Logout(gomock.Any(), "", claims).Return(nil)
+			},
+			auth:    true,
+			handler: handler.Logout,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, rec.Code)
+			},
+		},
+		{
+			description: "Logout refresh token of another user",
+			mockCalls: func(mtuc *tokenMock.MockTokenUsecase) {
+				mtuc.EXPECT().Logout(gomock.Any(), "refresh", claims).Return(domain.ErrForbidden)
+			},
+			reqBody: `{"refresh_token":"refresh"}`,
+			auth:    true,
+			handler: handler.Logout,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			description: "Logout not authorized",
+			mockCalls:   func(mtuc *tokenMock.MockTokenUsecase) {},
+			handler:     handler.Logout,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			description: "LogoutAll success",
+			mockCalls: func(mtuc *tokenMock.MockTokenUsecase) {
+				mtuc.EXPECT().LogoutAll(gomock.Any(), claims).Return(nil)
+			},
+			auth:    true,
+			handler: handler.LogoutAll,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, rec.Code)
+			},
+		},
+		{
+			description: "LogoutAll server error",
+			mockCalls: func(mtuc *tokenMock.MockTokenUsecase) {
+				mtuc.EXPECT().LogoutAll(gomock.Any(), claims).Return(domain.ErrInternalServerError)
+			},
+			auth:    true,
+			handler: handler.LogoutAll,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, rec.Code)
+			},
+		},
+		{
+			description: "LogoutAll not authorized",
+			mockCalls:   func(mtuc *tokenMock.MockTokenUsecase) {},
+			handler:     handler.LogoutAll,
+			checkResponse: func(rec *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+	}
+
+	for _, tc := range casesLogout {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls(tuc)
+			req = httptest.NewRequest(echo.POST, "/v1/user/logout", bytes.NewBufferString(tc.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+			c.SetPath("/v1/user/logout")
+			if tc.auth {
+				c.Set("user", token)
+			}
+
+			err = tc.handler(c)
+			require.NoError(t, err)
+
+			tc.checkResponse(rec)
+		})
+	}
+
 	// Test validation for models.CreateUser and models.UpdateUser structs
 	casesCreateUser := []struct {
 		description string
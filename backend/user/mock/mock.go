@@ -69,17 +69,18 @@ func (mr *MockUserUsecaseMockRecorder) Create(ctx, user interface{}) *gomock.Cal
 }
 
 // Delete mocks base method.
-func (m *MockUserUsecase) Delete(ctx context.Context, id string) error {
+func (m *MockUserUsecase) Delete(ctx context.Context, req domain.DeleteUser) (*domain.DeleteUserResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, id)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "Delete", ctx, req)
+	ret0, _ := ret[0].(*domain.DeleteUserResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockUserUsecaseMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+func (mr *MockUserUsecaseMockRecorder) Delete(ctx, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserUsecase)(nil).Delete), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserUsecase)(nil).Delete), ctx, req)
 }
 
 // GetByID mocks base method.
@@ -97,6 +98,36 @@ func (mr *MockUserUsecaseMockRecorder) GetByID(ctx, id interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockUserUsecase)(nil).GetByID), ctx, id)
 }
 
+// Purge mocks base method.
+func (m *MockUserUsecase) Purge(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockUserUsecaseMockRecorder) Purge(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockUserUsecase)(nil).Purge), ctx)
+}
+
+// Restore mocks base method.
+func (m *MockUserUsecase) Restore(ctx context.Context, id string) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockUserUsecaseMockRecorder) Restore(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockUserUsecase)(nil).Restore), ctx, id)
+}
+
 // Update mocks base method.
 func (m *MockUserUsecase) Update(ctx context.Context, user domain.UpdateUser, claims *auth.Claims) error {
 	m.ctrl.T.Helper()
@@ -134,6 +165,20 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddIdentity mocks base method.
+func (m *MockUserRepository) AddIdentity(ctx context.Context, id primitive.ObjectID, identity domain.Identity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddIdentity", ctx, id, identity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddIdentity indicates an expected call of AddIdentity.
+func (mr *MockUserRepositoryMockRecorder) AddIdentity(ctx, id, identity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddIdentity", reflect.TypeOf((*MockUserRepository)(nil).AddIdentity), ctx, id, identity)
+}
+
 // Create mocks base method.
 func (m *MockUserRepository) Create(ctx context.Context, user *domain.User) error {
 	m.ctrl.T.Helper()
@@ -192,6 +237,94 @@ func (mr *MockUserRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockUserRepository)(nil).GetByID), ctx, id)
 }
 
+// GetByIdentity mocks base method.
+func (m *MockUserRepository) GetByIdentity(ctx context.Context, issuer, subject string) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIdentity", ctx, issuer, subject)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIdentity indicates an expected call of GetByIdentity.
+func (mr *MockUserRepositoryMockRecorder) GetByIdentity(ctx, issuer, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIdentity", reflect.TypeOf((*MockUserRepository)(nil).GetByIdentity), ctx, issuer, subject)
+}
+
+// GetDeleted mocks base method.
+func (m *MockUserRepository) GetDeleted(ctx context.Context, id primitive.ObjectID) (*domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeleted", ctx, id)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeleted indicates an expected call of GetDeleted.
+func (mr *MockUserRepositoryMockRecorder) GetDeleted(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeleted", reflect.TypeOf((*MockUserRepository)(nil).GetDeleted), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockUserRepository) List(ctx context.Context, query domain.UserListQuery) (*domain.UserList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, query)
+	ret0, _ := ret[0].(*domain.UserList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockUserRepositoryMockRecorder) List(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserRepository)(nil).List), ctx, query)
+}
+
+// ListDeleted mocks base method.
+func (m *MockUserRepository) ListDeleted(ctx context.Context, before time.Time, limit int) ([]primitive.ObjectID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeleted", ctx, before, limit)
+	ret0, _ := ret[0].([]primitive.ObjectID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeleted indicates an expected call of ListDeleted.
+func (mr *MockUserRepositoryMockRecorder) ListDeleted(ctx, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeleted", reflect.TypeOf((*MockUserRepository)(nil).ListDeleted), ctx, before, limit)
+}
+
+// Purge mocks base method.
+func (m *MockUserRepository) Purge(ctx context.Context, ids []primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockUserRepositoryMockRecorder) Purge(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockUserRepository)(nil).Purge), ctx, ids)
+}
+
+// Restore mocks base method.
+func (m *MockUserRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockUserRepositoryMockRecorder) Restore(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockUserRepository)(nil).Restore), ctx, id)
+}
+
 // Update mocks base method.
 func (m *MockUserRepository) Update(ctx context.Context, user *domain.User) error {
 	m.ctrl.T.Helper()
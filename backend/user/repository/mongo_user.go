@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -15,6 +17,16 @@ import (
 	"github.com/semka95/shortener/backend/store"
 )
 
+// emailCollation matches collation of the unique email index, so emails are
+// compared case-insensitively and the lookup uses the index
+var emailCollation = bson.D{
+	primitive.E{Key: "locale", Value: "en"},
+	primitive.E{Key: "strength", Value: 2},
+}
+
+// notDeleted filters out users marked as deleted
+var notDeleted = primitive.E{Key: "deleted_at", Value: nil}
+
 type mongoUserRepository struct {
 	Conn   *mongo.Database
 	logger *zap.Logger
@@ -79,7 +91,10 @@ func (m *mongoUserRepository) GetByID(ctx context.Context, id primitive.ObjectID
 	command := bson.D{
 		primitive.E{Key: "find", Value: "user"},
 		primitive.E{Key: "limit", Value: 1},
-		primitive.E{Key: "filter", Value: bson.D{primitive.E{Key: "_id", Value: id}}},
+		primitive.E{Key: "filter", Value: bson.D{
+			primitive.E{Key: "_id", Value: id},
+			notDeleted,
+		}},
 	}
 
 	list, err := m.fetch(ctx, command)
@@ -106,6 +121,10 @@ func (m *mongoUserRepository) Create(ctx context.Context, user *domain.User) err
 	defer span.End()
 
 	_, err := m.Conn.Collection("user").InsertOne(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		span.RecordError(err)
+		return fmt.Errorf("user with email %s already exists: %w", user.Email, domain.ErrConflict)
+	}
 	if err != nil {
 		span.RecordError(err)
 		return fmt.Errorf("user store error: %w: %s", domain.ErrInternalServerError, err.Error())
@@ -114,6 +133,8 @@ func (m *mongoUserRepository) Create(ctx context.Context, user *domain.User) err
 	return nil
 }
 
+// Delete marks user as deleted, the document is kept until it is purged, so
+// the email can't be taken by another user meanwhile
 func (m *mongoUserRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
 	ctx, span := m.tracer.Start(
 		ctx,
@@ -125,15 +146,17 @@ func (m *mongoUserRepository) Delete(ctx context.Context, id primitive.ObjectID)
 
 	filter := bson.D{
 		primitive.E{Key: "_id", Value: id},
+		notDeleted,
 	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "deleted_at", Value: time.Now().Truncate(time.Millisecond).UTC()}}}}
 
-	delRes, err := m.Conn.Collection("user").DeleteOne(ctx, filter)
+	updRes, err := m.Conn.Collection("user").UpdateOne(ctx, filter, update)
 	if err != nil {
 		span.RecordError(err)
 		return fmt.Errorf("user delete error: %w: %s", domain.ErrInternalServerError, err.Error())
 	}
 
-	if delRes.DeletedCount == 0 {
+	if updRes.ModifiedCount == 0 {
 		err = fmt.Errorf("user was not deleted: %w", domain.ErrNoAffected)
 		span.RecordError(err)
 		return err
@@ -152,6 +175,7 @@ func (m *mongoUserRepository) Update(ctx context.Context, user *domain.User) err
 
 	filter := bson.D{
 		primitive.E{Key: "_id", Value: user.ID},
+		notDeleted,
 	}
 
 	doc, err := store.StructToDoc(&user)
@@ -162,6 +186,10 @@ func (m *mongoUserRepository) Update(ctx context.Context, user *domain.User) err
 	update := bson.D{primitive.E{Key: "$set", Value: doc}}
 
 	updRes, err := m.Conn.Collection("user").UpdateOne(ctx, filter, update)
+	if mongo.IsDuplicateKeyError(err) {
+		span.RecordError(err)
+		return fmt.Errorf("user with email %s already exists: %w", user.Email, domain.ErrConflict)
+	}
 	if err != nil {
 		span.RecordError(err)
 		return fmt.Errorf("user update error: %w: %s", domain.ErrInternalServerError, err.Error())
@@ -186,7 +214,11 @@ func (m *mongoUserRepository) GetByEmail(ctx context.Context, email string) (*do
 	command := bson.D{
 		primitive.E{Key: "find", Value: "user"},
 		primitive.E{Key: "limit", Value: 1},
-		primitive.E{Key: "filter", Value: bson.D{primitive.E{Key: "email", Value: email}}},
+		primitive.E{Key: "filter", Value: bson.D{
+			primitive.E{Key: "email", Value: email},
+			notDeleted,
+		}},
+		primitive.E{Key: "collation", Value: emailCollation},
 	}
 
 	list, err := m.fetch(ctx, command)
@@ -204,3 +236,267 @@ func (m *mongoUserRepository) GetByEmail(ctx context.Context, email string) (*do
 
 	return list[0], nil
 }
+
+// GetByIdentity returns user linked to account of identity provider
+func (m *mongoUserRepository) GetByIdentity(ctx context.Context, issuer, subject string) (*domain.User, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository GetByIdentity",
+		trace.WithAttributes(
+			attribute.String("issuer", issuer)),
+	)
+	defer span.End()
+
+	command := bson.D{
+		primitive.E{Key: "find", Value: "user"},
+		primitive.E{Key: "limit", Value: 1},
+		primitive.E{Key: "filter", Value: bson.D{
+			primitive.E{Key: "identities", Value: bson.D{primitive.E{Key: "$elemMatch", Value: bson.D{
+				primitive.E{Key: "issuer", Value: issuer},
+				primitive.E{Key: "subject", Value: subject},
+			}}}},
+			notDeleted,
+		}},
+	}
+
+	list, err := m.fetch(ctx, command)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("user get error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	if len(list) == 0 {
+		span.RecordError(domain.ErrNotFound)
+		return nil, fmt.Errorf("user with identity %s of %s was not found: %w", subject, issuer, domain.ErrNotFound)
+	}
+
+	span.SetAttributes(attribute.String("userid", list[0].ID.Hex()))
+
+	return list[0], nil
+}
+
+// AddIdentity links account of identity provider to user, the account can
+// be linked to one user only
+func (m *mongoUserRepository) AddIdentity(ctx context.Context, id primitive.ObjectID, identity domain.Identity) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository AddIdentity",
+		trace.WithAttributes(
+			attribute.String("userid", id.Hex()),
+			attribute.String("issuer", identity.Issuer)),
+	)
+	defer span.End()
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: id},
+		notDeleted,
+	}
+	update := bson.D{
+		{Key: "$push", Value: bson.D{{Key: "identities", Value: identity}}},
+		{Key: "$set", Value: bson.D{{Key: "updated_at", Value: identity.LinkedAt}}},
+	}
+
+	updRes, err := m.Conn.Collection("user").UpdateOne(ctx, filter, update)
+	if mongo.IsDuplicateKeyError(err) {
+		span.RecordError(err)
+		return fmt.Errorf("identity %s of %s is linked to another user: %w", identity.Subject, identity.Issuer, domain.ErrConflict)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("user update error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	if updRes.ModifiedCount == 0 {
+		err = fmt.Errorf("identity was not linked: %w", domain.ErrNoAffected)
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// List returns page of users sorted by id, so newest users are first, the
+// cursor is id of the last user on the page
+func (m *mongoUserRepository) List(ctx context.Context, query domain.UserListQuery) (*domain.UserList, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository List",
+	)
+	defer span.End()
+
+	filter := bson.D{notDeleted}
+
+	switch query.Status {
+	case domain.UserStatusActive:
+		filter = append(filter, primitive.E{Key: "suspended_at", Value: nil})
+	case domain.UserStatusSuspended:
+		filter = append(filter, primitive.E{Key: "suspended_at", Value: bson.D{primitive.E{Key: "$ne", Value: nil}}})
+	}
+
+	if query.Role != "" {
+		filter = append(filter, primitive.E{Key: "roles", Value: query.Role})
+	}
+
+	if query.Search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(query.Search), Options: "i"}
+		filter = append(filter, primitive.E{Key: "$or", Value: bson.A{
+			bson.D{primitive.E{Key: "email", Value: pattern}},
+			bson.D{primitive.E{Key: "full_name", Value: pattern}},
+		}})
+	}
+
+	if query.Cursor != "" {
+		cursor, err := primitive.ObjectIDFromHex(query.Cursor)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("can't decode cursor: %w: %s", domain.ErrBadParamInput, err.Error())
+		}
+		filter = append(filter, primitive.E{Key: "_id", Value: bson.D{primitive.E{Key: "$lt", Value: cursor}}})
+	}
+
+	// one extra document is fetched to find out whether there is a next page
+	command := bson.D{
+		primitive.E{Key: "find", Value: "user"},
+		primitive.E{Key: "filter", Value: filter},
+		primitive.E{Key: "sort", Value: bson.D{primitive.E{Key: "_id", Value: -1}}},
+		primitive.E{Key: "limit", Value: query.Limit + 1},
+	}
+
+	list, err := m.fetch(ctx, command)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("user list error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	result := &domain.UserList{Items: list}
+	if len(list) > query.Limit {
+		result.Items = list[:query.Limit]
+		result.NextCursor = result.Items[len(result.Items)-1].ID.Hex()
+	}
+
+	return result, nil
+}
+
+// GetDeleted returns user marked as deleted, so it can be restored
+func (m *mongoUserRepository) GetDeleted(ctx context.Context, id primitive.ObjectID) (*domain.User, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository GetDeleted",
+		trace.WithAttributes(
+			attribute.String("userid", id.Hex())),
+	)
+	defer span.End()
+
+	command := bson.D{
+		primitive.E{Key: "find", Value: "user"},
+		primitive.E{Key: "limit", Value: 1},
+		primitive.E{Key: "filter", Value: bson.D{
+			primitive.E{Key: "_id", Value: id},
+			primitive.E{Key: "deleted_at", Value: bson.D{primitive.E{Key: "$ne", Value: nil}}},
+		}},
+	}
+
+	list, err := m.fetch(ctx, command)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("user get error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	if len(list) == 0 {
+		span.RecordError(domain.ErrNotFound)
+		return nil, fmt.Errorf("deleted user was not found: %w", domain.ErrNotFound)
+	}
+
+	return list[0], nil
+}
+
+// Restore removes deletion mark of user
+func (m *mongoUserRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository Restore",
+		trace.WithAttributes(
+			attribute.String("userid", id.Hex())),
+	)
+	defer span.End()
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: id},
+		primitive.E{Key: "deleted_at", Value: bson.D{primitive.E{Key: "$ne", Value: nil}}},
+	}
+	update := bson.D{{Key: "$unset", Value: bson.D{{Key: "deleted_at", Value: ""}}}}
+
+	updRes, err := m.Conn.Collection("user").UpdateOne(ctx, filter, update)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("user restore error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	if updRes.ModifiedCount == 0 {
+		err = fmt.Errorf("user was not restored: %w", domain.ErrNoAffected)
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// ListDeleted returns ids of users deleted before the given time, oldest first
+func (m *mongoUserRepository) ListDeleted(ctx context.Context, before time.Time, limit int) ([]primitive.ObjectID, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository ListDeleted",
+	)
+	defer span.End()
+
+	command := bson.D{
+		primitive.E{Key: "find", Value: "user"},
+		primitive.E{Key: "filter", Value: bson.D{
+			primitive.E{Key: "deleted_at", Value: bson.D{
+				primitive.E{Key: "$ne", Value: nil},
+				primitive.E{Key: "$lte", Value: before.UTC()},
+			}},
+		}},
+		primitive.E{Key: "sort", Value: bson.D{primitive.E{Key: "deleted_at", Value: 1}}},
+		primitive.E{Key: "projection", Value: bson.D{primitive.E{Key: "_id", Value: 1}}},
+		primitive.E{Key: "limit", Value: limit},
+	}
+
+	list, err := m.fetch(ctx, command)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("deleted users list error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	ids := make([]primitive.ObjectID, len(list))
+	for i, u := range list {
+		ids[i] = u.ID
+	}
+
+	return ids, nil
+}
+
+// Purge removes users marked as deleted for good, users which are not
+// marked are kept
+func (m *mongoUserRepository) Purge(ctx context.Context, ids []primitive.ObjectID) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository Purge",
+		trace.WithAttributes(
+			attribute.Int("users", len(ids))),
+	)
+	defer span.End()
+
+	filter := bson.D{
+		primitive.E{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}},
+		primitive.E{Key: "deleted_at", Value: bson.D{primitive.E{Key: "$ne", Value: nil}}},
+	}
+
+	_, err := m.Conn.Collection("user").DeleteMany(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("users purge error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
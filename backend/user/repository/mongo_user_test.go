@@ -3,10 +3,12 @@ package repository_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 
@@ -94,6 +96,19 @@ func TestMongoUserRepository_Create(t *testing.T) {
 
 		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
 	})
+
+	mt.Run("email already exists", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    11000,
+			Message: "duplicate key error",
+		}))
+		r := repository.NewMongoUserRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Create(noopCtx, tUser)
+
+		assert.ErrorIs(mt, err, domain.ErrConflict)
+	})
 }
 
 func TestMongoUserRepository_Delete(t *testing.T) {
@@ -122,6 +137,7 @@ func TestMongoUserRepository_Delete(t *testing.T) {
 				{Key: "ok", Value: 1},
 				{Key: "acknowledged", Value: true},
 				{Key: "n", Value: 1},
+				{Key: "nModified", Value: 1},
 			},
 		)
 		r := repository.NewMongoUserRepository(mt.Client, mt.DB.Name(), nil, tracer)
@@ -188,6 +204,19 @@ func TestMongoUserRepository_Update(t *testing.T) {
 
 		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
 	})
+
+	mt.Run("email already exists", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    11000,
+			Message: "duplicate key error",
+		}))
+		r := repository.NewMongoUserRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Update(noopCtx, tUser)
+
+		assert.ErrorIs(mt, err, domain.ErrConflict)
+	})
 }
 
 //nolint:dupl // test getbyid and getbyemail separately
@@ -237,3 +266,166 @@ func TestMongoUserRepository_GetByEmail(t *testing.T) {
 		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
 	})
 }
+
+func TestMongoUserRepository_GetByIdentity(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tUser := tests.NewUser()
+	tUserBsonD := tests.NewUserBsonD()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, tUserBsonD))
+		r := repository.NewMongoUserRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetByIdentity(noopCtx, "https://idp.example.com", "248289761001")
+
+		require.NoError(mt, err)
+		assert.EqualValues(mt, tUser, result)
+	})
+
+	mt.Run("not linked", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch))
+		r := repository.NewMongoUserRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetByIdentity(noopCtx, "https://idp.example.com", "248289761001")
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrNotFound)
+	})
+}
+
+func TestMongoUserRepository_AddIdentity(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tUser := tests.NewUser()
+	identity := domain.Identity{Issuer: "https://idp.example.com", Subject: "248289761001", LinkedAt: time.Now()}
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+		r := repository.NewMongoUserRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.AddIdentity(noopCtx, tUser.ID, identity)
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("linked to another user", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    11000,
+			Message: "duplicate key error",
+		}))
+		r := repository.NewMongoUserRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.AddIdentity(noopCtx, tUser.ID, identity)
+
+		assert.ErrorIs(mt, err, domain.ErrConflict)
+	})
+
+	mt.Run("user not exists", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+		r := repository.NewMongoUserRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.AddIdentity(noopCtx, tUser.ID, identity)
+
+		assert.ErrorIs(mt, err, domain.ErrNoAffected)
+	})
+}
+
+func TestMongoUserRepository_List(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tUser := tests.NewUser()
+	tUserBsonD := tests.NewUserBsonD()
+	older := tests.NewUserBsonD()
+	older[0].Value, _ = primitive.ObjectIDFromHex("507f191e810c19729de860e9")
+
+	mt.Run("success with next page", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, tUserBsonD, older))
+		r := repository.NewMongoUserRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.List(noopCtx, domain.UserListQuery{Limit: 1, Status: domain.UserStatusActive, Search: "john"})
+
+		require.NoError(mt, err)
+		require.Len(mt, result.Items, 1)
+		assert.EqualValues(mt, tUser, result.Items[0])
+		assert.Equal(mt, tUser.ID.Hex(), result.NextCursor)
+	})
+
+	mt.Run("last page", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, tUserBsonD))
+		r := repository.NewMongoUserRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.List(noopCtx, domain.UserListQuery{Limit: 10, Cursor: "507f191e810c19729de860eb"})
+
+		require.NoError(mt, err)
+		assert.Len(mt, result.Items, 1)
+		assert.Empty(mt, result.NextCursor)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoUserRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.List(noopCtx, domain.UserListQuery{Limit: 10})
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoUserRepository_Restore(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tUser := tests.NewUser()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+		r := repository.NewMongoUserRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Restore(noopCtx, tUser.ID)
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("not deleted", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+		r := repository.NewMongoUserRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Restore(noopCtx, tUser.ID)
+
+		assert.ErrorIs(mt, err, domain.ErrNoAffected)
+	})
+}
+
+func TestMongoUserRepository_ListDeleted(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tUser := tests.NewUser()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, bson.D{{Key: "_id", Value: tUser.ID}}))
+		r := repository.NewMongoUserRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		ids, err := r.ListDeleted(noopCtx, time.Now(), 10)
+
+		require.NoError(mt, err)
+		assert.Equal(mt, []primitive.ObjectID{tUser.ID}, ids)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoUserRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		ids, err := r.ListDeleted(noopCtx, time.Now(), 10)
+
+		assert.Nil(mt, ids)
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
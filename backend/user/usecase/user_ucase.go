@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -11,21 +12,45 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/semka95/shortener/backend/audit"
 	"github.com/semka95/shortener/backend/domain"
 	"github.com/semka95/shortener/backend/web/auth"
 )
 
+// purgeBatchSize is the number of deleted users removed at once by Purge
+const purgeBatchSize = 500
+
 type userUsecase struct {
 	userRepo       domain.UserRepository
+	urlRepo        domain.URLRepository
+	urlCache       domain.URLCache
+	auditRepo      domain.AuditRepository
+	transactor     domain.Transactor
+	tokenUsecase   domain.TokenUsecase
+	linkPolicy     string
+	policy         *auth.Policy
 	contextTimeout time.Duration
+	deletionGrace  time.Duration
 	tracer         trace.Tracer
 }
 
-// NewUserUsecase will create new an userUsecase object representation of user.Usecase interface
-func NewUserUsecase(u domain.UserRepository, timeout time.Duration, tracer trace.Tracer) domain.UserUsecase {
+// NewUserUsecase will create new an userUsecase object representation of user.Usecase interface,
+// a records every change of users, deleted users can be restored during deletionGrace.
+// linkPolicy is applied to URLs of deleted user if request doesn't set one, policy grants permissions of roles.
+// Tokens of deleted users are revoked by t. url is URL repository without cache, as it is used in
+// transactions, changed URLs are invalidated in c.
+func NewUserUsecase(u domain.UserRepository, url domain.URLRepository, c domain.URLCache, a domain.AuditRepository, tx domain.Transactor, t domain.TokenUsecase, linkPolicy string, policy *auth.Policy, timeout, deletionGrace time.Duration, tracer trace.Tracer) domain.UserUsecase {
 	return &userUsecase{
 		userRepo:       u,
+		urlRepo:        url,
+		urlCache:       c,
+		auditRepo:      a,
+		transactor:     tx,
+		tokenUsecase:   t,
+		linkPolicy:     linkPolicy,
+		policy:         policy,
 		contextTimeout: timeout,
+		deletionGrace:  deletionGrace,
 		tracer:         tracer,
 	}
 }
@@ -74,17 +99,20 @@ func (uc *userUsecase) Update(c context.Context, updateUser domain.UpdateUser, c
 		return fmt.Errorf("compare password error: %w: %s", domain.ErrAuthenticationFailure, err.Error())
 	}
 
-	if !claims.HasRole(auth.RoleAdmin) && u.ID.Hex() != claims.Subject {
-		span.RecordError(domain.ErrForbidden)
-		return domain.ErrForbidden
+	if err = uc.policy.Authorize(claims, auth.ActionUserUpdate, &auth.Resource{Owner: u.ID.Hex()}); err != nil {
+		err = fmt.Errorf("%w: %s", domain.ErrForbidden, err.Error())
+		span.RecordError(err)
+		return err
 	}
 
+	before := *u
 	if updateUser.FullName != nil {
 		u.FullName = *updateUser.FullName
 	}
 
-	if updateUser.Email != nil {
+	if updateUser.Email != nil && *updateUser.Email != u.Email {
 		u.Email = *updateUser.Email
+		u.EmailVerifiedAt = nil
 	}
 
 	if updateUser.NewPassword != nil {
@@ -98,6 +126,16 @@ func (uc *userUsecase) Update(c context.Context, updateUser domain.UpdateUser, c
 
 	u.UpdatedAt = time.Now().Truncate(time.Millisecond).UTC()
 
+	// password hash is not shown in diff, so its change is recorded separately
+	var details map[string]string
+	if updateUser.NewPassword != nil {
+		details = map[string]string{"password": "changed"}
+	}
+	if err = uc.record(ctx, claims, domain.AuditUpdateUser, &before, u, details); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
 	return uc.userRepo.Update(ctx, u)
 }
 
@@ -112,17 +150,6 @@ func (uc *userUsecase) Create(c context.Context, m domain.CreateUser) (*domain.U
 	)
 	defer span.End()
 
-	ue, err := uc.userRepo.GetByEmail(ctx, m.Email)
-	if errors.Is(err, domain.ErrInternalServerError) {
-		span.RecordError(err)
-		return nil, err
-	}
-	if ue != nil && err == nil {
-		err = fmt.Errorf("user with %s email already exists, try another one, %w", m.Email, domain.ErrBadParamInput)
-		span.RecordError(err)
-		return nil, err
-	}
-
 	hashedPwd, err := generateHash(m.Password)
 	if err != nil {
 		span.RecordError(err)
@@ -140,22 +167,129 @@ func (uc *userUsecase) Create(c context.Context, m domain.CreateUser) (*domain.U
 	}
 	span.SetAttributes(attribute.String("urlid", u.ID.Hex()))
 
+	// email uniqueness is enforced by the unique index, repository returns ErrConflict
 	err = uc.userRepo.Create(ctx, u)
 	if err != nil {
 		span.RecordError(err)
 		return nil, err
 	}
 
+	if err = uc.record(ctx, nil, domain.AuditCreateUser, nil, u, nil); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
 	return u, nil
 }
 
-func (uc *userUsecase) Delete(c context.Context, id string) error {
+// Delete deletes user and applies link policy to their URLs, all changes are
+// made in one transaction, then all tokens of user are revoked
+func (uc *userUsecase) Delete(c context.Context, req domain.DeleteUser) (*domain.DeleteUserResult, error) {
 	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
 	defer cancel()
 
 	ctx, span := uc.tracer.Start(
 		ctx,
 		"usecase Delete",
+		trace.WithAttributes(
+			attribute.String("userid", req.ID)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	objID, err := primitive.ObjectIDFromHex(req.ID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("user ID is not valid ObjectID: %w: %s", domain.ErrBadParamInput, err.Error())
+	}
+
+	res := &domain.DeleteUserResult{ID: req.ID, Links: req.Links}
+	if res.Links == "" {
+		res.Links = uc.linkPolicy
+	}
+	span.SetAttributes(attribute.String("links", res.Links))
+
+	var targetID primitive.ObjectID
+	if res.Links == domain.LinkPolicyTransfer {
+		if targetID, err = primitive.ObjectIDFromHex(req.TransferTo); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("transfer_to is not valid ObjectID: %w: %s", domain.ErrBadParamInput, err.Error())
+		}
+		if targetID == objID {
+			err = fmt.Errorf("can't transfer links to deleted user: %w", domain.ErrBadParamInput)
+			span.RecordError(err)
+			return nil, err
+		}
+		res.TransferTo = req.TransferTo
+	}
+
+	var ids []string
+	err = uc.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		u, err := uc.userRepo.GetByID(ctx, objID)
+		if err != nil {
+			return err
+		}
+
+		switch res.Links {
+		case domain.LinkPolicyDelete:
+			ids, err = uc.urlRepo.DeleteByUser(ctx, req.ID)
+		case domain.LinkPolicyTransfer:
+			var target *domain.User
+			target, err = uc.userRepo.GetByID(ctx, targetID)
+			if errors.Is(err, domain.ErrNotFound) {
+				return fmt.Errorf("can't transfer links to %s user: %w: %s", req.TransferTo, domain.ErrBadParamInput, err.Error())
+			}
+			if err != nil {
+				return err
+			}
+			if target.IsSuspended() {
+				return fmt.Errorf("can't transfer links to suspended user %s: %w", req.TransferTo, domain.ErrBadParamInput)
+			}
+			ids, err = uc.urlRepo.SetOwner(ctx, req.ID, req.TransferTo)
+		case domain.LinkPolicyOrphan:
+			ids, err = uc.urlRepo.SetOwner(ctx, req.ID, "")
+		default:
+			return fmt.Errorf("unknown link policy %q: %w", res.Links, domain.ErrInternalServerError)
+		}
+		if err != nil {
+			return err
+		}
+		res.URLs = len(ids)
+
+		details := map[string]string{"links": res.Links, "urls": strconv.Itoa(res.URLs)}
+		if res.TransferTo != "" {
+			details["transfer_to"] = res.TransferTo
+		}
+		if err = uc.record(ctx, nil, domain.AuditDeleteUser, u, nil, details); err != nil {
+			return err
+		}
+
+		return uc.userRepo.Delete(ctx, objID)
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	uc.urlCache.Invalidate(ctx, ids...)
+
+	// tokens are revoked outside of transaction, which can be retried
+	if err = uc.tokenUsecase.RevokeAll(ctx, req.ID); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("urls", res.URLs))
+	return res, nil
+}
+
+// Restore restores deleted user until deletion grace period ends
+func (uc *userUsecase) Restore(c context.Context, id string) (*domain.User, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Restore",
 		trace.WithAttributes(
 			attribute.String("userid", id)),
 		trace.WithSpanKind(trace.SpanKindServer),
@@ -165,10 +299,96 @@ func (uc *userUsecase) Delete(c context.Context, id string) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("user ID is not valid ObjectID: %w: %s", domain.ErrBadParamInput, err.Error())
+		return nil, fmt.Errorf("user ID is not valid ObjectID: %w: %s", domain.ErrBadParamInput, err.Error())
+	}
+
+	u, err := uc.userRepo.GetDeleted(ctx, objID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if !u.DeletedAt.Add(uc.deletionGrace).After(time.Now()) {
+		err = fmt.Errorf("deletion grace period of user %s has ended: %w", id, domain.ErrGone)
+		span.RecordError(err)
+		return nil, err
 	}
 
-	return uc.userRepo.Delete(ctx, objID)
+	before := *u
+	u.DeletedAt = nil
+	if err = uc.record(ctx, nil, domain.AuditRestoreUser, &before, u, nil); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err = uc.userRepo.Restore(ctx, objID); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// Purge removes users whose deletion grace period has ended and returns
+// number of removed users
+func (uc *userUsecase) Purge(c context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Purge",
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	before := time.Now().Add(-uc.deletionGrace)
+	purged := 0
+	for {
+		ids, err := uc.userRepo.ListDeleted(ctx, before, purgeBatchSize)
+		if err != nil {
+			span.RecordError(err)
+			return purged, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		if err = uc.userRepo.Purge(ctx, ids); err != nil {
+			span.RecordError(err)
+			return purged, err
+		}
+
+		purged += len(ids)
+		if len(ids) < purgeBatchSize {
+			break
+		}
+	}
+
+	span.SetAttributes(attribute.Int("purged", purged))
+	return purged, nil
+}
+
+// record stores audit entry of user change. Updates and deletes are recorded
+// before the change, creates are recorded after user is stored. If claims
+// are nil, actor is taken from ctx.
+func (uc *userUsecase) record(ctx context.Context, claims *auth.Claims, action string, before, after *domain.User, details map[string]string) error {
+	target := ""
+	if after != nil {
+		target = after.ID.Hex()
+	} else if before != nil {
+		target = before.ID.Hex()
+	}
+
+	e := audit.NewEntry(ctx, claims, action, target)
+	e.Details = details
+
+	var err error
+	if e.Changes, err = audit.Diff(before, after); err != nil {
+		return err
+	}
+
+	return uc.auditRepo.Store(ctx, e)
 }
 
 func (uc *userUsecase) Authenticate(c context.Context, now time.Time, email, password string) (*auth.Claims, error) {
@@ -194,7 +414,14 @@ func (uc *userUsecase) Authenticate(c context.Context, now time.Time, email, pas
 		return nil, fmt.Errorf("compare password error: %w: %s", domain.ErrAuthenticationFailure, err.Error())
 	}
 
-	claims := auth.NewClaims(u.ID.Hex(), u.Roles, now, time.Hour)
+	// password is checked first, so suspension isn't disclosed to anyone who knows the email
+	if u.IsSuspended() {
+		err = fmt.Errorf("user %s is suspended: %w", u.ID.Hex(), domain.ErrForbidden)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	claims := auth.NewClaims(u.ID.Hex(), u.Roles, now, auth.AccessTokenTTL)
 	return claims, nil
 }
 
@@ -8,18 +8,42 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"golang.org/x/crypto/bcrypt"
 
+	auditMock "github.com/semka95/shortener/backend/audit/mock"
 	"github.com/semka95/shortener/backend/domain"
+	storeMock "github.com/semka95/shortener/backend/store/mock"
 	"github.com/semka95/shortener/backend/tests"
+	tokenMock "github.com/semka95/shortener/backend/token/mock"
+	urlMock "github.com/semka95/shortener/backend/url/mock"
 	"github.com/semka95/shortener/backend/user/mock"
 	"github.com/semka95/shortener/backend/user/usecase"
+	"github.com/semka95/shortener/backend/web"
 	"github.com/semka95/shortener/backend/web/auth"
 )
 
 var tracer = sdktrace.NewTracerProvider().Tracer("")
 
+// newAuditRepository returns audit repository which accepts any entries,
+// it is used by tests which don't check audit log
+func newAuditRepository(controller *gomock.Controller) *auditMock.MockAuditRepository {
+	r := auditMock.NewMockAuditRepository(controller)
+	r.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	return r
+}
+
+// newTransactor returns transactor which runs functions without transaction
+func newTransactor(controller *gomock.Controller) *storeMock.MockTransactor {
+	tx := storeMock.NewMockTransactor(controller)
+	tx.EXPECT().WithTransaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+	return tx
+}
+
 func TestUserUsecase_GetByID(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
@@ -27,7 +51,7 @@ func TestUserUsecase_GetByID(t *testing.T) {
 	tUser := tests.NewUser()
 
 	repository := mock.NewMockUserRepository(controller)
-	uc := usecase.NewUserUsecase(repository, 10*time.Second, tracer)
+	uc := usecase.NewUserUsecase(repository, urlMock.NewMockURLRepository(controller), urlMock.NewMockURLCache(controller), newAuditRepository(controller), newTransactor(controller), tokenMock.NewMockTokenUsecase(controller), domain.LinkPolicyOrphan, auth.DefaultPolicy(), 10*time.Second, 24*time.Hour, tracer)
 
 	t.Run("user id is not valid", func(t *testing.T) {
 		result, err := uc.GetByID(context.Background(), "not valid id")
@@ -58,7 +82,7 @@ func TestUserUsecase_Update(t *testing.T) {
 	tUpdateUser := tests.NewUpdateUser()
 
 	repository := mock.NewMockUserRepository(controller)
-	uc := usecase.NewUserUsecase(repository, 10*time.Second, tracer)
+	uc := usecase.NewUserUsecase(repository, urlMock.NewMockURLRepository(controller), urlMock.NewMockURLCache(controller), newAuditRepository(controller), newTransactor(controller), tokenMock.NewMockTokenUsecase(controller), domain.LinkPolicyOrphan, auth.DefaultPolicy(), 10*time.Second, 24*time.Hour, tracer)
 	claims := auth.NewClaims("507f191e810c19729de860ea", []string{auth.RoleUser}, time.Now(), time.Minute)
 
 	t.Run("user not exists", func(t *testing.T) {
@@ -68,6 +92,9 @@ func TestUserUsecase_Update(t *testing.T) {
 	})
 
 	t.Run("success", func(t *testing.T) {
+		verifiedAt := time.Now()
+		tUser.EmailVerifiedAt = &verifiedAt
+		tUpdateUser.Email = tests.StringPointer("new@example.com")
 		repository.EXPECT().GetByID(gomock.Any(), tUpdateUser.ID).Return(tUser, nil)
 		repository.EXPECT().Update(gomock.Any(), tUser).Return(nil)
 
@@ -76,6 +103,8 @@ func TestUserUsecase_Update(t *testing.T) {
 
 		assert.Equal(t, *tUpdateUser.FullName, tUser.FullName)
 		assert.Equal(t, *tUpdateUser.Email, tUser.Email)
+		// changed email isn't verified
+		assert.Nil(t, tUser.EmailVerifiedAt)
 		errP := bcrypt.CompareHashAndPassword([]byte(tUser.HashedPassword), []byte(*tUpdateUser.NewPassword))
 		assert.NoError(t, errP)
 	})
@@ -140,33 +169,23 @@ func TestUserUsecase_Create(t *testing.T) {
 	tCreateUser := tests.NewCreateUser()
 
 	repository := mock.NewMockUserRepository(controller)
-	uc := usecase.NewUserUsecase(repository, 10*time.Second, tracer)
+	uc := usecase.NewUserUsecase(repository, urlMock.NewMockURLRepository(controller), urlMock.NewMockURLCache(controller), newAuditRepository(controller), newTransactor(controller), tokenMock.NewMockTokenUsecase(controller), domain.LinkPolicyOrphan, auth.DefaultPolicy(), 10*time.Second, 24*time.Hour, tracer)
 
 	t.Run("internal server error", func(t *testing.T) {
-		repository.EXPECT().GetByEmail(gomock.Any(), tCreateUser.Email).Return(nil, domain.ErrNotFound)
 		repository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(domain.ErrInternalServerError)
 		result, err := uc.Create(context.Background(), tCreateUser)
-		assert.Error(t, err, domain.ErrInternalServerError)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
 		assert.Empty(t, result)
 	})
 
 	t.Run("email already exists", func(t *testing.T) {
-		tUser := tests.NewUser()
-		repository.EXPECT().GetByEmail(gomock.Any(), tCreateUser.Email).Return(tUser, nil)
+		repository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(domain.ErrConflict)
 		result, err := uc.Create(context.Background(), tCreateUser)
-		assert.Error(t, err, domain.ErrBadParamInput)
-		assert.Empty(t, result)
-	})
-
-	t.Run("email check server error", func(t *testing.T) {
-		repository.EXPECT().GetByEmail(gomock.Any(), tCreateUser.Email).Return(nil, domain.ErrInternalServerError)
-		result, err := uc.Create(context.Background(), tCreateUser)
-		assert.Error(t, err, domain.ErrInternalServerError)
+		assert.ErrorIs(t, err, domain.ErrConflict)
 		assert.Empty(t, result)
 	})
 
 	t.Run("success", func(t *testing.T) {
-		repository.EXPECT().GetByEmail(gomock.Any(), tCreateUser.Email).Return(nil, domain.ErrNotFound)
 		repository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
 		result, err := uc.Create(context.Background(), tCreateUser)
 		assert.NoError(t, err)
@@ -184,25 +203,250 @@ func TestUserUsecase_Delete(t *testing.T) {
 	defer controller.Finish()
 
 	tUser := tests.NewUser()
+	target := tests.NewUser()
+	target.ID = primitive.NewObjectID()
+	ids := []string{"test123", "test456"}
 
 	repository := mock.NewMockUserRepository(controller)
-	uc := usecase.NewUserUsecase(repository, 10*time.Second, tracer)
+	urlRepository := urlMock.NewMockURLRepository(controller)
+	urlCache := urlMock.NewMockURLCache(controller)
+	tokenUsecase := tokenMock.NewMockTokenUsecase(controller)
+	uc := usecase.NewUserUsecase(repository, urlRepository, urlCache, newAuditRepository(controller), newTransactor(controller), tokenUsecase, domain.LinkPolicyOrphan, auth.DefaultPolicy(), 10*time.Second, 24*time.Hour, tracer)
 
 	t.Run("user id is not valid", func(t *testing.T) {
-		err := uc.Delete(context.Background(), "not valid id")
-		assert.Error(t, err, domain.ErrBadParamInput)
+		result, err := uc.Delete(context.Background(), domain.DeleteUser{ID: "not valid id"})
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+		assert.Nil(t, result)
 	})
 
 	t.Run("user not exists", func(t *testing.T) {
-		repository.EXPECT().Delete(gomock.Any(), tUser.ID).Return(domain.ErrNoAffected)
-		err := uc.Delete(context.Background(), tUser.ID.Hex())
-		assert.Error(t, err, domain.ErrNoAffected)
+		repository.EXPECT().GetByID(gomock.Any(), tUser.ID).Return(nil, domain.ErrNotFound)
+		result, err := uc.Delete(context.Background(), domain.DeleteUser{ID: tUser.ID.Hex()})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		assert.Nil(t, result)
 	})
 
-	t.Run("success", func(t *testing.T) {
+	t.Run("default policy", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tUser.ID).Return(tUser, nil)
+		urlRepository.EXPECT().SetOwner(gomock.Any(), tUser.ID.Hex(), "").Return(ids, nil)
 		repository.EXPECT().Delete(gomock.Any(), tUser.ID).Return(nil)
-		err := uc.Delete(context.Background(), tUser.ID.Hex())
-		assert.NoError(t, err)
+		urlCache.EXPECT().Invalidate(gomock.Any(), ids[0], ids[1])
+		tokenUsecase.EXPECT().RevokeAll(gomock.Any(), tUser.ID.Hex()).Return(nil)
+
+		result, err := uc.Delete(context.Background(), domain.DeleteUser{ID: tUser.ID.Hex()})
+		require.NoError(t, err)
+		assert.Equal(t, &domain.DeleteUserResult{ID: tUser.ID.Hex(), Links: domain.LinkPolicyOrphan, URLs: 2}, result)
+	})
+
+	t.Run("delete links", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tUser.ID).Return(tUser, nil)
+		urlRepository.EXPECT().DeleteByUser(gomock.Any(), tUser.ID.Hex()).Return(ids, nil)
+		repository.EXPECT().Delete(gomock.Any(), tUser.ID).Return(nil)
+		urlCache.EXPECT().Invalidate(gomock.Any(), ids[0], ids[1])
+		tokenUsecase.EXPECT().RevokeAll(gomock.Any(), tUser.ID.Hex()).Return(nil)
+
+		result, err := uc.Delete(context.Background(), domain.DeleteUser{ID: tUser.ID.Hex(), Links: domain.LinkPolicyDelete})
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.URLs)
+	})
+
+	t.Run("transfer links", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tUser.ID).Return(tUser, nil)
+		repository.EXPECT().GetByID(gomock.Any(), target.ID).Return(target, nil)
+		urlRepository.EXPECT().SetOwner(gomock.Any(), tUser.ID.Hex(), target.ID.Hex()).Return(ids, nil)
+		repository.EXPECT().Delete(gomock.Any(), tUser.ID).Return(nil)
+		urlCache.EXPECT().Invalidate(gomock.Any(), ids[0], ids[1])
+		tokenUsecase.EXPECT().RevokeAll(gomock.Any(), tUser.ID.Hex()).Return(nil)
+
+		result, err := uc.Delete(context.Background(), domain.DeleteUser{ID: tUser.ID.Hex(), Links: domain.LinkPolicyTransfer, TransferTo: target.ID.Hex()})
+		require.NoError(t, err)
+		assert.Equal(t, &domain.DeleteUserResult{ID: tUser.ID.Hex(), Links: domain.LinkPolicyTransfer, URLs: 2, TransferTo: target.ID.Hex()}, result)
+	})
+
+	t.Run("transfer without target", func(t *testing.T) {
+		result, err := uc.Delete(context.Background(), domain.DeleteUser{ID: tUser.ID.Hex(), Links: domain.LinkPolicyTransfer})
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+		assert.Nil(t, result)
+	})
+
+	t.Run("transfer to same user", func(t *testing.T) {
+		result, err := uc.Delete(context.Background(), domain.DeleteUser{ID: tUser.ID.Hex(), Links: domain.LinkPolicyTransfer, TransferTo: tUser.ID.Hex()})
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+		assert.Nil(t, result)
+	})
+
+	t.Run("transfer target not exists", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tUser.ID).Return(tUser, nil)
+		repository.EXPECT().GetByID(gomock.Any(), target.ID).Return(nil, domain.ErrNotFound)
+
+		result, err := uc.Delete(context.Background(), domain.DeleteUser{ID: tUser.ID.Hex(), Links: domain.LinkPolicyTransfer, TransferTo: target.ID.Hex()})
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+		assert.Nil(t, result)
+	})
+
+	t.Run("failed transaction keeps cache", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tUser.ID).Return(tUser, nil)
+		urlRepository.EXPECT().SetOwner(gomock.Any(), tUser.ID.Hex(), "").Return(ids, nil)
+		repository.EXPECT().Delete(gomock.Any(), tUser.ID).Return(domain.ErrInternalServerError)
+
+		result, err := uc.Delete(context.Background(), domain.DeleteUser{ID: tUser.ID.Hex()})
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+		assert.Nil(t, result)
+	})
+
+	t.Run("links error", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tUser.ID).Return(tUser, nil)
+		urlRepository.EXPECT().DeleteByUser(gomock.Any(), tUser.ID.Hex()).Return(nil, domain.ErrInternalServerError)
+
+		result, err := uc.Delete(context.Background(), domain.DeleteUser{ID: tUser.ID.Hex(), Links: domain.LinkPolicyDelete})
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+		assert.Nil(t, result)
+	})
+
+	t.Run("revoke tokens error", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tUser.ID).Return(tUser, nil)
+		urlRepository.EXPECT().SetOwner(gomock.Any(), tUser.ID.Hex(), "").Return(ids, nil)
+		repository.EXPECT().Delete(gomock.Any(), tUser.ID).Return(nil)
+		urlCache.EXPECT().Invalidate(gomock.Any(), ids[0], ids[1])
+		tokenUsecase.EXPECT().RevokeAll(gomock.Any(), tUser.ID.Hex()).Return(domain.ErrInternalServerError)
+
+		result, err := uc.Delete(context.Background(), domain.DeleteUser{ID: tUser.ID.Hex()})
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+		assert.Nil(t, result)
+	})
+}
+
+func TestUserUsecase_Restore(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repository := mock.NewMockUserRepository(controller)
+	uc := usecase.NewUserUsecase(repository, urlMock.NewMockURLRepository(controller), urlMock.NewMockURLCache(controller), newAuditRepository(controller), newTransactor(controller), tokenMock.NewMockTokenUsecase(controller), domain.LinkPolicyOrphan, auth.DefaultPolicy(), 10*time.Second, 24*time.Hour, tracer)
+
+	deletedUser := func(ago time.Duration) *domain.User {
+		u := tests.NewUser()
+		deletedAt := time.Now().Add(-ago)
+		u.DeletedAt = &deletedAt
+		return u
+	}
+
+	t.Run("success", func(t *testing.T) {
+		tUser := deletedUser(time.Hour)
+		repository.EXPECT().GetDeleted(gomock.Any(), tUser.ID).Return(tUser, nil)
+		repository.EXPECT().Restore(gomock.Any(), tUser.ID).Return(nil)
+
+		result, err := uc.Restore(context.Background(), tUser.ID.Hex())
+		require.NoError(t, err)
+		assert.Nil(t, result.DeletedAt)
+	})
+
+	t.Run("grace period ended", func(t *testing.T) {
+		tUser := deletedUser(25 * time.Hour)
+		repository.EXPECT().GetDeleted(gomock.Any(), tUser.ID).Return(tUser, nil)
+
+		result, err := uc.Restore(context.Background(), tUser.ID.Hex())
+		assert.ErrorIs(t, err, domain.ErrGone)
+		assert.Nil(t, result)
+	})
+
+	t.Run("user id is not valid", func(t *testing.T) {
+		result, err := uc.Restore(context.Background(), "not valid id")
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+		assert.Nil(t, result)
+	})
+}
+
+func TestUserUsecase_Purge(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tUser := tests.NewUser()
+
+	repository := mock.NewMockUserRepository(controller)
+	uc := usecase.NewUserUsecase(repository, urlMock.NewMockURLRepository(controller), urlMock.NewMockURLCache(controller), newAuditRepository(controller), newTransactor(controller), tokenMock.NewMockTokenUsecase(controller), domain.LinkPolicyOrphan, auth.DefaultPolicy(), 10*time.Second, 24*time.Hour, tracer)
+
+	t.Run("success", func(t *testing.T) {
+		repository.EXPECT().ListDeleted(gomock.Any(), gomock.Any(), 500).Return([]primitive.ObjectID{tUser.ID}, nil)
+		repository.EXPECT().Purge(gomock.Any(), []primitive.ObjectID{tUser.ID}).Return(nil)
+
+		n, err := uc.Purge(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repository.EXPECT().ListDeleted(gomock.Any(), gomock.Any(), 500).Return(nil, domain.ErrInternalServerError)
+
+		n, err := uc.Purge(context.Background())
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+		assert.Zero(t, n)
+	})
+}
+
+func TestUserUsecase_Audit(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	admin := auth.NewClaims("507f191e810c19729de860eb", []string{auth.RoleAdmin}, time.Now(), time.Minute)
+	ctx := auth.NewContext(context.Background(), admin)
+	ctx = web.WithRequestInfo(ctx, web.RequestInfo{ID: "request-id", IP: "192.0.2.1"})
+
+	repository := mock.NewMockUserRepository(controller)
+	urlRepository := urlMock.NewMockURLRepository(controller)
+	auditRepository := auditMock.NewMockAuditRepository(controller)
+	uc := usecase.NewUserUsecase(repository, urlRepository, urlMock.NewMockURLCache(controller), auditRepository, newTransactor(controller), tokenMock.NewMockTokenUsecase(controller), domain.LinkPolicyOrphan, auth.DefaultPolicy(), 10*time.Second, 24*time.Hour, tracer)
+
+	t.Run("update records diff", func(t *testing.T) {
+		tUser := tests.NewUser()
+		update := tests.NewUpdateUser()
+		repository.EXPECT().GetByID(gomock.Any(), update.ID).Return(tUser, nil)
+		auditRepository.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.AuditEntry) error {
+			assert.Equal(t, domain.AuditUpdateUser, e.Action)
+			assert.Equal(t, tUser.ID.Hex(), e.Target)
+			assert.Equal(t, admin.Subject, e.Actor)
+			assert.Equal(t, admin.Roles, e.ActorRoles)
+			assert.Equal(t, "request-id", e.RequestID)
+			assert.Equal(t, "192.0.2.1", e.IP)
+			assert.Equal(t, "changed", e.Details["password"])
+			assert.NotContains(t, e.Changes, "email")
+			assert.Contains(t, e.Changes, "updated_at")
+			return nil
+		})
+		repository.EXPECT().Update(gomock.Any(), tUser).Return(nil)
+
+		err := uc.Update(ctx, update, admin)
+		require.NoError(t, err)
+	})
+
+	t.Run("delete is not performed if audit fails", func(t *testing.T) {
+		tUser := tests.NewUser()
+		repository.EXPECT().GetByID(gomock.Any(), tUser.ID).Return(tUser, nil)
+		urlRepository.EXPECT().SetOwner(gomock.Any(), tUser.ID.Hex(), "").Return([]string{"test123"}, nil)
+		auditRepository.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.AuditEntry) error {
+			assert.Equal(t, domain.AuditDeleteUser, e.Action)
+			assert.Equal(t, admin.Subject, e.Actor)
+			assert.Equal(t, tUser.Email, e.Changes["email"].Before)
+			assert.Nil(t, e.Changes["email"].After)
+			assert.Equal(t, map[string]string{"links": domain.LinkPolicyOrphan, "urls": "1"}, e.Details)
+			return domain.ErrInternalServerError
+		})
+
+		result, err := uc.Delete(ctx, domain.DeleteUser{ID: tUser.ID.Hex()})
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+		assert.Nil(t, result)
+	})
+
+	t.Run("create of unauthenticated user", func(t *testing.T) {
+		repository.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		auditRepository.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.AuditEntry) error {
+			assert.Equal(t, domain.AuditCreateUser, e.Action)
+			assert.Empty(t, e.Actor)
+			assert.NotContains(t, e.Changes, "hashed_password")
+			return nil
+		})
+
+		_, err := uc.Create(context.Background(), tests.NewCreateUser())
+		require.NoError(t, err)
 	})
 }
 
@@ -215,7 +459,7 @@ func TestUserUsecase_Authenticate(t *testing.T) {
 	password := "password"
 
 	repository := mock.NewMockUserRepository(controller)
-	uc := usecase.NewUserUsecase(repository, 10*time.Second, tracer)
+	uc := usecase.NewUserUsecase(repository, urlMock.NewMockURLRepository(controller), urlMock.NewMockURLCache(controller), newAuditRepository(controller), newTransactor(controller), tokenMock.NewMockTokenUsecase(controller), domain.LinkPolicyOrphan, auth.DefaultPolicy(), 10*time.Second, 24*time.Hour, tracer)
 
 	t.Run("user not found", func(t *testing.T) {
 		repository.EXPECT().GetByEmail(gomock.Any(), tUser.Email).Return(nil, domain.ErrNotFound)
@@ -231,6 +475,15 @@ func TestUserUsecase_Authenticate(t *testing.T) {
 		assert.Nil(t, result)
 	})
 
+	t.Run("suspended user", func(t *testing.T) {
+		suspended := tests.NewUser()
+		suspended.SuspendedAt = &now
+		repository.EXPECT().GetByEmail(gomock.Any(), tUser.Email).Return(suspended, nil)
+		result, err := uc.Authenticate(context.Background(), now, tUser.Email, password)
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, result)
+	})
+
 	t.Run("success", func(t *testing.T) {
 		repository.EXPECT().GetByEmail(gomock.Any(), tUser.Email).Return(tUser, nil)
 		result, err := uc.Authenticate(context.Background(), now, tUser.Email, password)
@@ -1,9 +1,12 @@
 package auth
 
 import (
+	"context"
 	"crypto/rsa"
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/golang-jwt/jwt/v4"
 	echojwt "github.com/labstack/echo-jwt/v4"
@@ -35,15 +38,32 @@ func NewSimpleKeyLookupFunc(activeKID string, publicKey *rsa.PublicKey) KeyLooku
 	return f
 }
 
+// RevocationCheckFunc is used to reject tokens that were revoked before they
+// expired, e.g. on logout. It returns an error if token must not be accepted.
+type RevocationCheckFunc func(ctx context.Context, claims *Claims) error
+
+// APIKeyScheme is the Authorization header scheme of API keys
+const APIKeyScheme = "ApiKey "
+
+// ErrInsufficientScope is returned if API key doesn't have scope required by route
+var ErrInsufficientScope = errors.New("API key has insufficient scope")
+
+// APIKeyLookupFunc returns claims of user who owns the API key, Scopes of
+// claims are scopes of the key. It returns an error if key is not valid.
+type APIKeyLookupFunc func(ctx context.Context, key string) (*Claims, error)
+
 // Authenticator is used to authenticate clients. It can generate a token for a
 // set of user claims and recreate the claims by parsing the token.
 type Authenticator struct {
-	JWTConfig        echojwt.Config
-	privateKey       *rsa.PrivateKey
-	activeKID        string
-	algorithm        string
-	pubKeyLookupFunc KeyLookupFunc
-	parser           *jwt.Parser
+	JWTConfig         echojwt.Config
+	OptionalJWTConfig echojwt.Config
+	privateKey        *rsa.PrivateKey
+	activeKID         string
+	algorithm         string
+	pubKeyLookupFunc  KeyLookupFunc
+	parser            *jwt.Parser
+	revocationCheck   RevocationCheckFunc
+	apiKeyLookup      APIKeyLookupFunc
 }
 
 // NewAuthenticator creates an *Authenticator for use. It will error if:
@@ -72,16 +92,7 @@ func NewAuthenticator(privateKey *rsa.PrivateKey, activeKID, algorithm string, p
 		ValidMethods: []string{algorithm},
 	}
 
-	jwtConfig := echojwt.Config{
-		SigningMethod: algorithm,
-		SigningKey:    privateKey.Public().(*rsa.PublicKey),
-		NewClaimsFunc: func(c echo.Context) jwt.Claims {
-			return new(Claims)
-		},
-	}
-
 	a := Authenticator{
-		JWTConfig:        jwtConfig,
 		privateKey:       privateKey,
 		activeKID:        activeKID,
 		algorithm:        algorithm,
@@ -89,9 +100,165 @@ func NewAuthenticator(privateKey *rsa.PrivateKey, activeKID, algorithm string, p
 		parser:           &parser,
 	}
 
+	a.JWTConfig = echojwt.Config{
+		ParseTokenFunc: a.parseToken,
+		SuccessHandler: storeClaims,
+	}
+
+	// Optional config lets unauthenticated requests through, but still
+	// validates the token if Authorization header is present.
+	a.OptionalJWTConfig = a.JWTConfig
+	a.OptionalJWTConfig.Skipper = func(c echo.Context) bool {
+		return c.Request().Header.Get(echo.HeaderAuthorization) == ""
+	}
+
 	return &a, nil
 }
 
+// SetRevocationCheck sets function which is called for every valid token
+// accepted by JWTConfig middleware. It must be set before serving requests.
+func (a *Authenticator) SetRevocationCheck(f RevocationCheckFunc) {
+	a.revocationCheck = f
+}
+
+// SetAPIKeyLookup sets function which is used to authenticate API keys
+// accepted by ScopedJWTConfig middleware. It must be set before serving requests.
+func (a *Authenticator) SetAPIKeyLookup(f APIKeyLookupFunc) {
+	a.apiKeyLookup = f
+}
+
+// ScopedJWTConfig returns config which accepts user tokens and API keys
+// having the scope. JWTConfig rejects API keys, so routes are not available
+// to API keys unless they use this config.
+func (a *Authenticator) ScopedJWTConfig(scope string) echojwt.Config {
+	return echojwt.Config{
+		TokenLookup: "header:Authorization:Bearer ,header:Authorization:" + APIKeyScheme,
+		ParseTokenFunc: func(c echo.Context, tokenString string) (interface{}, error) {
+			if !strings.HasPrefix(c.Request().Header.Get(echo.HeaderAuthorization), APIKeyScheme) {
+				return a.parseToken(c, tokenString)
+			}
+			return a.parseAPIKey(c, tokenString, scope)
+		},
+		SuccessHandler: storeClaims,
+		ErrorHandler:   scopeErrorHandler,
+	}
+}
+
+// parseAPIKey authenticates API key and checks it has the scope, claims of
+// key owner are returned as valid token, so handlers get them the same way
+func (a *Authenticator) parseAPIKey(c echo.Context, key, scope string) (interface{}, error) {
+	if a.apiKeyLookup == nil {
+		return nil, errors.New("API keys are not supported")
+	}
+
+	claims, err := a.apiKeyLookup(c.Request().Context(), key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API key: %w", err)
+	}
+
+	// API key without scopes must not get access of user token
+	if len(claims.Scopes) == 0 || !claims.HasScope(scope) {
+		return nil, fmt.Errorf("%w: %s is required", ErrInsufficientScope, scope)
+	}
+
+	return &jwt.Token{Claims: claims, Valid: true}, nil
+}
+
+// scopeErrorHandler responds with the same errors as default handler of
+// echojwt, except requests with API key lacking scope are forbidden
+func scopeErrorHandler(c echo.Context, err error) error {
+	if errors.Is(err, ErrInsufficientScope) {
+		return echo.NewHTTPError(http.StatusForbidden, ErrInsufficientScope.Error()).SetInternal(err)
+	}
+
+	var extractErr *echojwt.TokenExtractionError
+	if errors.As(err, &extractErr) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "missing or malformed jwt").SetInternal(err)
+	}
+
+	return echo.NewHTTPError(http.StatusUnauthorized, "invalid or expired jwt").SetInternal(err)
+}
+
+// parseToken parses and validates token string, it is used as echojwt.Config.ParseTokenFunc
+func (a *Authenticator) parseToken(c echo.Context, tokenString string) (interface{}, error) {
+	token, err := a.verifyToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.revocationCheck != nil {
+		claims, ok := token.Claims.(*Claims)
+		if !ok {
+			return nil, errors.New("can't convert jwt.Claims to auth.Claims")
+		}
+		if err = a.revocationCheck(c.Request().Context(), claims); err != nil {
+			return nil, fmt.Errorf("token was revoked: %w", err)
+		}
+	}
+
+	return token, nil
+}
+
+// storeClaims puts claims of valid token into request context, so they are
+// available to usecases which don't get claims explicitly
+func storeClaims(c echo.Context) {
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok {
+		return
+	}
+	claims, ok := token.Claims.(*Claims)
+	if !ok {
+		return
+	}
+
+	req := c.Request()
+	c.SetRequest(req.WithContext(NewContext(req.Context(), claims)))
+}
+
+// verifyToken parses token string and verifies its signature and expiration
+func (a *Authenticator) verifyToken(tokenString string) (*jwt.Token, error) {
+	keyFunc := func(t *jwt.Token) (interface{}, error) {
+		kid, ok := t.Header["kid"].(string)
+		if !ok || kid == "" {
+			return nil, errors.New("missing key id (kid) in token header")
+		}
+		return a.pubKeyLookupFunc(kid)
+	}
+
+	token, err := a.parser.ParseWithClaims(tokenString, new(Claims), keyFunc)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	return token, nil
+}
+
+// Subject returns subject of request bearer token if token is valid and empty
+// string otherwise. Token revocation is not checked, so it must be used only
+// to identify clients before authentication, e.g. for rate limiting.
+func (a *Authenticator) Subject(c echo.Context) string {
+	header := c.Request().Header.Get(echo.HeaderAuthorization)
+	tokenString := strings.TrimPrefix(header, "Bearer ")
+	if tokenString == header || tokenString == "" {
+		return ""
+	}
+
+	token, err := a.verifyToken(tokenString)
+	if err != nil {
+		return ""
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok {
+		return ""
+	}
+
+	return claims.Subject
+}
+
 // GenerateToken generates a signed JWT token string representing the user Claims.
 func (a *Authenticator) GenerateToken(claims *Claims) (string, error) {
 	method := jwt.GetSigningMethod(a.algorithm)
@@ -0,0 +1,155 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+func TestAuthenticator_Subject(t *testing.T) {
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "1")
+	ring, err := auth.LoadKeyRing(dir, "1")
+	require.NoError(t, err)
+	authenticator, err := auth.NewAuthenticator(ring.ActiveKey(), ring.ActiveKID(), "RS256", ring.PublicKey)
+	require.NoError(t, err)
+
+	token, err := authenticator.GenerateToken(auth.NewClaims("test user", []string{auth.RoleUser}, time.Now(), time.Minute))
+	require.NoError(t, err)
+	expired, err := authenticator.GenerateToken(auth.NewClaims("test user", []string{auth.RoleUser}, time.Now().Add(-time.Hour), time.Minute))
+	require.NoError(t, err)
+
+	cases := []struct {
+		Description string
+		Header      string
+		Subject     string
+	}{
+		{"valid token", "Bearer " + token, "test user"},
+		{"expired token", "Bearer " + expired, ""},
+		{"malformed token", "Bearer abc", ""},
+		{"api key", "ApiKey " + token, ""},
+		{"no header", "", ""},
+	}
+
+	for _, test := range cases {
+		t.Run(test.Description, func(t *testing.T) {
+			req := httptest.NewRequest(echo.GET, "/", nil)
+			if test.Header != "" {
+				req.Header.Set(echo.HeaderAuthorization, test.Header)
+			}
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+
+			assert.Equal(t, test.Subject, authenticator.Subject(c))
+		})
+	}
+}
+
+func TestAuthenticator_ScopedJWTConfig(t *testing.T) {
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "1")
+	ring, err := auth.LoadKeyRing(dir, "1")
+	require.NoError(t, err)
+	authenticator, err := auth.NewAuthenticator(ring.ActiveKey(), ring.ActiveKID(), "RS256", ring.PublicKey)
+	require.NoError(t, err)
+	authenticator.SetAPIKeyLookup(func(_ context.Context, key string) (*auth.Claims, error) {
+		if key != "valid" {
+			return nil, errors.New("unknown key")
+		}
+		claims := auth.NewClaims("key owner", []string{auth.RoleUser}, time.Now(), time.Minute)
+		claims.Scopes = []string{auth.ScopeURLRead}
+		return claims, nil
+	})
+
+	token, err := authenticator.GenerateToken(auth.NewClaims("test user", []string{auth.RoleUser}, time.Now(), time.Minute))
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.GET("/read", subject, echojwt.WithConfig(authenticator.ScopedJWTConfig(auth.ScopeURLRead)))
+	e.GET("/create", subject, echojwt.WithConfig(authenticator.ScopedJWTConfig(auth.ScopeURLCreate)))
+	e.GET("/token", subject, echojwt.WithConfig(authenticator.JWTConfig))
+
+	cases := []struct {
+		Description string
+		Path        string
+		Header      string
+		Code        int
+		Subject     string
+	}{
+		{"user token", "/create", "Bearer " + token, http.StatusOK, "test user"},
+		{"api key with scope", "/read", "ApiKey valid", http.StatusOK, "key owner"},
+		{"api key without scope", "/create", "ApiKey valid", http.StatusForbidden, ""},
+		{"unknown api key", "/read", "ApiKey unknown", http.StatusUnauthorized, ""},
+		{"api key on user token route", "/token", "ApiKey valid", http.StatusUnauthorized, ""},
+		{"no header", "/read", "", http.StatusUnauthorized, ""},
+	}
+
+	for _, test := range cases {
+		t.Run(test.Description, func(t *testing.T) {
+			req := httptest.NewRequest(echo.GET, test.Path, nil)
+			if test.Header != "" {
+				req.Header.Set(echo.HeaderAuthorization, test.Header)
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.Code, rec.Code)
+			if test.Subject != "" {
+				assert.Equal(t, test.Subject, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthenticator_RevocationCheck(t *testing.T) {
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "1")
+	ring, err := auth.LoadKeyRing(dir, "1")
+	require.NoError(t, err)
+	authenticator, err := auth.NewAuthenticator(ring.ActiveKey(), ring.ActiveKID(), "RS256", ring.PublicKey)
+	require.NoError(t, err)
+
+	// revocation is checked with issue time of milliseconds precision
+	now := time.Now()
+	var issuedAt time.Time
+	authenticator.SetRevocationCheck(func(_ context.Context, claims *auth.Claims) error {
+		issuedAt = claims.IssuedTime()
+		return nil
+	})
+
+	token, err := authenticator.GenerateToken(auth.NewClaims("test user", []string{auth.RoleUser}, now, time.Minute))
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.GET("/token", subject, echojwt.WithConfig(authenticator.JWTConfig))
+	req := httptest.NewRequest(echo.GET, "/token", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	rec := httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, now.Truncate(time.Millisecond).Equal(issuedAt))
+}
+
+// subject responds with subject of claims, it checks claims are available
+// to handlers and usecases the same way for tokens and API keys
+func subject(c echo.Context) error {
+	token := c.Get("user").(*jwt.Token)
+	claims := token.Claims.(*auth.Claims)
+	if auth.FromContext(c.Request().Context()) != claims {
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.String(http.StatusOK, claims.Subject)
+}
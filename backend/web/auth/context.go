@@ -0,0 +1,17 @@
+package auth
+
+import "context"
+
+type claimsKey struct{}
+
+// NewContext returns copy of ctx which carries claims of authenticated user
+func NewContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// FromContext returns claims stored in ctx, nil is returned for
+// unauthenticated requests
+func FromContext(ctx context.Context) *Claims {
+	claims, _ := ctx.Value(claimsKey{}).(*Claims)
+	return claims
+}
@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// KeyRing holds RSA keys used for tokens. The active private key signs new
+// tokens, all keys verify them. Keys are rotated by adding a new key to the
+// ring, making it active and removing the old one after its tokens expire.
+type KeyRing struct {
+	activeKID  string
+	activeKey  *rsa.PrivateKey
+	publicKeys map[string]*rsa.PublicKey
+}
+
+// LoadKeyRing reads all *.pem files from dir, file name without extension is
+// used as key id (kid). Files can contain either RSA private key or public key,
+// the latter can only be used for verification. Active key must be a private key.
+func LoadKeyRing(dir, activeKID string) (*KeyRing, error) {
+	if activeKID == "" {
+		return nil, errors.New("active kid can't be blank")
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("can't list key files: %w", err)
+	}
+
+	kr := &KeyRing{
+		activeKID:  activeKID,
+		publicKeys: make(map[string]*rsa.PublicKey, len(files)),
+	}
+
+	for _, file := range files {
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("can't read key %q: %w", kid, err)
+		}
+
+		block, _ := pem.Decode(contents)
+		if block == nil {
+			return nil, fmt.Errorf("key %q is not PEM encoded", kid)
+		}
+
+		if strings.Contains(block.Type, "PRIVATE KEY") {
+			key, err := jwt.ParseRSAPrivateKeyFromPEM(contents)
+			if err != nil {
+				return nil, fmt.Errorf("can't parse private key %q: %w", kid, err)
+			}
+			kr.publicKeys[kid] = &key.PublicKey
+			if kid == activeKID {
+				kr.activeKey = key
+			}
+			continue
+		}
+
+		key, err := jwt.ParseRSAPublicKeyFromPEM(contents)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse public key %q: %w", kid, err)
+		}
+		kr.publicKeys[kid] = key
+	}
+
+	if kr.activeKey == nil {
+		return nil, fmt.Errorf("private key for active kid %q not found in %s", activeKID, dir)
+	}
+
+	return kr, nil
+}
+
+// ActiveKID returns id of the key used for signing
+func (kr *KeyRing) ActiveKID() string {
+	return kr.activeKID
+}
+
+// ActiveKey returns private key used for signing
+func (kr *KeyRing) ActiveKey() *rsa.PrivateKey {
+	return kr.activeKey
+}
+
+// PublicKey returns public key by key id, it satisfies KeyLookupFunc
+func (kr *KeyRing) PublicKey(kid string) (*rsa.PublicKey, error) {
+	key, ok := kr.publicKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unrecognized key id %q", kid)
+	}
+	return key, nil
+}
+
+// JWK represents RSA public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+// PublicKey decodes RSA public key of JWK
+func (k JWK) PublicKey() (*rsa.PublicKey, error) {
+	if k.KeyType != "RSA" {
+		return nil, fmt.Errorf("key %q has unsupported type %q", k.KeyID, k.KeyType)
+	}
+
+	n, err := base64.RawURLEncoding.DecodeString(k.Modulus)
+	if err != nil {
+		return nil, fmt.Errorf("can't decode modulus of key %q: %w", k.KeyID, err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.Exponent)
+	if err != nil {
+		return nil, fmt.Errorf("can't decode exponent of key %q: %w", k.KeyID, err)
+	}
+
+	exp := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("key %q is not valid RSA public key", k.KeyID)
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+}
+
+// JWKS represents JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns all public keys of the ring sorted by key id
+func (kr *KeyRing) JWKS(algorithm string) JWKS {
+	kids := make([]string, 0, len(kr.publicKeys))
+	for kid := range kr.publicKeys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := JWKS{Keys: make([]JWK, 0, len(kids))}
+	for _, kid := range kids {
+		key := kr.publicKeys[kid]
+		set.Keys = append(set.Keys, JWK{
+			KeyType:   "RSA",
+			Use:       "sig",
+			Algorithm: algorithm,
+			KeyID:     kid,
+			Modulus:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+
+	return set
+}
+
+// NewJWKSHandler registers endpoint publishing public keys of the ring, so
+// other services can verify issued tokens
+func NewJWKSHandler(e *echo.Echo, kr *KeyRing, algorithm string) {
+	set := kr.JWKS(algorithm)
+	e.GET("/.well-known/jwks.json", func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=3600")
+		return c.JSON(http.StatusOK, set)
+	})
+}
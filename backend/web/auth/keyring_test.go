@@ -0,0 +1,202 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+func writePrivateKey(t *testing.T, dir, kid string) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	block := pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	err = os.WriteFile(filepath.Join(dir, kid+".pem"), pem.EncodeToMemory(&block), 0o600)
+	require.NoError(t, err)
+
+	return key
+}
+
+func writePublicKey(t *testing.T, dir, kid string) *rsa.PublicKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	b, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	block := pem.Block{Type: "PUBLIC KEY", Bytes: b}
+	err = os.WriteFile(filepath.Join(dir, kid+".pem"), pem.EncodeToMemory(&block), 0o600)
+	require.NoError(t, err)
+
+	return &key.PublicKey
+}
+
+func TestLoadKeyRing(t *testing.T) {
+	dir := t.TempDir()
+	active := writePrivateKey(t, dir, "2")
+	old := writePrivateKey(t, dir, "1")
+	external := writePublicKey(t, dir, "ext")
+
+	t.Run("success", func(t *testing.T) {
+		kr, err := auth.LoadKeyRing(dir, "2")
+		require.NoError(t, err)
+
+		assert.Equal(t, "2", kr.ActiveKID())
+		assert.Equal(t, active, kr.ActiveKey())
+
+		key, err := kr.PublicKey("1")
+		require.NoError(t, err)
+		assert.Equal(t, &old.PublicKey, key)
+
+		key, err = kr.PublicKey("ext")
+		require.NoError(t, err)
+		assert.Equal(t, external, key)
+
+		_, err = kr.PublicKey("unknown")
+		assert.Error(t, err)
+	})
+
+	t.Run("active key not found", func(t *testing.T) {
+		_, err := auth.LoadKeyRing(dir, "3")
+		assert.Error(t, err)
+	})
+
+	t.Run("active key is public", func(t *testing.T) {
+		_, err := auth.LoadKeyRing(dir, "ext")
+		assert.Error(t, err)
+	})
+
+	t.Run("blank active kid", func(t *testing.T) {
+		_, err := auth.LoadKeyRing(dir, "")
+		assert.Error(t, err)
+	})
+
+	t.Run("malformed key", func(t *testing.T) {
+		dir := t.TempDir()
+		writePrivateKey(t, dir, "1")
+		err := os.WriteFile(filepath.Join(dir, "2.pem"), []byte("not a key"), 0o600)
+		require.NoError(t, err)
+
+		_, err = auth.LoadKeyRing(dir, "1")
+		assert.Error(t, err)
+	})
+}
+
+func TestKeyRing_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "1")
+
+	oldRing, err := auth.LoadKeyRing(dir, "1")
+	require.NoError(t, err)
+	oldAuth, err := auth.NewAuthenticator(oldRing.ActiveKey(), oldRing.ActiveKID(), "RS256", oldRing.PublicKey)
+	require.NoError(t, err)
+	claims := auth.NewClaims("test user", []string{auth.RoleUser}, time.Now(), time.Minute)
+	oldToken, err := oldAuth.GenerateToken(claims)
+	require.NoError(t, err)
+
+	writePrivateKey(t, dir, "2")
+	ring, err := auth.LoadKeyRing(dir, "2")
+	require.NoError(t, err)
+	authenticator, err := auth.NewAuthenticator(ring.ActiveKey(), ring.ActiveKID(), "RS256", ring.PublicKey)
+	require.NoError(t, err)
+	newToken, err := authenticator.GenerateToken(claims)
+	require.NoError(t, err)
+
+	strangerDir := t.TempDir()
+	writePrivateKey(t, strangerDir, "3")
+	strangerRing, err := auth.LoadKeyRing(strangerDir, "3")
+	require.NoError(t, err)
+	stranger, err := auth.NewAuthenticator(strangerRing.ActiveKey(), strangerRing.ActiveKID(), "RS256", strangerRing.PublicKey)
+	require.NoError(t, err)
+	unknownToken, err := stranger.GenerateToken(claims)
+	require.NoError(t, err)
+
+	cases := []struct {
+		Description string
+		Token       string
+		Success     bool
+	}{
+		{"token signed with active key", newToken, true},
+		{"token signed with previous key", oldToken, true},
+		{"token signed with unknown key", unknownToken, false},
+	}
+
+	for _, test := range cases {
+		t.Run(test.Description, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(echo.GET, "/", nil)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+test.Token)
+			res := httptest.NewRecorder()
+			c := e.NewContext(req, res)
+
+			h := echojwt.WithConfig(authenticator.JWTConfig)(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			err := h(c)
+			if test.Success {
+				require.NoError(t, err)
+				return
+			}
+
+			var he *echo.HTTPError
+			require.True(t, errors.As(err, &he))
+			assert.Equal(t, http.StatusUnauthorized, he.Code)
+		})
+	}
+}
+
+func TestNewJWKSHandler(t *testing.T) {
+	dir := t.TempDir()
+	active := writePrivateKey(t, dir, "2")
+	writePublicKey(t, dir, "1")
+
+	kr, err := auth.LoadKeyRing(dir, "2")
+	require.NoError(t, err)
+
+	e := echo.New()
+	auth.NewJWKSHandler(e, kr, "RS256")
+
+	req := httptest.NewRequest(echo.GET, "/.well-known/jwks.json", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	set := new(auth.JWKS)
+	err = json.Unmarshal(rec.Body.Bytes(), set)
+	require.NoError(t, err)
+	require.Len(t, set.Keys, 2)
+	assert.Equal(t, "1", set.Keys[0].KeyID)
+
+	jwk := set.Keys[1]
+	assert.Equal(t, "2", jwk.KeyID)
+	assert.Equal(t, "RSA", jwk.KeyType)
+	assert.Equal(t, "sig", jwk.Use)
+	assert.Equal(t, "RS256", jwk.Algorithm)
+	assert.Equal(t, "AQAB", jwk.Exponent)
+	assert.NotContains(t, rec.Body.String(), `"d"`)
+
+	pub, err := kr.PublicKey("2")
+	require.NoError(t, err)
+	assert.Equal(t, &active.PublicKey, pub)
+
+	decoded, err := jwk.PublicKey()
+	require.NoError(t, err)
+	assert.Equal(t, &active.PublicKey, decoded)
+}
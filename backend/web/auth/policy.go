@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Permissions granted to roles. Owners don't need permissions to act on their
+// own items, ".any" permissions allow the same actions on items of others.
+const (
+	PermURLReadAny    = "url.read.any"
+	PermURLUpdateAny  = "url.update.any"
+	PermURLDeleteAny  = "url.delete.any"
+	PermURLModerate   = "url.moderate"
+	PermStatsReadAny  = "stats.read.any"
+	PermUserReadAny   = "user.read.any"
+	PermUserUpdateAny = "user.update.any"
+	PermUserDelete    = "user.delete"
+	PermUserRestore   = "user.restore"
+	PermUserModerate  = "user.moderate"
+	PermReportRead    = "report.read"
+	PermReportResolve = "report.resolve"
+	PermAuditRead     = "audit.read"
+)
+
+// Actions on owned items, they are authorized by owner or by ".any" permission
+const (
+	ActionURLRead    = "url.read"
+	ActionURLUpdate  = "url.update"
+	ActionURLDelete  = "url.delete"
+	ActionStatsRead  = "stats.read"
+	ActionUserRead   = "user.read"
+	ActionUserUpdate = "user.update"
+	ActionURLCreate  = "url.create"
+)
+
+// Roles of workspace members
+const (
+	WorkspaceOwner  = "owner"
+	WorkspaceEditor = "editor"
+	WorkspaceViewer = "viewer"
+)
+
+// Actions on workspaces, they are authorized only by workspace role
+const (
+	ActionWorkspaceRead   = "workspace.read"
+	ActionWorkspaceManage = "workspace.manage"
+)
+
+// workspaceActions maps roles of workspace members to actions they can
+// perform on workspace and its items
+var workspaceActions = map[string]map[string]struct{}{
+	WorkspaceOwner: {
+		ActionWorkspaceRead: {}, ActionWorkspaceManage: {}, ActionURLCreate: {},
+		ActionURLRead: {}, ActionURLUpdate: {}, ActionURLDelete: {}, ActionStatsRead: {},
+	},
+	WorkspaceEditor: {
+		ActionWorkspaceRead: {}, ActionURLCreate: {},
+		ActionURLRead: {}, ActionURLUpdate: {}, ActionURLDelete: {}, ActionStatsRead: {},
+	},
+	WorkspaceViewer: {
+		ActionWorkspaceRead: {}, ActionURLRead: {}, ActionStatsRead: {},
+	},
+}
+
+// ErrNotAuthorized is returned if claims don't allow action
+var ErrNotAuthorized = errors.New("not authorized for this action")
+
+var permissions = []string{
+	PermURLReadAny, PermURLUpdateAny, PermURLDeleteAny, PermURLModerate,
+	PermStatsReadAny, PermUserReadAny, PermUserUpdateAny, PermUserDelete,
+	PermUserRestore, PermUserModerate, PermReportRead, PermReportResolve,
+	PermAuditRead,
+}
+
+// Resource represents item which action is performed on, Owner is subject of
+// user who owns the item and WorkspaceRole is role of the acting user in
+// workspace which the item belongs to. Items without owner and workspace can
+// be accessed only with permissions.
+type Resource struct {
+	Owner         string
+	WorkspaceRole string
+}
+
+// Policy maps roles to permissions
+type Policy struct {
+	roles map[string]map[string]struct{}
+}
+
+// NewPolicy creates policy from permissions of roles, it returns an error if
+// any permission is unknown
+func NewPolicy(roles map[string][]string) (*Policy, error) {
+	known := make(map[string]struct{}, len(permissions))
+	for _, p := range permissions {
+		known[p] = struct{}{}
+	}
+
+	p := &Policy{roles: make(map[string]map[string]struct{}, len(roles))}
+	for role, perms := range roles {
+		p.roles[role] = make(map[string]struct{}, len(perms))
+		for _, perm := range perms {
+			if _, ok := known[perm]; !ok {
+				return nil, fmt.Errorf("unknown permission %q of role %s", perm, role)
+			}
+			p.roles[role][perm] = struct{}{}
+		}
+	}
+
+	return p, nil
+}
+
+// DefaultPolicy returns policy where admins have all permissions, support can
+// read everything but can't change anything and users can act only on
+// their own items
+func DefaultPolicy() *Policy {
+	admin := make([]string, len(permissions))
+	copy(admin, permissions)
+
+	p, _ := NewPolicy(map[string][]string{
+		RoleAdmin: admin,
+		RoleSupport: {
+			PermURLReadAny, PermStatsReadAny, PermUserReadAny, PermReportRead, PermAuditRead,
+		},
+		RoleUser: {},
+	})
+	return p
+}
+
+// Authorize returns an error if claims don't allow action on resource. If
+// resource is nil, action is a permission itself, otherwise owner of
+// resource and workspace members whose role allows action are allowed to
+// perform it and others need action ".any" permission.
+func (p *Policy) Authorize(claims *Claims, action string, resource *Resource) error {
+	if claims == nil {
+		return ErrNotAuthorized
+	}
+
+	perm := action
+	if resource != nil {
+		if resource.Owner != "" && resource.Owner == claims.Subject {
+			return nil
+		}
+		if _, ok := workspaceActions[resource.WorkspaceRole][action]; ok {
+			return nil
+		}
+		perm = action + ".any"
+	}
+
+	for _, role := range claims.Roles {
+		if _, ok := p.roles[role][perm]; ok {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s is required", ErrNotAuthorized, perm)
+}
@@ -0,0 +1,81 @@
+package auth_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+func TestPolicy_Authorize(t *testing.T) {
+	policy := auth.DefaultPolicy()
+	claims := func(subject, role string) *auth.Claims {
+		return &auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{Subject: subject},
+			Roles:            []string{role},
+		}
+	}
+	owned := &auth.Resource{Owner: "owner"}
+
+	cases := []struct {
+		Description string
+		Claims      *auth.Claims
+		Action      string
+		Resource    *auth.Resource
+		Allowed     bool
+	}{
+		{"owner updates own item", claims("owner", auth.RoleUser), auth.ActionURLUpdate, owned, true},
+		{"user reads item of other", claims("other", auth.RoleUser), auth.ActionURLRead, owned, false},
+		{"admin updates item of other", claims("admin", auth.RoleAdmin), auth.ActionURLUpdate, owned, true},
+		{"support reads item of other", claims("support", auth.RoleSupport), auth.ActionURLRead, owned, true},
+		{"support reads stats of other", claims("support", auth.RoleSupport), auth.ActionStatsRead, owned, true},
+		{"support updates item of other", claims("support", auth.RoleSupport), auth.ActionURLUpdate, owned, false},
+		{"support deletes item of other", claims("support", auth.RoleSupport), auth.ActionURLDelete, owned, false},
+		{"user updates ownerless item", claims("", auth.RoleUser), auth.ActionURLUpdate, &auth.Resource{}, false},
+		{"admin has permission", claims("admin", auth.RoleAdmin), auth.PermUserModerate, nil, true},
+		{"support lacks permission", claims("support", auth.RoleSupport), auth.PermUserModerate, nil, false},
+		{"unknown role", claims("owner", "GUEST"), auth.PermURLReadAny, nil, false},
+		{"no claims", nil, auth.ActionURLRead, owned, false},
+		{"workspace editor updates item", claims("editor", auth.RoleUser), auth.ActionURLUpdate, &auth.Resource{Owner: "owner", WorkspaceRole: auth.WorkspaceEditor}, true},
+		{"workspace viewer reads item", claims("viewer", auth.RoleUser), auth.ActionURLRead, &auth.Resource{WorkspaceRole: auth.WorkspaceViewer}, true},
+		{"workspace viewer updates item", claims("viewer", auth.RoleUser), auth.ActionURLUpdate, &auth.Resource{WorkspaceRole: auth.WorkspaceViewer}, false},
+		{"workspace editor manages workspace", claims("editor", auth.RoleUser), auth.ActionWorkspaceManage, &auth.Resource{WorkspaceRole: auth.WorkspaceEditor}, false},
+		{"workspace owner manages workspace", claims("owner", auth.RoleUser), auth.ActionWorkspaceManage, &auth.Resource{WorkspaceRole: auth.WorkspaceOwner}, true},
+	}
+
+	for _, test := range cases {
+		t.Run(test.Description, func(t *testing.T) {
+			err := policy.Authorize(test.Claims, test.Action, test.Resource)
+			if test.Allowed {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, auth.ErrNotAuthorized))
+		})
+	}
+}
+
+func TestNewPolicy(t *testing.T) {
+	t.Run("custom roles", func(t *testing.T) {
+		policy, err := auth.NewPolicy(map[string][]string{
+			"AUDITOR": {auth.PermAuditRead},
+		})
+		require.NoError(t, err)
+
+		auditor := &auth.Claims{Roles: []string{"AUDITOR"}}
+		assert.NoError(t, policy.Authorize(auditor, auth.PermAuditRead, nil))
+		admin := &auth.Claims{Roles: []string{auth.RoleAdmin}}
+		assert.Error(t, policy.Authorize(admin, auth.PermAuditRead, nil))
+	})
+
+	t.Run("unknown permission", func(t *testing.T) {
+		_, err := auth.NewPolicy(map[string][]string{
+			auth.RoleSupport: {"url.read.all"},
+		})
+		require.Error(t, err)
+	})
+}
@@ -1,29 +1,52 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
 // RoleAdmin represents admin role
+// RoleSupport represents support role, it can read but not change items of others
 // RoleUser represents user role
 const (
-	RoleAdmin = "ADMIN"
-	RoleUser  = "USER"
+	RoleAdmin   = "ADMIN"
+	RoleSupport = "SUPPORT"
+	RoleUser    = "USER"
 )
 
-// Claims represents the authorization claims transmitted via a JWT
+// Scopes of API keys, API key can be used only on routes which accept its scope
+const (
+	ScopeURLCreate = "url:create"
+	ScopeURLRead   = "url:read"
+	ScopeStatsRead = "stats:read"
+)
+
+// AccessTokenTTL is the lifetime of access tokens issued to users
+const AccessTokenTTL = time.Hour
+
+// Claims represents the authorization claims transmitted via a JWT or
+// produced from API key. Scopes are set only for API keys, user tokens are
+// not limited by scopes.
 type Claims struct {
-	Roles []string `json:"roles"`
+	Roles  []string `json:"roles"`
+	Scopes []string `json:"scopes,omitempty"`
+	// IssuedAtMilli is the issue time in unix milliseconds, iat has seconds
+	// precision and can't tell tokens issued before and after revocation
+	// in the same second
+	IssuedAtMilli int64 `json:"iat_ms,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // NewClaims constructs a Claims value for the identified user
 func NewClaims(subject string, roles []string, now time.Time, expires time.Duration) *Claims {
 	c := &Claims{
-		Roles: roles,
+		Roles:         roles,
+		IssuedAtMilli: now.UnixMilli(),
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        newTokenID(),
 			Subject:   subject,
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(now.Add(expires)),
@@ -33,6 +56,18 @@ func NewClaims(subject string, roles []string, now time.Time, expires time.Durat
 	return c
 }
 
+// IssuedTime returns issue time of the token with milliseconds precision,
+// tokens issued without iat_ms have seconds precision
+func (c *Claims) IssuedTime() time.Time {
+	if c.IssuedAtMilli != 0 {
+		return time.UnixMilli(c.IssuedAtMilli)
+	}
+	if c.IssuedAt != nil {
+		return c.IssuedAt.Time
+	}
+	return time.Time{}
+}
+
 // HasRole returns true if the claims has at least one of the provided roles.
 func (c *Claims) HasRole(roles ...string) bool {
 	for _, has := range c.Roles {
@@ -44,3 +79,24 @@ func (c *Claims) HasRole(roles ...string) bool {
 	}
 	return false
 }
+
+// HasScope returns true if the claims are not limited by scopes or have the
+// provided scope.
+func (c *Claims) HasScope(scope string) bool {
+	if len(c.Scopes) == 0 {
+		return true
+	}
+	for _, has := range c.Scopes {
+		if has == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// newTokenID generates random token id (jti), it is used to revoke single token
+func newTokenID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,24 @@
+package web
+
+import "context"
+
+type requestInfoKey struct{}
+
+// RequestInfo represents metadata of http request which is needed below
+// delivery layer, e.g. for audit log
+type RequestInfo struct {
+	ID string
+	IP string
+}
+
+// WithRequestInfo returns copy of ctx which carries request info
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFromContext returns request info stored in ctx, zero value is
+// returned if ctx doesn't carry it
+func RequestInfoFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info
+}
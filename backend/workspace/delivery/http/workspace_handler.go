@@ -0,0 +1,277 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v4"
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/web"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+// WorkspaceHandler represent the http handler for workspaces
+type WorkspaceHandler struct {
+	workspaceUsecase domain.WorkspaceUsecase
+	authenticator    *auth.Authenticator
+	validator        *web.AppValidator
+	logger           *zap.Logger
+	tracer           trace.Tracer
+}
+
+// NewWorkspaceHandler will initialize the workspace/ resources endpoint
+func NewWorkspaceHandler(ws domain.WorkspaceUsecase, authenticator *auth.Authenticator, v *web.AppValidator, logger *zap.Logger, tracer trace.Tracer) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceUsecase: ws,
+		authenticator:    authenticator,
+		validator:        v,
+		logger:           logger,
+		tracer:           tracer,
+	}
+}
+
+// RegisterRoutes registers routes for a path with matching handler
+func (wh *WorkspaceHandler) RegisterRoutes(e *echo.Echo) {
+	g := e.Group("/v1/workspace", echojwt.WithConfig(wh.authenticator.JWTConfig))
+	g.GET("", wh.List)
+	g.POST("", wh.Create)
+	g.GET("/:id", wh.GetByID)
+	g.POST("/:id/members", wh.Invite)
+	g.DELETE("/:id/members/:user_id", wh.RemoveMember)
+	g.POST("/:id/urls/transfer", wh.TransferURLs)
+}
+
+// Create will create workspace by given request body
+func (wh *WorkspaceHandler) Create(c echo.Context) error {
+	ctx, span := wh.start(c, "http Create")
+	defer span.End()
+
+	r := new(domain.CreateWorkspace)
+	if err := c.Bind(r); err != nil {
+		span.RecordError(err)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
+	}
+
+	if err := c.Validate(r); err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(wh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	user, err := wh.claims(c)
+	if user == nil {
+		span.RecordError(domain.ErrForbidden)
+		return err
+	}
+
+	w, err := wh.workspaceUsecase.Create(ctx, *r, user)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, wh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("workspaceid", w.ID.Hex()),
+	)
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusCreated, w)
+}
+
+// List will list workspaces of user
+func (wh *WorkspaceHandler) List(c echo.Context) error {
+	ctx, span := wh.start(c, "http List")
+	defer span.End()
+
+	user, err := wh.claims(c)
+	if user == nil {
+		span.RecordError(domain.ErrForbidden)
+		return err
+	}
+
+	list, err := wh.workspaceUsecase.List(ctx, user)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, wh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusOK, list)
+}
+
+// GetByID will get workspace by given id
+func (wh *WorkspaceHandler) GetByID(c echo.Context) error {
+	id := c.Param("id")
+
+	ctx, span := wh.start(c, "http GetByID")
+	defer span.End()
+
+	if err := wh.validator.V.Var(id, "required,len=24,hexadecimal"); err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(wh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	user, err := wh.claims(c)
+	if user == nil {
+		span.RecordError(domain.ErrForbidden)
+		return err
+	}
+
+	w, err := wh.workspaceUsecase.GetByID(ctx, id, user)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, wh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("workspaceid", id),
+	)
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusOK, w)
+}
+
+// Invite will add user to workspace or change role of member
+func (wh *WorkspaceHandler) Invite(c echo.Context) error {
+	ctx, span := wh.start(c, "http Invite")
+	defer span.End()
+
+	r := new(domain.InviteMember)
+	if err := c.Bind(r); err != nil {
+		span.RecordError(err)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
+	}
+
+	if err := c.Validate(r); err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(wh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	user, err := wh.claims(c)
+	if user == nil {
+		span.RecordError(domain.ErrForbidden)
+		return err
+	}
+
+	w, err := wh.workspaceUsecase.Invite(ctx, *r, user)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, wh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("workspaceid", r.WorkspaceID),
+	)
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusOK, w)
+}
+
+// RemoveMember will remove user from workspace
+func (wh *WorkspaceHandler) RemoveMember(c echo.Context) error {
+	ctx, span := wh.start(c, "http RemoveMember")
+	defer span.End()
+
+	r := new(domain.RemoveMember)
+	if err := c.Bind(r); err != nil {
+		span.RecordError(err)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
+	}
+
+	if err := c.Validate(r); err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(wh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	user, err := wh.claims(c)
+	if user == nil {
+		span.RecordError(domain.ErrForbidden)
+		return err
+	}
+
+	w, err := wh.workspaceUsecase.RemoveMember(ctx, *r, user)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, wh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("workspaceid", r.WorkspaceID),
+		attribute.String("userid", r.UserID),
+	)
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusOK, w)
+}
+
+// TransferURLs will move URLs to another workspace
+func (wh *WorkspaceHandler) TransferURLs(c echo.Context) error {
+	ctx, span := wh.start(c, "http TransferURLs")
+	defer span.End()
+
+	r := new(domain.TransferURLs)
+	if err := c.Bind(r); err != nil {
+		span.RecordError(err)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: err.Error()})
+	}
+
+	if err := c.Validate(r); err != nil {
+		span.RecordError(err)
+		fields := err.(validator.ValidationErrors).Translate(wh.validator.Translator)
+		return c.JSON(http.StatusBadRequest, domain.ResponseError{Error: "validation error", Fields: fields})
+	}
+
+	user, err := wh.claims(c)
+	if user == nil {
+		span.RecordError(domain.ErrForbidden)
+		return err
+	}
+
+	result, err := wh.workspaceUsecase.TransferURLs(ctx, *r, user)
+	if err != nil {
+		span.RecordError(err)
+		return c.JSON(domain.GetStatusCode(err, wh.logger), domain.ResponseError{Error: err.Error()})
+	}
+
+	span.SetAttributes(
+		attribute.String("workspaceid", r.WorkspaceID),
+		attribute.String("to", r.To),
+	)
+	span.SetStatus(codes.Ok, "success")
+	return c.JSON(http.StatusOK, result)
+}
+
+func (wh *WorkspaceHandler) start(c echo.Context, name string) (context.Context, trace.Span) {
+	ctx := c.Request().Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return wh.tracer.Start(
+		ctx,
+		name,
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+}
+
+// claims returns claims of authenticated user, if claims are nil the response
+// is already written and returned error must be returned by handler
+func (wh *WorkspaceHandler) claims(c echo.Context) (*auth.Claims, error) {
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok || token == nil {
+		return nil, c.JSON(http.StatusForbidden, domain.ResponseError{Error: domain.ErrForbidden.Error()})
+	}
+	user, ok := token.Claims.(*auth.Claims)
+	if !ok {
+		return nil, fmt.Errorf("%w can't convert jwt.Claims to auth.Claims", domain.ErrInternalServerError)
+	}
+
+	return user, nil
+}
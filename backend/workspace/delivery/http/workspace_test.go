@@ -0,0 +1,242 @@
+package http_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/tests"
+	"github.com/semka95/shortener/backend/web"
+	"github.com/semka95/shortener/backend/web/auth"
+	workspaceHttp "github.com/semka95/shortener/backend/workspace/delivery/http"
+	"github.com/semka95/shortener/backend/workspace/mock"
+)
+
+func TestWorkspaceHTTP(t *testing.T) {
+	tUser := tests.NewUser()
+	tWorkspace := tests.NewWorkspace()
+	claims := auth.NewClaims(tUser.ID.Hex(), tUser.Roles, time.Now(), time.Hour)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	kid := "4754d86b-7a6d-4df5-9c65-224741361492"
+	kf := auth.NewSimpleKeyLookupFunc(kid, key.Public().(*rsa.PublicKey))
+	authenticator, err := auth.NewAuthenticator(key, kid, "RS256", kf)
+	require.NoError(t, err)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	uc := mock.NewMockWorkspaceUsecase(controller)
+
+	tracer := sdktrace.NewTracerProvider().Tracer("")
+	v, err := web.NewAppValidator()
+	require.NoError(t, err)
+	// linkid is registered by url handler which shares validator in main
+	err = v.V.RegisterValidation("linkid", func(fl validator.FieldLevel) bool { return fl.Field().String() != "" })
+	require.NoError(t, err)
+	err = v.V.RegisterValidation("urlid", func(fl validator.FieldLevel) bool { return fl.Field().String() != "" })
+	require.NoError(t, err)
+
+	handler := workspaceHttp.NewWorkspaceHandler(uc, authenticator, v, zap.NewNop(), tracer)
+
+	e := echo.New()
+	e.Validator = v
+	req := new(http.Request)
+	c := e.NewContext(req, nil)
+
+	// Test WorkspaceHandler.Create
+	casesCreate := []struct {
+		description string
+		mockCalls   func()
+		reqBody     string
+		code        int
+	}{
+		{
+			description: "Create success",
+			mockCalls: func() {
+				uc.EXPECT().Create(gomock.Any(), domain.CreateWorkspace{Name: tWorkspace.Name}, claims).Return(tWorkspace, nil)
+			},
+			reqBody: `{"name":"marketing"}`,
+			code:    http.StatusCreated,
+		},
+		{
+			description: "Create without name",
+			mockCalls:   func() {},
+			reqBody:     `{"name":""}`,
+			code:        http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range casesCreate {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls()
+			req = httptest.NewRequest(echo.POST, "/v1/workspace", strings.NewReader(tc.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+			c.SetPath("/v1/workspace")
+			c.Set("user", token)
+
+			err = handler.Create(c)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.code, rec.Code)
+		})
+	}
+
+	// Test WorkspaceHandler.GetByID
+	casesGet := []struct {
+		description string
+		mockCalls   func()
+		param       string
+		code        int
+	}{
+		{
+			description: "GetByID success",
+			mockCalls: func() {
+				uc.EXPECT().GetByID(gomock.Any(), tWorkspace.ID.Hex(), claims).Return(tWorkspace, nil)
+			},
+			param: tWorkspace.ID.Hex(),
+			code:  http.StatusOK,
+		},
+		{
+			description: "GetByID not a member",
+			mockCalls: func() {
+				uc.EXPECT().GetByID(gomock.Any(), tWorkspace.ID.Hex(), claims).Return(nil, domain.ErrForbidden)
+			},
+			param: tWorkspace.ID.Hex(),
+			code:  http.StatusForbidden,
+		},
+		{
+			description: "GetByID wrong id",
+			mockCalls:   func() {},
+			param:       "wrong",
+			code:        http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range casesGet {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls()
+			req = httptest.NewRequest(echo.GET, "/v1/workspace/"+tc.param, nil)
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+			c.SetPath("/v1/workspace/:id")
+			c.SetParamNames("id")
+			c.SetParamValues(tc.param)
+			c.Set("user", token)
+
+			err = handler.GetByID(c)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.code, rec.Code)
+		})
+	}
+
+	// Test WorkspaceHandler.Invite
+	casesInvite := []struct {
+		description string
+		mockCalls   func()
+		reqBody     string
+		code        int
+	}{
+		{
+			description: "Invite success",
+			mockCalls: func() {
+				invite := domain.InviteMember{WorkspaceID: tWorkspace.ID.Hex(), Email: "new@example.com", Role: auth.WorkspaceEditor}
+				uc.EXPECT().Invite(gomock.Any(), invite, claims).Return(tWorkspace, nil)
+			},
+			reqBody: `{"email":"new@example.com","role":"editor"}`,
+			code:    http.StatusOK,
+		},
+		{
+			description: "Invite unknown role",
+			mockCalls:   func() {},
+			reqBody:     `{"email":"new@example.com","role":"admin"}`,
+			code:        http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range casesInvite {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.mockCalls()
+			req = httptest.NewRequest(echo.POST, "/v1/workspace/"+tWorkspace.ID.Hex()+"/members", strings.NewReader(tc.reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+			rec := httptest.NewRecorder()
+			c.Reset(req, rec)
+			c.SetPath("/v1/workspace/:id/members")
+			c.SetParamNames("id")
+			c.SetParamValues(tWorkspace.ID.Hex())
+			c.Set("user", token)
+
+			err = handler.Invite(c)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.code, rec.Code)
+		})
+	}
+
+	// Test WorkspaceHandler.RemoveMember
+	t.Run("RemoveMember last owner", func(t *testing.T) {
+		remove := domain.RemoveMember{WorkspaceID: tWorkspace.ID.Hex(), UserID: tUser.ID.Hex()}
+		uc.EXPECT().RemoveMember(gomock.Any(), remove, claims).Return(nil, domain.ErrBadParamInput)
+		req = httptest.NewRequest(echo.DELETE, "/v1/workspace/"+remove.WorkspaceID+"/members/"+remove.UserID, nil)
+
+		rec := httptest.NewRecorder()
+		c.Reset(req, rec)
+		c.SetPath("/v1/workspace/:id/members/:user_id")
+		c.SetParamNames("id", "user_id")
+		c.SetParamValues(remove.WorkspaceID, remove.UserID)
+		c.Set("user", token)
+
+		err = handler.RemoveMember(c)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	// Test WorkspaceHandler.TransferURLs
+	t.Run("TransferURLs success", func(t *testing.T) {
+		to := "507f191e810c19729de860ff"
+		transfer := domain.TransferURLs{WorkspaceID: tWorkspace.ID.Hex(), To: to, IDs: []string{"test123"}}
+		uc.EXPECT().TransferURLs(gomock.Any(), transfer, claims).Return(&domain.TransferURLsResult{To: to, URLs: []string{"test123"}}, nil)
+		req = httptest.NewRequest(echo.POST, "/v1/workspace/"+transfer.WorkspaceID+"/urls/transfer", strings.NewReader(`{"to":"`+to+`","ids":["test123"]}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		rec := httptest.NewRecorder()
+		c.Reset(req, rec)
+		c.SetPath("/v1/workspace/:id/urls/transfer")
+		c.SetParamNames("id")
+		c.SetParamValues(transfer.WorkspaceID)
+		c.Set("user", token)
+
+		err = handler.TransferURLs(c)
+		require.NoError(t, err)
+
+		body := new(domain.TransferURLsResult)
+		err = json.NewDecoder(rec.Body).Decode(body)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"test123"}, body.URLs)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
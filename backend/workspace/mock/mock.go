@@ -0,0 +1,209 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./domain/workspace.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/semka95/shortener/backend/domain"
+	auth "github.com/semka95/shortener/backend/web/auth"
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockWorkspaceUsecase is a mock of WorkspaceUsecase interface.
+type MockWorkspaceUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceUsecaseMockRecorder
+}
+
+// MockWorkspaceUsecaseMockRecorder is the mock recorder for MockWorkspaceUsecase.
+type MockWorkspaceUsecaseMockRecorder struct {
+	mock *MockWorkspaceUsecase
+}
+
+// NewMockWorkspaceUsecase creates a new mock instance.
+func NewMockWorkspaceUsecase(ctrl *gomock.Controller) *MockWorkspaceUsecase {
+	mock := &MockWorkspaceUsecase{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWorkspaceUsecase) EXPECT() *MockWorkspaceUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockWorkspaceUsecase) Create(ctx context.Context, create domain.CreateWorkspace, claims *auth.Claims) (*domain.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, create, claims)
+	ret0, _ := ret[0].(*domain.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWorkspaceUsecaseMockRecorder) Create(ctx, create, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWorkspaceUsecase)(nil).Create), ctx, create, claims)
+}
+
+// GetByID mocks base method.
+func (m *MockWorkspaceUsecase) GetByID(ctx context.Context, id string, claims *auth.Claims) (*domain.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id, claims)
+	ret0, _ := ret[0].(*domain.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockWorkspaceUsecaseMockRecorder) GetByID(ctx, id, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockWorkspaceUsecase)(nil).GetByID), ctx, id, claims)
+}
+
+// Invite mocks base method.
+func (m *MockWorkspaceUsecase) Invite(ctx context.Context, invite domain.InviteMember, claims *auth.Claims) (*domain.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Invite", ctx, invite, claims)
+	ret0, _ := ret[0].(*domain.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Invite indicates an expected call of Invite.
+func (mr *MockWorkspaceUsecaseMockRecorder) Invite(ctx, invite, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Invite", reflect.TypeOf((*MockWorkspaceUsecase)(nil).Invite), ctx, invite, claims)
+}
+
+// List mocks base method.
+func (m *MockWorkspaceUsecase) List(ctx context.Context, claims *auth.Claims) ([]*domain.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, claims)
+	ret0, _ := ret[0].([]*domain.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockWorkspaceUsecaseMockRecorder) List(ctx, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockWorkspaceUsecase)(nil).List), ctx, claims)
+}
+
+// RemoveMember mocks base method.
+func (m *MockWorkspaceUsecase) RemoveMember(ctx context.Context, remove domain.RemoveMember, claims *auth.Claims) (*domain.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, remove, claims)
+	ret0, _ := ret[0].(*domain.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockWorkspaceUsecaseMockRecorder) RemoveMember(ctx, remove, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockWorkspaceUsecase)(nil).RemoveMember), ctx, remove, claims)
+}
+
+// TransferURLs mocks base method.
+func (m *MockWorkspaceUsecase) TransferURLs(ctx context.Context, transfer domain.TransferURLs, claims *auth.Claims) (*domain.TransferURLsResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferURLs", ctx, transfer, claims)
+	ret0, _ := ret[0].(*domain.TransferURLsResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferURLs indicates an expected call of TransferURLs.
+func (mr *MockWorkspaceUsecaseMockRecorder) TransferURLs(ctx, transfer, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferURLs", reflect.TypeOf((*MockWorkspaceUsecase)(nil).TransferURLs), ctx, transfer, claims)
+}
+
+// MockWorkspaceRepository is a mock of WorkspaceRepository interface.
+type MockWorkspaceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceRepositoryMockRecorder
+}
+
+// MockWorkspaceRepositoryMockRecorder is the mock recorder for MockWorkspaceRepository.
+type MockWorkspaceRepositoryMockRecorder struct {
+	mock *MockWorkspaceRepository
+}
+
+// NewMockWorkspaceRepository creates a new mock instance.
+func NewMockWorkspaceRepository(ctrl *gomock.Controller) *MockWorkspaceRepository {
+	mock := &MockWorkspaceRepository{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWorkspaceRepository) EXPECT() *MockWorkspaceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetByID mocks base method.
+func (m *MockWorkspaceRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockWorkspaceRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockWorkspaceRepository)(nil).GetByID), ctx, id)
+}
+
+// ListByMember mocks base method.
+func (m *MockWorkspaceRepository) ListByMember(ctx context.Context, userID string) ([]*domain.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByMember", ctx, userID)
+	ret0, _ := ret[0].([]*domain.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByMember indicates an expected call of ListByMember.
+func (mr *MockWorkspaceRepositoryMockRecorder) ListByMember(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByMember", reflect.TypeOf((*MockWorkspaceRepository)(nil).ListByMember), ctx, userID)
+}
+
+// Store mocks base method.
+func (m *MockWorkspaceRepository) Store(ctx context.Context, w *domain.Workspace) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store.
+func (mr *MockWorkspaceRepositoryMockRecorder) Store(ctx, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockWorkspaceRepository)(nil).Store), ctx, w)
+}
+
+// Update mocks base method.
+func (m *MockWorkspaceRepository) Update(ctx context.Context, w *domain.Workspace) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockWorkspaceRepositoryMockRecorder) Update(ctx, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockWorkspaceRepository)(nil).Update), ctx, w)
+}
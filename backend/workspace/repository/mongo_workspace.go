@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/semka95/shortener/backend/domain"
+)
+
+const workspaceCollection = "workspace"
+
+type mongoWorkspaceRepository struct {
+	Conn   *mongo.Database
+	logger *zap.Logger
+	tracer trace.Tracer
+}
+
+// NewMongoWorkspaceRepository will create an object that represent the workspace.Repository interface
+func NewMongoWorkspaceRepository(c *mongo.Client, db string, logger *zap.Logger, tracer trace.Tracer) domain.WorkspaceRepository {
+	return &mongoWorkspaceRepository{
+		Conn:   c.Database(db),
+		logger: logger,
+		tracer: tracer,
+	}
+}
+
+func (m *mongoWorkspaceRepository) Store(ctx context.Context, w *domain.Workspace) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository Store",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("workspaceid", w.ID.Hex())),
+	)
+	defer span.End()
+
+	_, err := m.Conn.Collection(workspaceCollection).InsertOne(ctx, w)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("workspace store error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (m *mongoWorkspaceRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Workspace, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository GetByID",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("workspaceid", id.Hex())),
+	)
+	defer span.End()
+
+	w := new(domain.Workspace)
+	err := m.Conn.Collection(workspaceCollection).FindOne(ctx, bson.D{primitive.E{Key: "_id", Value: id}}).Decode(w)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		err = fmt.Errorf("workspace %s was not found: %w", id.Hex(), domain.ErrNotFound)
+		span.RecordError(err)
+		return nil, err
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("workspace get error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return w, nil
+}
+
+// ListByMember returns workspaces user is a member of, newest first
+func (m *mongoWorkspaceRepository) ListByMember(ctx context.Context, userID string) ([]*domain.Workspace, error) {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository ListByMember",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("userid", userID)),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "members.user_id", Value: userID}}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+
+	cur, err := m.Conn.Collection(workspaceCollection).Find(ctx, filter, opts)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("workspace list error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	list := make([]*domain.Workspace, 0)
+	if err = cur.All(ctx, &list); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("can't decode workspaces: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	return list, nil
+}
+
+// Update replaces name and members of workspace
+func (m *mongoWorkspaceRepository) Update(ctx context.Context, w *domain.Workspace) error {
+	ctx, span := m.tracer.Start(
+		ctx,
+		"repository Update",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("workspaceid", w.ID.Hex())),
+	)
+	defer span.End()
+
+	filter := bson.D{primitive.E{Key: "_id", Value: w.ID}}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "name", Value: w.Name},
+		{Key: "members", Value: w.Members},
+		{Key: "updated_at", Value: w.UpdatedAt},
+	}}}
+
+	res, err := m.Conn.Collection(workspaceCollection).UpdateOne(ctx, filter, update)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("workspace update error: %w: %s", domain.ErrInternalServerError, err.Error())
+	}
+
+	if res.MatchedCount == 0 {
+		err = fmt.Errorf("workspace was not updated: %w", domain.ErrNoAffected)
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
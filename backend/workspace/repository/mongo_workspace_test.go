@@ -0,0 +1,145 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/tests"
+	"github.com/semka95/shortener/backend/workspace/repository"
+)
+
+var tracer = sdktrace.NewTracerProvider().Tracer("")
+var noopCtx = context.Background()
+
+const tableName = "shortener.workspace"
+
+func workspaceBsonD(w *domain.Workspace) bson.D {
+	m := w.Members[0]
+	return bson.D{
+		{Key: "_id", Value: w.ID},
+		{Key: "name", Value: w.Name},
+		{Key: "members", Value: bson.A{bson.D{
+			{Key: "user_id", Value: m.UserID},
+			{Key: "role", Value: m.Role},
+			{Key: "added_at", Value: m.AddedAt},
+		}}},
+		{Key: "created_at", Value: w.CreatedAt},
+		{Key: "updated_at", Value: w.UpdatedAt},
+	}
+}
+
+func TestMongoWorkspaceRepository_Store(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tWorkspace := tests.NewWorkspace()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		r := repository.NewMongoWorkspaceRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Store(noopCtx, tWorkspace)
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   1,
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoWorkspaceRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Store(noopCtx, tWorkspace)
+
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoWorkspaceRepository_GetByID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tWorkspace := tests.NewWorkspace()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, workspaceBsonD(tWorkspace)))
+		r := repository.NewMongoWorkspaceRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetByID(noopCtx, tWorkspace.ID)
+
+		require.NoError(mt, err)
+		assert.EqualValues(mt, tWorkspace, result)
+	})
+
+	mt.Run("not exists", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch))
+		r := repository.NewMongoWorkspaceRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		result, err := r.GetByID(noopCtx, tWorkspace.ID)
+
+		assert.Nil(mt, result)
+		assert.ErrorIs(mt, err, domain.ErrNotFound)
+	})
+}
+
+func TestMongoWorkspaceRepository_ListByMember(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tWorkspace := tests.NewWorkspace()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, tableName, mtest.FirstBatch, workspaceBsonD(tWorkspace)),
+		)
+		r := repository.NewMongoWorkspaceRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		list, err := r.ListByMember(noopCtx, tWorkspace.Members[0].UserID)
+
+		require.NoError(mt, err)
+		assert.Equal(mt, []*domain.Workspace{tWorkspace}, list)
+	})
+
+	mt.Run("server error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    123,
+			Message: "server error",
+		}))
+		r := repository.NewMongoWorkspaceRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		list, err := r.ListByMember(noopCtx, tWorkspace.Members[0].UserID)
+
+		assert.Nil(mt, list)
+		assert.ErrorIs(mt, err, domain.ErrInternalServerError)
+	})
+}
+
+func TestMongoWorkspaceRepository_Update(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	tWorkspace := tests.NewWorkspace()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+		r := repository.NewMongoWorkspaceRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Update(noopCtx, tWorkspace)
+
+		require.NoError(mt, err)
+	})
+
+	mt.Run("not exists", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+		r := repository.NewMongoWorkspaceRepository(mt.Client, mt.DB.Name(), nil, tracer)
+
+		err := r.Update(noopCtx, tWorkspace)
+
+		assert.ErrorIs(mt, err, domain.ErrNoAffected)
+	})
+}
@@ -0,0 +1,369 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/semka95/shortener/backend/audit"
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/web/auth"
+)
+
+type workspaceUsecase struct {
+	workspaceRepo  domain.WorkspaceRepository
+	userRepo       domain.UserRepository
+	urlRepo        domain.URLRepository
+	auditRepo      domain.AuditRepository
+	policy         *auth.Policy
+	contextTimeout time.Duration
+	tracer         trace.Tracer
+}
+
+// NewWorkspaceUsecase will create new a workspaceUsecase object representation of workspace.Usecase interface,
+// u is used to find invited users by email and url to move URLs between workspaces,
+// policy grants permissions of roles
+func NewWorkspaceUsecase(w domain.WorkspaceRepository, u domain.UserRepository, url domain.URLRepository, a domain.AuditRepository, policy *auth.Policy, timeout time.Duration, tracer trace.Tracer) domain.WorkspaceUsecase {
+	return &workspaceUsecase{
+		workspaceRepo:  w,
+		userRepo:       u,
+		urlRepo:        url,
+		auditRepo:      a,
+		policy:         policy,
+		contextTimeout: timeout,
+		tracer:         tracer,
+	}
+}
+
+// Create creates workspace, the user who creates it becomes its owner
+func (uc *workspaceUsecase) Create(c context.Context, create domain.CreateWorkspace, claims *auth.Claims) (*domain.Workspace, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Create",
+		trace.WithAttributes(
+			attribute.String("userid", claims.Subject)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	now := time.Now().Truncate(time.Millisecond).UTC()
+	w := &domain.Workspace{
+		ID:   primitive.NewObjectID(),
+		Name: create.Name,
+		Members: []domain.WorkspaceMember{
+			{UserID: claims.Subject, Role: auth.WorkspaceOwner, AddedAt: now},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := uc.workspaceRepo.Store(ctx, w); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err := uc.record(ctx, claims, domain.AuditCreateWorkspace, nil, w, nil); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// GetByID returns workspace, only its members can see it
+func (uc *workspaceUsecase) GetByID(c context.Context, id string, claims *auth.Claims) (*domain.Workspace, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase GetByID",
+		trace.WithAttributes(
+			attribute.String("workspaceid", id)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	w, err := uc.get(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err = uc.authorize(w, claims, auth.ActionWorkspaceRead); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// List returns workspaces user is a member of
+func (uc *workspaceUsecase) List(c context.Context, claims *auth.Claims) ([]*domain.Workspace, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase List",
+		trace.WithAttributes(
+			attribute.String("userid", claims.Subject)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	list, err := uc.workspaceRepo.ListByMember(ctx, claims.Subject)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// Invite adds user with the given email to workspace or changes role of
+// existing member, only owners can invite users. Workspace can't be left
+// without owners.
+func (uc *workspaceUsecase) Invite(c context.Context, invite domain.InviteMember, claims *auth.Claims) (*domain.Workspace, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase Invite",
+		trace.WithAttributes(
+			attribute.String("workspaceid", invite.WorkspaceID)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	w, err := uc.get(ctx, invite.WorkspaceID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err = uc.authorize(w, claims, auth.ActionWorkspaceManage); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	u, err := uc.userRepo.GetByEmail(ctx, invite.Email)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	userID := u.ID.Hex()
+
+	before := copyWorkspace(w)
+	now := time.Now().Truncate(time.Millisecond).UTC()
+	if w.Role(userID) == "" {
+		w.Members = append(w.Members, domain.WorkspaceMember{UserID: userID, Role: invite.Role, AddedAt: now})
+	} else {
+		for i := range w.Members {
+			if w.Members[i].UserID == userID {
+				w.Members[i].Role = invite.Role
+			}
+		}
+	}
+
+	if w.Owners() == 0 {
+		err = fmt.Errorf("workspace must have an owner: %w", domain.ErrBadParamInput)
+		span.RecordError(err)
+		return nil, err
+	}
+	w.UpdatedAt = now
+
+	if err = uc.update(ctx, claims, before, w); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// RemoveMember removes user from workspace, owners can remove any member and
+// other members can only leave workspace themselves. The last owner can't
+// be removed.
+func (uc *workspaceUsecase) RemoveMember(c context.Context, remove domain.RemoveMember, claims *auth.Claims) (*domain.Workspace, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase RemoveMember",
+		trace.WithAttributes(
+			attribute.String("workspaceid", remove.WorkspaceID),
+			attribute.String("userid", remove.UserID)),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	w, err := uc.get(ctx, remove.WorkspaceID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	action := auth.ActionWorkspaceManage
+	if remove.UserID == claims.Subject {
+		action = auth.ActionWorkspaceRead
+	}
+	if err = uc.authorize(w, claims, action); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if w.Role(remove.UserID) == "" {
+		err = fmt.Errorf("user %s is not a member of workspace: %w", remove.UserID, domain.ErrNotFound)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	before := copyWorkspace(w)
+	members := make([]domain.WorkspaceMember, 0, len(w.Members))
+	for _, m := range w.Members {
+		if m.UserID != remove.UserID {
+			members = append(members, m)
+		}
+	}
+	w.Members = members
+
+	if w.Owners() == 0 {
+		err = fmt.Errorf("workspace must have an owner: %w", domain.ErrBadParamInput)
+		span.RecordError(err)
+		return nil, err
+	}
+	w.UpdatedAt = time.Now().Truncate(time.Millisecond).UTC()
+
+	if err = uc.update(ctx, claims, before, w); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// TransferURLs moves URLs from workspace to another one, user must be allowed
+// to delete URLs in the source workspace and to create them in the target one
+func (uc *workspaceUsecase) TransferURLs(c context.Context, transfer domain.TransferURLs, claims *auth.Claims) (*domain.TransferURLsResult, error) {
+	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
+	defer cancel()
+
+	ctx, span := uc.tracer.Start(
+		ctx,
+		"usecase TransferURLs",
+		trace.WithAttributes(
+			attribute.String("workspaceid", transfer.WorkspaceID),
+			attribute.String("to", transfer.To),
+			attribute.Int("urls", len(transfer.IDs))),
+		trace.WithSpanKind(trace.SpanKindServer),
+	)
+	defer span.End()
+
+	if transfer.WorkspaceID == transfer.To {
+		err := fmt.Errorf("URLs can't be moved to the same workspace: %w", domain.ErrBadParamInput)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	from, err := uc.get(ctx, transfer.WorkspaceID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if err = uc.authorize(from, claims, auth.ActionURLDelete); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	to, err := uc.get(ctx, transfer.To)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if err = uc.authorize(to, claims, auth.ActionURLCreate); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	moved, err := uc.urlRepo.SetWorkspace(ctx, transfer.IDs, transfer.WorkspaceID, transfer.To)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// transfer is recorded after URLs are moved, as only then moved URLs are known
+	err = uc.record(ctx, claims, domain.AuditTransferWorkspaceURLs, nil, nil, map[string]string{
+		"workspace": transfer.WorkspaceID,
+		"to":        transfer.To,
+		"urls":      strconv.Itoa(len(moved)),
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return &domain.TransferURLsResult{To: transfer.To, URLs: moved}, nil
+}
+
+func (uc *workspaceUsecase) get(ctx context.Context, id string) (*domain.Workspace, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("workspace ID is not valid ObjectID: %w: %s", domain.ErrBadParamInput, err.Error())
+	}
+
+	return uc.workspaceRepo.GetByID(ctx, objID)
+}
+
+// update records change of workspace members before it is stored
+func (uc *workspaceUsecase) update(ctx context.Context, claims *auth.Claims, before, after *domain.Workspace) error {
+	if err := uc.record(ctx, claims, domain.AuditUpdateWorkspace, before, after, nil); err != nil {
+		return err
+	}
+
+	return uc.workspaceRepo.Update(ctx, after)
+}
+
+func (uc *workspaceUsecase) record(ctx context.Context, claims *auth.Claims, action string, before, after *domain.Workspace, details map[string]string) error {
+	target := ""
+	if after != nil {
+		target = after.ID.Hex()
+	} else if before != nil {
+		target = before.ID.Hex()
+	}
+
+	e := audit.NewEntry(ctx, claims, action, target)
+	e.Details = details
+
+	var err error
+	if e.Changes, err = audit.Diff(before, after); err != nil {
+		return err
+	}
+
+	return uc.auditRepo.Store(ctx, e)
+}
+
+// authorize returns error if role of user in workspace doesn't allow action
+func (uc *workspaceUsecase) authorize(w *domain.Workspace, claims *auth.Claims, action string) error {
+	if err := uc.policy.Authorize(claims, action, &auth.Resource{WorkspaceRole: w.Role(claims.Subject)}); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrForbidden, err.Error())
+	}
+
+	return nil
+}
+
+// copyWorkspace returns copy of workspace which doesn't share members with it
+func copyWorkspace(w *domain.Workspace) *domain.Workspace {
+	c := *w
+	c.Members = append([]domain.WorkspaceMember(nil), w.Members...)
+	return &c
+}
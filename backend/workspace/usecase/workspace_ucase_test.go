@@ -0,0 +1,299 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	auditMock "github.com/semka95/shortener/backend/audit/mock"
+	"github.com/semka95/shortener/backend/domain"
+	"github.com/semka95/shortener/backend/tests"
+	urlMock "github.com/semka95/shortener/backend/url/mock"
+	userMock "github.com/semka95/shortener/backend/user/mock"
+	"github.com/semka95/shortener/backend/web/auth"
+	"github.com/semka95/shortener/backend/workspace/mock"
+	"github.com/semka95/shortener/backend/workspace/usecase"
+)
+
+var tracer = sdktrace.NewTracerProvider().Tracer("")
+
+const (
+	editorID = "507f191e810c19729de860eb"
+	viewerID = "507f191e810c19729de860ec"
+)
+
+// newWorkspace returns workspace with owner of tests.NewWorkspace, an editor and a viewer
+func newWorkspace() *domain.Workspace {
+	w := tests.NewWorkspace()
+	w.Members = append(w.Members,
+		domain.WorkspaceMember{UserID: editorID, Role: auth.WorkspaceEditor},
+		domain.WorkspaceMember{UserID: viewerID, Role: auth.WorkspaceViewer},
+	)
+	return w
+}
+
+func newClaims(subject string) *auth.Claims {
+	return auth.NewClaims(subject, []string{auth.RoleUser}, time.Now(), time.Minute)
+}
+
+func TestWorkspaceUsecase_Create(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repository := mock.NewMockWorkspaceRepository(controller)
+	auditRepository := auditMock.NewMockAuditRepository(controller)
+	uc := usecase.NewWorkspaceUsecase(repository, userMock.NewMockUserRepository(controller), urlMock.NewMockURLRepository(controller), auditRepository, auth.DefaultPolicy(), 10*time.Second, tracer)
+	claims := newClaims(editorID)
+
+	t.Run("success", func(t *testing.T) {
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		auditRepository.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.AuditEntry) error {
+			assert.Equal(t, domain.AuditCreateWorkspace, e.Action)
+			assert.Equal(t, editorID, e.Actor)
+			return nil
+		})
+
+		w, err := uc.Create(context.Background(), domain.CreateWorkspace{Name: "marketing"}, claims)
+		require.NoError(t, err)
+		assert.Equal(t, "marketing", w.Name)
+		assert.Equal(t, auth.WorkspaceOwner, w.Role(editorID))
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		repository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(domain.ErrInternalServerError)
+
+		w, err := uc.Create(context.Background(), domain.CreateWorkspace{Name: "marketing"}, claims)
+		assert.ErrorIs(t, err, domain.ErrInternalServerError)
+		assert.Nil(t, w)
+	})
+}
+
+func TestWorkspaceUsecase_GetByID(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tWorkspace := newWorkspace()
+	repository := mock.NewMockWorkspaceRepository(controller)
+	uc := usecase.NewWorkspaceUsecase(repository, userMock.NewMockUserRepository(controller), urlMock.NewMockURLRepository(controller), auditMock.NewMockAuditRepository(controller), auth.DefaultPolicy(), 10*time.Second, tracer)
+
+	t.Run("member", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+
+		w, err := uc.GetByID(context.Background(), tWorkspace.ID.Hex(), newClaims(viewerID))
+		require.NoError(t, err)
+		assert.Equal(t, tWorkspace, w)
+	})
+
+	t.Run("not a member", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+
+		w, err := uc.GetByID(context.Background(), tWorkspace.ID.Hex(), newClaims("507f191e810c19729de860ed"))
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, w)
+	})
+
+	t.Run("id is not valid", func(t *testing.T) {
+		_, err := uc.GetByID(context.Background(), "wrong id", newClaims(viewerID))
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	})
+}
+
+func TestWorkspaceUsecase_Invite(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	tUser := tests.NewUser()
+	tUser.ID, _ = primitive.ObjectIDFromHex("507f191e810c19729de860ed")
+	repository := mock.NewMockWorkspaceRepository(controller)
+	userRepository := userMock.NewMockUserRepository(controller)
+	auditRepository := auditMock.NewMockAuditRepository(controller)
+	uc := usecase.NewWorkspaceUsecase(repository, userRepository, urlMock.NewMockURLRepository(controller), auditRepository, auth.DefaultPolicy(), 10*time.Second, tracer)
+	owner := newClaims(tests.NewWorkspace().Members[0].UserID)
+
+	t.Run("new member", func(t *testing.T) {
+		tWorkspace := newWorkspace()
+		repository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+		userRepository.EXPECT().GetByEmail(gomock.Any(), tUser.Email).Return(tUser, nil)
+		auditRepository.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.AuditEntry) error {
+			assert.Equal(t, domain.AuditUpdateWorkspace, e.Action)
+			assert.Contains(t, e.Changes, "members")
+			return nil
+		})
+		repository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+		invite := domain.InviteMember{WorkspaceID: tWorkspace.ID.Hex(), Email: tUser.Email, Role: auth.WorkspaceEditor}
+		w, err := uc.Invite(context.Background(), invite, owner)
+		require.NoError(t, err)
+		assert.Equal(t, auth.WorkspaceEditor, w.Role(tUser.ID.Hex()))
+		assert.Len(t, w.Members, 4)
+	})
+
+	t.Run("role of existing member is changed", func(t *testing.T) {
+		tWorkspace := newWorkspace()
+		viewer := tests.NewUser()
+		viewer.ID, _ = primitive.ObjectIDFromHex(viewerID)
+		repository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+		userRepository.EXPECT().GetByEmail(gomock.Any(), viewer.Email).Return(viewer, nil)
+		auditRepository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		repository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+		invite := domain.InviteMember{WorkspaceID: tWorkspace.ID.Hex(), Email: viewer.Email, Role: auth.WorkspaceOwner}
+		w, err := uc.Invite(context.Background(), invite, owner)
+		require.NoError(t, err)
+		assert.Equal(t, auth.WorkspaceOwner, w.Role(viewerID))
+		assert.Len(t, w.Members, 3)
+	})
+
+	t.Run("the last owner can't be demoted", func(t *testing.T) {
+		tWorkspace := newWorkspace()
+		ownerUser := tests.NewUser()
+		repository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+		userRepository.EXPECT().GetByEmail(gomock.Any(), ownerUser.Email).Return(ownerUser, nil)
+
+		invite := domain.InviteMember{WorkspaceID: tWorkspace.ID.Hex(), Email: ownerUser.Email, Role: auth.WorkspaceViewer}
+		_, err := uc.Invite(context.Background(), invite, owner)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	})
+
+	t.Run("editor can't invite", func(t *testing.T) {
+		tWorkspace := newWorkspace()
+		repository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+
+		invite := domain.InviteMember{WorkspaceID: tWorkspace.ID.Hex(), Email: tUser.Email, Role: auth.WorkspaceViewer}
+		_, err := uc.Invite(context.Background(), invite, newClaims(editorID))
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		tWorkspace := newWorkspace()
+		repository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+		userRepository.EXPECT().GetByEmail(gomock.Any(), "nobody@example.com").Return(nil, domain.ErrNotFound)
+
+		invite := domain.InviteMember{WorkspaceID: tWorkspace.ID.Hex(), Email: "nobody@example.com", Role: auth.WorkspaceViewer}
+		_, err := uc.Invite(context.Background(), invite, owner)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func TestWorkspaceUsecase_RemoveMember(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repository := mock.NewMockWorkspaceRepository(controller)
+	auditRepository := auditMock.NewMockAuditRepository(controller)
+	uc := usecase.NewWorkspaceUsecase(repository, userMock.NewMockUserRepository(controller), urlMock.NewMockURLRepository(controller), auditRepository, auth.DefaultPolicy(), 10*time.Second, tracer)
+	ownerID := tests.NewWorkspace().Members[0].UserID
+
+	t.Run("removed by owner", func(t *testing.T) {
+		tWorkspace := newWorkspace()
+		repository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+		auditRepository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		repository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+		remove := domain.RemoveMember{WorkspaceID: tWorkspace.ID.Hex(), UserID: editorID}
+		w, err := uc.RemoveMember(context.Background(), remove, newClaims(ownerID))
+		require.NoError(t, err)
+		assert.Empty(t, w.Role(editorID))
+		assert.Len(t, w.Members, 2)
+	})
+
+	t.Run("member leaves", func(t *testing.T) {
+		tWorkspace := newWorkspace()
+		repository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+		auditRepository.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		repository.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+		remove := domain.RemoveMember{WorkspaceID: tWorkspace.ID.Hex(), UserID: viewerID}
+		_, err := uc.RemoveMember(context.Background(), remove, newClaims(viewerID))
+		require.NoError(t, err)
+	})
+
+	t.Run("editor can't remove others", func(t *testing.T) {
+		tWorkspace := newWorkspace()
+		repository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+
+		remove := domain.RemoveMember{WorkspaceID: tWorkspace.ID.Hex(), UserID: viewerID}
+		_, err := uc.RemoveMember(context.Background(), remove, newClaims(editorID))
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("the last owner can't leave", func(t *testing.T) {
+		tWorkspace := newWorkspace()
+		repository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+
+		remove := domain.RemoveMember{WorkspaceID: tWorkspace.ID.Hex(), UserID: ownerID}
+		_, err := uc.RemoveMember(context.Background(), remove, newClaims(ownerID))
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	})
+
+	t.Run("not a member", func(t *testing.T) {
+		tWorkspace := newWorkspace()
+		repository.EXPECT().GetByID(gomock.Any(), tWorkspace.ID).Return(tWorkspace, nil)
+
+		remove := domain.RemoveMember{WorkspaceID: tWorkspace.ID.Hex(), UserID: "507f191e810c19729de860ed"}
+		_, err := uc.RemoveMember(context.Background(), remove, newClaims(ownerID))
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func TestWorkspaceUsecase_TransferURLs(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	repository := mock.NewMockWorkspaceRepository(controller)
+	urlRepository := urlMock.NewMockURLRepository(controller)
+	auditRepository := auditMock.NewMockAuditRepository(controller)
+	uc := usecase.NewWorkspaceUsecase(repository, userMock.NewMockUserRepository(controller), urlRepository, auditRepository, auth.DefaultPolicy(), 10*time.Second, tracer)
+
+	from := newWorkspace()
+	to := newWorkspace()
+	to.ID = primitive.NewObjectID()
+	tURL := tests.NewURL()
+	transfer := domain.TransferURLs{WorkspaceID: from.ID.Hex(), To: to.ID.Hex(), IDs: []string{tURL.ID, "missing"}}
+
+	t.Run("success", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), from.ID).Return(from, nil)
+		repository.EXPECT().GetByID(gomock.Any(), to.ID).Return(to, nil)
+		urlRepository.EXPECT().SetWorkspace(gomock.Any(), transfer.IDs, from.ID.Hex(), to.ID.Hex()).Return([]string{tURL.ID}, nil)
+		auditRepository.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *domain.AuditEntry) error {
+			assert.Equal(t, domain.AuditTransferWorkspaceURLs, e.Action)
+			assert.Equal(t, "1", e.Details["urls"])
+			return nil
+		})
+
+		result, err := uc.TransferURLs(context.Background(), transfer, newClaims(editorID))
+		require.NoError(t, err)
+		assert.Equal(t, []string{tURL.ID}, result.URLs)
+	})
+
+	t.Run("viewer of source workspace", func(t *testing.T) {
+		repository.EXPECT().GetByID(gomock.Any(), from.ID).Return(from, nil)
+
+		_, err := uc.TransferURLs(context.Background(), transfer, newClaims(viewerID))
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("not a member of target workspace", func(t *testing.T) {
+		other := tests.NewWorkspace()
+		other.ID = to.ID
+		repository.EXPECT().GetByID(gomock.Any(), from.ID).Return(from, nil)
+		repository.EXPECT().GetByID(gomock.Any(), to.ID).Return(other, nil)
+
+		_, err := uc.TransferURLs(context.Background(), transfer, newClaims(editorID))
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("same workspace", func(t *testing.T) {
+		same := transfer
+		same.To = same.WorkspaceID
+
+		_, err := uc.TransferURLs(context.Background(), same, newClaims(editorID))
+		assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	})
+}